@@ -34,6 +34,7 @@ import (
 	"github.com/offchainlabs/nitro/broadcastclient"
 	"github.com/offchainlabs/nitro/broadcastclients"
 	"github.com/offchainlabs/nitro/broadcaster"
+	m "github.com/offchainlabs/nitro/broadcaster/message"
 	"github.com/offchainlabs/nitro/cmd/chaininfo"
 	"github.com/offchainlabs/nitro/das"
 	"github.com/offchainlabs/nitro/execution"
@@ -502,6 +503,45 @@ func getMaintenanceRunner(
 	return maintenanceRunner, nil
 }
 
+// connectExpressLaneControllerFeed has the sequencer broadcast the express lane auction results and control transfers
+// it sees on the parent chain, and has the controller tracker of nodes following the feed apply them and the
+// transaction streamer verify the timeboosted transactions of feed messages against it
+func connectExpressLaneControllerFeed(
+	execNode *gethexec.ExecutionNode,
+	txStreamer *TransactionStreamer,
+	broadcastServer *broadcaster.Broadcaster,
+	broadcastClients *broadcastclients.BroadcastClients,
+) {
+	if broadcastServer != nil && execNode.Sequencer != nil {
+		execNode.Sequencer.SetAuctionResultListener(func(round uint64, winner common.Address, firstPrice, secondPrice *big.Int) {
+			err := broadcastServer.BroadcastAuctionResult(&m.AuctionResultMessage{
+				Round:       round,
+				Winner:      winner,
+				FirstPrice:  firstPrice,
+				SecondPrice: secondPrice,
+			})
+			if err != nil {
+				log.Error("Failed broadcasting express lane auction result", "round", round, "err", err)
+			}
+		})
+		execNode.Sequencer.SetControllerTransferListener(func(round uint64, previous, controller common.Address) {
+			err := broadcastServer.BroadcastControllerTransfer(&m.ControllerTransferMessage{
+				Round:              round,
+				PreviousController: previous,
+				NewController:      controller,
+			})
+			if err != nil {
+				log.Error("Failed broadcasting express lane control transfer", "round", round, "err", err)
+			}
+		})
+	}
+	if broadcastClients != nil {
+		broadcastClients.SetAuctionResultListener(execNode.ExpressLaneControllerTracker.AuctionResultListener())
+		broadcastClients.SetControllerTransferListener(execNode.ExpressLaneControllerTracker.ControllerTransferListener())
+		txStreamer.SetExpressLaneRoundTracker(&feedExpressLaneRounds{execNode: execNode, since: time.Now()})
+	}
+}
+
 func getBroadcastClients(
 	config *Config,
 	configFetcher ConfigFetcher,
@@ -1068,6 +1108,10 @@ func createNodeImpl(
 		return nil, err
 	}
 
+	if execNode, ok := executionClient.(*gethexec.ExecutionNode); ok {
+		connectExpressLaneControllerFeed(execNode, txStreamer, broadcastServer, broadcastClients)
+	}
+
 	if !config.ParentChainReader.Enable {
 		return getNodeParentChainReaderDisabled(ctx, arbDb, stack, executionClient, executionSequencer, executionRecorder, txStreamer, blobReader, broadcastServer, broadcastClients, coordinator, maintenanceRunner, syncMonitor, configFetcher, blockMetadataFetcher), nil
 	}
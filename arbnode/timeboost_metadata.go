@@ -0,0 +1,92 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/execution/gethexec"
+)
+
+var timeboostMetadataDivergenceCounter = metrics.NewRegisteredCounter("arb/feed/timeboost/metadata/divergence", nil)
+
+// ExpressLaneRoundTracker provides the timeboost rounds and the express lane controller resolved for each of them by
+// the auction contract. RoundAt returns false while the round timing isn't known yet
+type ExpressLaneRoundTracker interface {
+	RoundAt(timestamp time.Time) (uint64, bool)
+	RoundController(round uint64) (common.Address, bool)
+}
+
+// feedExpressLaneRounds tracks the express lane rounds by the round timing of the auction contract, and their
+// controllers by the auction results and control transfers announced on the feed. As the feed doesn't replay auction
+// results to clients connecting later, only the rounds auctioned after the node started following the feed are known.
+type feedExpressLaneRounds struct {
+	execNode *gethexec.ExecutionNode
+	since    time.Time
+}
+
+func (r *feedExpressLaneRounds) RoundAt(timestamp time.Time) (uint64, bool) {
+	roundTimingInfo, err := r.execNode.ExpressLaneRoundTimingInfo()
+	if err != nil {
+		log.Debug("Express lane round timing not available", "err", err)
+		return 0, false
+	}
+	round := roundTimingInfo.RoundNumberAt(timestamp)
+	// The auction of a round is resolved during the round before it
+	if round <= roundTimingInfo.RoundNumberAt(r.since)+1 {
+		return round, false
+	}
+	return round, true
+}
+
+func (r *feedExpressLaneRounds) RoundController(round uint64) (common.Address, bool) {
+	return r.execNode.ExpressLaneControllerTracker.Controller(round)
+}
+
+// verifyTimeboostMetadata re-derives which transactions of a message received from the feed may be timeboosted and
+// logs a TimeboostMetadataDivergence when its block metadata disagrees. Only what's derivable without the express
+// lane submissions is checked: the internal start block transaction is never timeboosted, and no transaction is
+// timeboosted in a round without an express lane controller. Returns false on divergence
+func verifyTimeboostMetadata(rounds ExpressLaneRoundTracker, pos arbutil.MessageIndex, msg *arbostypes.MessageWithMetadata, blockMetadata common.BlockMetadata) bool {
+	if len(blockMetadata) <= 1 || msg.Message == nil || msg.Message.Header == nil {
+		return true
+	}
+	var timeboosted []int
+	for txIndex := 0; txIndex < (len(blockMetadata)-1)*8; txIndex++ {
+		isTimeboosted, err := blockMetadata.IsTxTimeboosted(txIndex)
+		if err != nil {
+			log.Warn("Failed reading timeboosted transactions from feed block metadata", "pos", pos, "err", err)
+			return true
+		}
+		if isTimeboosted {
+			timeboosted = append(timeboosted, txIndex)
+		}
+	}
+	if len(timeboosted) == 0 {
+		return true
+	}
+	// #nosec G115
+	round, ok := rounds.RoundAt(time.Unix(int64(msg.Message.Header.Timestamp), 0))
+	if !ok {
+		return true
+	}
+	controller, hasController := rounds.RoundController(round)
+	var reason string
+	if timeboosted[0] == 0 {
+		reason = "start block transaction marked as timeboosted"
+	} else if !hasController {
+		reason = "transactions timeboosted in a round without express lane controller"
+	} else {
+		return true
+	}
+	timeboostMetadataDivergenceCounter.Inc(1)
+	log.Error("TimeboostMetadataDivergence", "pos", pos, "reason", reason, "round", round, "controller", controller, "timeboostedTxs", timeboosted)
+	return false
+}
@@ -69,6 +69,7 @@ type TransactionStreamer struct {
 	delayedBridge   *DelayedBridge
 
 	trackBlockMetadataFrom arbutil.MessageIndex
+	expressLaneRounds      ExpressLaneRoundTracker
 }
 
 type TransactionStreamerConfig struct {
@@ -76,6 +77,7 @@ type TransactionStreamerConfig struct {
 	MaxReorgResequenceDepth int64         `koanf:"max-reorg-resequence-depth" reload:"hot"`
 	ExecuteMessageLoopDelay time.Duration `koanf:"execute-message-loop-delay" reload:"hot"`
 	TrackBlockMetadataFrom  uint64        `koanf:"track-block-metadata-from"`
+	VerifyTimeboostMetadata bool          `koanf:"verify-timeboost-metadata" reload:"hot"`
 }
 
 type TransactionStreamerConfigFetcher func() *TransactionStreamerConfig
@@ -99,6 +101,7 @@ func TransactionStreamerConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Int64(prefix+".max-reorg-resequence-depth", DefaultTransactionStreamerConfig.MaxReorgResequenceDepth, "maximum number of messages to attempt to resequence on reorg (0 = never resequence, -1 = always resequence)")
 	f.Duration(prefix+".execute-message-loop-delay", DefaultTransactionStreamerConfig.ExecuteMessageLoopDelay, "delay when polling calls to execute messages")
 	f.Uint64(prefix+".track-block-metadata-from", DefaultTransactionStreamerConfig.TrackBlockMetadataFrom, "this is the block number starting from which blockmetadata is being tracked in the local disk and is being published to the feed. This is also the starting position for bulk syncing of missing blockmetadata. Setting to zero (default value) disables this")
+	f.Bool(prefix+".verify-timeboost-metadata", DefaultTransactionStreamerConfig.VerifyTimeboostMetadata, "re-derive which transactions of feed messages may be timeboosted from the express lane controllers announced on the feed and the round timing of the configured auction contract, and log a TimeboostMetadataDivergence when the received blockmetadata disagrees")
 }
 
 func NewTransactionStreamer(
@@ -175,6 +178,15 @@ func (s *TransactionStreamer) SetSeqCoordinator(coordinator *SeqCoordinator) {
 	s.coordinator = coordinator
 }
 
+// SetExpressLaneRoundTracker sets the source of express lane controllers the blockMetadata of feed messages is verified
+// against when verify-timeboost-metadata is enabled
+func (s *TransactionStreamer) SetExpressLaneRoundTracker(rounds ExpressLaneRoundTracker) {
+	if s.Started() {
+		panic("trying to set express lane round tracker after start")
+	}
+	s.expressLaneRounds = rounds
+}
+
 func (s *TransactionStreamer) SetInboxReaders(inboxReader *InboxReader, delayedBridge *DelayedBridge) {
 	if s.Started() {
 		panic("trying to set inbox reader after start")
@@ -568,6 +580,9 @@ func (s *TransactionStreamer) AddBroadcastMessages(feedMessages []*m.BroadcastFe
 		if feedMessage.Message.Message == nil || feedMessage.Message.Message.Header == nil {
 			return fmt.Errorf("invalid feed message at sequence number %v", feedMessage.SequenceNumber)
 		}
+		if s.config().VerifyTimeboostMetadata && s.expressLaneRounds != nil {
+			verifyTimeboostMetadata(s.expressLaneRounds, feedMessage.SequenceNumber, &feedMessage.Message, feedMessage.BlockMetadata)
+		}
 		msgWithBlockInfo := arbostypes.MessageWithMetadataAndBlockInfo{
 			MessageWithMeta: feedMessage.Message,
 			BlockHash:       feedMessage.BlockHash,
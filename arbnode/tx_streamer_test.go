@@ -4,12 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/binary"
+	"log/slog"
 	"testing"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/rlp"
 
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
 	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/util/testhelpers"
 )
 
 func TestTimeboostBackfillingsTrackersForMissingBlockMetadata(t *testing.T) {
@@ -65,3 +70,62 @@ func TestTimeboostBackfillingsTrackersForMissingBlockMetadata(t *testing.T) {
 	// Backfill trackers for missing data and verify that 5, 6, 7, 8, 9 get added to already existing 10, 11, 16, 17, 18, 19 keys
 	backfillAndVerifyCorrectness(5, []uint64{5, 6, 7, 8, 9, 10, 11, 15, 16, 17, 19})
 }
+
+// testExpressLaneRounds has minute long rounds, with controllers set for some of them
+type testExpressLaneRounds struct {
+	controllers   map[uint64]common.Address
+	timingUnknown bool
+}
+
+func (r *testExpressLaneRounds) RoundAt(timestamp time.Time) (uint64, bool) {
+	// #nosec G115
+	return uint64(timestamp.Unix() / 60), !r.timingUnknown
+}
+
+func (r *testExpressLaneRounds) RoundController(round uint64) (common.Address, bool) {
+	controller, ok := r.controllers[round]
+	return controller, ok
+}
+
+func TestVerifyTimeboostMetadata(t *testing.T) {
+	logHandler := testhelpers.InitTestLog(t, slog.LevelError)
+	rounds := &testExpressLaneRounds{controllers: map[uint64]common.Address{1: common.HexToAddress("0xc0")}}
+	message := func(timestamp uint64) *arbostypes.MessageWithMetadata {
+		return &arbostypes.MessageWithMetadata{
+			Message: &arbostypes.L1IncomingMessage{
+				Header: &arbostypes.L1IncomingMessageHeader{Kind: arbostypes.L1MessageType_L2Message, Timestamp: timestamp},
+			},
+		}
+	}
+	// The second transaction is timeboosted
+	timeboostedMetadata := common.BlockMetadata{0, 0b00000010}
+
+	if !verifyTimeboostMetadata(rounds, 1, message(90), timeboostedMetadata) {
+		t.Fatal("timeboosted transaction in a round with a controller reported as divergent")
+	}
+	if !verifyTimeboostMetadata(rounds, 2, message(150), common.BlockMetadata{0, 0}) {
+		t.Fatal("metadata without timeboosted transactions reported as divergent")
+	}
+	if logHandler.WasLogged("TimeboostMetadataDivergence") {
+		t.Fatal("unexpected TimeboostMetadataDivergence logged")
+	}
+
+	// There's no express lane controller in round 2
+	if verifyTimeboostMetadata(rounds, 3, message(150), timeboostedMetadata) {
+		t.Fatal("timeboosted transaction in a round without a controller not reported as divergent")
+	}
+	if !logHandler.WasLogged("TimeboostMetadataDivergence") {
+		t.Fatal("expected TimeboostMetadataDivergence to be logged")
+	}
+
+	// The start block transaction can't be timeboosted
+	if verifyTimeboostMetadata(rounds, 4, message(90), common.BlockMetadata{0, 0b00000001}) {
+		t.Fatal("timeboosted start block transaction not reported as divergent")
+	}
+
+	// Nothing can be verified before the round timing is known
+	rounds.timingUnknown = true
+	if !verifyTimeboostMetadata(rounds, 5, message(150), timeboostedMetadata) {
+		t.Fatal("metadata reported as divergent without known round timing")
+	}
+}
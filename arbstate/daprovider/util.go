@@ -149,18 +149,15 @@ func RecoverPayloadFromDasBatch(
 	preimageRecorder PreimageRecorder,
 	validateSeqMsg bool,
 ) ([]byte, error) {
-	cert, err := DeserializeDASCertFrom(bytes.NewReader(sequencerMsg[40:]))
-	if err != nil {
-		log.Error("Failed to deserialize DAS message", "err", err)
+	cert, err := checkDasCertificate(ctx, batchNum, sequencerMsg, keysetFetcher, preimageRecorder, validateSeqMsg)
+	if errors.Is(err, errInvalidDasCertificate) {
+		log.Error("Invalid DAS certificate", "err", err)
 		return nil, nil
+	} else if err != nil {
+		return nil, err
 	}
 	version := cert.Version
 
-	if version >= 2 {
-		log.Error("Your node software is probably out of date", "certificateVersion", version)
-		return nil, nil
-	}
-
 	getByHash := func(ctx context.Context, hash common.Hash) ([]byte, error) {
 		newHash := hash
 		if version == 0 {
@@ -189,6 +186,53 @@ func RecoverPayloadFromDasBatch(
 		return preimage, nil
 	}
 
+	dataHash := cert.DataHash
+	payload, err := getByHash(ctx, dataHash)
+	if err != nil {
+		log.Error("Couldn't fetch DAS batch contents", "err", err)
+		return nil, err
+	}
+
+	if preimageRecorder != nil {
+		if version == 0 {
+			treeLeaf := dastree.FlatHashToTreeLeaf(dataHash)
+			preimageRecorder(dataHash, payload, arbutil.Keccak256PreimageType)
+			preimageRecorder(crypto.Keccak256Hash(treeLeaf), treeLeaf, arbutil.Keccak256PreimageType)
+		} else {
+			dastree.RecordHash(preimageRecorder, payload)
+		}
+	}
+
+	return payload, nil
+}
+
+// errInvalidDasCertificate is returned by checkDasCertificate for a certificate that makes the batch invalid,
+// as opposed to one whose keyset couldn't be fetched.
+var errInvalidDasCertificate = errors.New("invalid DAS certificate")
+
+// checkDasCertificate deserializes the DAS certificate of a batch's sequencer message and checks it before the batch
+// data is fetched: the certificate must be of a supported version, be signed by enough members of its keyset and not
+// expire too soon after the batch's max timestamp.
+func checkDasCertificate(
+	ctx context.Context,
+	batchNum uint64,
+	sequencerMsg []byte,
+	keysetFetcher DASKeysetFetcher,
+	preimageRecorder PreimageRecorder,
+	validateSeqMsg bool,
+) (*DataAvailabilityCertificate, error) {
+	if len(sequencerMsg) <= 40 || !IsDASMessageHeaderByte(sequencerMsg[40]) {
+		return nil, fmt.Errorf("%w: batch %d has no DAS message header", errInvalidDasCertificate, batchNum)
+	}
+	cert, err := DeserializeDASCertFrom(bytes.NewReader(sequencerMsg[40:]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to deserialize DAS message of batch %d: %w", errInvalidDasCertificate, batchNum, err)
+	}
+	if cert.Version >= 2 {
+		log.Error("Your node software is probably out of date", "certificateVersion", cert.Version)
+		return nil, fmt.Errorf("%w: unsupported certificate version %d of batch %d", errInvalidDasCertificate, cert.Version, batchNum)
+	}
+
 	keysetPreimage, err := keysetFetcher.GetKeysetByHash(ctx, cert.KeysetHash)
 	if err != nil {
 		log.Error("Couldn't get keyset", "err", err, "keysetHash", common.Bytes2Hex(cert.KeysetHash[:]))
@@ -204,34 +248,38 @@ func RecoverPayloadFromDasBatch(
 	}
 	err = keyset.VerifySignature(cert.SignersMask, cert.SerializeSignableFields(), cert.Sig)
 	if err != nil {
-		log.Error("Bad signature on DAS batch", "err", err)
-		return nil, nil
+		return nil, fmt.Errorf("%w: %w. Bad signature on DAS certificate, err: %w, keyset hash: %x batch num: %d", errInvalidDasCertificate, ErrSeqMsgValidation, err, cert.KeysetHash, batchNum)
 	}
 
 	maxTimestamp := binary.BigEndian.Uint64(sequencerMsg[8:16])
 	if cert.Timeout < maxTimestamp+MinLifetimeSecondsForDataAvailabilityCert {
-		log.Error("Data availability cert expires too soon", "err", "")
-		return nil, nil
+		return nil, fmt.Errorf("%w: certificate of batch %d expires too soon, timeout %d max timestamp %d", errInvalidDasCertificate, batchNum, cert.Timeout, maxTimestamp)
 	}
+	return cert, nil
+}
 
-	dataHash := cert.DataHash
-	payload, err := getByHash(ctx, dataHash)
+// hashCheckingKeysetFetcher rejects keysets not matching the hash they were fetched by.
+type hashCheckingKeysetFetcher struct {
+	DASKeysetFetcher
+}
+
+func (f hashCheckingKeysetFetcher) GetKeysetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	keyset, err := f.DASKeysetFetcher.GetKeysetByHash(ctx, hash)
 	if err != nil {
-		log.Error("Couldn't fetch DAS batch contents", "err", err)
 		return nil, err
 	}
-
-	if preimageRecorder != nil {
-		if version == 0 {
-			treeLeaf := dastree.FlatHashToTreeLeaf(dataHash)
-			preimageRecorder(dataHash, payload, arbutil.Keccak256PreimageType)
-			preimageRecorder(crypto.Keccak256Hash(treeLeaf), treeLeaf, arbutil.Keccak256PreimageType)
-		} else {
-			dastree.RecordHash(preimageRecorder, payload)
-		}
+	if !dastree.ValidHash(hash, keyset) {
+		return nil, fmt.Errorf("%w: keyset %x", ErrHashMismatch, hash)
 	}
+	return keyset, nil
+}
 
-	return payload, nil
+// ValidateBatchKeyset checks the DAS certificate in a batch's sequencer message the way RecoverPayloadFromDasBatch does
+// before fetching the batch data, additionally checking that the fetched keyset matches the certificate's keyset hash,
+// so that a batch's keyset can be checked on its own, e.g. when debugging a keyset rotation.
+func ValidateBatchKeyset(ctx context.Context, batchNum uint64, sequencerMsg []byte, keysetFetcher DASKeysetFetcher) error {
+	_, err := checkDasCertificate(ctx, batchNum, sequencerMsg, hashCheckingKeysetFetcher{keysetFetcher}, nil, true)
+	return err
 }
 
 type DataAvailabilityCertificate struct {
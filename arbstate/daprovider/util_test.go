@@ -0,0 +1,105 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package daprovider
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/blsSignatures"
+	"github.com/offchainlabs/nitro/das/dastree"
+)
+
+type testKeysetFetcher map[common.Hash][]byte
+
+func (f testKeysetFetcher) GetKeysetByHash(_ context.Context, hash common.Hash) ([]byte, error) {
+	keyset, ok := f[hash]
+	if !ok {
+		return nil, errors.New("keyset not found")
+	}
+	return keyset, nil
+}
+
+func TestValidateBatchKeyset(t *testing.T) {
+	ctx := context.Background()
+	signerPubKey, signerPrivKey, err := blsSignatures.GenerateKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPubKey, _, err := blsSignatures.GenerateKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetcher := testKeysetFetcher{}
+	addKeyset := func(keyset *DataAvailabilityKeyset) common.Hash {
+		buf := new(bytes.Buffer)
+		if err := keyset.Serialize(buf); err != nil {
+			t.Fatal(err)
+		}
+		hash := dastree.Hash(buf.Bytes())
+		fetcher[hash] = buf.Bytes()
+		return hash
+	}
+	sequencerMsgOfVersion := func(keysetHash common.Hash, version uint8) []byte {
+		cert := &DataAvailabilityCertificate{
+			KeysetHash:  keysetHash,
+			DataHash:    dastree.Hash([]byte("batch data")),
+			Timeout:     1 << 40,
+			SignersMask: 1,
+			Version:     version,
+		}
+		cert.Sig, err = blsSignatures.SignMessage(signerPrivKey, cert.SerializeSignableFields())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return append(make([]byte, 40), Serialize(cert)...)
+	}
+	sequencerMsg := func(keysetHash common.Hash) []byte {
+		return sequencerMsgOfVersion(keysetHash, 1)
+	}
+
+	validKeyset := addKeyset(&DataAvailabilityKeyset{AssumedHonest: 1, PubKeys: []blsSignatures.PublicKey{signerPubKey}})
+	if err := ValidateBatchKeyset(ctx, 1, sequencerMsg(validKeyset), fetcher); err != nil {
+		t.Fatal("valid keyset rejected:", err)
+	}
+
+	// The certificate wasn't signed by the members of the keyset
+	otherKeyset := addKeyset(&DataAvailabilityKeyset{AssumedHonest: 1, PubKeys: []blsSignatures.PublicKey{otherPubKey}})
+	if err := ValidateBatchKeyset(ctx, 2, sequencerMsg(otherKeyset), fetcher); !errors.Is(err, ErrSeqMsgValidation) {
+		t.Fatal("expected keyset whose members didn't sign to be rejected, got:", err)
+	}
+
+	// The keyset can't be deserialized
+	malformedKeyset := dastree.Hash([]byte{1, 2, 3})
+	fetcher[malformedKeyset] = []byte{1, 2, 3}
+	if err := ValidateBatchKeyset(ctx, 3, sequencerMsg(malformedKeyset), fetcher); !errors.Is(err, ErrSeqMsgValidation) {
+		t.Fatal("expected malformed keyset to be rejected, got:", err)
+	}
+
+	// The fetched keyset doesn't match the certificate's keyset hash
+	fetcher[otherKeyset] = fetcher[validKeyset]
+	if err := ValidateBatchKeyset(ctx, 4, sequencerMsg(otherKeyset), fetcher); !errors.Is(err, ErrHashMismatch) {
+		t.Fatal("expected keyset not matching its hash to be rejected, got:", err)
+	}
+
+	// The certificate is rejected the way RecoverPayloadFromDasBatch rejects it
+	if err := ValidateBatchKeyset(ctx, 5, sequencerMsgOfVersion(validKeyset, 2), fetcher); !errors.Is(err, errInvalidDasCertificate) {
+		t.Fatal("expected certificate of unsupported version to be rejected, got:", err)
+	}
+	expiring := sequencerMsg(validKeyset)
+	binary.BigEndian.PutUint64(expiring[8:16], 1<<40)
+	if err := ValidateBatchKeyset(ctx, 6, expiring, fetcher); !errors.Is(err, errInvalidDasCertificate) {
+		t.Fatal("expected certificate expiring too soon to be rejected, got:", err)
+	}
+	notDas := sequencerMsg(validKeyset)
+	notDas[40] = 0
+	if err := ValidateBatchKeyset(ctx, 7, notDas, fetcher); !errors.Is(err, errInvalidDasCertificate) {
+		t.Fatal("expected message without DAS header to be rejected, got:", err)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/gobwas/ws/wsflate"
 	flag "github.com/spf13/pflag"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 
@@ -140,6 +141,8 @@ type BroadcastClient struct {
 	retrying                        bool
 	shuttingDown                    bool
 	confirmedSequenceNumberListener chan arbutil.MessageIndex
+	auctionResultListener           chan<- *m.AuctionResultMessage
+	controllerTransferListener      chan<- *m.ControllerTransferMessage
 	txStreamer                      TransactionStreamerInterface
 	fatalErrChan                    chan error
 	adjustCount                     func(int32)
@@ -178,6 +181,18 @@ func NewBroadcastClient(
 	}, err
 }
 
+// SetAuctionResultListener sets the channel express lane auction results received from the feed are sent to,
+// must be called before Start
+func (bc *BroadcastClient) SetAuctionResultListener(listener chan<- *m.AuctionResultMessage) {
+	bc.auctionResultListener = listener
+}
+
+// SetControllerTransferListener sets the channel express lane control transfers received from the feed are sent to,
+// must be called before Start
+func (bc *BroadcastClient) SetControllerTransferListener(listener chan<- *m.ControllerTransferMessage) {
+	bc.controllerTransferListener = listener
+}
+
 func (bc *BroadcastClient) Start(ctxIn context.Context) {
 	bc.StopWaiter.Start(ctxIn, bc)
 	if bc.StopWaiter.Stopped() {
@@ -434,6 +449,10 @@ func (bc *BroadcastClient) startBackgroundReader(earlyFrameData io.Reader) {
 					log.Debug("received batch item", "count", len(res.Messages), "first seq", res.Messages[0].SequenceNumber)
 				} else if res.ConfirmedSequenceNumberMessage != nil {
 					log.Debug("confirmed sequence number", "seq", res.ConfirmedSequenceNumberMessage.SequenceNumber)
+				} else if res.AuctionResultMessage != nil {
+					log.Debug("auction result", "round", res.AuctionResultMessage.Round, "winner", res.AuctionResultMessage.Winner)
+				} else if res.ControllerTransferMessage != nil {
+					log.Debug("controller transfer", "round", res.ControllerTransferMessage.Round, "new", res.ControllerTransferMessage.NewController)
 				} else {
 					log.Debug("received broadcast with no messages populated", "length", len(msg))
 				}
@@ -461,6 +480,28 @@ func (bc *BroadcastClient) startBackgroundReader(earlyFrameData io.Reader) {
 					if res.ConfirmedSequenceNumberMessage != nil && bc.confirmedSequenceNumberListener != nil {
 						bc.confirmedSequenceNumberListener <- res.ConfirmedSequenceNumberMessage.SequenceNumber
 					}
+					if res.AuctionResultMessage != nil && bc.auctionResultListener != nil {
+						if err := bc.isValidHashSignature(ctx, res.AuctionResultMessage.Signature, res.AuctionResultMessage.Hash(bc.chainId)); err != nil {
+							log.Error("error validating auction result signature, ignoring it", "error", err, "round", res.AuctionResultMessage.Round)
+						} else {
+							select {
+							case bc.auctionResultListener <- res.AuctionResultMessage:
+							default:
+								log.Warn("auction result listener is full, dropping auction result", "round", res.AuctionResultMessage.Round)
+							}
+						}
+					}
+					if res.ControllerTransferMessage != nil && bc.controllerTransferListener != nil {
+						if err := bc.isValidHashSignature(ctx, res.ControllerTransferMessage.Signature, res.ControllerTransferMessage.Hash(bc.chainId)); err != nil {
+							log.Error("error validating controller transfer signature, ignoring it", "error", err, "round", res.ControllerTransferMessage.Round)
+						} else {
+							select {
+							case bc.controllerTransferListener <- res.ControllerTransferMessage:
+							default:
+								log.Warn("controller transfer listener is full, dropping controller transfer", "round", res.ControllerTransferMessage.Round)
+							}
+						}
+					}
 				}
 			}
 		}
@@ -530,3 +571,13 @@ func (bc *BroadcastClient) isValidSignature(ctx context.Context, message *m.Broa
 	}
 	return bc.sigVerifier.VerifyHash(ctx, message.Signature, hash)
 }
+
+// isValidHashSignature verifies the signature of feed data other than feed messages, such as express lane auction
+// results, with the same signers as feed messages
+func (bc *BroadcastClient) isValidHashSignature(ctx context.Context, signature []byte, hash common.Hash) error {
+	if bc.config().Verify.Dangerous.AcceptMissing && bc.sigVerifier == nil {
+		// Verifier disabled
+		return nil
+	}
+	return bc.sigVerifier.VerifyHash(ctx, signature, hash)
+}
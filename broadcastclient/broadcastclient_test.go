@@ -8,6 +8,7 @@ import (
 	"crypto/ecdsa"
 	"errors"
 	"fmt"
+	"math/big"
 	"net"
 	"net/http"
 	"strconv"
@@ -420,6 +421,131 @@ func TestBroadcastClientConfirmedMessage(t *testing.T) {
 
 	broadcastClient.StopAndWait()
 }
+func TestBroadcastClientAuctionResult(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := wsbroadcastserver.DefaultTestBroadcasterConfig
+
+	privateKey, err := crypto.GenerateKey()
+	Require(t, err)
+	sequencerAddr := crypto.PubkeyToAddress(privateKey.PublicKey)
+	dataSigner := signature.DataSignerFromPrivateKey(privateKey)
+
+	chainId := uint64(8742)
+	feedErrChan := make(chan error, 10)
+	b := broadcaster.NewBroadcaster(func() *wsbroadcastserver.BroadcasterConfig { return &config }, chainId, feedErrChan, dataSigner)
+
+	Require(t, b.Initialize())
+	Require(t, b.Start(ctx))
+	defer b.StopAndWait()
+
+	auctionResultListener := make(chan *m.AuctionResultMessage, 10)
+	controllerTransferListener := make(chan *m.ControllerTransferMessage, 10)
+	ts := NewDummyTransactionStreamer(chainId, nil)
+	broadcastClient, err := newTestBroadcastClient(
+		DefaultTestConfig,
+		b.ListenerAddr(),
+		chainId,
+		0,
+		ts,
+		nil,
+		feedErrChan,
+		&sequencerAddr,
+		t,
+	)
+	Require(t, err)
+	broadcastClient.SetAuctionResultListener(auctionResultListener)
+	broadcastClient.SetControllerTransferListener(controllerTransferListener)
+	broadcastClient.Start(ctx)
+	defer broadcastClient.StopAndWait()
+
+	Require(t, b.BroadcastSingle(arbostypes.EmptyTestMessageWithMetadata, 0, nil, nil))
+
+	// Wait for client to receive batch to ensure it is connected
+	timer := time.NewTimer(5 * time.Second)
+	defer timer.Stop()
+	select {
+	case err := <-feedErrChan:
+		t.Fatalf("Broadcaster error: %s", err.Error())
+	case <-ts.messageReceiver:
+	case <-timer.C:
+		t.Fatal("Client did not receive batch item")
+	}
+
+	// Results not signed by the sequencer are dropped, so the client only receives the signed one sent after
+	forged := &m.AuctionResultMessage{
+		Round:       7,
+		Winner:      common.HexToAddress("0x9999"),
+		FirstPrice:  big.NewInt(1000),
+		SecondPrice: big.NewInt(600),
+	}
+	forgerKey, err := crypto.GenerateKey()
+	Require(t, err)
+	forged.Signature, err = signature.DataSignerFromPrivateKey(forgerKey)(forged.Hash(chainId).Bytes())
+	Require(t, err)
+	b.BroadcastAuctionResultMessage(forged)
+	result := &m.AuctionResultMessage{
+		Round:       7,
+		Winner:      common.HexToAddress("0x1234"),
+		FirstPrice:  big.NewInt(1000),
+		SecondPrice: big.NewInt(600),
+	}
+	Require(t, b.BroadcastAuctionResult(result))
+
+	timer2 := time.NewTimer(5 * time.Second)
+	defer timer2.Stop()
+	select {
+	case err := <-feedErrChan:
+		t.Fatalf("Broadcaster error: %s", err.Error())
+	case received := <-auctionResultListener:
+		if received.Round != result.Round || received.Winner != result.Winner || received.FirstPrice.Cmp(result.FirstPrice) != 0 || received.SecondPrice.Cmp(result.SecondPrice) != 0 {
+			t.Fatalf("Incorrect auction result: %+v, expected: %+v", received, result)
+		}
+	case <-timer2.C:
+		t.Fatal("Client did not receive auction result")
+	}
+
+	transfer := &m.ControllerTransferMessage{
+		Round:              7,
+		PreviousController: common.HexToAddress("0x1234"),
+		NewController:      common.HexToAddress("0x5678"),
+	}
+	Require(t, b.BroadcastControllerTransfer(transfer))
+
+	timer3 := time.NewTimer(5 * time.Second)
+	defer timer3.Stop()
+	select {
+	case err := <-feedErrChan:
+		t.Fatalf("Broadcaster error: %s", err.Error())
+	case received := <-controllerTransferListener:
+		if received.Round != transfer.Round || received.PreviousController != transfer.PreviousController || received.NewController != transfer.NewController {
+			t.Fatalf("Incorrect controller transfer: %+v, expected: %+v", received, transfer)
+		}
+	case <-timer3.C:
+		t.Fatal("Client did not receive controller transfer")
+	}
+
+	// A full listener doesn't hold up the feed, the auction results it has no room for are dropped instead
+	for round := uint64(8); round <= uint64(8+cap(auctionResultListener)); round++ {
+		Require(t, b.BroadcastAuctionResult(&m.AuctionResultMessage{Round: round, Winner: result.Winner, FirstPrice: result.FirstPrice, SecondPrice: result.SecondPrice}))
+	}
+	Require(t, b.BroadcastSingle(arbostypes.EmptyTestMessageWithMetadata, 1, nil, nil))
+	timer4 := time.NewTimer(5 * time.Second)
+	defer timer4.Stop()
+	select {
+	case err := <-feedErrChan:
+		t.Fatalf("Broadcaster error: %s", err.Error())
+	case <-ts.messageReceiver:
+	case <-timer4.C:
+		t.Fatal("Client did not receive batch item behind a full auction result listener")
+	}
+	if len(auctionResultListener) != cap(auctionResultListener) {
+		t.Fatalf("Expected a full auction result listener, got %d results", len(auctionResultListener))
+	}
+}
+
 func TestServerIncorrectChainId(t *testing.T) {
 	t.Parallel()
 	ctx, cancel := context.WithCancel(context.Background())
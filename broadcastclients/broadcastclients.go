@@ -48,6 +48,9 @@ type BroadcastClients struct {
 	primaryRouter   *Router
 	secondaryRouter *Router
 
+	auctionResultListener      chan<- *m.AuctionResultMessage
+	controllerTransferListener chan<- *m.ControllerTransferMessage
+
 	// Use atomic access
 	connected atomic.Int32
 }
@@ -81,7 +84,7 @@ func NewBroadcastClients(
 		secondaryURL:     config.SecondaryURL,
 	}
 	clients.makeClient = func(url string, router *Router) (*broadcastclient.BroadcastClient, error) {
-		return broadcastclient.NewBroadcastClient(
+		client, err := broadcastclient.NewBroadcastClient(
 			configFetcher,
 			url,
 			l2ChainId,
@@ -92,6 +95,13 @@ func NewBroadcastClients(
 			addrVerifier,
 			func(delta int32) { clients.adjustCount(delta) },
 		)
+		if err == nil && clients.auctionResultListener != nil {
+			client.SetAuctionResultListener(clients.auctionResultListener)
+		}
+		if err == nil && clients.controllerTransferListener != nil {
+			client.SetControllerTransferListener(clients.controllerTransferListener)
+		}
+		return client, err
 	}
 
 	var lastClientErr error
@@ -112,6 +122,24 @@ func NewBroadcastClients(
 	return &clients, nil
 }
 
+// SetAuctionResultListener sets the channel express lane auction results received from any of the feeds are sent to,
+// must be called before Start
+func (bcs *BroadcastClients) SetAuctionResultListener(listener chan<- *m.AuctionResultMessage) {
+	bcs.auctionResultListener = listener
+	for _, client := range bcs.primaryClients {
+		client.SetAuctionResultListener(listener)
+	}
+}
+
+// SetControllerTransferListener sets the channel express lane control transfers received from any of the feeds are sent to,
+// must be called before Start
+func (bcs *BroadcastClients) SetControllerTransferListener(listener chan<- *m.ControllerTransferMessage) {
+	bcs.controllerTransferListener = listener
+	for _, client := range bcs.primaryClients {
+		client.SetControllerTransferListener(listener)
+	}
+}
+
 func (bcs *BroadcastClients) adjustCount(delta int32) {
 	connected := bcs.connected.Add(delta)
 	if connected <= 0 {
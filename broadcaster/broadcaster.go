@@ -140,6 +140,52 @@ func (b *Broadcaster) Confirm(seq arbutil.MessageIndex) {
 	})
 }
 
+// BroadcastAuctionResult signs an express lane auction result and sends it to feed clients.
+// It isn't kept in the backlog, so clients only receive the results of auctions resolved while they are connected.
+func (b *Broadcaster) BroadcastAuctionResult(result *m.AuctionResultMessage) error {
+	if b.dataSigner != nil {
+		sig, err := b.dataSigner(result.Hash(b.chainId).Bytes())
+		if err != nil {
+			return err
+		}
+		result.Signature = sig
+	}
+	b.BroadcastAuctionResultMessage(result)
+	return nil
+}
+
+// BroadcastAuctionResultMessage sends an already signed express lane auction result to feed clients
+func (b *Broadcaster) BroadcastAuctionResultMessage(result *m.AuctionResultMessage) {
+	log.Debug("broadcasting auction result", "round", result.Round, "winner", result.Winner)
+	b.server.Broadcast(&m.BroadcastMessage{
+		Version:              1,
+		AuctionResultMessage: result,
+	})
+}
+
+// BroadcastControllerTransfer signs an express lane control transfer and sends it to feed clients.
+// Like auction results, transfers aren't kept in the backlog.
+func (b *Broadcaster) BroadcastControllerTransfer(transfer *m.ControllerTransferMessage) error {
+	if b.dataSigner != nil {
+		sig, err := b.dataSigner(transfer.Hash(b.chainId).Bytes())
+		if err != nil {
+			return err
+		}
+		transfer.Signature = sig
+	}
+	b.BroadcastControllerTransferMessage(transfer)
+	return nil
+}
+
+// BroadcastControllerTransferMessage sends an already signed express lane control transfer to feed clients
+func (b *Broadcaster) BroadcastControllerTransferMessage(transfer *m.ControllerTransferMessage) {
+	log.Debug("broadcasting controller transfer", "round", transfer.Round, "previous", transfer.PreviousController, "new", transfer.NewController)
+	b.server.Broadcast(&m.BroadcastMessage{
+		Version:                   1,
+		ControllerTransferMessage: transfer,
+	})
+}
+
 func (b *Broadcaster) ClientCount() int32 {
 	return b.server.ClientCount()
 }
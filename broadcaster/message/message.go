@@ -1,10 +1,14 @@
 package message
 
 import (
+	"math/big"
+
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 
 	"github.com/offchainlabs/nitro/arbos/arbostypes"
 	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/util/arbmath"
 )
 
 const (
@@ -31,6 +35,8 @@ type BroadcastMessage struct {
 	// TODO better name than messages since there are different types of messages
 	Messages                       []*BroadcastFeedMessage         `json:"messages,omitempty"`
 	ConfirmedSequenceNumberMessage *ConfirmedSequenceNumberMessage `json:"confirmedSequenceNumberMessage,omitempty"`
+	AuctionResultMessage           *AuctionResultMessage           `json:"auctionResultMessage,omitempty"`
+	ControllerTransferMessage      *ControllerTransferMessage      `json:"controllerTransferMessage,omitempty"`
 }
 
 type BroadcastFeedMessage struct {
@@ -59,3 +65,55 @@ func (m *BroadcastFeedMessage) Hash(chainId uint64) (common.Hash, error) {
 type ConfirmedSequenceNumberMessage struct {
 	SequenceNumber arbutil.MessageIndex `json:"sequenceNumber"`
 }
+
+var (
+	auctionResultPrefix      = []byte("Arbitrum Nitro Feed Auction Result:")
+	controllerTransferPrefix = []byte("Arbitrum Nitro Feed Controller Transfer:")
+)
+
+// AuctionResultMessage is an express lane auction resolved on the parent chain, as observed by the sequencer.
+// Like feed messages, it is signed by the sequencer so that relays can't forge the express lane controller.
+type AuctionResultMessage struct {
+	Round       uint64         `json:"round"`
+	Winner      common.Address `json:"winner"`
+	FirstPrice  *big.Int       `json:"firstPrice"`
+	SecondPrice *big.Int       `json:"secondPrice"`
+	Signature   []byte         `json:"signature"`
+}
+
+func (r *AuctionResultMessage) Hash(chainId uint64) common.Hash {
+	return crypto.Keccak256Hash(
+		auctionResultPrefix,
+		arbmath.UintToBytes(chainId),
+		arbmath.UintToBytes(r.Round),
+		r.Winner.Bytes(),
+		priceBytes(r.FirstPrice),
+		priceBytes(r.SecondPrice),
+	)
+}
+
+// ControllerTransferMessage is a transfer of a round's express lane control on the parent chain, as observed by the
+// sequencer, which signs it like feed messages.
+type ControllerTransferMessage struct {
+	Round              uint64         `json:"round"`
+	PreviousController common.Address `json:"previousController"`
+	NewController      common.Address `json:"newController"`
+	Signature          []byte         `json:"signature"`
+}
+
+func (t *ControllerTransferMessage) Hash(chainId uint64) common.Hash {
+	return crypto.Keccak256Hash(
+		controllerTransferPrefix,
+		arbmath.UintToBytes(chainId),
+		arbmath.UintToBytes(t.Round),
+		t.PreviousController.Bytes(),
+		t.NewController.Bytes(),
+	)
+}
+
+func priceBytes(price *big.Int) []byte {
+	if price == nil {
+		return make([]byte, 32)
+	}
+	return arbmath.U256Bytes(price)
+}
@@ -18,20 +18,21 @@ import (
 )
 
 type AutonomousAuctioneerConfig struct {
-	AuctioneerServer timeboost.AuctioneerServerConfig `koanf:"auctioneer-server"`
-	BidValidator     timeboost.BidValidatorConfig     `koanf:"bid-validator"`
-	Persistent       conf.PersistentConfig            `koanf:"persistent"`
-	Conf             genericconf.ConfConfig           `koanf:"conf" reload:"hot"`
-	LogLevel         string                           `koanf:"log-level" reload:"hot"`
-	LogType          string                           `koanf:"log-type" reload:"hot"`
-	FileLogging      genericconf.FileLoggingConfig    `koanf:"file-logging" reload:"hot"`
-	HTTP             genericconf.HTTPConfig           `koanf:"http"`
-	WS               genericconf.WSConfig             `koanf:"ws"`
-	IPC              genericconf.IPCConfig            `koanf:"ipc"`
-	Metrics          bool                             `koanf:"metrics"`
-	MetricsServer    genericconf.MetricsServerConfig  `koanf:"metrics-server"`
-	PProf            bool                             `koanf:"pprof"`
-	PprofCfg         genericconf.PProf                `koanf:"pprof-cfg"`
+	AuctioneerServer  timeboost.AuctioneerServerConfig `koanf:"auctioneer-server"`
+	BidValidator      timeboost.BidValidatorConfig     `koanf:"bid-validator" reload:"hot"`
+	Persistent        conf.PersistentConfig            `koanf:"persistent"`
+	Conf              genericconf.ConfConfig           `koanf:"conf" reload:"hot"`
+	LogLevel          string                           `koanf:"log-level" reload:"hot"`
+	LogType           string                           `koanf:"log-type" reload:"hot"`
+	FileLogging       genericconf.FileLoggingConfig    `koanf:"file-logging" reload:"hot"`
+	HTTP              genericconf.HTTPConfig           `koanf:"http"`
+	WS                genericconf.WSConfig             `koanf:"ws"`
+	IPC               genericconf.IPCConfig            `koanf:"ipc"`
+	Metrics           bool                             `koanf:"metrics"`
+	MetricsServer     genericconf.MetricsServerConfig  `koanf:"metrics-server"`
+	PrometheusMetrics bool                             `koanf:"prometheus-metrics"`
+	PProf             bool                             `koanf:"pprof"`
+	PprofCfg          genericconf.PProf                `koanf:"pprof-cfg"`
 }
 
 var HTTPConfigDefault = genericconf.HTTPConfig{
@@ -58,17 +59,18 @@ var IPCConfigDefault = genericconf.IPCConfig{
 }
 
 var AutonomousAuctioneerConfigDefault = AutonomousAuctioneerConfig{
-	Conf:          genericconf.ConfConfigDefault,
-	LogLevel:      "INFO",
-	LogType:       "plaintext",
-	HTTP:          HTTPConfigDefault,
-	WS:            WSConfigDefault,
-	IPC:           IPCConfigDefault,
-	Metrics:       false,
-	MetricsServer: genericconf.MetricsServerConfigDefault,
-	PProf:         false,
-	Persistent:    conf.PersistentConfigDefault,
-	PprofCfg:      genericconf.PProfDefault,
+	Conf:              genericconf.ConfConfigDefault,
+	LogLevel:          "INFO",
+	LogType:           "plaintext",
+	HTTP:              HTTPConfigDefault,
+	WS:                WSConfigDefault,
+	IPC:               IPCConfigDefault,
+	Metrics:           false,
+	MetricsServer:     genericconf.MetricsServerConfigDefault,
+	PrometheusMetrics: false,
+	PProf:             false,
+	Persistent:        conf.PersistentConfigDefault,
+	PprofCfg:          genericconf.PProfDefault,
 }
 
 func AuctioneerConfigAddOptions(f *flag.FlagSet) {
@@ -84,6 +86,7 @@ func AuctioneerConfigAddOptions(f *flag.FlagSet) {
 	genericconf.IPCConfigAddOptions("ipc", f)
 	f.Bool("metrics", AutonomousAuctioneerConfigDefault.Metrics, "enable metrics")
 	genericconf.MetricsServerAddOptions("metrics-server", f)
+	f.Bool("prometheus-metrics", AutonomousAuctioneerConfigDefault.PrometheusMetrics, "also serve metrics in prometheus format at /metrics on the metrics server, for prometheus scrapers expecting the conventional path")
 	f.Bool("pprof", AutonomousAuctioneerConfigDefault.PProf, "enable pprof")
 	genericconf.PProfAddOptions("pprof-cfg", f)
 }
@@ -134,6 +137,9 @@ func (c *AutonomousAuctioneerConfig) Validate() error {
 	if err := c.AuctioneerServer.S3Storage.Validate(); err != nil {
 		return err
 	}
+	if err := c.BidValidator.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
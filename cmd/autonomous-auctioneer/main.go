@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -14,6 +15,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/metrics/exp"
+	"github.com/ethereum/go-ethereum/metrics/prometheus"
 	"github.com/ethereum/go-ethereum/node"
 
 	"github.com/offchainlabs/nitro/cmd/genericconf"
@@ -43,7 +45,15 @@ func startMetrics(cfg *AutonomousAuctioneerConfig) error {
 	}
 	if cfg.Metrics {
 		go metrics.CollectProcessMetrics(time.Second)
-		exp.Setup(fmt.Sprintf("%v:%v", cfg.MetricsServer.Addr, cfg.MetricsServer.Port))
+		if cfg.PrometheusMetrics {
+			go func() {
+				if err := http.ListenAndServe(mAddr, metricsHandler()); /* #nosec G114 */ err != nil {
+					log.Error("Failure in running metrics server", "err", err)
+				}
+			}()
+		} else {
+			exp.Setup(mAddr)
+		}
 	}
 	if cfg.PProf {
 		genericconf.StartPprof(pAddr)
@@ -51,6 +61,16 @@ func startMetrics(cfg *AutonomousAuctioneerConfig) error {
 	return nil
 }
 
+// metricsHandler serves the same endpoints as the default metrics server, and the registered metrics in
+// prometheus format at /metrics as well.
+func metricsHandler() http.Handler {
+	m := http.NewServeMux()
+	m.Handle("/debug/metrics", exp.ExpHandler(metrics.DefaultRegistry))
+	m.Handle("/debug/metrics/prometheus", prometheus.Handler(metrics.DefaultRegistry))
+	m.Handle("/metrics", prometheus.Handler(metrics.DefaultRegistry))
+	return m
+}
+
 func mainImpl() int {
 	ctx, cancelFunc := context.WithCancel(context.Background())
 	defer cancelFunc()
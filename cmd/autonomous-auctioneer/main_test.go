@@ -0,0 +1,31 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPrometheusMetricsHandler(t *testing.T) {
+	server := httptest.NewServer(metricsHandler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Registered by the timeboost package
+	if !strings.Contains(string(body), "arb_auctioneer_bids_received") {
+		t.Fatalf("auctioneer metrics missing from prometheus output:\n%s", body)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"sort"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/offchainlabs/nitro/arbstate/daprovider"
 )
@@ -78,6 +79,68 @@ func (s *simpleExploreExploitStrategy) newInstance() aggregatorStrategyInstance
 	return &basicStrategyInstance{readerSets: readerSets}
 }
 
+// Round Robin Strategy, spreading requests across the healthy readers in proportion to their weights.
+// Readers whose recent requests all failed are only tried after the healthy ones, until a period after their last failure.
+type roundRobinStrategy struct {
+	next                   atomic.Uint32
+	maxConsecutiveFailures int
+	unhealthyPeriod        time.Duration
+	// weight returns the reader's share of the requests relative to the others, nil weighs all readers equally
+	weight func(daprovider.DASReader) int
+
+	abstractAggregatorStrategy
+}
+
+func (s *roundRobinStrategy) newInstance() aggregatorStrategyInstance {
+	start := s.next.Add(1) - 1
+	s.RLock()
+	defer s.RUnlock()
+
+	now := time.Now()
+	healthy := make([]daprovider.DASReader, 0, len(s.readers))
+	var unhealthy []daprovider.DASReader
+	for _, reader := range s.readers {
+		stats := s.stats[reader]
+		if now.Before(stats.unhealthyUntil(s.maxConsecutiveFailures, s.unhealthyPeriod)) {
+			unhealthy = append(unhealthy, reader)
+		} else {
+			healthy = append(healthy, reader)
+		}
+	}
+
+	// The reader tried first takes turns according to its weight, the others follow in order as fallbacks
+	first := 0
+	if len(healthy) > 0 {
+		weights := make([]int, len(healthy))
+		totalWeight := 0
+		for i, reader := range healthy {
+			weights[i] = s.readerWeight(reader)
+			totalWeight += weights[i]
+		}
+		turn := int(start % uint32(totalWeight)) // #nosec G115
+		for turn >= weights[first] {
+			turn -= weights[first]
+			first++
+		}
+	}
+	si := basicStrategyInstance{}
+	for i := range healthy {
+		reader := healthy[(first+i)%len(healthy)]
+		si.readerSets = append(si.readerSets, []daprovider.DASReader{reader})
+	}
+	for _, reader := range unhealthy {
+		si.readerSets = append(si.readerSets, []daprovider.DASReader{reader})
+	}
+	return &si
+}
+
+func (s *roundRobinStrategy) readerWeight(reader daprovider.DASReader) int {
+	if s.weight == nil {
+		return 1
+	}
+	return s.weight(reader)
+}
+
 // Sequential Strategy for Testing
 type testingSequentialStrategy struct {
 	abstractAggregatorStrategy
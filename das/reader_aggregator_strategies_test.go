@@ -35,27 +35,27 @@ func TestDAS_SimpleExploreExploit(t *testing.T) {
 	readers := []daprovider.DASReader{&dummyReader{0}, &dummyReader{1}, &dummyReader{2}, &dummyReader{3}, &dummyReader{4}, &dummyReader{5}}
 	stats := make(map[daprovider.DASReader]readerStats)
 	stats[readers[0]] = []readerStat{ // weighted avg 10s
-		{10 * time.Second, true},
+		{latency: 10 * time.Second, success: true},
 	}
 	stats[readers[1]] = []readerStat{ // weighted avg 5s
-		{6 * time.Second, true},
-		{4 * time.Second, true},
+		{latency: 6 * time.Second, success: true},
+		{latency: 4 * time.Second, success: true},
 	}
 	stats[readers[2]] = []readerStat{ // weighted avg 3 / (1/2) = 6s
-		{3 * time.Second, true},
-		{3 * time.Second, false},
+		{latency: 3 * time.Second, success: true},
+		{latency: 3 * time.Second, success: false},
 	}
 	stats[readers[3]] = []readerStat{ // weighted avg max int
-		{1 * time.Second, false},
-		{1 * time.Second, false},
+		{latency: 1 * time.Second, success: false},
+		{latency: 1 * time.Second, success: false},
 	}
 	stats[readers[4]] = []readerStat{ // weighted avg 3 / (1/3) = 9s
-		{3 * time.Second, true},
-		{3 * time.Second, false},
-		{3 * time.Second, false},
+		{latency: 3 * time.Second, success: true},
+		{latency: 3 * time.Second, success: false},
+		{latency: 3 * time.Second, success: false},
 	}
 	stats[readers[5]] = []readerStat{ // weighted avg 8s
-		{8 * time.Second, true},
+		{latency: 8 * time.Second, success: true},
 	}
 
 	expectedOrdering := []daprovider.DASReader{readers[1], readers[2], readers[5], readers[4], readers[0], readers[3]}
@@ -117,3 +117,116 @@ func TestDAS_SimpleExploreExploit(t *testing.T) {
 	}
 
 }
+
+func TestDAS_RoundRobin(t *testing.T) {
+	readers := []daprovider.DASReader{&dummyReader{0}, &dummyReader{1}, &dummyReader{2}}
+	stats := make(map[daprovider.DASReader]readerStats)
+	strategy := roundRobinStrategy{
+		maxConsecutiveFailures: 2,
+		unhealthyPeriod:        time.Minute,
+	}
+	strategy.update(readers, stats)
+
+	firstReaderCounts := func(iterations int) map[int]int {
+		counts := make(map[int]int)
+		for i := 0; i < iterations; i++ {
+			si := strategy.newInstance()
+			first := si.nextReaders()
+			if len(first) != 1 {
+				Fail(t, fmt.Sprintf("Incorrect number of nextReaders %d, expected 1", len(first)))
+			}
+			counts[first[0].(*dummyReader).int]++
+			tried := 1
+			for next := si.nextReaders(); len(next) != 0; next = si.nextReaders() {
+				tried++
+			}
+			if tried != len(readers) {
+				Fail(t, fmt.Sprintf("%d readers were tried, expected all %d", tried, len(readers)))
+			}
+		}
+		return counts
+	}
+
+	// Requests are spread evenly across the readers
+	counts := firstReaderCounts(30)
+	for i := range readers {
+		if counts[i] != 10 {
+			Fail(t, fmt.Sprintf("reader %d was tried first %d times, expected 10", i, counts[i]))
+		}
+	}
+
+	// A reader that failed repeatedly is skipped, and only tried after the healthy ones
+	stats[readers[1]] = []readerStat{
+		{latency: time.Second, success: true, timestamp: time.Now()},
+		{latency: time.Second, success: false, timestamp: time.Now()},
+		{latency: time.Second, success: false, timestamp: time.Now()},
+	}
+	strategy.update(readers, stats)
+	counts = firstReaderCounts(30)
+	if counts[0] != 15 || counts[1] != 0 || counts[2] != 15 {
+		Fail(t, fmt.Sprintf("unexpected distribution with an unhealthy reader: %v", counts))
+	}
+	si := strategy.newInstance()
+	si.nextReaders()
+	si.nextReaders()
+	if last := si.nextReaders(); len(last) != 1 || last[0].(*dummyReader).int != 1 {
+		Fail(t, "expected the unhealthy reader to be tried last")
+	}
+
+	// A single failure doesn't make a reader unhealthy
+	stats[readers[1]] = []readerStat{
+		{latency: time.Second, success: true, timestamp: time.Now()},
+		{latency: time.Second, success: false, timestamp: time.Now()},
+	}
+	strategy.update(readers, stats)
+	if counts = firstReaderCounts(30); counts[1] != 10 {
+		Fail(t, fmt.Sprintf("reader 1 was tried first %d times after a single failure, expected 10", counts[1]))
+	}
+
+	// The reader is tried again once the unhealthy period since its last failure has passed
+	stats[readers[1]] = []readerStat{
+		{latency: time.Second, success: false, timestamp: time.Now().Add(-2 * time.Minute)},
+		{latency: time.Second, success: false, timestamp: time.Now().Add(-2 * time.Minute)},
+	}
+	strategy.update(readers, stats)
+	if counts = firstReaderCounts(30); counts[1] != 10 {
+		Fail(t, fmt.Sprintf("reader 1 was tried first %d times after its unhealthy period, expected 10", counts[1]))
+	}
+
+	// Requests are spread in proportion to the readers' weights
+	strategy.weight = func(reader daprovider.DASReader) int {
+		return []int{1, 3, 2}[reader.(*dummyReader).int]
+	}
+	counts = firstReaderCounts(60)
+	if counts[0] != 10 || counts[1] != 30 || counts[2] != 20 {
+		Fail(t, fmt.Sprintf("unexpected distribution with weighted readers: %v", counts))
+	}
+
+	// An unhealthy reader's weight is spread across the healthy ones
+	stats[readers[1]] = []readerStat{
+		{latency: time.Second, success: false, timestamp: time.Now()},
+		{latency: time.Second, success: false, timestamp: time.Now()},
+	}
+	strategy.update(readers, stats)
+	counts = firstReaderCounts(60)
+	if counts[0] != 20 || counts[1] != 0 || counts[2] != 40 {
+		Fail(t, fmt.Sprintf("unexpected distribution with weighted readers and an unhealthy one: %v", counts))
+	}
+}
+
+func TestDAS_RoundRobinWeightsConfig(t *testing.T) {
+	config := RoundRobinStrategyConfig{Weights: []string{"http://a:9876=3", "https://b=1"}}
+	weights, err := config.urlWeights()
+	if err != nil {
+		Fail(t, err)
+	}
+	if len(weights) != 2 || weights["http://a:9876"] != 3 || weights["https://b"] != 1 {
+		Fail(t, fmt.Sprintf("unexpected weights %v", weights))
+	}
+	for _, entry := range []string{"http://a:9876", "http://a:9876=0", "http://a:9876=x"} {
+		config.Weights = []string{entry}
+		if _, err := config.urlWeights(); err == nil {
+			Fail(t, fmt.Sprintf("expected weights entry %q to be rejected", entry))
+		}
+	}
+}
@@ -37,6 +37,14 @@ func (m *mockS3FullClient) Upload(ctx context.Context, input *s3.PutObjectInput,
 	return nil, err
 }
 
+func (m *mockS3FullClient) HeadBucket(ctx context.Context, input *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error) {
+	return &s3.HeadBucketOutput{}, nil
+}
+
+func (m *mockS3FullClient) ListObjectsV2(ctx context.Context, input *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	return &s3.ListObjectsV2Output{}, nil
+}
+
 func (m *mockS3FullClient) Download(ctx context.Context, w io.WriterAt, input *s3.GetObjectInput, options ...func(*manager.Downloader)) (n int64, err error) {
 	key, err := DecodeStorageServiceKey(*input.Key)
 	if err != nil {
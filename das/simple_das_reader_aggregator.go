@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -36,6 +37,7 @@ type RestfulClientAggregatorConfig struct {
 	WaitBeforeTryNext            time.Duration                      `koanf:"wait-before-try-next"`
 	MaxPerEndpointStats          int                                `koanf:"max-per-endpoint-stats"`
 	SimpleExploreExploitStrategy SimpleExploreExploitStrategyConfig `koanf:"simple-explore-exploit-strategy"`
+	RoundRobinStrategy           RoundRobinStrategyConfig           `koanf:"round-robin-strategy"`
 	SyncToStorage                SyncToStorageConfig                `koanf:"sync-to-storage"`
 }
 
@@ -48,6 +50,7 @@ var DefaultRestfulClientAggregatorConfig = RestfulClientAggregatorConfig{
 	WaitBeforeTryNext:            2 * time.Second,
 	MaxPerEndpointStats:          20,
 	SimpleExploreExploitStrategy: DefaultSimpleExploreExploitStrategyConfig,
+	RoundRobinStrategy:           DefaultRoundRobinStrategyConfig,
 	SyncToStorage:                DefaultSyncToStorageConfig,
 }
 
@@ -61,16 +64,46 @@ var DefaultSimpleExploreExploitStrategyConfig = SimpleExploreExploitStrategyConf
 	ExploitIterations: 1000,
 }
 
+type RoundRobinStrategyConfig struct {
+	MaxConsecutiveFailures int           `koanf:"max-consecutive-failures"`
+	UnhealthyPeriod        time.Duration `koanf:"unhealthy-period"`
+	Weights                []string      `koanf:"weights"`
+}
+
+var DefaultRoundRobinStrategyConfig = RoundRobinStrategyConfig{
+	MaxConsecutiveFailures: 3,
+	UnhealthyPeriod:        time.Minute,
+	Weights:                []string{},
+}
+
+// urlWeights parses the weights option into the weight of each REST endpoint URL listed.
+func (c *RoundRobinStrategyConfig) urlWeights() (map[string]int, error) {
+	weights := make(map[string]int)
+	for _, entry := range c.Weights {
+		url, weightStr, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid round-robin-strategy.weights entry \"%v\", expected <url>=<weight>", entry)
+		}
+		weight, err := strconv.Atoi(weightStr)
+		if err != nil || weight <= 0 {
+			return nil, fmt.Errorf("invalid round-robin-strategy.weights entry \"%v\", weight must be a positive integer", entry)
+		}
+		weights[url] = weight
+	}
+	return weights, nil
+}
+
 func RestfulClientAggregatorConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Bool(prefix+".enable", DefaultRestfulClientAggregatorConfig.Enable, "enable retrieval of sequencer batch data from a list of remote REST endpoints; if other DAS storage types are enabled, this mode is used as a fallback")
 	f.StringSlice(prefix+".urls", DefaultRestfulClientAggregatorConfig.Urls, "list of URLs including 'http://' or 'https://' prefixes and port numbers to REST DAS endpoints; additive with the online-url-list option")
 	f.String(prefix+".online-url-list", DefaultRestfulClientAggregatorConfig.OnlineUrlList, "a URL to a list of URLs of REST das endpoints that is checked at startup; additive with the url option")
 	f.Duration(prefix+".online-url-list-fetch-interval", DefaultRestfulClientAggregatorConfig.OnlineUrlListFetchInterval, "time interval to periodically fetch url list from online-url-list")
-	f.String(prefix+".strategy", DefaultRestfulClientAggregatorConfig.Strategy, "strategy to use to determine order and parallelism of calling REST endpoint URLs; valid options are 'simple-explore-exploit'")
+	f.String(prefix+".strategy", DefaultRestfulClientAggregatorConfig.Strategy, "strategy to use to determine order and parallelism of calling REST endpoint URLs; valid options are 'simple-explore-exploit' and 'round-robin'")
 	f.Duration(prefix+".strategy-update-interval", DefaultRestfulClientAggregatorConfig.StrategyUpdateInterval, "how frequently to update the strategy with endpoint latency and error rate data")
 	f.Duration(prefix+".wait-before-try-next", DefaultRestfulClientAggregatorConfig.WaitBeforeTryNext, "time to wait until trying the next set of REST endpoints while waiting for a response; the next set of REST endpoints is determined by the strategy selected")
 	f.Int(prefix+".max-per-endpoint-stats", DefaultRestfulClientAggregatorConfig.MaxPerEndpointStats, "number of stats entries (latency and success rate) to keep for each REST endpoint; controls whether strategy is faster or slower to respond to changing conditions")
 	SimpleExploreExploitStrategyConfigAddOptions(prefix+".simple-explore-exploit-strategy", f)
+	RoundRobinStrategyConfigAddOptions(prefix+".round-robin-strategy", f)
 	SyncToStorageConfigAddOptions(prefix+".sync-to-storage", f)
 }
 
@@ -79,6 +112,12 @@ func SimpleExploreExploitStrategyConfigAddOptions(prefix string, f *flag.FlagSet
 	f.Uint32(prefix+".exploit-iterations", DefaultSimpleExploreExploitStrategyConfig.ExploitIterations, "number of consecutive GetByHash calls to the aggregator where each call will cause it to select from REST endpoints in order of best latency and success rate, before switching to explore mode")
 }
 
+func RoundRobinStrategyConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Int(prefix+".max-consecutive-failures", DefaultRoundRobinStrategyConfig.MaxConsecutiveFailures, "number of consecutive failed requests to a REST endpoint after which it is skipped for the unhealthy-period, as long as other endpoints are healthy; 0 disables skipping endpoints, and it must not exceed max-per-endpoint-stats to have an effect")
+	f.Duration(prefix+".unhealthy-period", DefaultRoundRobinStrategyConfig.UnhealthyPeriod, "time after its last failure that an unhealthy REST endpoint is skipped for, after which it is tried again")
+	f.StringSlice(prefix+".weights", DefaultRoundRobinStrategyConfig.Weights, "weights of REST endpoints, as a list of <url>=<weight> entries, that requests are spread across the endpoints in proportion to; endpoints not listed have a weight of 1")
+}
+
 func NewRestfulClientAggregator(ctx context.Context, config *RestfulClientAggregatorConfig) (*SimpleDASReaderAggregator, error) {
 	a := SimpleDASReaderAggregator{
 		config: config,
@@ -125,6 +164,23 @@ func NewRestfulClientAggregator(ctx context.Context, config *RestfulClientAggreg
 			exploreIterations: config.SimpleExploreExploitStrategy.ExploreIterations,
 			exploitIterations: config.SimpleExploreExploitStrategy.ExploitIterations,
 		}
+	case "round-robin":
+		weights, err := config.RoundRobinStrategy.urlWeights()
+		if err != nil {
+			return nil, err
+		}
+		a.strategy = &roundRobinStrategy{
+			maxConsecutiveFailures: config.RoundRobinStrategy.MaxConsecutiveFailures,
+			unhealthyPeriod:        config.RoundRobinStrategy.UnhealthyPeriod,
+			weight: func(reader daprovider.DASReader) int {
+				if client, ok := reader.(*RestfulDasClient); ok {
+					if weight, ok := weights[client.url]; ok {
+						return weight
+					}
+				}
+				return 1
+			},
+		}
 	case "testing-sequential":
 		a.strategy = &testingSequentialStrategy{}
 	default:
@@ -155,9 +211,24 @@ func (s *readerStats) successRatioWeightedMeanLatency() time.Duration {
 	return time.Duration(avgLatency / successRatio)
 }
 
+// unhealthyUntil returns the time until which the reader is considered unhealthy, which is unhealthyPeriod after
+// its last failure if its last maxConsecutiveFailures requests all failed, and the zero time otherwise.
+func (s *readerStats) unhealthyUntil(maxConsecutiveFailures int, unhealthyPeriod time.Duration) time.Time {
+	if maxConsecutiveFailures <= 0 || len(*s) < maxConsecutiveFailures {
+		return time.Time{}
+	}
+	for _, stat := range (*s)[len(*s)-maxConsecutiveFailures:] {
+		if stat.success {
+			return time.Time{}
+		}
+	}
+	return (*s)[len(*s)-1].timestamp.Add(unhealthyPeriod)
+}
+
 type readerStat struct {
-	latency time.Duration
-	success bool
+	latency   time.Duration
+	success   bool
+	timestamp time.Time
 }
 
 type readerStatMessage struct {
@@ -260,6 +331,7 @@ func (a *SimpleDASReaderAggregator) tryGetByHash(
 		}
 	}
 	stat.latency = time.Since(start)
+	stat.timestamp = time.Now()
 
 	select {
 	case a.statMessages <- stat:
@@ -48,13 +48,22 @@ func (a *ArbAPI) CheckPublisherHealth(ctx context.Context) error {
 	return a.txPublisher.CheckHealth(ctx)
 }
 
-func (a *ArbAPI) GetRawBlockMetadata(ctx context.Context, fromBlock, toBlock rpc.BlockNumber) ([]NumberAndBlockMetadata, error) {
+// GetRawBlockMetadata returns blockMetadata of the given range of blocks, in addition to the usual block number specifiers
+// a negative number -N can be passed to refer to the block N blocks before latest
+func (a *ArbAPI) GetRawBlockMetadata(ctx context.Context, fromBlock, toBlock BlockNumberOrRelative) ([]NumberAndBlockMetadata, error) {
 	if a.bulkBlockMetadataFetcher == nil {
 		return nil, errors.New("arb_getRawBlockMetadata is not available")
 	}
 	return a.bulkBlockMetadataFetcher.Fetch(fromBlock, toBlock)
 }
 
+func (a *ArbAPI) GetRawBlockMetadataForBlocks(ctx context.Context, blockNumbers []uint64) ([]NumberAndBlockMetadata, error) {
+	if a.bulkBlockMetadataFetcher == nil {
+		return nil, errors.New("arb_getRawBlockMetadataForBlocks is not available")
+	}
+	return a.bulkBlockMetadataFetcher.FetchBlocks(blockNumbers)
+}
+
 type ArbTimeboostAuctioneerAPI struct {
 	txPublisher TransactionPublisher
 }
@@ -67,15 +76,55 @@ func (a *ArbTimeboostAuctioneerAPI) SubmitAuctionResolutionTransaction(ctx conte
 	return a.txPublisher.PublishAuctionResolutionTransaction(ctx, tx)
 }
 
-type ArbTimeboostAPI struct {
+// ArbAdminAPI holds the arb namespace methods that are only served on the authenticated RPC endpoint
+type ArbAdminAPI struct {
+	bulkBlockMetadataFetcher *BulkBlockMetadataFetcher
+}
+
+func NewArbAdminAPI(bulkBlockMetadataFetcher *BulkBlockMetadataFetcher) *ArbAdminAPI {
+	return &ArbAdminAPI{bulkBlockMetadataFetcher}
+}
+
+// WarmBlockMetadataCache pre-populates the blockMetadata cache with the given range of blocks in the background
+func (a *ArbAdminAPI) WarmBlockMetadataCache(ctx context.Context, fromBlock, toBlock uint64) error {
+	if a.bulkBlockMetadataFetcher == nil {
+		return errors.New("arb_warmBlockMetadataCache is not available")
+	}
+	return a.bulkBlockMetadataFetcher.WarmCache(fromBlock, toBlock)
+}
+
+// TimeboostAPIs returns the services of the timeboost namespace. Express lane submissions are served separately from
+// the read methods so that they can be restricted to JWT authenticated clients with authenticateSubmissions.
+func TimeboostAPIs(publisher TransactionPublisher, sequencer *Sequencer, controllerTracker *timeboost.ExpressLaneControllerTracker, authenticateSubmissions bool) []rpc.API {
+	return []rpc.API{{
+		Namespace: "timeboost",
+		Version:   "1.0",
+		Service:   NewArbTimeboostAPI(sequencer, controllerTracker),
+		Public:    false,
+	}, {
+		Namespace:     "timeboost",
+		Version:       "1.0",
+		Service:       NewArbTimeboostSubmissionAPI(publisher),
+		Public:        false,
+		Authenticated: authenticateSubmissions,
+	}, {
+		Namespace:     "timeboost",
+		Version:       "1.0",
+		Service:       NewArbTimeboostAdminAPI(sequencer),
+		Public:        false,
+		Authenticated: true,
+	}}
+}
+
+type ArbTimeboostSubmissionAPI struct {
 	txPublisher TransactionPublisher
 }
 
-func NewArbTimeboostAPI(publisher TransactionPublisher) *ArbTimeboostAPI {
-	return &ArbTimeboostAPI{publisher}
+func NewArbTimeboostSubmissionAPI(publisher TransactionPublisher) *ArbTimeboostSubmissionAPI {
+	return &ArbTimeboostSubmissionAPI{publisher}
 }
 
-func (a *ArbTimeboostAPI) SendExpressLaneTransaction(ctx context.Context, msg *timeboost.JsonExpressLaneSubmission) error {
+func (a *ArbTimeboostSubmissionAPI) SendExpressLaneTransaction(ctx context.Context, msg *timeboost.JsonExpressLaneSubmission) error {
 	goMsg, err := timeboost.JsonSubmissionToGo(msg)
 	if err != nil {
 		return err
@@ -83,6 +132,111 @@ func (a *ArbTimeboostAPI) SendExpressLaneTransaction(ctx context.Context, msg *t
 	return a.txPublisher.PublishExpressLaneTransaction(ctx, goMsg)
 }
 
+type ArbTimeboostAPI struct {
+	sequencer         *Sequencer
+	controllerTracker *timeboost.ExpressLaneControllerTracker
+}
+
+func NewArbTimeboostAPI(sequencer *Sequencer, controllerTracker *timeboost.ExpressLaneControllerTracker) *ArbTimeboostAPI {
+	return &ArbTimeboostAPI{sequencer, controllerTracker}
+}
+
+// GetExpressLaneController returns the express lane controller of the given round. The sequencer answers from the
+// auction contract's events, other nodes from the auction results and control transfers the sequencer signs and
+// broadcasts on its feed. These aren't replayed to feed clients connecting later, so other nodes only know the
+// controllers of rounds auctioned while they were following the feed, and report no controller for other rounds.
+func (a *ArbTimeboostAPI) GetExpressLaneController(ctx context.Context, round hexutil.Uint64) (common.Address, error) {
+	if a.sequencer != nil && a.sequencer.expressLaneService != nil {
+		return a.sequencer.ExpressLaneController(uint64(round))
+	}
+	if a.controllerTracker == nil {
+		return common.Address{}, errors.New("timeboost_getExpressLaneController is not available")
+	}
+	controller, ok := a.controllerTracker.Controller(uint64(round))
+	if !ok {
+		return common.Address{}, fmt.Errorf("%w: round %d", timeboost.ErrNoOnchainController, round)
+	}
+	return controller, nil
+}
+
+// GetExpressLaneRoundForBlock returns the express lane round during which the given block was produced, derived from
+// the block's timestamp, to correlate blocks with the auction winners of their rounds.
+func (a *ArbTimeboostAPI) GetExpressLaneRoundForBlock(ctx context.Context, blockNumber hexutil.Uint64) (hexutil.Uint64, error) {
+	if a.sequencer == nil {
+		return 0, errors.New("timeboost_getExpressLaneRoundForBlock is only available on the sequencer")
+	}
+	round, err := a.sequencer.ExpressLaneRoundForBlock(uint64(blockNumber))
+	return hexutil.Uint64(round), err
+}
+
+func (a *ArbTimeboostAPI) ExpressLaneControllerHistory(ctx context.Context, fromRound, toRound hexutil.Uint64) ([]*ExpressLaneRoundControllers, error) {
+	if a.sequencer == nil {
+		return nil, errors.New("timeboost_expressLaneControllerHistory is only available on the sequencer")
+	}
+	return a.sequencer.ExpressLaneControllerHistory(ctx, uint64(fromRound), uint64(toRound))
+}
+
+// ExpressLaneSequenceStatus reports the express lane submissions buffered waiting on missing sequence numbers
+// for the given round, or the current one if omitted.
+func (a *ArbTimeboostAPI) ExpressLaneSequenceStatus(ctx context.Context, round *hexutil.Uint64) (*ExpressLaneSequenceStatus, error) {
+	if a.sequencer == nil {
+		return nil, errors.New("timeboost_expressLaneSequenceStatus is only available on the sequencer")
+	}
+	return a.sequencer.ExpressLaneSequenceStatus((*uint64)(round))
+}
+
+// ExpressLaneAdvantage reports the advantage the express lane controller currently has over other transactions.
+func (a *ArbTimeboostAPI) ExpressLaneAdvantage(ctx context.Context) (*ExpressLaneAdvantage, error) {
+	if a.sequencer == nil {
+		return nil, errors.New("timeboost_expressLaneAdvantage is only available on the sequencer")
+	}
+	return a.sequencer.ExpressLaneAdvantage()
+}
+
+// SimulateOrdering previews the order in which the sequencer would sequence the given express lane transactions,
+// in sequence number order, against the given regular transactions, without sequencing any of them.
+func (a *ArbTimeboostAPI) SimulateOrdering(ctx context.Context, expressLaneTxs []SimulatedTransaction, txs []SimulatedTransaction) ([]SimulatedOrderedTransaction, error) {
+	if a.sequencer == nil {
+		return nil, errors.New("timeboost_simulateOrdering is only available on the sequencer")
+	}
+	return a.sequencer.SimulateExpressLaneOrdering(expressLaneTxs, txs)
+}
+
+// ArbTimeboostAdminAPI is the authenticated part of the timeboost namespace for sequencer operators, and the
+// express lane controller's clients they give access to.
+type ArbTimeboostAdminAPI struct {
+	sequencer *Sequencer
+}
+
+func NewArbTimeboostAdminAPI(sequencer *Sequencer) *ArbTimeboostAdminAPI {
+	return &ArbTimeboostAdminAPI{sequencer}
+}
+
+// NextExpressLaneSequence reserves the next sequence number of the given round, or the current one if omitted,
+// for active-active express lane clients of the same controller that can't track the round's sequence themselves.
+// Reservations would otherwise let any client stall the round's express lane, hence the authentication.
+func (a *ArbTimeboostAdminAPI) NextExpressLaneSequence(ctx context.Context, round *hexutil.Uint64) (*timeboost.SequenceHint, error) {
+	if a.sequencer == nil {
+		return nil, errors.New("timeboost_nextExpressLaneSequence is only available on the sequencer")
+	}
+	return a.sequencer.ReserveExpressLaneSequenceNumber((*uint64)(round))
+}
+
+// PauseExpressLane stops giving the express lane controller priority, e.g. for maintenance, without a restart.
+func (a *ArbTimeboostAdminAPI) PauseExpressLane(ctx context.Context) error {
+	if a.sequencer == nil {
+		return errors.New("timeboost_pauseExpressLane is only available on the sequencer")
+	}
+	return a.sequencer.PauseExpressLane()
+}
+
+func (a *ArbTimeboostAdminAPI) ResumeExpressLane(ctx context.Context) error {
+	if a.sequencer == nil {
+		return errors.New("timeboost_resumeExpressLane is only available on the sequencer")
+	}
+	return a.sequencer.ResumeExpressLane()
+}
+
 type ArbDebugAPI struct {
 	blockchain        *core.BlockChain
 	blockRangeBound   uint64
@@ -0,0 +1,114 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/broadcaster/message"
+	"github.com/offchainlabs/nitro/timeboost"
+)
+
+type submissionRecordingPublisher struct {
+	TransactionPublisher
+	submissions []*timeboost.ExpressLaneSubmission
+}
+
+func (p *submissionRecordingPublisher) PublishExpressLaneTransaction(_ context.Context, msg *timeboost.ExpressLaneSubmission) error {
+	p.submissions = append(p.submissions, msg)
+	return nil
+}
+
+// unauthenticatedTimeboostClient serves the given APIs in process the way the regular http and ws endpoints do,
+// leaving out those only served on the JWT authenticated endpoint
+func unauthenticatedTimeboostClient(t *testing.T, apis []rpc.API) *rpc.Client {
+	t.Helper()
+	server := rpc.NewServer()
+	t.Cleanup(server.Stop)
+	for _, api := range apis {
+		if api.Authenticated {
+			continue
+		}
+		require.NoError(t, server.RegisterName(api.Namespace, api.Service))
+	}
+	return rpc.DialInProc(server)
+}
+
+func TestTimeboostSubmissionAuthentication(t *testing.T) {
+	ctx := context.Background()
+	submission := buildValidSubmissionWithSeqAndTx(t, 0, 0, emptyTx)
+	jsonSubmission, err := submission.ToJson()
+	require.NoError(t, err)
+
+	publisher := &submissionRecordingPublisher{}
+	client := unauthenticatedTimeboostClient(t, TimeboostAPIs(publisher, nil, nil, false))
+	require.NoError(t, client.CallContext(ctx, nil, "timeboost_sendExpressLaneTransaction", jsonSubmission))
+	require.Len(t, publisher.submissions, 1)
+
+	publisher = &submissionRecordingPublisher{}
+	client = unauthenticatedTimeboostClient(t, TimeboostAPIs(publisher, nil, nil, true))
+	err = client.CallContext(ctx, nil, "timeboost_sendExpressLaneTransaction", jsonSubmission)
+	require.Error(t, err)
+	var rpcErr rpc.Error
+	require.ErrorAs(t, err, &rpcErr)
+	require.Equal(t, -32601, rpcErr.ErrorCode()) // method not found
+	require.Empty(t, publisher.submissions)
+
+	// Read methods stay open
+	var status *ExpressLaneSequenceStatus
+	err = client.CallContext(ctx, &status, "timeboost_expressLaneSequenceStatus", hexutil.Uint64(0))
+	require.ErrorContains(t, err, "only available on the sequencer")
+}
+
+func TestTimeboostExpressLaneControllerFromFeed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tracker := timeboost.NewExpressLaneControllerTracker()
+	tracker.Start(ctx)
+	defer tracker.StopAndWait()
+	client := unauthenticatedTimeboostClient(t, TimeboostAPIs(&submissionRecordingPublisher{}, nil, tracker, false))
+	getController := func(round uint64) (common.Address, error) {
+		var controller common.Address
+		err := client.CallContext(ctx, &controller, "timeboost_getExpressLaneController", hexutil.Uint64(round))
+		return controller, err
+	}
+	requireController := func(round uint64, want common.Address) {
+		require.Eventually(t, func() bool {
+			controller, err := getController(round)
+			return err == nil && controller == want
+		}, time.Second, 10*time.Millisecond)
+	}
+
+	// Auction results and control transfers as received from the sequencer feed
+	alice := common.HexToAddress("0xa")
+	bob := common.HexToAddress("0xb")
+	for _, result := range []*message.AuctionResultMessage{
+		{Round: 1, Winner: alice, FirstPrice: big.NewInt(5), SecondPrice: big.NewInt(3)},
+		{Round: 2, Winner: bob, FirstPrice: big.NewInt(7), SecondPrice: big.NewInt(2)},
+	} {
+		tracker.AuctionResultListener() <- result
+	}
+	requireController(1, alice)
+	requireController(2, bob)
+	_, err := getController(3)
+	require.ErrorContains(t, err, timeboost.ErrNoOnchainController.Error())
+
+	tracker.ControllerTransferListener() <- &message.ControllerTransferMessage{Round: 2, PreviousController: bob, NewController: alice}
+	requireController(2, alice)
+
+	// Old rounds are forgotten as newer results arrive
+	tracker.AuctionResultListener() <- &message.AuctionResultMessage{Round: 100, Winner: alice, FirstPrice: big.NewInt(1), SecondPrice: big.NewInt(1)}
+	requireController(100, alice)
+	_, err = getController(1)
+	require.ErrorContains(t, err, timeboost.ErrNoOnchainController.Error())
+}
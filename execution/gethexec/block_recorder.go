@@ -43,6 +43,9 @@ type BlockRecorder struct {
 
 	preparedQueue []*types.Header
 	preparedLock  sync.Mutex
+
+	// chainContext, if set, is used instead of the recording chain context to produce recorded blocks
+	chainContext core.ChainContext
 }
 
 type BlockRecorderConfig struct {
@@ -77,6 +80,21 @@ func NewBlockRecorder(config *BlockRecorderConfig, execEngine *ExecutionEngine,
 	return recorder
 }
 
+// SetChainContext makes the recorder produce blocks with the given chain context, e.g. a synthetic chain for testing
+// forks, instead of the one backed by the blockchain. Headers read through it aren't recorded as preimages.
+// Must be called before recording starts, a nil chainContext restores the default
+func (r *BlockRecorder) SetChainContext(chainContext core.ChainContext) {
+	r.chainContext = chainContext
+}
+
+// blockChainContext returns the chain context to produce recorded blocks with
+func (r *BlockRecorder) blockChainContext(recordingChainContext core.ChainContext) core.ChainContext {
+	if r.chainContext != nil {
+		return r.chainContext
+	}
+	return recordingChainContext
+}
+
 func stateLogFunc(targetHeader *types.Header) arbitrum.StateBuildingLogFunction {
 	return func(header *types.Header, hasState bool) {
 		if targetHeader == nil || header == nil {
@@ -156,7 +174,7 @@ func (r *BlockRecorder) RecordBlockCreation(
 			msg.DelayedMessagesRead,
 			prevHeader,
 			recordingdb,
-			chaincontext,
+			r.blockChainContext(chaincontext),
 			chainConfig,
 			false,
 			core.MessageReplayMode,
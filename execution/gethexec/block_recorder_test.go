@@ -0,0 +1,44 @@
+// Copyright 2021-2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// stubChainContext serves headers of a synthetic chain
+type stubChainContext struct {
+	headers map[common.Hash]*types.Header
+}
+
+func (c *stubChainContext) Engine() consensus.Engine {
+	return nil
+}
+
+func (c *stubChainContext) GetHeader(hash common.Hash, _ uint64) *types.Header {
+	return c.headers[hash]
+}
+
+func TestBlockRecorderChainContext(t *testing.T) {
+	recording := &stubChainContext{}
+	recorder := &BlockRecorder{}
+	if recorder.blockChainContext(recording) != recording {
+		t.Fatal("expected the recording chain context to be used by default")
+	}
+
+	stub := &stubChainContext{headers: make(map[common.Hash]*types.Header)}
+	recorder.SetChainContext(stub)
+	if recorder.blockChainContext(recording) != stub {
+		t.Fatal("expected the custom chain context to be used")
+	}
+
+	recorder.SetChainContext(nil)
+	if recorder.blockChainContext(recording) != recording {
+		t.Fatal("expected the recording chain context to be restored")
+	}
+}
@@ -4,18 +4,62 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/lru"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/util/arbmath"
 	"github.com/offchainlabs/nitro/util/stopwaiter"
 )
 
 var ErrBlockMetadataApiBlocksLimitExceeded = errors.New("number of blocks requested for blockMetadata exceeded")
+var ErrBlockMetadataCacheWarmupRunning = errors.New("blockMetadata cache warmup already running")
+
+var (
+	blockMetadataCacheHitCounter  = metrics.NewRegisteredCounter("arb/blockmetadata/cache/hit", nil)
+	blockMetadataCacheMissCounter = metrics.NewRegisteredCounter("arb/blockmetadata/cache/miss", nil)
+	blockMetadataCacheSizeGauge   = metrics.NewRegisteredGauge("arb/blockmetadata/cache/size", nil)
+)
+
+// BlockNumberOrRelative is an rpc.BlockNumber that additionally accepts relative specifiers, a negative number -N
+// refers to the block N blocks before latest
+type BlockNumberOrRelative struct {
+	BlockNumber        rpc.BlockNumber
+	BlocksBeforeLatest *uint64
+}
+
+func (b *BlockNumberOrRelative) UnmarshalJSON(data []byte) error {
+	input := strings.Trim(strings.TrimSpace(string(data)), `"`)
+	if strings.HasPrefix(input, "-") {
+		blocksBeforeLatest, err := strconv.ParseUint(input[1:], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid relative block number %s: %w", input, err)
+		}
+		b.BlocksBeforeLatest = &blocksBeforeLatest
+		return nil
+	}
+	return b.BlockNumber.UnmarshalJSON(data)
+}
+
+// resolve converts a relative block number to an absolute one given the latest block number
+func (b BlockNumberOrRelative) resolve(latest uint64) rpc.BlockNumber {
+	if b.BlocksBeforeLatest == nil {
+		return b.BlockNumber
+	}
+	// #nosec G115
+	return rpc.BlockNumber(arbmath.SaturatingUSub(latest, *b.BlocksBeforeLatest))
+}
 
 type BlockMetadataFetcher interface {
 	BlockMetadataAtCount(count arbutil.MessageIndex) (common.BlockMetadata, error)
@@ -32,14 +76,16 @@ type BulkBlockMetadataFetcher struct {
 	fetcher       BlockMetadataFetcher
 	reorgDetector chan struct{}
 	blocksLimit   uint64
-	cache         *lru.SizeConstrainedCache[arbutil.MessageIndex, common.BlockMetadata]
+	clampLatest   bool
+	cache         *blockMetadataCache
+	warming       atomic.Bool
 }
 
-func NewBulkBlockMetadataFetcher(bc *core.BlockChain, fetcher BlockMetadataFetcher, cacheSize, blocksLimit uint64) *BulkBlockMetadataFetcher {
-	var cache *lru.SizeConstrainedCache[arbutil.MessageIndex, common.BlockMetadata]
+func NewBulkBlockMetadataFetcher(bc *core.BlockChain, fetcher BlockMetadataFetcher, cacheSize, blocksLimit uint64, clampLatest bool) *BulkBlockMetadataFetcher {
+	var cache *blockMetadataCache
 	var reorgDetector chan struct{}
 	if cacheSize != 0 {
-		cache = lru.NewSizeConstrainedCache[arbutil.MessageIndex, common.BlockMetadata](cacheSize)
+		cache = newBlockMetadataCache(cacheSize)
 		reorgDetector = make(chan struct{})
 		fetcher.SetReorgEventsNotifier(reorgDetector)
 	}
@@ -49,12 +95,21 @@ func NewBulkBlockMetadataFetcher(bc *core.BlockChain, fetcher BlockMetadataFetch
 		cache:         cache,
 		reorgDetector: reorgDetector,
 		blocksLimit:   blocksLimit,
+		clampLatest:   clampLatest,
 	}
 }
 
 // Fetch won't include block numbers for whom consensus (arbDB) doesn't have blockMetadata, it stores recently fetched blockMetadata into an LRU
-// which is cleared in the events of reorg in order to provide accurate blockMetadata
-func (b *BulkBlockMetadataFetcher) Fetch(fromBlock, toBlock rpc.BlockNumber) ([]NumberAndBlockMetadata, error) {
+// which is cleared in the events of reorg in order to provide accurate blockMetadata. If clampLatest is enabled, a range ending at
+// "latest" that exceeds the blocks limit is clamped to the most recent blocks instead of erroring. Relative block numbers are
+// resolved against the current head before the range is expanded
+func (b *BulkBlockMetadataFetcher) Fetch(from, to BlockNumberOrRelative) ([]NumberAndBlockMetadata, error) {
+	fromBlock, toBlock := from.BlockNumber, to.BlockNumber
+	if from.BlocksBeforeLatest != nil || to.BlocksBeforeLatest != nil {
+		latest := b.bc.CurrentBlock().Number.Uint64()
+		fromBlock, toBlock = from.resolve(latest), to.resolve(latest)
+	}
+	openEnded := toBlock == rpc.LatestBlockNumber
 	fromBlock, _ = b.bc.ClipToPostNitroGenesis(fromBlock)
 	toBlock, _ = b.bc.ClipToPostNitroGenesis(toBlock)
 	// #nosec G115
@@ -71,23 +126,16 @@ func (b *BulkBlockMetadataFetcher) Fetch(fromBlock, toBlock rpc.BlockNumber) ([]
 		return nil, fmt.Errorf("invalid inputs, fromBlock: %d is greater than toBlock: %d", fromBlock, toBlock)
 	}
 	if b.blocksLimit > 0 && end-start+1 > arbutil.MessageIndex(b.blocksLimit) {
-		return nil, fmt.Errorf("%w. Range requested- %d, Limit- %d", ErrBlockMetadataApiBlocksLimitExceeded, end-start+1, b.blocksLimit)
+		if !b.clampLatest || !openEnded {
+			return nil, fmt.Errorf("%w. Range requested- %d, Limit- %d", ErrBlockMetadataApiBlocksLimitExceeded, end-start+1, b.blocksLimit)
+		}
+		start = end - arbutil.MessageIndex(b.blocksLimit) + 1
 	}
 	var result []NumberAndBlockMetadata
 	for i := start; i <= end; i++ {
-		var data common.BlockMetadata
-		var found bool
-		if b.cache != nil {
-			data, found = b.cache.Get(i)
-		}
-		if !found {
-			data, err = b.fetcher.BlockMetadataAtCount(i + 1)
-			if err != nil {
-				return nil, err
-			}
-			if data != nil && b.cache != nil {
-				b.cache.Add(i, data)
-			}
+		data, err := b.blockMetadataAt(i)
+		if err != nil {
+			return nil, err
 		}
 		if data != nil {
 			result = append(result, NumberAndBlockMetadata{
@@ -99,6 +147,107 @@ func (b *BulkBlockMetadataFetcher) Fetch(fromBlock, toBlock rpc.BlockNumber) ([]
 	return result, nil
 }
 
+// FetchBlocks returns blockMetadata for an arbitrary list of block numbers, in the order requested. Unlike Fetch, blocks
+// for whom consensus (arbDB) doesn't have blockMetadata are included in the result with empty metadata
+func (b *BulkBlockMetadataFetcher) FetchBlocks(blockNumbers []uint64) ([]NumberAndBlockMetadata, error) {
+	if b.blocksLimit > 0 && uint64(len(blockNumbers)) > b.blocksLimit {
+		return nil, fmt.Errorf("%w. Blocks requested- %d, Limit- %d", ErrBlockMetadataApiBlocksLimitExceeded, len(blockNumbers), b.blocksLimit)
+	}
+	result := make([]NumberAndBlockMetadata, 0, len(blockNumbers))
+	for _, blockNumber := range blockNumbers {
+		var data common.BlockMetadata
+		// Blocks prior to nitro genesis can't be converted to a message index and don't have blockMetadata
+		if index, err := b.fetcher.BlockNumberToMessageIndex(blockNumber); err == nil {
+			data, err = b.blockMetadataAt(index)
+			if err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, NumberAndBlockMetadata{
+			BlockNumber: blockNumber,
+			RawMetadata: (hexutil.Bytes)(data),
+		})
+	}
+	return result, nil
+}
+
+// blockMetadataAt returns the blockMetadata of the block at the given message index from the LRU if present, otherwise from consensus
+func (b *BulkBlockMetadataFetcher) blockMetadataAt(index arbutil.MessageIndex) (common.BlockMetadata, error) {
+	if b.cache != nil {
+		if data, found := b.cache.Get(index); found {
+			blockMetadataCacheHitCounter.Inc(1)
+			return data, nil
+		}
+		blockMetadataCacheMissCounter.Inc(1)
+	}
+	data, err := b.fetcher.BlockMetadataAtCount(index + 1)
+	if err != nil {
+		return nil, err
+	}
+	if data != nil && b.cache != nil {
+		b.cache.Add(index, data)
+	}
+	return data, nil
+}
+
+// WarmCache pre-populates the LRU in the background with blockMetadata of blocks in the given range, most recent blocks first.
+// Warming stops as soon as adding to the LRU evicts an entry, so that it never evicts more than it adds.
+// Only one warmup runs at a time, a request made while one is in progress is rejected. The range is clamped to the
+// current head and, if a blocks limit is set, to its most recent blocks within the limit
+func (b *BulkBlockMetadataFetcher) WarmCache(fromBlock, toBlock uint64) error {
+	if b.cache == nil {
+		return errors.New("blockMetadata cache is disabled")
+	}
+	if b.bc != nil {
+		toBlock = min(toBlock, b.bc.CurrentBlock().Number.Uint64())
+	}
+	if fromBlock > toBlock {
+		return fmt.Errorf("invalid inputs, fromBlock: %d is greater than toBlock: %d", fromBlock, toBlock)
+	}
+	start, err := b.fetcher.BlockNumberToMessageIndex(fromBlock)
+	if err != nil {
+		return fmt.Errorf("error converting fromBlock blocknumber to message index: %w", err)
+	}
+	end, err := b.fetcher.BlockNumberToMessageIndex(toBlock)
+	if err != nil {
+		return fmt.Errorf("error converting toBlock blocknumber to message index: %w", err)
+	}
+	if b.blocksLimit > 0 && end-start+1 > arbutil.MessageIndex(b.blocksLimit) {
+		start = end - arbutil.MessageIndex(b.blocksLimit) + 1
+	}
+	if !b.warming.CompareAndSwap(false, true) {
+		return ErrBlockMetadataCacheWarmupRunning
+	}
+	err = b.LaunchThreadSafe(func(ctx context.Context) {
+		defer b.warming.Store(false)
+		added := 0
+		for i := end; ctx.Err() == nil; i-- {
+			if _, found := b.cache.Get(i); !found {
+				data, err := b.fetcher.BlockMetadataAtCount(i + 1)
+				if err != nil {
+					log.Warn("Error fetching blockMetadata to warm up cache", "block", b.fetcher.MessageIndexToBlockNumber(i), "err", err)
+					return
+				}
+				if data != nil {
+					if evicted := b.cache.Add(i, data); evicted {
+						log.Info("blockMetadata cache is full, stopping warmup", "block", b.fetcher.MessageIndexToBlockNumber(i), "added", added)
+						return
+					}
+					added++
+				}
+			}
+			if i == start {
+				break
+			}
+		}
+		log.Info("Finished warming up blockMetadata cache", "fromBlock", b.fetcher.MessageIndexToBlockNumber(start), "toBlock", toBlock, "added", added)
+	})
+	if err != nil {
+		b.warming.Store(false)
+	}
+	return err
+}
+
 func (b *BulkBlockMetadataFetcher) ClearCache(ctx context.Context, ignored struct{}) {
 	b.cache.Clear()
 }
@@ -113,3 +262,55 @@ func (b *BulkBlockMetadataFetcher) Start(ctx context.Context) {
 func (b *BulkBlockMetadataFetcher) StopAndWait() {
 	b.StopWaiter.StopAndWait()
 }
+
+// blockMetadataCache is an LRU of blockMetadata constrained by the total size of the cached blockMetadata, it keeps track of
+// its current size so that the cache occupancy can be reported
+type blockMetadataCache struct {
+	lock    sync.Mutex
+	lru     lru.BasicLRU[arbutil.MessageIndex, common.BlockMetadata]
+	size    uint64
+	maxSize uint64
+}
+
+func newBlockMetadataCache(maxSize uint64) *blockMetadataCache {
+	return &blockMetadataCache{
+		lru:     lru.NewBasicLRU[arbutil.MessageIndex, common.BlockMetadata](math.MaxInt),
+		maxSize: maxSize,
+	}
+}
+
+// Add adds blockMetadata to the cache, evicting least recently used entries until it fits. Returns true if an entry was evicted
+func (c *blockMetadataCache) Add(index arbutil.MessageIndex, data common.BlockMetadata) (evicted bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if prev, found := c.lru.Peek(index); found {
+		c.size -= uint64(len(prev))
+	}
+	c.lru.Add(index, data)
+	c.size += uint64(len(data))
+	for c.size > c.maxSize {
+		_, oldest, ok := c.lru.RemoveOldest()
+		if !ok {
+			break
+		}
+		c.size -= uint64(len(oldest))
+		evicted = true
+	}
+	// #nosec G115
+	blockMetadataCacheSizeGauge.Update(int64(c.size))
+	return evicted
+}
+
+func (c *blockMetadataCache) Get(index arbutil.MessageIndex) (common.BlockMetadata, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lru.Get(index)
+}
+
+func (c *blockMetadataCache) Clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.lru.Purge()
+	c.size = 0
+	blockMetadataCacheSizeGauge.Update(0)
+}
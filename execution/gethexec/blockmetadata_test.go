@@ -0,0 +1,205 @@
+package gethexec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/arbutil"
+)
+
+type testBlockMetadataFetcher struct {
+	genesis       uint64
+	blockMetadata map[arbutil.MessageIndex]common.BlockMetadata
+	reads         atomic.Int64
+	// If set, reads block until it is closed
+	release chan struct{}
+}
+
+func (f *testBlockMetadataFetcher) BlockMetadataAtCount(count arbutil.MessageIndex) (common.BlockMetadata, error) {
+	f.reads.Add(1)
+	if f.release != nil {
+		<-f.release
+	}
+	return f.blockMetadata[count-1], nil
+}
+
+func (f *testBlockMetadataFetcher) BlockNumberToMessageIndex(blockNum uint64) (arbutil.MessageIndex, error) {
+	if blockNum < f.genesis {
+		return 0, fmt.Errorf("blockNum %d < genesis %d", blockNum, f.genesis)
+	}
+	return arbutil.MessageIndex(blockNum - f.genesis), nil
+}
+
+func (f *testBlockMetadataFetcher) MessageIndexToBlockNumber(messageNum arbutil.MessageIndex) uint64 {
+	return uint64(messageNum) + f.genesis
+}
+
+func (f *testBlockMetadataFetcher) SetReorgEventsNotifier(reorgEventsNotifier chan struct{}) {}
+
+func newTestBlockMetadataFetcher(genesis uint64, numBlocks int) *testBlockMetadataFetcher {
+	fetcher := &testBlockMetadataFetcher{
+		genesis:       genesis,
+		blockMetadata: make(map[arbutil.MessageIndex]common.BlockMetadata),
+	}
+	for i := 0; i < numBlocks; i++ {
+		fetcher.blockMetadata[arbutil.MessageIndex(i)] = common.BlockMetadata{0, byte(i)}
+	}
+	return fetcher
+}
+
+func TestBulkBlockMetadataFetcherFetchBlocks(t *testing.T) {
+	fetcher := newTestBlockMetadataFetcher(10, 20)
+	delete(fetcher.blockMetadata, 5) // Block 15 is missing blockMetadata
+	bulkFetcher := NewBulkBlockMetadataFetcher(nil, fetcher, 0, 4, false)
+
+	result, err := bulkFetcher.FetchBlocks([]uint64{27, 15, 11, 5})
+	require.NoError(t, err)
+	require.Len(t, result, 4)
+	require.Equal(t, uint64(27), result[0].BlockNumber)
+	require.Equal(t, []byte{0, 17}, []byte(result[0].RawMetadata))
+	// Missing blocks, whether without blockMetadata or prior to genesis, are returned with empty metadata
+	require.Equal(t, uint64(15), result[1].BlockNumber)
+	require.Empty(t, result[1].RawMetadata)
+	require.Equal(t, uint64(11), result[2].BlockNumber)
+	require.Equal(t, []byte{0, 1}, []byte(result[2].RawMetadata))
+	require.Equal(t, uint64(5), result[3].BlockNumber)
+	require.Empty(t, result[3].RawMetadata)
+
+	_, err = bulkFetcher.FetchBlocks([]uint64{11, 12, 13, 14, 16})
+	require.True(t, errors.Is(err, ErrBlockMetadataApiBlocksLimitExceeded))
+}
+
+func TestBulkBlockMetadataFetcherWarmCache(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fetcher := newTestBlockMetadataFetcher(10, 20)
+	// Each blockMetadata is 2 bytes, so the cache fits 5 of them
+	bulkFetcher := NewBulkBlockMetadataFetcher(nil, fetcher, 10, 0, false)
+	bulkFetcher.Start(ctx)
+	defer bulkFetcher.StopAndWait()
+
+	require.NoError(t, bulkFetcher.WarmCache(10, 29))
+	// The five most recent blocks are cached, and adding the sixth evicts one of them which stops the warmup
+	require.Eventually(t, func() bool {
+		return fetcher.reads.Load() == 6
+	}, 5*time.Second, 10*time.Millisecond)
+	// Give the warmup a chance to go past its stopping point if it were to ignore the cache size
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, int64(6), fetcher.reads.Load())
+
+	// Post-warmup queries of the most recent blocks hit the cache
+	readsBefore := fetcher.reads.Load()
+	result, err := bulkFetcher.FetchBlocks([]uint64{26, 27, 28, 29})
+	require.NoError(t, err)
+	require.Len(t, result, 4)
+	require.Equal(t, []byte{0, 19}, []byte(result[3].RawMetadata))
+	require.Equal(t, readsBefore, fetcher.reads.Load())
+
+	require.Error(t, bulkFetcher.WarmCache(20, 10))
+}
+
+func TestBulkBlockMetadataFetcherWarmCacheBlocksLimit(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fetcher := newTestBlockMetadataFetcher(10, 20)
+	bulkFetcher := NewBulkBlockMetadataFetcher(nil, fetcher, 100, 3, false)
+	bulkFetcher.Start(ctx)
+	defer bulkFetcher.StopAndWait()
+
+	// Only the most recent blocks within the blocks limit are warmed
+	require.NoError(t, bulkFetcher.WarmCache(10, 29))
+	require.Eventually(t, func() bool {
+		return !bulkFetcher.warming.Load()
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, int64(3), fetcher.reads.Load())
+	result, err := bulkFetcher.FetchBlocks([]uint64{27, 28, 29})
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+	require.Equal(t, int64(3), fetcher.reads.Load())
+}
+
+func TestBulkBlockMetadataFetcherWarmCacheRejectsConcurrentWarmup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fetcher := newTestBlockMetadataFetcher(10, 20)
+	fetcher.release = make(chan struct{})
+	bulkFetcher := NewBulkBlockMetadataFetcher(nil, fetcher, 10, 0, false)
+	bulkFetcher.Start(ctx)
+	defer bulkFetcher.StopAndWait()
+
+	require.NoError(t, bulkFetcher.WarmCache(10, 29))
+	require.Eventually(t, func() bool {
+		return fetcher.reads.Load() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.ErrorIs(t, bulkFetcher.WarmCache(10, 29), ErrBlockMetadataCacheWarmupRunning)
+
+	// Once the running warmup finishes a new one is accepted
+	close(fetcher.release)
+	require.Eventually(t, func() bool {
+		return bulkFetcher.WarmCache(10, 29) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestBulkBlockMetadataFetcherCacheMetrics(t *testing.T) {
+	fetcher := newTestBlockMetadataFetcher(10, 20)
+	// Each blockMetadata is 2 bytes, so the cache fits 5 of them
+	bulkFetcher := NewBulkBlockMetadataFetcher(nil, fetcher, 10, 0, false)
+	hits := blockMetadataCacheHitCounter.Snapshot().Count()
+	misses := blockMetadataCacheMissCounter.Snapshot().Count()
+
+	_, err := bulkFetcher.FetchBlocks([]uint64{10, 11, 12})
+	require.NoError(t, err)
+	require.Equal(t, hits, blockMetadataCacheHitCounter.Snapshot().Count())
+	require.Equal(t, misses+3, blockMetadataCacheMissCounter.Snapshot().Count())
+	require.Equal(t, int64(6), blockMetadataCacheSizeGauge.Snapshot().Value())
+
+	_, err = bulkFetcher.FetchBlocks([]uint64{11, 12, 13, 14, 15})
+	require.NoError(t, err)
+	require.Equal(t, hits+2, blockMetadataCacheHitCounter.Snapshot().Count())
+	require.Equal(t, misses+6, blockMetadataCacheMissCounter.Snapshot().Count())
+	// Adding the sixth blockMetadata evicted the least recently used one
+	require.Equal(t, int64(10), blockMetadataCacheSizeGauge.Snapshot().Value())
+
+	bulkFetcher.ClearCache(context.Background(), struct{}{})
+	require.Equal(t, int64(0), blockMetadataCacheSizeGauge.Snapshot().Value())
+
+	// Lookups aren't counted when the cache is disabled
+	_, err = NewBulkBlockMetadataFetcher(nil, fetcher, 0, 0, false).FetchBlocks([]uint64{10})
+	require.NoError(t, err)
+	require.Equal(t, hits+2, blockMetadataCacheHitCounter.Snapshot().Count())
+	require.Equal(t, misses+6, blockMetadataCacheMissCounter.Snapshot().Count())
+}
+
+func TestBlockNumberOrRelative(t *testing.T) {
+	var relative BlockNumberOrRelative
+	require.NoError(t, relative.UnmarshalJSON([]byte(`"-100"`)))
+	require.NotNil(t, relative.BlocksBeforeLatest)
+	require.Equal(t, uint64(100), *relative.BlocksBeforeLatest)
+	require.Equal(t, rpc.BlockNumber(900), relative.resolve(1000))
+	require.Equal(t, rpc.BlockNumber(0), relative.resolve(50))
+
+	var unquoted BlockNumberOrRelative
+	require.NoError(t, unquoted.UnmarshalJSON([]byte(`-5`)))
+	require.Equal(t, rpc.BlockNumber(995), unquoted.resolve(1000))
+
+	var latest BlockNumberOrRelative
+	require.NoError(t, latest.UnmarshalJSON([]byte(`"latest"`)))
+	require.Nil(t, latest.BlocksBeforeLatest)
+	require.Equal(t, rpc.LatestBlockNumber, latest.resolve(1000))
+
+	var absolute BlockNumberOrRelative
+	require.NoError(t, absolute.UnmarshalJSON([]byte(`"0x10"`)))
+	require.Equal(t, rpc.BlockNumber(16), absolute.resolve(1000))
+
+	var invalid BlockNumberOrRelative
+	require.Error(t, invalid.UnmarshalJSON([]byte(`"-0x10"`)))
+}
@@ -7,7 +7,11 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"math/big"
+	"runtime"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
@@ -16,6 +20,7 @@ import (
 	"github.com/ethereum/go-ethereum/arbitrum"
 	"github.com/ethereum/go-ethereum/arbitrum_types"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/eth/filters"
@@ -26,6 +31,7 @@ import (
 
 	"github.com/offchainlabs/nitro/solgen/go/express_lane_auctiongen"
 	"github.com/offchainlabs/nitro/timeboost"
+	"github.com/offchainlabs/nitro/util/arbmath"
 	"github.com/offchainlabs/nitro/util/containers"
 	"github.com/offchainlabs/nitro/util/stopwaiter"
 )
@@ -38,6 +44,77 @@ type transactionPublisher interface {
 	PublishTimeboostedTransaction(context.Context, *types.Transaction, *arbitrum_types.ConditionalOptions, chan error)
 }
 
+// resolvedRoundsFetcher returns one of the last two resolved rounds stored in the auction contract,
+// matching the signature of the ExpressLaneAuction's ResolvedRounds binding.
+type resolvedRoundsFetcher func(opts *bind.CallOpts, i *big.Int) (struct {
+	ExpressLaneController common.Address
+	Round                 uint64
+}, error)
+
+// maxControllerHistoryRounds bounds the number of rounds that can be queried in a single controller history request
+const maxControllerHistoryRounds = 1000
+
+// ExpressLaneControllerTransfer is a mid-round transfer of express lane control.
+type ExpressLaneControllerTransfer struct {
+	Timestamp          hexutil.Uint64 `json:"timestamp"`
+	PreviousController common.Address `json:"previousController"`
+	NewController      common.Address `json:"newController"`
+}
+
+// ExpressLaneRoundControllers is the express lane controller history of a single round.
+type ExpressLaneRoundControllers struct {
+	Round             hexutil.Uint64                  `json:"round"`
+	InitialController common.Address                  `json:"initialController"`
+	Transfers         []ExpressLaneControllerTransfer `json:"transfers"`
+}
+
+// ExpressLaneSequenceStatus describes the express lane submissions accepted for a round, to help debug stuck bundles.
+// Submissions with sequence numbers past NextSequenceNumber are buffered until all of the ones before them arrive.
+type ExpressLaneSequenceStatus struct {
+	Round              hexutil.Uint64   `json:"round"`
+	NextSequenceNumber hexutil.Uint64   `json:"nextSequenceNumber"`
+	BufferedSequences  []hexutil.Uint64 `json:"bufferedSequences"`
+	MissingSequences   []hexutil.Uint64 `json:"missingSequences"`
+}
+
+// ExpressLaneAdvantage is the delay the sequencer applies to transactions not sent through the express lane
+// in the given round, which is zero unless the round has an express lane controller.
+type ExpressLaneAdvantage struct {
+	Round                 hexutil.Uint64 `json:"round"`
+	HasController         bool           `json:"hasController"`
+	Paused                bool           `json:"paused"`
+	ConfiguredAdvantageMs hexutil.Uint64 `json:"configuredAdvantageMs"`
+	AdvantageMs           hexutil.Uint64 `json:"advantageMs"`
+}
+
+// SimulatedTransaction is a transaction arriving at the sequencer at the given offset from the start of an ordering simulation.
+type SimulatedTransaction struct {
+	Transaction     hexutil.Bytes  `json:"transaction"`
+	ArrivalOffsetMs hexutil.Uint64 `json:"arrivalOffsetMs"`
+}
+
+// SimulatedOrderedTransaction is a transaction's place in the order resulting from an ordering simulation.
+type SimulatedOrderedTransaction struct {
+	TxHash      common.Hash    `json:"txHash"`
+	Timeboosted bool           `json:"timeboosted"`
+	QueuedAtMs  hexutil.Uint64 `json:"queuedAtMs"`
+}
+
+// controllerChange is a SetExpressLaneController event as emitted by the auction contract,
+// with a zero previous controller indicating the controller set at auction resolution.
+type controllerChange struct {
+	round      uint64
+	previous   common.Address
+	controller common.Address
+	timestamp  uint64
+}
+
+// AuctionResultListener is notified of each express lane auction resolved on the parent chain
+type AuctionResultListener func(round uint64, winner common.Address, firstPrice, secondPrice *big.Int)
+
+// ControllerTransferListener is notified of each transfer of a round's express lane control on the parent chain
+type ControllerTransferListener func(round uint64, previous, controller common.Address)
+
 type msgAndResult struct {
 	msg        *timeboost.ExpressLaneSubmission
 	resultChan chan error
@@ -48,21 +125,37 @@ type expressLaneRoundInfo struct {
 	msgAndResultBySequenceNumber map[uint64]*msgAndResult
 }
 
+// sequenceReservations are the sequence numbers of a round handed out via reserveSequenceNumber.
+type sequenceReservations struct {
+	next uint64
+	// reserved sequence numbers not known to be submitted yet, and when their reservation lapses
+	lapseAt map[uint64]time.Time
+}
+
 type expressLaneService struct {
 	stopwaiter.StopWaiter
-	transactionPublisher transactionPublisher
-	seqConfig            SequencerConfigFetcher
-	auctionContractAddr  common.Address
-	apiBackend           *arbitrum.APIBackend
-	roundTimingInfo      timeboost.RoundTimingInfo
-	earlySubmissionGrace time.Duration
-	chainConfig          *params.ChainConfig
-	auctionContract      *express_lane_auctiongen.ExpressLaneAuction
-	redisCoordinator     *timeboost.RedisCoordinator
-	roundControl         containers.SyncMap[uint64, common.Address] // thread safe
+	transactionPublisher       transactionPublisher
+	seqConfig                  SequencerConfigFetcher
+	auctionContractAddr        common.Address
+	apiBackend                 *arbitrum.APIBackend
+	roundTimingInfo            timeboost.RoundTimingInfo
+	earlySubmissionGrace       time.Duration
+	maxClockSkew               time.Duration
+	parentChainTime            func() (time.Time, error) // nil disables clock skew correction
+	chainConfig                *params.ChainConfig
+	auctionContract            *express_lane_auctiongen.ExpressLaneAuction
+	redisCoordinator           *timeboost.RedisCoordinator
+	roundControl               containers.SyncMap[uint64, common.Address] // thread safe
+	recoveryTokens             chan struct{}
+	auctionResultListener      AuctionResultListener
+	controllerTransferListener ControllerTransferListener
 
 	roundInfoMutex sync.Mutex
 	roundInfo      *containers.LruCache[uint64, *expressLaneRoundInfo]
+	// sequence numbers handed out per round via reserveSequenceNumber, guarded by roundInfoMutex
+	sequenceReservations *containers.LruCache[uint64, *sequenceReservations]
+	// while paused the express lane advantage isn't applied, set under roundInfoMutex
+	paused atomic.Bool
 }
 
 func newExpressLaneService(
@@ -111,7 +204,13 @@ pending:
 		}
 	}
 
+	recoveryWorkers := seqConfig().Dangerous.Timeboost.SenderRecoveryWorkers
+	if recoveryWorkers == 0 {
+		recoveryWorkers = runtime.NumCPU()
+	}
+
 	return &expressLaneService{
+		recoveryTokens:       make(chan struct{}, recoveryWorkers),
 		transactionPublisher: transactionPublisher,
 		seqConfig:            seqConfig,
 		auctionContract:      auctionContract,
@@ -119,9 +218,11 @@ pending:
 		chainConfig:          chainConfig,
 		roundTimingInfo:      *roundTimingInfo,
 		earlySubmissionGrace: earlySubmissionGrace,
+		maxClockSkew:         seqConfig().Dangerous.Timeboost.MaxClockSkew,
 		auctionContractAddr:  auctionContractAddr,
 		redisCoordinator:     redisCoordinator,
 		roundInfo:            containers.NewLruCache[uint64, *expressLaneRoundInfo](8),
+		sequenceReservations: containers.NewLruCache[uint64, *sequenceReservations](8),
 	}, nil
 }
 
@@ -155,7 +256,7 @@ func (es *expressLaneService) Start(ctxIn context.Context) {
 			case t = <-ticker.C:
 			}
 
-			round := es.roundTimingInfo.RoundNumber()
+			round := es.currentRound()
 			// TODO (BUG?) is there a race here where messages for a new round can come
 			// in before this tick has been processed?
 			log.Info(
@@ -233,6 +334,24 @@ func (es *expressLaneService) Start(ctxIn context.Context) {
 					"timeSinceAuctionClose", timeSinceAuctionClose,
 				)
 				es.roundControl.Store(it.Event.Round, it.Event.FirstPriceExpressLaneController)
+				if es.auctionResultListener != nil {
+					es.auctionResultListener(it.Event.Round, it.Event.FirstPriceExpressLaneController, it.Event.FirstPriceAmount, it.Event.Price)
+				}
+			}
+
+			if es.controllerTransferListener != nil {
+				transfers, err := es.auctionContract.FilterSetExpressLaneController(filterOpts, nil, nil, nil)
+				if err != nil {
+					log.Error("Could not filter express lane controller transfer events", "error", err)
+					continue
+				}
+				for transfers.Next() {
+					// A zero previous controller marks the controller set at auction resolution, reported above
+					if transfers.Event.PreviousExpressLaneController == (common.Address{}) {
+						continue
+					}
+					es.controllerTransferListener(transfers.Event.Round, transfers.Event.PreviousExpressLaneController, transfers.Event.NewExpressLaneController)
+				}
 			}
 
 			// setExpressLaneIterator, err := es.auctionContract.FilterSetExpressLaneController(filterOpts, nil, nil, nil)
@@ -292,6 +411,291 @@ func (es *expressLaneService) Start(ctxIn context.Context) {
 			fromBlock = toBlock + 1
 		}
 	})
+
+	es.CallIteratively(func(ctx context.Context) time.Duration {
+		interval := es.seqConfig().Dangerous.Timeboost.ControllerReconcileInterval
+		if interval == 0 {
+			return time.Minute // Disabled, check again later in case of config change
+		}
+		es.reconcileRoundController(ctx, es.auctionContract.ResolvedRounds)
+		return interval
+	})
+}
+
+// reconcileRoundController reads the current round's express lane controller from the auction contract
+// and corrects the in-memory controller state if it has drifted, e.g. due to a reorg or a missed event.
+func (es *expressLaneService) reconcileRoundController(ctx context.Context, fetchResolvedRound resolvedRoundsFetcher) {
+	currentRound := es.currentRound()
+	var onchainController common.Address
+	// The contract stores the last two resolved rounds, either of which could be the current one
+	for i := int64(0); i < 2; i++ {
+		resolved, err := fetchResolvedRound(&bind.CallOpts{Context: ctx}, big.NewInt(i))
+		if err != nil {
+			log.Error("Could not fetch resolved round from the auction contract for reconciliation", "index", i, "err", err)
+			return
+		}
+		if resolved.Round == currentRound {
+			onchainController = resolved.ExpressLaneController
+			break
+		}
+	}
+	controller, ok := es.roundControl.Load(currentRound)
+	if !ok {
+		controller = common.Address{}
+	}
+	if controller == onchainController {
+		return
+	}
+	log.Warn("Express lane controller drifted from the auction contract, correcting in-memory state",
+		"round", currentRound,
+		"controller", controller,
+		"onchainController", onchainController)
+	if onchainController == (common.Address{}) {
+		es.roundControl.Delete(currentRound)
+	} else {
+		es.roundControl.Store(currentRound, onchainController)
+	}
+	// Sequence numbers tracked so far belong to the wrong controller, so start the round afresh
+	es.roundInfoMutex.Lock()
+	if es.roundInfo.Contains(currentRound) {
+		es.roundInfo.Add(currentRound, &expressLaneRoundInfo{
+			0,
+			make(map[uint64]*msgAndResult),
+		})
+	}
+	es.sequenceReservations.Remove(currentRound)
+	es.roundInfoMutex.Unlock()
+}
+
+// controllerHistory reconstructs the express lane controllers of the given (inclusive) range of rounds
+// from the SetExpressLaneController events emitted by the auction contract.
+func (es *expressLaneService) controllerHistory(ctx context.Context, fromRound, toRound uint64) ([]*ExpressLaneRoundControllers, error) {
+	if fromRound > toRound {
+		return nil, fmt.Errorf("fromRound %d is greater than toRound %d", fromRound, toRound)
+	}
+	if toRound-fromRound >= maxControllerHistoryRounds {
+		return nil, fmt.Errorf("requested %d rounds, exceeding the limit of %d", toRound-fromRound+1, maxControllerHistoryRounds)
+	}
+
+	latestHeader, err := es.apiBackend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	latestBlock := latestHeader.Number.Uint64()
+	// A round's controller is set by the auction resolution during the previous round
+	startTime := es.roundTimingInfo.Offset.Add(es.roundTimingInfo.Round * arbmath.SaturatingCast[time.Duration](arbmath.SaturatingUSub(fromRound, 1)))
+	endTime := es.roundTimingInfo.Offset.Add(es.roundTimingInfo.Round * arbmath.SaturatingCast[time.Duration](arbmath.SaturatingUAdd(toRound, 1)))
+	fromBlock, err := es.firstBlockAtOrAfter(ctx, startTime, latestBlock)
+	if err != nil {
+		return nil, err
+	}
+	toBlock, err := es.firstBlockAtOrAfter(ctx, endTime, latestBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	filterOpts := &bind.FilterOpts{
+		Context: ctx,
+		Start:   fromBlock,
+		End:     &toBlock,
+	}
+	it, err := es.auctionContract.FilterSetExpressLaneController(filterOpts, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	var changes []controllerChange
+	for it.Next() {
+		changes = append(changes, controllerChange{
+			round:      it.Event.Round,
+			previous:   it.Event.PreviousExpressLaneController,
+			controller: it.Event.NewExpressLaneController,
+			timestamp:  it.Event.StartTimestamp,
+		})
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	return buildControllerHistory(fromRound, toRound, changes), nil
+}
+
+// firstBlockAtOrAfter binary searches for the first block with a timestamp no earlier than t,
+// returning latestBlock if there is no such block.
+func (es *expressLaneService) firstBlockAtOrAfter(ctx context.Context, t time.Time, latestBlock uint64) (uint64, error) {
+	timestamp := arbmath.SaturatingUCast[uint64](t.Unix())
+	low, high := uint64(0), latestBlock
+	for low < high {
+		mid := low + (high-low)/2
+		// #nosec G115
+		header, err := es.apiBackend.HeaderByNumber(ctx, rpc.BlockNumber(mid))
+		if err != nil {
+			return 0, err
+		}
+		if header.Time < timestamp {
+			low = mid + 1
+		} else {
+			high = mid
+		}
+	}
+	return low, nil
+}
+
+// buildControllerHistory groups the controller changes, which must be in emission order, by round.
+// Changes for rounds outside of the requested range are ignored.
+func buildControllerHistory(fromRound, toRound uint64, changes []controllerChange) []*ExpressLaneRoundControllers {
+	history := make([]*ExpressLaneRoundControllers, 0, toRound-fromRound+1)
+	for round := fromRound; ; round++ {
+		history = append(history, &ExpressLaneRoundControllers{
+			Round:     hexutil.Uint64(round),
+			Transfers: []ExpressLaneControllerTransfer{},
+		})
+		if round == toRound {
+			break
+		}
+	}
+	for _, change := range changes {
+		if change.round < fromRound || change.round > toRound {
+			continue
+		}
+		entry := history[change.round-fromRound]
+		if change.previous == (common.Address{}) {
+			entry.InitialController = change.controller
+			continue
+		}
+		entry.Transfers = append(entry.Transfers, ExpressLaneControllerTransfer{
+			Timestamp:          hexutil.Uint64(change.timestamp),
+			PreviousController: change.previous,
+			NewController:      change.controller,
+		})
+	}
+	return history
+}
+
+// sequenceStatus reports the next sequence number expected for the given round, the accepted submissions buffered
+// waiting on it, and the sequence numbers missing for them to be sequenced.
+func (es *expressLaneService) sequenceStatus(round uint64) *ExpressLaneSequenceStatus {
+	es.roundInfoMutex.Lock()
+	defer es.roundInfoMutex.Unlock()
+	status := &ExpressLaneSequenceStatus{
+		Round:             hexutil.Uint64(round),
+		BufferedSequences: []hexutil.Uint64{},
+		MissingSequences:  []hexutil.Uint64{},
+	}
+	roundInfo, exists := es.roundInfo.Get(round)
+	if !exists {
+		return status
+	}
+	status.NextSequenceNumber = hexutil.Uint64(roundInfo.sequence)
+	for seq := range roundInfo.msgAndResultBySequenceNumber {
+		if seq >= roundInfo.sequence {
+			status.BufferedSequences = append(status.BufferedSequences, hexutil.Uint64(seq))
+		}
+	}
+	sort.Slice(status.BufferedSequences, func(i, j int) bool {
+		return status.BufferedSequences[i] < status.BufferedSequences[j]
+	})
+	next := roundInfo.sequence
+	for _, seq := range status.BufferedSequences {
+		for ; next < uint64(seq); next++ {
+			status.MissingSequences = append(status.MissingSequences, hexutil.Uint64(next))
+		}
+		next = uint64(seq) + 1
+	}
+	return status
+}
+
+// reserveSequenceNumber returns the next sequence number of the given round that has been neither reserved
+// nor submitted, and reserves it. Only the current and next rounds are accepted, as the sequence state of
+// at most a few rounds is kept. A reservation that isn't submitted within the sequence reservation timeout
+// lapses and its sequence number is handed out again first, as the round's express lane stalls until it's used.
+func (es *expressLaneService) reserveSequenceNumber(round uint64) (uint64, error) {
+	currentRound := es.currentRound()
+	if round != currentRound && round != currentRound+1 {
+		return 0, fmt.Errorf("can only reserve sequence numbers of the current round %d or the next one, got round %d", currentRound, round)
+	}
+	now := time.Now()
+	lapseAt := now.Add(es.seqConfig().Dangerous.Timeboost.SequenceReservationTimeout)
+	es.roundInfoMutex.Lock()
+	defer es.roundInfoMutex.Unlock()
+	reservations, exists := es.sequenceReservations.Get(round)
+	if !exists {
+		reservations = &sequenceReservations{lapseAt: make(map[uint64]time.Time)}
+		es.sequenceReservations.Add(round, reservations)
+	}
+	roundInfo, _ := es.roundInfo.Get(round)
+	submitted := func(sequence uint64) bool {
+		if roundInfo == nil {
+			return false
+		}
+		_, buffered := roundInfo.msgAndResultBySequenceNumber[sequence]
+		return sequence < roundInfo.sequence || buffered
+	}
+	lapsed, found := uint64(0), false
+	for sequence, sequenceLapseAt := range reservations.lapseAt {
+		if submitted(sequence) {
+			delete(reservations.lapseAt, sequence)
+		} else if !now.Before(sequenceLapseAt) && (!found || sequence < lapsed) {
+			lapsed, found = sequence, true
+		}
+	}
+	if found {
+		reservations.lapseAt[lapsed] = lapseAt
+		return lapsed, nil
+	}
+	next := reservations.next
+	if roundInfo != nil {
+		next = arbmath.MaxInt(next, roundInfo.sequence)
+	}
+	for submitted(next) {
+		next++
+	}
+	reservations.next = next + 1
+	reservations.lapseAt[next] = lapseAt
+	return next, nil
+}
+
+// simulateExpressLaneOrdering returns the order in which the sequencer would queue the given transactions during
+// a round with an express lane controller. expressLaneTxs are the controller's submissions in sequence number order,
+// each of which is only queued once all of the preceding ones have been, while txs are delayed by the advantage.
+// Transactions queued at the same time keep the order in which they were given, express lane transactions first.
+func simulateExpressLaneOrdering(advantage time.Duration, expressLaneTxs, txs []SimulatedTransaction) ([]SimulatedOrderedTransaction, error) {
+	type queuedTx struct {
+		hash        common.Hash
+		timeboosted bool
+		queuedAt    time.Duration
+	}
+	queue := make([]queuedTx, 0, len(expressLaneTxs)+len(txs))
+	var prevQueuedAt time.Duration
+	for i, sub := range expressLaneTxs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(sub.Transaction); err != nil {
+			return nil, fmt.Errorf("error decoding express lane transaction %d: %w", i, err)
+		}
+		// #nosec G115
+		queuedAt := arbmath.MaxInt(time.Duration(sub.ArrivalOffsetMs)*time.Millisecond, prevQueuedAt)
+		prevQueuedAt = queuedAt
+		queue = append(queue, queuedTx{tx.Hash(), true, queuedAt})
+	}
+	for i, sub := range txs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(sub.Transaction); err != nil {
+			return nil, fmt.Errorf("error decoding transaction %d: %w", i, err)
+		}
+		// #nosec G115
+		queue = append(queue, queuedTx{tx.Hash(), false, time.Duration(sub.ArrivalOffsetMs)*time.Millisecond + advantage})
+	}
+	sort.SliceStable(queue, func(i, j int) bool {
+		return queue[i].queuedAt < queue[j].queuedAt
+	})
+	ordered := make([]SimulatedOrderedTransaction, 0, len(queue))
+	for _, queued := range queue {
+		ordered = append(ordered, SimulatedOrderedTransaction{
+			TxHash:      queued.hash,
+			Timeboosted: queued.timeboosted,
+			QueuedAtMs:  hexutil.Uint64(queued.queuedAt.Milliseconds()),
+		})
+	}
+	return ordered, nil
 }
 
 func (es *expressLaneService) StopAndWait() {
@@ -301,12 +705,133 @@ func (es *expressLaneService) StopAndWait() {
 	}
 }
 
+// currentTime returns the time the service's round computations are based on: the wall clock, corrected for
+// clock skew near round boundaries.
+func (es *expressLaneService) currentTime() time.Time {
+	return es.correctedTime(time.Now(), es.parentChainTime)
+}
+
+// currentRound returns the current express lane round as of currentTime.
+// All of the service's round computations use currentTime so that they agree on which round is current.
+func (es *expressLaneService) currentRound() uint64 {
+	return es.roundTimingInfo.RoundNumberAt(es.currentTime())
+}
+
+// roundAt returns the round as of the wall clock time now, corrected as by correctedTime.
+func (es *expressLaneService) roundAt(now time.Time, chainTime func() (time.Time, error)) uint64 {
+	return es.roundTimingInfo.RoundNumberAt(es.correctedTime(now, chainTime))
+}
+
+// correctedTime returns the wall clock time now, unless now is within maxClockSkew of a round boundary and the
+// parent chain time, which unlike the latest L2 block's timestamp keeps advancing while the chain is idle, is ahead
+// of it. Parent chain timestamps lag the wall clock by up to a parent chain block time, so being ahead of them means
+// the wall clock is behind and may still report the previous round; the correction is capped at maxClockSkew.
+// A wall clock running ahead can't be told apart from the parent chain's usual lag, so it isn't corrected.
+func (es *expressLaneService) correctedTime(now time.Time, chainTime func() (time.Time, error)) time.Time {
+	if es.maxClockSkew == 0 || chainTime == nil {
+		return now
+	}
+	round := es.roundTimingInfo.RoundNumberAt(now)
+	roundStart := es.roundTimingInfo.Offset.Add(es.roundTimingInfo.Round * arbmath.SaturatingCast[time.Duration](round))
+	if now.Sub(roundStart) > es.maxClockSkew && es.roundTimingInfo.TimeTilNextRoundAt(now) > es.maxClockSkew {
+		return now
+	}
+	t, err := chainTime()
+	if err != nil {
+		log.Warn("Could not get parent chain time to correct for clock skew near express lane round boundary, using wall clock", "err", err)
+		return now
+	}
+	if !t.After(now) {
+		return now
+	}
+	if latest := now.Add(es.maxClockSkew); t.After(latest) {
+		t = latest
+	}
+	if chainRound := es.roundTimingInfo.RoundNumberAt(t); chainRound != round {
+		log.Info("Correcting express lane round for clock skew near round boundary", "wallClockRound", round, "parentChainRound", chainRound, "wallClock", now, "parentChainTime", t)
+	}
+	return t
+}
+
+// roundController returns the express lane controller of the given round, if the round's auction was resolved
+// and control wasn't transferred to the zero address.
+func (es *expressLaneService) roundController(round uint64) (common.Address, bool) {
+	controller, ok := es.roundControl.Load(round)
+	if !ok || controller == (common.Address{}) {
+		return common.Address{}, false
+	}
+	return controller, true
+}
+
+// advantage reports the express lane advantage in effect for the given round
+func (es *expressLaneService) advantage(round uint64) *ExpressLaneAdvantage {
+	// #nosec G115
+	configured := hexutil.Uint64(es.seqConfig().Dangerous.Timeboost.ExpressLaneAdvantage.Milliseconds())
+	_, hasController := es.roundController(round)
+	paused := es.paused.Load()
+	result := &ExpressLaneAdvantage{
+		Round:                 hexutil.Uint64(round),
+		HasController:         hasController,
+		Paused:                paused,
+		ConfiguredAdvantageMs: configured,
+	}
+	if hasController && !paused {
+		result.AdvantageMs = configured
+	}
+	return result
+}
+
 func (es *expressLaneService) currentRoundHasController() bool {
-	controller, ok := es.roundControl.Load(es.roundTimingInfo.RoundNumber())
-	if !ok {
-		return false
+	_, ok := es.roundController(es.currentRound())
+	return ok
+}
+
+// advantageApplies is whether transactions not sent through the express lane are currently delayed
+func (es *expressLaneService) advantageApplies() bool {
+	return !es.paused.Load() && es.currentRoundHasController()
+}
+
+// holdingSubmissions is whether accepted submissions are kept buffered until the express lane is resumed,
+// must be called with the roundInfo lock held
+func (es *expressLaneService) holdingSubmissions() bool {
+	return es.paused.Load() && es.seqConfig().Dangerous.Timeboost.HoldSubmissionsWhilePaused
+}
+
+func (es *expressLaneService) pause() {
+	es.roundInfoMutex.Lock()
+	defer es.roundInfoMutex.Unlock()
+	es.paused.Store(true)
+}
+
+// resume lifts a pause and publishes the current round's submissions held during it, in sequence order.
+// Held submissions whose submitter already timed out waiting are still sequenced, same as buffered future ones.
+func (es *expressLaneService) resume() {
+	es.roundInfoMutex.Lock()
+	defer es.roundInfoMutex.Unlock()
+	es.paused.Store(false)
+	round := es.currentRound()
+	roundInfo, exists := es.roundInfo.Get(round)
+	if !exists {
+		return
+	}
+	queueTimeout := es.seqConfig().QueueTimeout
+	for {
+		nextMsgAndResult, exists := roundInfo.msgAndResultBySequenceNumber[roundInfo.sequence]
+		if !exists {
+			break
+		}
+		queueCtx, _ := ctxWithTimeout(es.GetContext(), queueTimeout)
+		es.transactionPublisher.PublishTimeboostedTransaction(queueCtx, nextMsgAndResult.msg.Transaction, nextMsgAndResult.msg.Options, nextMsgAndResult.resultChan)
+		roundInfo.sequence += 1
+	}
+	if es.redisCoordinator != nil {
+		seqCount := roundInfo.sequence
+		es.LaunchThread(func(context.Context) {
+			if err := es.redisCoordinator.UpdateSequenceCount(round, seqCount); err != nil {
+				log.Error("Error updating round's sequence count in redis", "err", err)
+			}
+		})
 	}
-	return controller != (common.Address{})
 }
 
 // sequenceExpressLaneSubmission with the roundInfo lock held, validates sequence number and sender address fields of the message
@@ -324,7 +849,7 @@ func (es *expressLaneService) sequenceExpressLaneSubmission(
 	}()
 
 	// Below code block isn't a repetition, it prevents stale messages to be accepted during control transfer within or after the round ends!
-	controller, ok := es.roundControl.Load(msg.Round)
+	controller, ok := es.roundController(msg.Round)
 	if !ok {
 		return timeboost.ErrNoOnchainController
 	}
@@ -352,7 +877,7 @@ func (es *expressLaneService) sequenceExpressLaneSubmission(
 		if exists && bytes.Equal(prev.msg.Signature, msg.Signature) {
 			return nil
 		}
-		return timeboost.ErrSequenceNumberTooLow
+		return timeboost.NewSequenceNumberError(timeboost.ErrSequenceNumberTooLow, msg.Round, roundInfo.sequence)
 	}
 
 	// Check if a duplicate submission exists already, and reject if so.
@@ -368,7 +893,7 @@ func (es *expressLaneService) sequenceExpressLaneSubmission(
 	// Log an informational warning if the message's sequence number is in the future.
 	if msg.SequenceNumber > roundInfo.sequence {
 		if msg.SequenceNumber > roundInfo.sequence+seqConfig.Dangerous.Timeboost.MaxFutureSequenceDistance {
-			return fmt.Errorf("message sequence number has reached max allowed limit. SequenceNumber: %d, Limit: %d", msg.SequenceNumber, roundInfo.sequence+seqConfig.Dangerous.Timeboost.MaxFutureSequenceDistance)
+			return timeboost.NewSequenceNumberError(timeboost.ErrSequenceNumberTooHigh, msg.Round, roundInfo.sequence)
 		}
 		log.Info("Received express lane submission with future sequence number", "SequenceNumber", msg.SequenceNumber)
 	}
@@ -388,7 +913,7 @@ func (es *expressLaneService) sequenceExpressLaneSubmission(
 
 	now := time.Now()
 	queueTimeout := seqConfig.QueueTimeout
-	for es.roundTimingInfo.RoundNumber() == msg.Round { // This check ensures that the controller for this round is not allowed to send transactions from msgAndResultBySequenceNumber map once the next round starts
+	for !es.holdingSubmissions() && es.currentRound() == msg.Round { // This check ensures that the controller for this round is not allowed to send transactions from msgAndResultBySequenceNumber map once the next round starts
 		// Get the next message in the sequence.
 		nextMsgAndResult, exists := roundInfo.msgAndResultBySequenceNumber[roundInfo.sequence]
 		if !exists {
@@ -442,31 +967,50 @@ func (es *expressLaneService) sequenceExpressLaneSubmission(
 	return nil
 }
 
-// validateExpressLaneTx checks for the correctness of all fields of msg
-func (es *expressLaneService) validateExpressLaneTx(msg *timeboost.ExpressLaneSubmission) error {
-	if msg == nil || msg.Transaction == nil || msg.Signature == nil {
-		return timeboost.ErrMalformedData
+// recoverSenders recovers the signer of msg and the sender of its transaction, both of which are cached, so that
+// large bundles can have their signatures recovered in parallel while they're still executed in sequence order.
+// At most sender-recovery-workers recoveries run at the same time.
+func (es *expressLaneService) recoverSenders(ctx context.Context, msg *timeboost.ExpressLaneSubmission) error {
+	if msg == nil || msg.Transaction == nil || msg.ChainId == nil {
+		return nil // Malformed submissions are rejected by validation
 	}
-	if msg.ChainId.Cmp(es.chainConfig.ChainID) != 0 {
-		return errors.Wrapf(timeboost.ErrWrongChainId, "express lane tx chain ID %d does not match current chain ID %d", msg.ChainId, es.chainConfig.ChainID)
+	select {
+	case es.recoveryTokens <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-	if msg.AuctionContractAddress != es.auctionContractAddr {
-		return errors.Wrapf(timeboost.ErrWrongAuctionContract, "msg auction contract address %s does not match sequencer auction contract address %s", msg.AuctionContractAddress, es.auctionContractAddr)
+	defer func() { <-es.recoveryTokens }()
+	// Invalid signatures are left to be reported by validation, or for the transaction when it's sequenced
+	_, _ = msg.Sender()
+	_, _ = types.Sender(types.LatestSigner(es.chainConfig), msg.Transaction)
+	return nil
+}
+
+// validateExpressLaneTx checks for the correctness of all fields of msg
+func (es *expressLaneService) validateExpressLaneTx(msg *timeboost.ExpressLaneSubmission) error {
+	if err := msg.VerifyTarget(es.chainConfig.ChainID, es.auctionContractAddr); err != nil {
+		return err
 	}
 
-	currentRound := es.roundTimingInfo.RoundNumber()
+	now := es.currentTime()
+	currentRound := es.roundTimingInfo.RoundNumberAt(now)
 	if msg.Round != currentRound {
-		timeTilNextRound := es.roundTimingInfo.TimeTilNextRound()
+		timeTilNextRound := es.roundTimingInfo.TimeTilNextRoundAt(now)
 		// We allow txs to come in for the next round if it is close enough to that round,
 		// but we sleep until the round starts.
-		if msg.Round == currentRound+1 && timeTilNextRound <= es.earlySubmissionGrace {
+		if msg.Round == currentRound+1 && timeTilNextRound <= es.earlySubmissionGraceFor(msg) {
 			time.Sleep(timeTilNextRound)
 		} else {
 			return errors.Wrapf(timeboost.ErrBadRoundNumber, "express lane tx round %d does not match current round %d", msg.Round, currentRound)
 		}
+	} else if maxLateness := es.seqConfig().Dangerous.Timeboost.MaxSubmissionLatenessIntoRound; maxLateness > 0 {
+		// Submissions late into the round would be sequenced ahead of txs that arrived long before them
+		if intoRound := es.roundTimingInfo.Round - es.roundTimingInfo.TimeTilNextRoundAt(now); intoRound > maxLateness {
+			return errors.Wrapf(timeboost.ErrExpressLaneSubmissionTooLate, "express lane tx submitted %v into round %d, limit is %v", intoRound, currentRound, maxLateness)
+		}
 	}
 
-	controller, ok := es.roundControl.Load(msg.Round)
+	controller, ok := es.roundController(msg.Round)
 	if !ok {
 		return timeboost.ErrNoOnchainController
 	}
@@ -478,15 +1022,51 @@ func (es *expressLaneService) validateExpressLaneTx(msg *timeboost.ExpressLaneSu
 	if sender != controller {
 		return timeboost.ErrNotExpressLaneController
 	}
+
+	// Bound the size of express lane txs to prevent the controller from bloating blocks
+	seqConfig := es.seqConfig()
+	maxTxBytes := seqConfig.Dangerous.Timeboost.MaxExpressLaneTxBytes
+	if maxTxBytes == 0 {
+		maxTxBytes = seqConfig.MaxTxDataSize
+	}
+	txBytes, err := msg.Transaction.MarshalBinary()
+	if err != nil {
+		return errors.Wrap(timeboost.ErrMalformedData, err.Error())
+	}
+	if len(txBytes) > maxTxBytes {
+		return errors.Wrapf(timeboost.ErrExpressLaneTxTooLarge, "express lane tx size %d exceeds limit %d", len(txBytes), maxTxBytes)
+	}
+	// Prevent replaying a tx signed for another chain under this chain's submission.
+	// Unprotected legacy txs aren't bound to any chain id, so there's nothing to compare.
+	if msg.Transaction.Protected() && msg.Transaction.ChainId().Cmp(msg.ChainId) != 0 {
+		return errors.Wrapf(timeboost.ErrInnerTxChainIdMismatch, "express lane inner tx chain ID %d does not match submission chain ID %d", msg.Transaction.ChainId(), msg.ChainId)
+	}
 	return nil
 }
 
+// earlySubmissionGraceFor returns how long before the start of the next round msg may be submitted for it,
+// which is the configured override for msg's sender if there is one, and the global grace otherwise.
+func (es *expressLaneService) earlySubmissionGraceFor(msg *timeboost.ExpressLaneSubmission) time.Duration {
+	timeboostConfig := &es.seqConfig().Dangerous.Timeboost
+	if len(timeboostConfig.EarlySubmissionGraceOverrides) == 0 {
+		return es.earlySubmissionGrace
+	}
+	sender, err := msg.Sender()
+	if err != nil {
+		return es.earlySubmissionGrace
+	}
+	if grace, ok := timeboostConfig.earlySubmissionGraceOverride(sender); ok {
+		return grace
+	}
+	return es.earlySubmissionGrace
+}
+
 func (es *expressLaneService) syncFromRedis() {
 	if es.redisCoordinator == nil {
 		return
 	}
 
-	currentRound := es.roundTimingInfo.RoundNumber()
+	currentRound := es.currentRound()
 	redisSeqCount, err := es.redisCoordinator.GetSequenceCount(currentRound)
 	if err != nil {
 		log.Error("error fetching current round's global sequence count from redis", "err", err)
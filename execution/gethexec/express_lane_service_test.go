@@ -6,6 +6,7 @@ package gethexec
 import (
 	"context"
 	"crypto/ecdsa"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
@@ -15,11 +16,14 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/arbitrum_types"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/offchainlabs/nitro/timeboost"
 	"github.com/offchainlabs/nitro/util/containers"
@@ -193,6 +197,24 @@ func Test_expressLaneService_validateExpressLaneTx(t *testing.T) {
 			sub:         buildValidSubmission(t, common.HexToAddress("0x2Aef36410182881a4b13664a1E079762D7F716e6"), testPriv, 0),
 			expectedErr: timeboost.ErrNotExpressLaneController,
 		},
+		{
+			name: "tx too large",
+			es: &expressLaneService{
+				auctionContractAddr: common.HexToAddress("0x2Aef36410182881a4b13664a1E079762D7F716e6"),
+				roundTimingInfo:     defaultTestRoundTimingInfo(time.Now()),
+				chainConfig: &params.ChainConfig{
+					ChainID: big.NewInt(1),
+				},
+				seqConfig: func() *SequencerConfig {
+					config := DefaultSequencerConfig
+					config.Dangerous.Timeboost.MaxExpressLaneTxBytes = 1000
+					return &config
+				},
+			},
+			controller:  crypto.PubkeyToAddress(testPriv.PublicKey),
+			sub:         buildValidSubmissionWithSeqAndTx(t, 0, 0, types.NewTx(&types.DynamicFeeTx{Data: make([]byte, 1000)})),
+			expectedErr: timeboost.ErrExpressLaneTxTooLarge,
+		},
 		{
 			name: "OK",
 			es: &expressLaneService{
@@ -201,6 +223,7 @@ func Test_expressLaneService_validateExpressLaneTx(t *testing.T) {
 				chainConfig: &params.ChainConfig{
 					ChainID: big.NewInt(1),
 				},
+				seqConfig: func() *SequencerConfig { return &DefaultSequencerConfig },
 			},
 			controller: crypto.PubkeyToAddress(testPriv.PublicKey),
 			sub:        buildValidSubmission(t, common.HexToAddress("0x2Aef36410182881a4b13664a1E079762D7F716e6"), testPriv, 0),
@@ -227,6 +250,34 @@ func Test_expressLaneService_validateExpressLaneTx(t *testing.T) {
 	}
 }
 
+func Test_expressLaneService_validateExpressLaneTx_noController(t *testing.T) {
+	auctionContractAddr := common.HexToAddress("0x2Aef36410182881a4b13664a1E079762D7F716e6")
+	es := &expressLaneService{
+		auctionContractAddr: auctionContractAddr,
+		roundTimingInfo:     defaultTestRoundTimingInfo(time.Now()),
+		chainConfig: &params.ChainConfig{
+			ChainID: big.NewInt(1),
+		},
+		roundInfo: containers.NewLruCache[uint64, *expressLaneRoundInfo](8),
+		seqConfig: func() *SequencerConfig { return &DefaultSequencerConfig },
+	}
+	sub := buildValidSubmission(t, auctionContractAddr, testPriv, 0)
+
+	// No auction was resolved for the round
+	require.ErrorIs(t, es.validateExpressLaneTx(sub), timeboost.ErrNoOnchainController)
+	require.False(t, es.currentRoundHasController())
+
+	// Control was transferred to the zero address
+	es.roundControl.Store(0, common.Address{})
+	require.ErrorIs(t, es.validateExpressLaneTx(sub), timeboost.ErrNoOnchainController)
+	require.ErrorIs(t, es.sequenceExpressLaneSubmission(context.Background(), sub), timeboost.ErrNoOnchainController)
+	require.False(t, es.currentRoundHasController())
+
+	es.roundControl.Store(0, crypto.PubkeyToAddress(testPriv.PublicKey))
+	require.NoError(t, es.validateExpressLaneTx(sub))
+	require.True(t, es.currentRoundHasController())
+}
+
 func Test_expressLaneService_validateExpressLaneTx_gracePeriod(t *testing.T) {
 	auctionContractAddr := common.HexToAddress("0x2Aef36410182881a4b13664a1E079762D7F716e6")
 	es := &expressLaneService{
@@ -240,6 +291,7 @@ func Test_expressLaneService_validateExpressLaneTx_gracePeriod(t *testing.T) {
 		chainConfig: &params.ChainConfig{
 			ChainID: big.NewInt(1),
 		},
+		seqConfig: func() *SequencerConfig { return &DefaultSequencerConfig },
 	}
 	es.roundControl.Store(0, crypto.PubkeyToAddress(testPriv.PublicKey))
 	es.roundControl.Store(1, crypto.PubkeyToAddress(testPriv2.PublicKey))
@@ -264,6 +316,172 @@ func Test_expressLaneService_validateExpressLaneTx_gracePeriod(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func Test_expressLaneService_validateExpressLaneTx_gracePeriodOverride(t *testing.T) {
+	auctionContractAddr := common.HexToAddress("0x2Aef36410182881a4b13664a1E079762D7F716e6")
+	seqConfig := DefaultSequencerConfig
+	seqConfig.Dangerous.Timeboost.Enable = true
+	seqConfig.Dangerous.Timeboost.RedisUrl = ""
+	seqConfig.Dangerous.Timeboost.EarlySubmissionGraceOverrides = []string{crypto.PubkeyToAddress(testPriv2.PublicKey).Hex() + ":1s"}
+	// The overrides apply without the config being validated
+	es := &expressLaneService{
+		auctionContractAddr: auctionContractAddr,
+		roundTimingInfo: timeboost.RoundTimingInfo{
+			Offset:         time.Now(),
+			Round:          time.Second,
+			AuctionClosing: time.Millisecond * 500,
+		},
+		earlySubmissionGrace: time.Millisecond * 100,
+		chainConfig: &params.ChainConfig{
+			ChainID: big.NewInt(1),
+		},
+		seqConfig: func() *SequencerConfig { return &seqConfig },
+	}
+	es.roundControl.Store(1, crypto.PubkeyToAddress(testPriv2.PublicKey))
+
+	// Without an override the global grace applies
+	err := es.validateExpressLaneTx(buildValidSubmission(t, auctionContractAddr, testPriv, 1))
+	require.ErrorIs(t, err, timeboost.ErrBadRoundNumber)
+
+	// The controller with a larger override can already submit for the next round
+	err = es.validateExpressLaneTx(buildValidSubmission(t, auctionContractAddr, testPriv2, 1))
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), es.roundTimingInfo.RoundNumber())
+
+	for _, invalid := range []string{"0x2Aef36410182881a4b13664a1E079762D7F716e6", "0x2Aef:1s", "0x2Aef36410182881a4b13664a1E079762D7F716e6:-1s"} {
+		seqConfig.Dangerous.Timeboost.EarlySubmissionGraceOverrides = []string{invalid}
+		require.Error(t, seqConfig.Dangerous.Timeboost.Validate())
+	}
+}
+
+func Test_expressLaneService_validateExpressLaneTx_submissionLateness(t *testing.T) {
+	auctionContractAddr := common.HexToAddress("0x2Aef36410182881a4b13664a1E079762D7F716e6")
+	seqConfig := DefaultSequencerConfig
+	es := &expressLaneService{
+		auctionContractAddr: auctionContractAddr,
+		roundTimingInfo: timeboost.RoundTimingInfo{
+			Offset:         time.Now().Add(-time.Second * 9),
+			Round:          time.Second * 10,
+			AuctionClosing: time.Second * 5,
+		},
+		chainConfig: &params.ChainConfig{
+			ChainID: big.NewInt(1),
+		},
+		seqConfig: func() *SequencerConfig { return &seqConfig },
+	}
+	es.roundControl.Store(0, crypto.PubkeyToAddress(testPriv.PublicKey))
+	sub := buildValidSubmission(t, auctionContractAddr, testPriv, 0)
+
+	// By default submissions are accepted throughout the round
+	require.NoError(t, es.validateExpressLaneTx(sub))
+
+	// Submitting 9 seconds into the round is too late with a 5 second limit
+	seqConfig.Dangerous.Timeboost.MaxSubmissionLatenessIntoRound = time.Second * 5
+	require.ErrorIs(t, es.validateExpressLaneTx(sub), timeboost.ErrExpressLaneSubmissionTooLate)
+
+	// But not early in the round
+	es.roundTimingInfo.Offset = time.Now().Add(-time.Second)
+	require.NoError(t, es.validateExpressLaneTx(sub))
+
+	seqConfig.Dangerous.Timeboost.Enable = true
+	seqConfig.Dangerous.Timeboost.RedisUrl = ""
+	seqConfig.Dangerous.Timeboost.MaxSubmissionLatenessIntoRound = -time.Second
+	require.Error(t, seqConfig.Dangerous.Timeboost.Validate())
+}
+
+func Test_expressLaneService_validateExpressLaneTx_legacyTxTypes(t *testing.T) {
+	es := &expressLaneService{
+		auctionContractAddr: common.HexToAddress("0x2Aef36410182881a4b13664a1E079762D7F716e6"),
+		roundTimingInfo:     defaultTestRoundTimingInfo(time.Now()),
+		chainConfig: &params.ChainConfig{
+			ChainID: big.NewInt(1),
+		},
+		seqConfig: func() *SequencerConfig { return &DefaultSequencerConfig },
+	}
+	es.roundControl.Store(0, crypto.PubkeyToAddress(testPriv.PublicKey))
+
+	to := common.Address{'t'}
+	for _, txData := range []types.TxData{
+		&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1e8), Gas: 21000, To: &to, Value: big.NewInt(1)},
+		&types.AccessListTx{
+			ChainID:    big.NewInt(1),
+			Nonce:      2,
+			GasPrice:   big.NewInt(1e8),
+			Gas:        30000,
+			To:         &to,
+			Value:      big.NewInt(1),
+			AccessList: types.AccessList{{Address: to, StorageKeys: []common.Hash{{1}}}},
+		},
+	} {
+		tx := types.NewTx(txData)
+		sub := buildValidSubmissionWithSeqAndTx(t, 0, 0, tx)
+
+		// Legacy typed txs should survive the encoding used by the express lane RPC
+		jsonSub, err := sub.ToJson()
+		require.NoError(t, err)
+		decoded, err := timeboost.JsonSubmissionToGo(jsonSub)
+		require.NoError(t, err)
+		require.Equal(t, tx.Type(), decoded.Transaction.Type())
+		require.Equal(t, tx.Hash(), decoded.Transaction.Hash())
+		require.Equal(t, tx.GasPrice(), decoded.Transaction.GasFeeCap())
+
+		require.NoError(t, es.validateExpressLaneTx(decoded))
+	}
+}
+
+func Test_expressLaneService_validateExpressLaneTx_innerTxChainId(t *testing.T) {
+	es := &expressLaneService{
+		auctionContractAddr: common.HexToAddress("0x2Aef36410182881a4b13664a1E079762D7F716e6"),
+		roundTimingInfo:     defaultTestRoundTimingInfo(time.Now()),
+		chainConfig: &params.ChainConfig{
+			ChainID: big.NewInt(1),
+		},
+		seqConfig: func() *SequencerConfig { return &DefaultSequencerConfig },
+	}
+	es.roundControl.Store(0, crypto.PubkeyToAddress(testPriv.PublicKey))
+
+	to := common.Address{'t'}
+	for _, tt := range []struct {
+		name        string
+		signer      types.Signer
+		txData      types.TxData
+		expectedErr error
+	}{
+		{
+			name:   "dynamic fee tx for this chain",
+			signer: types.LatestSignerForChainID(big.NewInt(1)),
+			txData: &types.DynamicFeeTx{ChainID: big.NewInt(1), GasFeeCap: big.NewInt(1e8), Gas: 21000, To: &to},
+		},
+		{
+			name:        "dynamic fee tx for another chain",
+			signer:      types.LatestSignerForChainID(big.NewInt(2)),
+			txData:      &types.DynamicFeeTx{ChainID: big.NewInt(2), GasFeeCap: big.NewInt(1e8), Gas: 21000, To: &to},
+			expectedErr: timeboost.ErrInnerTxChainIdMismatch,
+		},
+		{
+			name:        "legacy tx for another chain",
+			signer:      types.NewEIP155Signer(big.NewInt(2)),
+			txData:      &types.LegacyTx{GasPrice: big.NewInt(1e8), Gas: 21000, To: &to},
+			expectedErr: timeboost.ErrInnerTxChainIdMismatch,
+		},
+		{
+			name:   "unprotected legacy tx",
+			signer: types.HomesteadSigner{},
+			txData: &types.LegacyTx{GasPrice: big.NewInt(1e8), Gas: 21000, To: &to},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := types.SignNewTx(testPriv2, tt.signer, tt.txData)
+			require.NoError(t, err)
+			err = es.validateExpressLaneTx(buildValidSubmissionWithSeqAndTx(t, 0, 0, tx))
+			if tt.expectedErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
+
 type stubPublisher struct {
 	els              *expressLaneService
 	publishedTxOrder []uint64
@@ -302,6 +520,57 @@ func Test_expressLaneService_sequenceExpressLaneSubmission_nonceTooLow(t *testin
 	msg := buildValidSubmissionWithSeqAndTx(t, 0, 0, emptyTx)
 	err := els.sequenceExpressLaneSubmission(ctx, msg)
 	require.ErrorIs(t, err, timeboost.ErrSequenceNumberTooLow)
+	hint, ok := timeboost.SequenceHintFromError(err)
+	require.True(t, ok)
+	require.Equal(t, timeboost.SequenceHint{Round: 0, ExpectedSequenceNumber: 1}, *hint)
+}
+
+func Test_expressLaneService_sequenceExpressLaneSubmission_nonceTooHigh(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	els := &expressLaneService{
+		roundInfo: containers.NewLruCache[uint64, *expressLaneRoundInfo](8),
+		seqConfig: func() *SequencerConfig { return &DefaultSequencerConfig },
+	}
+	els.roundInfo.Add(0, &expressLaneRoundInfo{3, make(map[uint64]*msgAndResult)})
+	els.StopWaiter.Start(ctx, els)
+	els.roundControl.Store(0, crypto.PubkeyToAddress(testPriv.PublicKey))
+	els.transactionPublisher = makeStubPublisher(els)
+
+	// A sequence number left over from a previous round is rejected with a hint to resynchronize from
+	msg := buildValidSubmissionWithSeqAndTx(t, 0, 4+DefaultSequencerConfig.Dangerous.Timeboost.MaxFutureSequenceDistance, emptyTx)
+	err := els.sequenceExpressLaneSubmission(ctx, msg)
+	require.ErrorIs(t, err, timeboost.ErrSequenceNumberTooHigh)
+	hint, ok := timeboost.SequenceHintFromError(err)
+	require.True(t, ok)
+	require.Equal(t, timeboost.SequenceHint{Round: 0, ExpectedSequenceNumber: 3}, *hint)
+
+	// The hint survives being sent over RPC as the error's data
+	rpcErr := &testDataError{data: err.(rpc.DataError).ErrorData()}
+	hint, ok = timeboost.SequenceHintFromError(rpcErr)
+	require.True(t, ok)
+	require.Equal(t, hexutil.Uint64(3), hint.ExpectedSequenceNumber)
+	_, ok = timeboost.SequenceHintFromError(&testDataError{data: "0x1234"})
+	require.False(t, ok)
+}
+
+type testDataError struct {
+	data interface{}
+}
+
+func (e *testDataError) Error() string { return "test error" }
+
+// ErrorData decodes the data the way an RPC client receives it
+func (e *testDataError) ErrorData() interface{} {
+	encoded, err := json.Marshal(e.data)
+	if err != nil {
+		panic(err)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		panic(err)
+	}
+	return decoded
 }
 
 func Test_expressLaneService_sequenceExpressLaneSubmission_duplicateNonce(t *testing.T) {
@@ -407,6 +676,272 @@ func Test_expressLaneService_sequenceExpressLaneSubmission_outOfOrder(t *testing
 	require.Equal(t, 5, len(stubPublisher.publishedTxOrder))
 }
 
+type orderRecordingPublisher struct {
+	mutex     sync.Mutex
+	published []*types.Transaction
+}
+
+func (p *orderRecordingPublisher) PublishTimeboostedTransaction(_ context.Context, tx *types.Transaction, _ *arbitrum_types.ConditionalOptions, resultChan chan error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.published = append(p.published, tx)
+	resultChan <- nil
+}
+
+func Test_expressLaneService_parallelSenderRecovery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	const bundleSize = 64
+	seqConfig := DefaultSequencerConfig
+	seqConfig.Dangerous.Timeboost.MaxFutureSequenceDistance = bundleSize
+	chainConfig := &params.ChainConfig{ChainID: big.NewInt(1)}
+	publisher := &orderRecordingPublisher{}
+	els := &expressLaneService{
+		auctionContractAddr:  common.HexToAddress("0x2Aef36410182881a4b13664a1E079762D7F716e6"),
+		roundInfo:            containers.NewLruCache[uint64, *expressLaneRoundInfo](8),
+		roundTimingInfo:      defaultTestRoundTimingInfo(time.Now()),
+		chainConfig:          chainConfig,
+		seqConfig:            func() *SequencerConfig { return &seqConfig },
+		recoveryTokens:       make(chan struct{}, 4),
+		transactionPublisher: publisher,
+	}
+	els.StopWaiter.Start(ctx, els)
+	els.roundControl.Store(0, crypto.PubkeyToAddress(testPriv.PublicKey))
+
+	signer := types.LatestSigner(chainConfig)
+	messages := make([]*timeboost.ExpressLaneSubmission, bundleSize)
+	for i := range messages {
+		tx, err := types.SignNewTx(testPriv2, signer, &types.LegacyTx{Nonce: uint64(i), Gas: 21000, GasPrice: big.NewInt(1)})
+		require.NoError(t, err)
+		// Decode the tx like the RPC handler would, so that its sender isn't cached yet
+		txBytes, err := tx.MarshalBinary()
+		require.NoError(t, err)
+		decoded := new(types.Transaction)
+		require.NoError(t, decoded.UnmarshalBinary(txBytes))
+		// #nosec G115
+		messages[i] = buildValidSubmissionWithSeqAndTx(t, 0, uint64(i), decoded)
+	}
+
+	// Submit the bundle in reverse order, with all submissions validated concurrently
+	var wg sync.WaitGroup
+	for i := len(messages) - 1; i >= 0; i-- {
+		msg := messages[i]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, els.recoverSenders(ctx, msg))
+			require.NoError(t, els.validateExpressLaneTx(msg))
+			require.NoError(t, els.sequenceExpressLaneSubmission(ctx, msg))
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, publisher.published, bundleSize)
+	for i, tx := range publisher.published {
+		// #nosec G115
+		require.Equal(t, uint64(i), tx.Nonce(), "express lane txs weren't executed in sequence order")
+	}
+	require.Empty(t, els.recoveryTokens)
+}
+
+func Test_expressLaneService_sequenceStatus(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	publisher := &orderRecordingPublisher{}
+	els := &expressLaneService{
+		roundInfo:            containers.NewLruCache[uint64, *expressLaneRoundInfo](8),
+		roundTimingInfo:      defaultTestRoundTimingInfo(time.Now()),
+		seqConfig:            func() *SequencerConfig { return &DefaultSequencerConfig },
+		transactionPublisher: publisher,
+	}
+	els.StopWaiter.Start(ctx, els)
+	els.roundControl.Store(0, crypto.PubkeyToAddress(testPriv.PublicKey))
+
+	require.Equal(t, &ExpressLaneSequenceStatus{BufferedSequences: []hexutil.Uint64{}, MissingSequences: []hexutil.Uint64{}}, els.sequenceStatus(0))
+
+	require.NoError(t, els.sequenceExpressLaneSubmission(ctx, buildValidSubmissionWithSeqAndTx(t, 0, 0, emptyTx)))
+	var wg sync.WaitGroup
+	submit := func(seqs ...uint64) {
+		for _, seq := range seqs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				require.NoError(t, els.sequenceExpressLaneSubmission(ctx, buildValidSubmissionWithSeqAndTx(t, 0, seq, emptyTx)))
+			}()
+		}
+	}
+
+	// 3 and 5 are held until the gaps before them are filled
+	submit(5, 3)
+	require.Eventually(t, func() bool {
+		return len(els.sequenceStatus(0).BufferedSequences) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, &ExpressLaneSequenceStatus{
+		Round:              0,
+		NextSequenceNumber: 1,
+		BufferedSequences:  []hexutil.Uint64{3, 5},
+		MissingSequences:   []hexutil.Uint64{1, 2, 4},
+	}, els.sequenceStatus(0))
+
+	submit(1, 2, 4)
+	wg.Wait()
+	require.Equal(t, &ExpressLaneSequenceStatus{
+		Round:              0,
+		NextSequenceNumber: 6,
+		BufferedSequences:  []hexutil.Uint64{},
+		MissingSequences:   []hexutil.Uint64{},
+	}, els.sequenceStatus(0))
+	require.Len(t, publisher.published, 6)
+}
+
+func Test_expressLaneService_advantage(t *testing.T) {
+	config := DefaultSequencerConfig
+	config.Dangerous.Timeboost.ExpressLaneAdvantage = 350 * time.Millisecond
+	els := &expressLaneService{
+		roundTimingInfo: defaultTestRoundTimingInfo(time.Now()),
+		seqConfig:       func() *SequencerConfig { return &config },
+	}
+	els.roundControl.Store(1, crypto.PubkeyToAddress(testPriv.PublicKey))
+
+	// No advantage is applied in a round without a controller
+	require.Equal(t, &ExpressLaneAdvantage{
+		Round:                 0,
+		ConfiguredAdvantageMs: 350,
+	}, els.advantage(0))
+	require.Equal(t, &ExpressLaneAdvantage{
+		Round:                 1,
+		HasController:         true,
+		ConfiguredAdvantageMs: 350,
+		AdvantageMs:           350,
+	}, els.advantage(1))
+
+	// Picks up config reloads
+	config.Dangerous.Timeboost.ExpressLaneAdvantage = 100 * time.Millisecond
+	require.Equal(t, hexutil.Uint64(100), els.advantage(1).AdvantageMs)
+}
+
+func Test_expressLaneService_pause(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	config := DefaultSequencerConfig
+	publisher := &orderRecordingPublisher{}
+	els := &expressLaneService{
+		roundInfo:            containers.NewLruCache[uint64, *expressLaneRoundInfo](8),
+		roundTimingInfo:      defaultTestRoundTimingInfo(time.Now()),
+		seqConfig:            func() *SequencerConfig { return &config },
+		transactionPublisher: publisher,
+	}
+	els.StopWaiter.Start(ctx, els)
+	els.roundControl.Store(0, crypto.PubkeyToAddress(testPriv.PublicKey))
+
+	require.True(t, els.advantageApplies())
+	els.pause()
+	// The controller's transactions get no advantage over others during the pause
+	require.False(t, els.advantageApplies())
+	require.Equal(t, hexutil.Uint64(0), els.advantage(0).AdvantageMs)
+	require.True(t, els.advantage(0).Paused)
+
+	// Without holding, submissions are sequenced in normal order during the pause
+	require.NoError(t, els.sequenceExpressLaneSubmission(ctx, buildValidSubmissionWithSeqAndTx(t, 0, 0, emptyTx)))
+	require.Len(t, publisher.published, 1)
+
+	// Held submissions are only sequenced once resumed
+	config.Dangerous.Timeboost.HoldSubmissionsWhilePaused = true
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		require.NoError(t, els.sequenceExpressLaneSubmission(ctx, buildValidSubmissionWithSeqAndTx(t, 0, 1, emptyTx)))
+	}()
+	require.Eventually(t, func() bool {
+		return len(els.sequenceStatus(0).BufferedSequences) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Len(t, publisher.published, 1)
+
+	els.resume()
+	wg.Wait()
+	require.True(t, els.advantageApplies())
+	require.Len(t, publisher.published, 2)
+	require.Equal(t, hexutil.Uint64(2), els.sequenceStatus(0).NextSequenceNumber)
+}
+
+func Test_expressLaneService_reserveSequenceNumber(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	publisher := &orderRecordingPublisher{}
+	els := &expressLaneService{
+		roundInfo:            containers.NewLruCache[uint64, *expressLaneRoundInfo](8),
+		sequenceReservations: containers.NewLruCache[uint64, *sequenceReservations](8),
+		roundTimingInfo:      defaultTestRoundTimingInfo(time.Now()),
+		seqConfig:            func() *SequencerConfig { return &DefaultSequencerConfig },
+		transactionPublisher: publisher,
+	}
+	els.StopWaiter.Start(ctx, els)
+	els.roundControl.Store(0, crypto.PubkeyToAddress(testPriv.PublicKey))
+
+	// Two clients of the same controller interleave their submissions, each using the sequence numbers handed out to it
+	var wg sync.WaitGroup
+	for client := 0; client < 2; client++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 5; i++ {
+				seq, err := els.reserveSequenceNumber(0)
+				require.NoError(t, err)
+				require.NoError(t, els.sequenceExpressLaneSubmission(ctx, buildValidSubmissionWithSeqAndTx(t, 0, seq, emptyTx)))
+			}
+		}()
+	}
+	wg.Wait()
+	require.Len(t, publisher.published, 10)
+	require.Equal(t, hexutil.Uint64(10), els.sequenceStatus(0).NextSequenceNumber)
+
+	// Sequence numbers submitted without a reservation aren't handed out again
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		require.NoError(t, els.sequenceExpressLaneSubmission(ctx, buildValidSubmissionWithSeqAndTx(t, 0, 11, emptyTx)))
+	}()
+	require.Eventually(t, func() bool {
+		return len(els.sequenceStatus(0).BufferedSequences) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	seq, err := els.reserveSequenceNumber(0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), seq)
+	seq, err = els.reserveSequenceNumber(0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(12), seq)
+	require.NoError(t, els.sequenceExpressLaneSubmission(ctx, buildValidSubmissionWithSeqAndTx(t, 0, 10, emptyTx)))
+	wg.Wait()
+
+	// The next round has its own sequence, rounds further ahead can't be reserved
+	seq, err = els.reserveSequenceNumber(1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), seq)
+	_, err = els.reserveSequenceNumber(2)
+	require.Error(t, err)
+
+	// A reservation that isn't used lapses and is handed out again, rather than stalling the round's express lane
+	config := DefaultSequencerConfig
+	config.Dangerous.Timeboost.SequenceReservationTimeout = 100 * time.Millisecond
+	els.seqConfig = func() *SequencerConfig { return &config }
+	require.NoError(t, els.sequenceExpressLaneSubmission(ctx, buildValidSubmissionWithSeqAndTx(t, 0, 12, emptyTx)))
+	seq, err = els.reserveSequenceNumber(0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(13), seq)
+	time.Sleep(config.Dangerous.Timeboost.SequenceReservationTimeout)
+	seq, err = els.reserveSequenceNumber(0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(13), seq)
+	seq, err = els.reserveSequenceNumber(0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(14), seq)
+	require.NoError(t, els.sequenceExpressLaneSubmission(ctx, buildValidSubmissionWithSeqAndTx(t, 0, 13, emptyTx)))
+	seq, err = els.reserveSequenceNumber(0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(15), seq)
+}
+
 func Test_expressLaneService_sequenceExpressLaneSubmission_erroredTx(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -544,6 +1079,157 @@ func Test_expressLaneService_syncFromRedis(t *testing.T) {
 	els2.roundInfoMutex.Unlock()
 }
 
+func Test_expressLaneService_roundAt(t *testing.T) {
+	offset := time.Unix(1_700_000_000, 0)
+	els := &expressLaneService{
+		roundTimingInfo: defaultTestRoundTimingInfo(offset),
+		maxClockSkew:    time.Second * 2,
+	}
+	chainTimeAt := func(chainTime time.Time) func() (time.Time, error) {
+		return func() (time.Time, error) { return chainTime, nil }
+	}
+	boundary := offset.Add(time.Minute) // Start of round 1
+
+	// Parent chain time lagging the wall clock, as it usually does, doesn't hold back the new round
+	require.Equal(t, uint64(1), els.roundAt(boundary.Add(time.Second), chainTimeAt(boundary.Add(-time.Second*12))))
+	require.Equal(t, uint64(1), els.roundAt(boundary, chainTimeAt(boundary.Add(-time.Second))))
+	// Wall clock running behind: it still reports round 0 but the parent chain is already in round 1
+	require.Equal(t, uint64(1), els.roundAt(boundary.Add(-time.Second), chainTimeAt(boundary)))
+	// The correction is capped at the max clock skew
+	require.Equal(t, boundary.Add(time.Second), els.correctedTime(boundary.Add(-time.Second), chainTimeAt(boundary.Add(time.Minute))))
+
+	// Away from the boundary the wall clock is used without consulting the chain
+	noChainTime := func() (time.Time, error) {
+		t.Fatal("chain time should not be consulted away from a round boundary")
+		return time.Time{}, nil
+	}
+	require.Equal(t, uint64(1), els.roundAt(boundary.Add(time.Second*30), noChainTime))
+
+	// Falls back to the wall clock if the parent chain time is unavailable, or there's no parent chain reader
+	chainTimeErr := func() (time.Time, error) { return time.Time{}, errors.New("no header") }
+	require.Equal(t, uint64(1), els.roundAt(boundary.Add(time.Second), chainTimeErr))
+	require.Equal(t, uint64(1), els.roundAt(boundary.Add(time.Second), nil))
+
+	// Disabled skew tolerance always uses the wall clock
+	els.maxClockSkew = 0
+	require.Equal(t, uint64(1), els.roundAt(boundary.Add(time.Second), noChainTime))
+}
+
+func Test_buildControllerHistory(t *testing.T) {
+	first := crypto.PubkeyToAddress(testPriv.PublicKey)
+	second := crypto.PubkeyToAddress(testPriv2.PublicKey)
+	changes := []controllerChange{
+		{round: 4, controller: second, timestamp: 40}, // Outside of the requested range
+		{round: 5, controller: first, timestamp: 50},
+		{round: 6, controller: first, timestamp: 60},
+		{round: 6, previous: first, controller: second, timestamp: 65},
+		{round: 6, previous: second, controller: first, timestamp: 67},
+	}
+	history := buildControllerHistory(5, 7, changes)
+	require.Len(t, history, 3)
+
+	require.Equal(t, hexutil.Uint64(5), history[0].Round)
+	require.Equal(t, first, history[0].InitialController)
+	require.Empty(t, history[0].Transfers)
+
+	require.Equal(t, hexutil.Uint64(6), history[1].Round)
+	require.Equal(t, first, history[1].InitialController)
+	require.Equal(t, []ExpressLaneControllerTransfer{
+		{Timestamp: 65, PreviousController: first, NewController: second},
+		{Timestamp: 67, PreviousController: second, NewController: first},
+	}, history[1].Transfers)
+
+	// A round without an auction winner has no controller
+	require.Equal(t, hexutil.Uint64(7), history[2].Round)
+	require.Equal(t, common.Address{}, history[2].InitialController)
+	require.Empty(t, history[2].Transfers)
+}
+
+func Test_simulateExpressLaneOrdering(t *testing.T) {
+	encode := func(nonce uint64, arrivalOffsetMs uint64) (SimulatedTransaction, common.Hash) {
+		tx := types.NewTransaction(nonce, common.Address{}, big.NewInt(0), 21000, big.NewInt(0), nil)
+		txBytes, err := tx.MarshalBinary()
+		require.NoError(t, err)
+		return SimulatedTransaction{txBytes, hexutil.Uint64(arrivalOffsetMs)}, tx.Hash()
+	}
+	express0, express0Hash := encode(0, 150)
+	express1, express1Hash := encode(1, 50) // Arrives early, but waits for express0
+	express2, express2Hash := encode(2, 500)
+	normal0, normal0Hash := encode(10, 0)
+	normal1, normal1Hash := encode(11, 100)
+
+	ordered, err := simulateExpressLaneOrdering(200*time.Millisecond, []SimulatedTransaction{express0, express1, express2}, []SimulatedTransaction{normal0, normal1})
+	require.NoError(t, err)
+	require.Equal(t, []SimulatedOrderedTransaction{
+		{TxHash: express0Hash, Timeboosted: true, QueuedAtMs: 150},
+		{TxHash: express1Hash, Timeboosted: true, QueuedAtMs: 150},
+		{TxHash: normal0Hash, Timeboosted: false, QueuedAtMs: 200},
+		{TxHash: normal1Hash, Timeboosted: false, QueuedAtMs: 300},
+		{TxHash: express2Hash, Timeboosted: true, QueuedAtMs: 500},
+	}, ordered)
+
+	// Without an advantage the normal tx arriving first is queued first
+	ordered, err = simulateExpressLaneOrdering(0, []SimulatedTransaction{express0}, []SimulatedTransaction{normal0})
+	require.NoError(t, err)
+	require.Equal(t, normal0Hash, ordered[0].TxHash)
+
+	_, err = simulateExpressLaneOrdering(0, []SimulatedTransaction{{Transaction: []byte{1, 2, 3}}}, nil)
+	require.Error(t, err)
+}
+
+func Test_expressLaneService_reconcileRoundController(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	els := &expressLaneService{
+		roundInfo:       containers.NewLruCache[uint64, *expressLaneRoundInfo](8),
+		roundTimingInfo: defaultTestRoundTimingInfo(time.Now()),
+	}
+	onchainController := crypto.PubkeyToAddress(testPriv.PublicKey)
+	driftedController := crypto.PubkeyToAddress(testPriv2.PublicKey)
+	fetchResolvedRound := func(_ *bind.CallOpts, i *big.Int) (struct {
+		ExpressLaneController common.Address
+		Round                 uint64
+	}, error) {
+		resolved := struct {
+			ExpressLaneController common.Address
+			Round                 uint64
+		}{}
+		if i.Sign() == 0 {
+			resolved.ExpressLaneController = onchainController
+			resolved.Round = 0
+		}
+		return resolved, nil
+	}
+
+	// Inject a drift in the controller of the current round along with some sequencing state
+	els.roundControl.Store(0, driftedController)
+	els.roundInfo.Add(0, &expressLaneRoundInfo{3, make(map[uint64]*msgAndResult)})
+
+	els.reconcileRoundController(ctx, fetchResolvedRound)
+	controller, ok := els.roundControl.Load(0)
+	require.True(t, ok)
+	require.Equal(t, onchainController, controller)
+	roundInfo, ok := els.roundInfo.Get(0)
+	require.True(t, ok)
+	require.Equal(t, uint64(0), roundInfo.sequence)
+
+	// Reconciling again without drift leaves the state untouched
+	roundInfo.sequence = 2
+	els.reconcileRoundController(ctx, fetchResolvedRound)
+	controller, ok = els.roundControl.Load(0)
+	require.True(t, ok)
+	require.Equal(t, onchainController, controller)
+	roundInfo, ok = els.roundInfo.Get(0)
+	require.True(t, ok)
+	require.Equal(t, uint64(2), roundInfo.sequence)
+
+	// A controller the contract doesn't know about is removed
+	onchainController = common.Address{}
+	els.reconcileRoundController(ctx, fetchResolvedRound)
+	_, ok = els.roundControl.Load(0)
+	require.False(t, ok)
+}
+
 func TestIsWithinAuctionCloseWindow(t *testing.T) {
 	initialTimestamp := time.Date(2024, 8, 8, 15, 0, 0, 0, time.UTC)
 	roundTimingInfo := defaultTestRoundTimingInfo(initialTimestamp)
@@ -600,6 +1286,7 @@ func Benchmark_expressLaneService_validateExpressLaneTx(b *testing.B) {
 		chainConfig: &params.ChainConfig{
 			ChainID: big.NewInt(1),
 		},
+		seqConfig: func() *SequencerConfig { return &DefaultSequencerConfig },
 	}
 	es.roundControl.Store(0, addr)
 	es.roundInfo.Add(0, &expressLaneRoundInfo{1, make(map[uint64]*msgAndResult)})
@@ -10,6 +10,7 @@ import (
 
 	flag "github.com/spf13/pflag"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/arbitrum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
@@ -27,7 +28,9 @@ import (
 	"github.com/offchainlabs/nitro/arbos/programs"
 	"github.com/offchainlabs/nitro/arbutil"
 	"github.com/offchainlabs/nitro/execution"
+	"github.com/offchainlabs/nitro/solgen/go/express_lane_auctiongen"
 	"github.com/offchainlabs/nitro/solgen/go/precompilesgen"
+	"github.com/offchainlabs/nitro/timeboost"
 	"github.com/offchainlabs/nitro/util/arbmath"
 	"github.com/offchainlabs/nitro/util/containers"
 	"github.com/offchainlabs/nitro/util/dbutil"
@@ -100,6 +103,7 @@ type Config struct {
 	StylusTarget                StylusTargetConfig  `koanf:"stylus-target"`
 	BlockMetadataApiCacheSize   uint64              `koanf:"block-metadata-api-cache-size"`
 	BlockMetadataApiBlocksLimit uint64              `koanf:"block-metadata-api-blocks-limit"`
+	BlockMetadataApiClampLatest bool                `koanf:"block-metadata-api-clamp-latest"`
 
 	forwardingTarget string
 }
@@ -144,6 +148,7 @@ func ConfigAddOptions(prefix string, f *flag.FlagSet) {
 	StylusTargetConfigAddOptions(prefix+".stylus-target", f)
 	f.Uint64(prefix+".block-metadata-api-cache-size", ConfigDefault.BlockMetadataApiCacheSize, "size (in bytes) of lru cache storing the blockMetadata to service arb_getRawBlockMetadata")
 	f.Uint64(prefix+".block-metadata-api-blocks-limit", ConfigDefault.BlockMetadataApiBlocksLimit, "maximum number of blocks allowed to be queried for blockMetadata per arb_getRawBlockMetadata query. Enabled by default, set 0 to disable the limit")
+	f.Bool(prefix+".block-metadata-api-clamp-latest", ConfigDefault.BlockMetadataApiClampLatest, "when an arb_getRawBlockMetadata query ending at \"latest\" exceeds the blocks limit, return blockMetadata of the most recent blocks within the limit instead of erroring")
 }
 
 var ConfigDefault = Config{
@@ -180,6 +185,9 @@ type ExecutionNode struct {
 	ClassicOutbox            *ClassicOutboxRetriever
 	started                  atomic.Bool
 	bulkBlockMetadataFetcher *BulkBlockMetadataFetcher
+	// Tracks the express lane controllers announced on the sequencer feed, for nodes other than the sequencer
+	ExpressLaneControllerTracker *timeboost.ExpressLaneControllerTracker
+	expressLaneRoundTimingInfo   atomic.Pointer[timeboost.RoundTimingInfo]
 }
 
 func CreateExecutionNode(
@@ -269,13 +277,19 @@ func CreateExecutionNode(
 		}
 	}
 
-	bulkBlockMetadataFetcher := NewBulkBlockMetadataFetcher(l2BlockChain, execEngine, config.BlockMetadataApiCacheSize, config.BlockMetadataApiBlocksLimit)
+	bulkBlockMetadataFetcher := NewBulkBlockMetadataFetcher(l2BlockChain, execEngine, config.BlockMetadataApiCacheSize, config.BlockMetadataApiBlocksLimit, config.BlockMetadataApiClampLatest)
 
 	apis := []rpc.API{{
 		Namespace: "arb",
 		Version:   "1.0",
 		Service:   NewArbAPI(txPublisher, bulkBlockMetadataFetcher),
 		Public:    false,
+	}, {
+		Namespace:     "arb",
+		Version:       "1.0",
+		Service:       NewArbAdminAPI(bulkBlockMetadataFetcher),
+		Public:        false,
+		Authenticated: true,
 	}}
 	apis = append(apis, rpc.API{
 		Namespace:     "auctioneer",
@@ -284,12 +298,8 @@ func CreateExecutionNode(
 		Public:        false,
 		Authenticated: false,
 	})
-	apis = append(apis, rpc.API{
-		Namespace: "timeboost",
-		Version:   "1.0",
-		Service:   NewArbTimeboostAPI(txPublisher),
-		Public:    false,
-	})
+	expressLaneControllerTracker := timeboost.NewExpressLaneControllerTracker()
+	apis = append(apis, TimeboostAPIs(txPublisher, sequencer, expressLaneControllerTracker, config.Sequencer.Dangerous.Timeboost.AuthenticateSubmissions)...)
 	apis = append(apis, rpc.API{
 		Namespace: "arbdebug",
 		Version:   "1.0",
@@ -319,19 +329,20 @@ func CreateExecutionNode(
 	stack.RegisterAPIs(apis)
 
 	return &ExecutionNode{
-		ChainDB:                  chainDB,
-		Backend:                  backend,
-		FilterSystem:             filterSystem,
-		ArbInterface:             arbInterface,
-		ExecEngine:               execEngine,
-		Recorder:                 recorder,
-		Sequencer:                sequencer,
-		TxPublisher:              txPublisher,
-		ConfigFetcher:            configFetcher,
-		SyncMonitor:              syncMon,
-		ParentChainReader:        parentChainReader,
-		ClassicOutbox:            classicOutbox,
-		bulkBlockMetadataFetcher: bulkBlockMetadataFetcher,
+		ChainDB:                      chainDB,
+		Backend:                      backend,
+		FilterSystem:                 filterSystem,
+		ArbInterface:                 arbInterface,
+		ExecEngine:                   execEngine,
+		Recorder:                     recorder,
+		Sequencer:                    sequencer,
+		TxPublisher:                  txPublisher,
+		ConfigFetcher:                configFetcher,
+		SyncMonitor:                  syncMon,
+		ParentChainReader:            parentChainReader,
+		ClassicOutbox:                classicOutbox,
+		bulkBlockMetadataFetcher:     bulkBlockMetadataFetcher,
+		ExpressLaneControllerTracker: expressLaneControllerTracker,
 	}, nil
 
 }
@@ -365,6 +376,34 @@ func (n *ExecutionNode) Initialize(ctx context.Context) error {
 }
 
 // not thread safe
+// ExpressLaneRoundTimingInfo returns the round timing of the express lane auction contract configured by
+// sequencer.dangerous.timeboost.auction-contract-address. It is read from the chain state, so it fails until the
+// node has synced past the deployment of the auction contract, and is cached from then on.
+func (n *ExecutionNode) ExpressLaneRoundTimingInfo() (*timeboost.RoundTimingInfo, error) {
+	if roundTimingInfo := n.expressLaneRoundTimingInfo.Load(); roundTimingInfo != nil {
+		return roundTimingInfo, nil
+	}
+	auctionContractAddr := n.ConfigFetcher().Sequencer.Dangerous.Timeboost.AuctionContractAddress
+	if auctionContractAddr == "" {
+		return nil, errors.New("no express lane auction contract configured")
+	}
+	contractBackend := &contractAdapter{filters.NewFilterAPI(n.FilterSystem), nil, n.Backend.APIBackend()}
+	auctionContract, err := express_lane_auctiongen.NewExpressLaneAuctionCaller(common.HexToAddress(auctionContractAddr), contractBackend)
+	if err != nil {
+		return nil, err
+	}
+	rawRoundTimingInfo, err := auctionContract.RoundTimingInfo(&bind.CallOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("failed reading express lane round timing: %w", err)
+	}
+	roundTimingInfo, err := timeboost.NewRoundTimingInfo(rawRoundTimingInfo)
+	if err != nil {
+		return nil, err
+	}
+	n.expressLaneRoundTimingInfo.Store(roundTimingInfo)
+	return roundTimingInfo, nil
+}
+
 func (n *ExecutionNode) Start(ctx context.Context) containers.PromiseInterface[struct{}] {
 	if n.started.Swap(true) {
 		return containers.NewReadyPromise(struct{}{}, errors.New("already started"))
@@ -383,6 +422,7 @@ func (n *ExecutionNode) Start(ctx context.Context) containers.PromiseInterface[s
 		n.ParentChainReader.Start(ctx)
 	}
 	n.bulkBlockMetadataFetcher.Start(ctx)
+	n.ExpressLaneControllerTracker.Start(ctx)
 	return containers.NewReadyPromise(struct{}{}, nil)
 }
 
@@ -391,6 +431,9 @@ func (n *ExecutionNode) StopAndWait() containers.PromiseInterface[struct{}] {
 		return containers.NewReadyPromise(struct{}{}, nil)
 	}
 	n.bulkBlockMetadataFetcher.StopAndWait()
+	if n.ExpressLaneControllerTracker.Started() {
+		n.ExpressLaneControllerTracker.StopAndWait()
+	}
 	// TODO after separation
 	// n.Stack.StopRPC() // does nothing if not running
 	if n.TxPublisher.Started() {
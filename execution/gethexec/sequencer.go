@@ -11,6 +11,7 @@ import (
 	"math/big"
 	"runtime/debug"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -20,6 +21,7 @@ import (
 	"github.com/ethereum/go-ethereum/arbitrum"
 	"github.com/ethereum/go-ethereum/arbitrum_types"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
@@ -89,25 +91,45 @@ type DangerousConfig struct {
 }
 
 type TimeboostConfig struct {
-	Enable                    bool          `koanf:"enable"`
-	AuctionContractAddress    string        `koanf:"auction-contract-address"`
-	AuctioneerAddress         string        `koanf:"auctioneer-address"`
-	ExpressLaneAdvantage      time.Duration `koanf:"express-lane-advantage"`
-	SequencerHTTPEndpoint     string        `koanf:"sequencer-http-endpoint"`
-	EarlySubmissionGrace      time.Duration `koanf:"early-submission-grace"`
-	MaxFutureSequenceDistance uint64        `koanf:"max-future-sequence-distance"`
-	RedisUrl                  string        `koanf:"redis-url"`
+	Enable                         bool          `koanf:"enable"`
+	AuctionContractAddress         string        `koanf:"auction-contract-address"`
+	AuctioneerAddress              string        `koanf:"auctioneer-address"`
+	ExpressLaneAdvantage           time.Duration `koanf:"express-lane-advantage"`
+	SequencerHTTPEndpoint          string        `koanf:"sequencer-http-endpoint"`
+	EarlySubmissionGrace           time.Duration `koanf:"early-submission-grace"`
+	MaxFutureSequenceDistance      uint64        `koanf:"max-future-sequence-distance"`
+	RedisUrl                       string        `koanf:"redis-url"`
+	MaxExpressLaneTxBytes          int           `koanf:"max-express-lane-tx-bytes"`
+	ControllerReconcileInterval    time.Duration `koanf:"controller-reconcile-interval"`
+	MaxClockSkew                   time.Duration `koanf:"max-clock-skew"`
+	EarlySubmissionGraceOverrides  []string      `koanf:"early-submission-grace-overrides"`
+	SenderRecoveryWorkers          int           `koanf:"sender-recovery-workers"`
+	EnableSequenceReservation      bool          `koanf:"enable-sequence-reservation"`
+	SequenceReservationTimeout     time.Duration `koanf:"sequence-reservation-timeout"`
+	HoldSubmissionsWhilePaused     bool          `koanf:"hold-submissions-while-paused"`
+	AuthenticateSubmissions        bool          `koanf:"authenticate-submissions"`
+	MaxSubmissionLatenessIntoRound time.Duration `koanf:"max-submission-lateness-into-round"`
 }
 
 var DefaultTimeboostConfig = TimeboostConfig{
-	Enable:                    false,
-	AuctionContractAddress:    "",
-	AuctioneerAddress:         "",
-	ExpressLaneAdvantage:      time.Millisecond * 200,
-	SequencerHTTPEndpoint:     "http://localhost:8547",
-	EarlySubmissionGrace:      time.Second * 2,
-	MaxFutureSequenceDistance: 25,
-	RedisUrl:                  "unset",
+	Enable:                         false,
+	AuctionContractAddress:         "",
+	AuctioneerAddress:              "",
+	ExpressLaneAdvantage:           time.Millisecond * 200,
+	SequencerHTTPEndpoint:          "http://localhost:8547",
+	EarlySubmissionGrace:           time.Second * 2,
+	MaxFutureSequenceDistance:      25,
+	RedisUrl:                       "unset",
+	MaxExpressLaneTxBytes:          0, // Defaults to the sequencer's max-tx-data-size
+	ControllerReconcileInterval:    time.Second * 30,
+	MaxClockSkew:                   0,
+	EarlySubmissionGraceOverrides:  nil,
+	SenderRecoveryWorkers:          0, // Defaults to the number of CPUs
+	EnableSequenceReservation:      false,
+	SequenceReservationTimeout:     time.Second * 2,
+	HoldSubmissionsWhilePaused:     false,
+	AuthenticateSubmissions:        false,
+	MaxSubmissionLatenessIntoRound: 0, // Allows the whole round
 }
 
 func (c *SequencerConfig) Validate() error {
@@ -155,9 +177,58 @@ func (c *TimeboostConfig) Validate() error {
 	if c.MaxFutureSequenceDistance == 0 {
 		return errors.New("timeboost max-future-sequence-distance option cannot be zero, it should be set to a positive value")
 	}
+	if c.MaxExpressLaneTxBytes < 0 {
+		return fmt.Errorf("timeboost max-express-lane-tx-bytes option cannot be negative, got: %d", c.MaxExpressLaneTxBytes)
+	}
+	if c.MaxClockSkew < 0 {
+		return fmt.Errorf("timeboost max-clock-skew option cannot be negative, got: %v", c.MaxClockSkew)
+	}
+	if c.SenderRecoveryWorkers < 0 {
+		return fmt.Errorf("timeboost sender-recovery-workers option cannot be negative, got: %d", c.SenderRecoveryWorkers)
+	}
+	if c.EnableSequenceReservation && c.SequenceReservationTimeout <= 0 {
+		return fmt.Errorf("timeboost sequence-reservation-timeout option must be positive, got: %v", c.SequenceReservationTimeout)
+	}
+	if c.MaxSubmissionLatenessIntoRound < 0 {
+		return fmt.Errorf("timeboost max-submission-lateness-into-round option cannot be negative, got: %v", c.MaxSubmissionLatenessIntoRound)
+	}
+	for _, override := range c.EarlySubmissionGraceOverrides {
+		if _, _, err := parseEarlySubmissionGraceOverride(override); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// parseEarlySubmissionGraceOverride parses an <address>:<duration> entry of early-submission-grace-overrides
+func parseEarlySubmissionGraceOverride(override string) (common.Address, time.Duration, error) {
+	address, graceStr, found := strings.Cut(override, ":")
+	if !found || !common.IsHexAddress(address) {
+		return common.Address{}, 0, fmt.Errorf("invalid timeboost.early-submission-grace-overrides entry \"%v\", expected <address>:<duration>", override)
+	}
+	grace, err := time.ParseDuration(graceStr)
+	if err != nil {
+		return common.Address{}, 0, fmt.Errorf("invalid timeboost.early-submission-grace-overrides duration in entry \"%v\": %w", override, err)
+	}
+	if grace < 0 {
+		return common.Address{}, 0, fmt.Errorf("timeboost early-submission-grace-overrides entry \"%v\" cannot have a negative grace", override)
+	}
+	return common.HexToAddress(address), grace, nil
+}
+
+// earlySubmissionGraceOverride returns the early submission grace configured for the given controller, if any.
+// The overrides are parsed on use rather than by Validate, so that they apply to configs that weren't validated,
+// and invalid entries are skipped.
+func (c *TimeboostConfig) earlySubmissionGraceOverride(controller common.Address) (time.Duration, bool) {
+	for _, override := range c.EarlySubmissionGraceOverrides {
+		address, grace, err := parseEarlySubmissionGraceOverride(override)
+		if err == nil && address == controller {
+			return grace, true
+		}
+	}
+	return 0, false
+}
+
 type SequencerConfigFetcher func() *SequencerConfig
 
 var DefaultSequencerConfig = SequencerConfig{
@@ -214,6 +285,16 @@ func TimeboostAddOptions(prefix string, f *flag.FlagSet) {
 	f.Duration(prefix+".early-submission-grace", DefaultTimeboostConfig.EarlySubmissionGrace, "period of time before the next round where submissions for the next round will be queued")
 	f.Uint64(prefix+".max-future-sequence-distance", DefaultTimeboostConfig.MaxFutureSequenceDistance, "maximum allowed difference (in terms of sequence numbers) between a future express lane tx and the current sequence count of a round")
 	f.String(prefix+".redis-url", DefaultTimeboostConfig.RedisUrl, "the Redis URL for expressLaneService to coordinate via")
+	f.Int(prefix+".max-express-lane-tx-bytes", DefaultTimeboostConfig.MaxExpressLaneTxBytes, "maximum size in bytes of a transaction submitted via the express lane, 0 uses the sequencer's max-tx-data-size")
+	f.Duration(prefix+".controller-reconcile-interval", DefaultTimeboostConfig.ControllerReconcileInterval, "interval at which the current round's express lane controller is re-read from the auction contract to correct drifted in-memory state, 0 to disable")
+	f.Duration(prefix+".max-clock-skew", DefaultTimeboostConfig.MaxClockSkew, "tolerated wall clock skew; within this period of a round boundary a latest parent chain block timestamp ahead of the wall clock, by at most this much, decides the current round (0 = disabled)")
+	f.StringSlice(prefix+".early-submission-grace-overrides", DefaultTimeboostConfig.EarlySubmissionGraceOverrides, "per controller overrides of early-submission-grace, as a list of <address>:<duration> entries")
+	f.Int(prefix+".sender-recovery-workers", DefaultTimeboostConfig.SenderRecoveryWorkers, "maximum number of express lane submissions whose signatures are recovered in parallel ahead of being sequenced in order, 0 uses the number of CPUs")
+	f.Bool(prefix+".enable-sequence-reservation", DefaultTimeboostConfig.EnableSequenceReservation, "enable timeboost_nextExpressLaneSequence, served on the JWT authenticated RPC endpoint, for express lane clients of the controller to share a round's sequence")
+	f.Duration(prefix+".sequence-reservation-timeout", DefaultTimeboostConfig.SequenceReservationTimeout, "period after which a sequence number reserved via timeboost_nextExpressLaneSequence but not submitted is handed out again, so that an unused reservation doesn't stall the round's express lane")
+	f.Bool(prefix+".hold-submissions-while-paused", DefaultTimeboostConfig.HoldSubmissionsWhilePaused, "while the express lane is paused via timeboost_pauseExpressLane, hold accepted express lane submissions until it's resumed instead of sequencing them without advantage")
+	f.Bool(prefix+".authenticate-submissions", DefaultTimeboostConfig.AuthenticateSubmissions, "only serve timeboost_sendExpressLaneTransaction on the JWT authenticated RPC endpoint, the rest of the timeboost namespace is unaffected")
+	f.Duration(prefix+".max-submission-lateness-into-round", DefaultTimeboostConfig.MaxSubmissionLatenessIntoRound, "period after the start of a round within which express lane txs for it are accepted, later submissions are rejected (0 = the whole round)")
 }
 
 func DangerousAddOptions(prefix string, f *flag.FlagSet) {
@@ -414,6 +495,8 @@ type Sequencer struct {
 	expectedSurplusUpdated            bool
 	auctioneerAddr                    common.Address
 	timeboostAuctionResolutionTxQueue chan txQueueItem
+	auctionResultListener             AuctionResultListener
+	controllerTransferListener        ControllerTransferListener
 }
 
 func NewSequencer(execEngine *ExecutionEngine, l1Reader *headerreader.HeaderReader, configFetcher SequencerConfigFetcher) (*Sequencer, error) {
@@ -597,6 +680,9 @@ func (s *Sequencer) PublishExpressLaneTransaction(ctx context.Context, msg *time
 	if s.expressLaneService == nil {
 		return errors.New("express lane service not enabled")
 	}
+	if err := s.expressLaneService.recoverSenders(ctx, msg); err != nil {
+		return err
+	}
 	if err := s.expressLaneService.validateExpressLaneTx(msg); err != nil {
 		return err
 	}
@@ -612,6 +698,131 @@ func (s *Sequencer) PublishExpressLaneTransaction(ctx context.Context, msg *time
 	return s.expressLaneService.sequenceExpressLaneSubmission(ctx, msg)
 }
 
+// ExpressLaneControllerHistory returns the express lane controllers of the given range of rounds, including mid-round transfers.
+func (s *Sequencer) ExpressLaneControllerHistory(ctx context.Context, fromRound, toRound uint64) ([]*ExpressLaneRoundControllers, error) {
+	if !s.config().Dangerous.Timeboost.Enable {
+		return nil, errors.New("timeboost not enabled")
+	}
+	if s.expressLaneService == nil {
+		return nil, errors.New("express lane service not enabled")
+	}
+	return s.expressLaneService.controllerHistory(ctx, fromRound, toRound)
+}
+
+// ExpressLaneSequenceStatus reports the express lane submissions accepted for the given round that are buffered
+// waiting on missing sequence numbers. The current round is used if round is nil.
+func (s *Sequencer) ExpressLaneSequenceStatus(round *uint64) (*ExpressLaneSequenceStatus, error) {
+	if !s.config().Dangerous.Timeboost.Enable {
+		return nil, errors.New("timeboost not enabled")
+	}
+	if s.expressLaneService == nil {
+		return nil, errors.New("express lane service not enabled")
+	}
+	if round == nil {
+		currentRound := s.expressLaneService.currentRound()
+		round = &currentRound
+	}
+	return s.expressLaneService.sequenceStatus(*round), nil
+}
+
+// ExpressLaneAdvantage reports the delay currently applied to transactions not sent through the express lane.
+func (s *Sequencer) ExpressLaneAdvantage() (*ExpressLaneAdvantage, error) {
+	if !s.config().Dangerous.Timeboost.Enable {
+		return nil, errors.New("timeboost not enabled")
+	}
+	if s.expressLaneService == nil {
+		return nil, errors.New("express lane service not enabled")
+	}
+	return s.expressLaneService.advantage(s.expressLaneService.currentRound()), nil
+}
+
+// ExpressLaneController returns the express lane controller of the given round as seen by the express lane service.
+func (s *Sequencer) ExpressLaneController(round uint64) (common.Address, error) {
+	if s.expressLaneService == nil {
+		return common.Address{}, errors.New("express lane service not enabled")
+	}
+	controller, ok := s.expressLaneService.roundController(round)
+	if !ok {
+		return common.Address{}, fmt.Errorf("%w: round %d", timeboost.ErrNoOnchainController, round)
+	}
+	return controller, nil
+}
+
+// ExpressLaneRoundForBlock returns the express lane round during which the block with the given number was produced.
+func (s *Sequencer) ExpressLaneRoundForBlock(number uint64) (uint64, error) {
+	if s.expressLaneService == nil {
+		return 0, errors.New("express lane service not enabled")
+	}
+	header := s.execEngine.bc.GetHeaderByNumber(number)
+	if header == nil {
+		return 0, fmt.Errorf("block %d not found", number)
+	}
+	return timeboost.RoundForBlock(header.Time, s.expressLaneService.roundTimingInfo), nil
+}
+
+// PauseExpressLane stops applying the express lane advantage until ResumeExpressLane is called.
+func (s *Sequencer) PauseExpressLane() error {
+	if !s.config().Dangerous.Timeboost.Enable {
+		return errors.New("timeboost not enabled")
+	}
+	if s.expressLaneService == nil {
+		return errors.New("express lane service not enabled")
+	}
+	s.expressLaneService.pause()
+	log.Info("Express lane paused")
+	return nil
+}
+
+// ResumeExpressLane applies the express lane advantage again, sequencing any submissions held during the pause.
+func (s *Sequencer) ResumeExpressLane() error {
+	if !s.config().Dangerous.Timeboost.Enable {
+		return errors.New("timeboost not enabled")
+	}
+	if s.expressLaneService == nil {
+		return errors.New("express lane service not enabled")
+	}
+	s.expressLaneService.resume()
+	log.Info("Express lane resumed")
+	return nil
+}
+
+// ReserveExpressLaneSequenceNumber hands out the next unreserved sequence number of the given round,
+// or the current one if omitted, so that multiple clients of the same controller don't need to coordinate.
+func (s *Sequencer) ReserveExpressLaneSequenceNumber(round *uint64) (*timeboost.SequenceHint, error) {
+	config := s.config()
+	if !config.Dangerous.Timeboost.Enable {
+		return nil, errors.New("timeboost not enabled")
+	}
+	if !config.Dangerous.Timeboost.EnableSequenceReservation {
+		return nil, errors.New("express lane sequence reservation not enabled")
+	}
+	if s.expressLaneService == nil {
+		return nil, errors.New("express lane service not enabled")
+	}
+	if round == nil {
+		currentRound := s.expressLaneService.currentRound()
+		round = &currentRound
+	}
+	sequence, err := s.expressLaneService.reserveSequenceNumber(*round)
+	if err != nil {
+		return nil, err
+	}
+	return &timeboost.SequenceHint{
+		Round:                  hexutil.Uint64(*round),
+		ExpectedSequenceNumber: hexutil.Uint64(sequence),
+	}, nil
+}
+
+// SimulateExpressLaneOrdering returns the order in which the given express lane and regular transactions would be
+// sequenced during a round with an express lane controller, without sequencing them.
+func (s *Sequencer) SimulateExpressLaneOrdering(expressLaneTxs, txs []SimulatedTransaction) ([]SimulatedOrderedTransaction, error) {
+	config := s.config()
+	if !config.Dangerous.Timeboost.Enable {
+		return nil, errors.New("timeboost not enabled")
+	}
+	return simulateExpressLaneOrdering(config.Dangerous.Timeboost.ExpressLaneAdvantage, expressLaneTxs, txs)
+}
+
 func (s *Sequencer) PublishTimeboostedTransaction(queueCtx context.Context, tx *types.Transaction, options *arbitrum_types.ConditionalOptions, resultChan chan error) {
 	if err := s.publishTransactionToQueue(queueCtx, tx, options, resultChan, true); err != nil {
 		resultChan <- err
@@ -656,7 +867,7 @@ func (s *Sequencer) publishTransactionToQueue(queueCtx context.Context, tx *type
 	}
 
 	if s.config().Dangerous.Timeboost.Enable && s.expressLaneService != nil {
-		if !isExpressLaneController && s.expressLaneService.currentRoundHasController() {
+		if !isExpressLaneController && s.expressLaneService.advantageApplies() {
 			time.Sleep(s.config().Dangerous.Timeboost.ExpressLaneAdvantage)
 		}
 	}
@@ -1269,6 +1480,19 @@ func (s *Sequencer) Initialize(ctx context.Context) error {
 	return nil
 }
 
+// parentChainTime returns the timestamp of the latest parent chain header seen by the l1Reader
+func (s *Sequencer) parentChainTime() (time.Time, error) {
+	header, err := s.l1Reader.LastHeaderWithError()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if header == nil {
+		return time.Time{}, errors.New("no parent chain header received yet")
+	}
+	// #nosec G115
+	return time.Unix(int64(header.Time), 0), nil
+}
+
 func (s *Sequencer) InitializeExpressLaneService(
 	apiBackend *arbitrum.APIBackend,
 	filterSystem *filters.FilterSystem,
@@ -1288,11 +1512,30 @@ func (s *Sequencer) InitializeExpressLaneService(
 	if err != nil {
 		return fmt.Errorf("failed to create express lane service. auctionContractAddr: %v err: %w", auctionContractAddr, err)
 	}
+	els.auctionResultListener = s.auctionResultListener
+	els.controllerTransferListener = s.controllerTransferListener
+	if s.l1Reader != nil {
+		els.parentChainTime = s.parentChainTime
+	} else if els.maxClockSkew > 0 {
+		log.Warn("Timeboost max-clock-skew is set but there is no parent chain reader, express lane rounds will follow the wall clock")
+	}
 	s.auctioneerAddr = auctioneerAddr
 	s.expressLaneService = els
 	return nil
 }
 
+// SetAuctionResultListener sets the function notified of the auction results seen by the express lane service,
+// must be called before InitializeExpressLaneService
+func (s *Sequencer) SetAuctionResultListener(listener AuctionResultListener) {
+	s.auctionResultListener = listener
+}
+
+// SetControllerTransferListener sets the function notified of the express lane control transfers seen by the express
+// lane service, must be called before InitializeExpressLaneService
+func (s *Sequencer) SetControllerTransferListener(listener ControllerTransferListener) {
+	s.controllerTransferListener = listener
+}
+
 var (
 	usableBytesInBlob    = big.NewInt(int64(len(kzg4844.Blob{}) * 31 / 32))
 	blobTxBlobGasPerBlob = big.NewInt(params.BlobTxBlobGasPerBlob)
@@ -28,6 +28,8 @@ type Relay struct {
 	broadcaster                 *broadcaster.Broadcaster
 	confirmedSequenceNumberChan chan arbutil.MessageIndex
 	messageChan                 chan m.BroadcastFeedMessage
+	auctionResultChan           chan *m.AuctionResultMessage
+	controllerTransferChan      chan *m.ControllerTransferMessage
 }
 
 type MessageQueue struct {
@@ -63,6 +65,12 @@ func NewRelay(config *Config, feedErrChan chan error) (*Relay, error) {
 	if clients == nil {
 		return nil, errors.New("no feed servers found")
 	}
+	// Express lane auction results and control transfers are forwarded with the sequencer's signature,
+	// so that nodes behind the relay can verify them like feed messages
+	auctionResultListener := make(chan *m.AuctionResultMessage, config.Queue)
+	controllerTransferListener := make(chan *m.ControllerTransferMessage, config.Queue)
+	clients.SetAuctionResultListener(auctionResultListener)
+	clients.SetControllerTransferListener(controllerTransferListener)
 
 	dataSignerErr := func([]byte) ([]byte, error) {
 		return nil, errors.New("relay attempted to sign feed message")
@@ -72,6 +80,8 @@ func NewRelay(config *Config, feedErrChan chan error) (*Relay, error) {
 		broadcastClients:            clients,
 		confirmedSequenceNumberChan: confirmedSequenceNumberListener,
 		messageChan:                 q.queue,
+		auctionResultChan:           auctionResultListener,
+		controllerTransferChan:      controllerTransferListener,
 	}, nil
 }
 
@@ -98,6 +108,10 @@ func (r *Relay) Start(ctx context.Context) error {
 				r.broadcaster.BroadcastSingleFeedMessage(&msg)
 			case cs := <-r.confirmedSequenceNumberChan:
 				r.broadcaster.Confirm(cs)
+			case result := <-r.auctionResultChan:
+				r.broadcaster.BroadcastAuctionResultMessage(result)
+			case transfer := <-r.controllerTransferChan:
+				r.broadcaster.BroadcastControllerTransferMessage(transfer)
 			}
 		}
 	})
@@ -20,6 +20,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
@@ -49,6 +50,7 @@ var (
 	validatorMsgCountCreatedGauge     = metrics.NewRegisteredGauge("arb/validator/msg_count_created", nil)
 	validatorMsgCountRecordSentGauge  = metrics.NewRegisteredGauge("arb/validator/msg_count_record_sent", nil)
 	validatorMsgCountValidatedGauge   = metrics.NewRegisteredGauge("arb/validator/msg_count_validated", nil)
+	validatorMsgCountCheckedGauge     = metrics.NewRegisteredGauge("arb/validator/msg_count_checked", nil)
 )
 
 type BlockValidator struct {
@@ -69,6 +71,7 @@ type BlockValidator struct {
 
 	// can only be accessed from from validation thread or if holding reorg-write
 	lastValidGS     validator.GoGlobalState
+	lastCheckedGS   validator.GoGlobalState
 	valLoopPos      arbutil.MessageIndex
 	legacyValidInfo *legacyLastBlockValidatedDbInfo
 
@@ -80,6 +83,8 @@ type BlockValidator struct {
 	createdA    atomic.Uint64
 	recordSentA atomic.Uint64
 	validatedA  atomic.Uint64
+	// checkedA runs ahead of validatedA only after continuing past a mismatch, it is never persisted
+	checkedA    atomic.Uint64
 	validations containers.SyncMap[arbutil.MessageIndex, *validationStatus]
 
 	config BlockValidatorConfigFetcher
@@ -117,9 +122,15 @@ type BlockValidatorConfig struct {
 	RecordingIterLimit          uint64                        `koanf:"recording-iter-limit"`
 	ForwardBlocks               uint64                        `koanf:"forward-blocks" reload:"hot"`
 	BatchCacheLimit             uint32                        `koanf:"batch-cache-limit"`
+	BatchPrefetch               uint64                        `koanf:"batch-prefetch"`
+	ReuseValidationEntries      bool                          `koanf:"reuse-validation-entries"`
+	ArchivePreimages            bool                          `koanf:"archive-preimages"`
+	PreimageMemoryBudget        uint64                        `koanf:"preimage-memory-budget"`
+	PreimageSpillDir            string                        `koanf:"preimage-spill-dir"`
 	CurrentModuleRoot           string                        `koanf:"current-module-root"`         // TODO(magic) requires reinitialization on hot reload
 	PendingUpgradeModuleRoot    string                        `koanf:"pending-upgrade-module-root"` // TODO(magic) requires StatelessBlockValidator recreation on hot reload
 	FailureIsFatal              bool                          `koanf:"failure-is-fatal" reload:"hot"`
+	ContinueOnMismatch          bool                          `koanf:"continue-on-mismatch" reload:"hot"`
 	Dangerous                   BlockValidatorDangerousConfig `koanf:"dangerous"`
 	MemoryFreeLimit             string                        `koanf:"memory-free-limit" reload:"hot"`
 	ValidationServerConfigsList string                        `koanf:"validation-server-configs-list"`
@@ -187,10 +198,16 @@ func BlockValidatorConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.Uint64(prefix+".forward-blocks", DefaultBlockValidatorConfig.ForwardBlocks, "prepare entries for up to that many blocks ahead of validation (stores batch-copy per block)")
 	f.Uint64(prefix+".prerecorded-blocks", DefaultBlockValidatorConfig.PrerecordedBlocks, "record that many blocks ahead of validation (larger footprint)")
 	f.Uint32(prefix+".batch-cache-limit", DefaultBlockValidatorConfig.BatchCacheLimit, "limit number of old batches to keep in block-validator")
+	f.Uint64(prefix+".batch-prefetch", DefaultBlockValidatorConfig.BatchPrefetch, "number of upcoming batches to read from the inbox concurrently while validating the current one (0 to disable)")
+	f.Bool(prefix+".reuse-validation-entries", DefaultBlockValidatorConfig.ReuseValidationEntries, "reuse the preimage maps and batch slices of validation entries across validations to reduce allocations")
+	f.Bool(prefix+".archive-preimages", DefaultBlockValidatorConfig.ArchivePreimages, "persist the preimages recorded for validation to the database, building a preimage archive")
+	f.Uint64(prefix+".preimage-memory-budget", DefaultBlockValidatorConfig.PreimageMemoryBudget, "maximum bytes of recorded preimages kept in memory per validation entry, the rest are spilled to disk (0 to keep all in memory)")
+	f.String(prefix+".preimage-spill-dir", DefaultBlockValidatorConfig.PreimageSpillDir, "directory to spill preimages beyond the preimage-memory-budget to (defaults to the system temporary directory)")
 	f.String(prefix+".current-module-root", DefaultBlockValidatorConfig.CurrentModuleRoot, "current wasm module root ('current' read from chain, 'latest' from machines/latest dir, or provide hash)")
 	f.Uint64(prefix+".recording-iter-limit", DefaultBlockValidatorConfig.RecordingIterLimit, "limit on block recordings sent per iteration")
 	f.String(prefix+".pending-upgrade-module-root", DefaultBlockValidatorConfig.PendingUpgradeModuleRoot, "pending upgrade wasm module root to additionally validate (hash, 'latest' or empty)")
 	f.Bool(prefix+".failure-is-fatal", DefaultBlockValidatorConfig.FailureIsFatal, "failing a validation is treated as a fatal error")
+	f.Bool(prefix+".continue-on-mismatch", DefaultBlockValidatorConfig.ContinueOnMismatch, "log validations ending in an unexpected state and continue checking the next blocks instead of failing, without advancing the last validated block, e.g. for monitoring-only validators")
 	BlockValidatorDangerousConfigAddOptions(prefix+".dangerous", f)
 	f.String(prefix+".memory-free-limit", DefaultBlockValidatorConfig.MemoryFreeLimit, "minimum free-memory limit after reaching which the blockvalidator pauses validation. Enabled by default as 1GB, to disable provide empty string")
 	f.String(prefix+".block-inputs-file-path", DefaultBlockValidatorConfig.BlockInputsFilePath, "directory to write block validation inputs files")
@@ -209,6 +226,7 @@ var DefaultBlockValidatorConfig = BlockValidatorConfig{
 	ForwardBlocks:               128,
 	PrerecordedBlocks:           uint64(2 * runtime.NumCPU()),
 	BatchCacheLimit:             20,
+	BatchPrefetch:               0,
 	CurrentModuleRoot:           "current",
 	PendingUpgradeModuleRoot:    "latest",
 	FailureIsFatal:              true,
@@ -365,6 +383,11 @@ func (v *BlockValidator) validated() arbutil.MessageIndex {
 	return atomicLoadPos(&v.validatedA)
 }
 
+// checked is the count of messages whose validations completed, including ones continued past a mismatch
+func (v *BlockValidator) checked() arbutil.MessageIndex {
+	return atomicLoadPos(&v.checkedA)
+}
+
 func (v *BlockValidator) Validated(t *testing.T) arbutil.MessageIndex {
 	return v.validated()
 }
@@ -403,6 +426,80 @@ func (v *BlockValidator) GetModuleRootsToValidate() []common.Hash {
 	return validatingModuleRoots
 }
 
+// ModuleRootValidationReport is the outcome of validating a block against a single wasm module root
+type ModuleRootValidationReport struct {
+	ModuleRoot common.Hash              `json:"moduleRoot"`
+	Valid      bool                     `json:"valid"`
+	Expected   *validator.GoGlobalState `json:"expected,omitempty"`
+	Actual     *validator.GoGlobalState `json:"actual,omitempty"`
+	Latency    string                   `json:"latency"`
+	Error      string                   `json:"error,omitempty"`
+}
+
+// BlockValidationReport is the outcome of validating a block against all the module roots being validated
+type BlockValidationReport struct {
+	BlockNumber uint64                       `json:"blockNumber"`
+	MessageNum  uint64                       `json:"messageNum"`
+	Valid       bool                         `json:"valid"`
+	Latency     string                       `json:"latency"`
+	ModuleRoots []ModuleRootValidationReport `json:"moduleRoots"`
+}
+
+// ValidateBlockJSON validates the given block against each of the module roots returned by GetModuleRootsToValidate,
+// and returns a JSON encoded BlockValidationReport. Failing to validate against a module root is reported in the JSON
+// rather than returned as an error, so that the report covers all of them.
+func (v *BlockValidator) ValidateBlockJSON(ctx context.Context, blockNum uint64, full bool) ([]byte, error) {
+	pos, err := v.blockMessageIndex(blockNum)
+	if err != nil {
+		return nil, err
+	}
+	report := BlockValidationReport{
+		BlockNumber: blockNum,
+		MessageNum:  uint64(pos),
+		Valid:       true,
+	}
+	start := time.Now()
+	for _, moduleRoot := range v.GetModuleRootsToValidate() {
+		rootStart := time.Now()
+		valid, expected, actual, err := v.validateResult(ctx, pos, full, moduleRoot)
+		rootReport := ModuleRootValidationReport{
+			ModuleRoot: moduleRoot,
+			Valid:      valid,
+			Expected:   expected,
+			Actual:     actual,
+			Latency:    fmt.Sprintf("%vms", time.Since(rootStart).Milliseconds()),
+		}
+		if err != nil {
+			rootReport.Error = err.Error()
+		}
+		report.Valid = report.Valid && valid
+		report.ModuleRoots = append(report.ModuleRoots, rootReport)
+	}
+	report.Latency = fmt.Sprintf("%vms", time.Since(start).Milliseconds())
+	return json.Marshal(report)
+}
+
+// ValidateBlockAllRoots validates the block of the given header against each of the module roots returned by
+// GetModuleRootsToValidate, e.g. to confirm it passes under both the current and the pending root before an upgrade
+func (v *BlockValidator) ValidateBlockAllRoots(ctx context.Context, header *types.Header, full bool) (map[common.Hash]bool, error) {
+	pos, err := v.blockMessageIndex(header.Number.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[common.Hash]bool)
+	for _, moduleRoot := range v.GetModuleRootsToValidate() {
+		valid, expected, _, err := v.validateResult(ctx, pos, full, moduleRoot)
+		if err != nil {
+			return nil, fmt.Errorf("validating block %d with module root %v: %w", header.Number, moduleRoot, err)
+		}
+		if expected.BlockHash != header.Hash() {
+			return nil, fmt.Errorf("header %v isn't the block %d of the chain, which is %v", header.Hash(), header.Number, expected.BlockHash)
+		}
+		results[moduleRoot] = valid
+	}
+	return results, nil
+}
+
 // called from NewBlockValidator, doesn't need to catch locks
 func ReadLastValidatedInfo(db ethdb.Database) (*GlobalStateValidatedInfo, error) {
 	exists, err := db.Has(lastGlobalStateValidatedInfoKey)
@@ -534,7 +631,10 @@ func (v *BlockValidator) writeToFile(validationEntry *validationEntry) error {
 	if err != nil {
 		return err
 	}
-	inputJson := server_api.ValidationInputToJson(input)
+	inputJson, err := server_api.ValidationInputToJson(input)
+	if err != nil {
+		return err
+	}
 	if err := v.validationInputsWriter.Write(inputJson); err != nil {
 		return err
 	}
@@ -573,8 +673,8 @@ func (v *BlockValidator) createNextValidationEntry(ctx context.Context) (bool, e
 	v.reorgMutex.RLock()
 	defer v.reorgMutex.RUnlock()
 	pos := v.created()
-	if pos > v.validated()+arbutil.MessageIndex(v.config().ForwardBlocks) {
-		log.Trace("create validation entry: nothing to do", "pos", pos, "validated", v.validated())
+	if pos > v.checked()+arbutil.MessageIndex(v.config().ForwardBlocks) {
+		log.Trace("create validation entry: nothing to do", "pos", pos, "checked", v.checked())
 		return false, nil
 	}
 	streamerMsgCount, err := v.streamer.GetProcessedMessageCount()
@@ -700,10 +800,10 @@ func (v *BlockValidator) sendNextRecordRequests(ctx context.Context) (bool, erro
 	v.reorgMutex.RLock()
 	pos := v.recordSent()
 	created := v.created()
-	validated := v.validated()
+	checked := v.checked()
 	v.reorgMutex.RUnlock()
 
-	recordUntil := validated + arbutil.MessageIndex(v.config().PrerecordedBlocks) - 1
+	recordUntil := checked + arbutil.MessageIndex(v.config().PrerecordedBlocks) - 1
 	if recordUntil > created-1 {
 		recordUntil = created - 1
 	}
@@ -802,7 +902,7 @@ func (v *BlockValidator) advanceValidations(ctx context.Context) (*arbutil.Messa
 	defer v.reorgMutex.RUnlock()
 
 	wasmRoots := v.GetModuleRootsToValidate()
-	pos := v.validated() - 1 // to reverse the first +1 in the loop
+	pos := v.checked() - 1 // to reverse the first +1 in the loop
 validationsLoop:
 	for {
 		if ctx.Err() != nil {
@@ -826,13 +926,14 @@ validationsLoop:
 			log.Warn("Recording for validation failed, retrying..", "pos", pos)
 			return &pos, nil
 		}
-		if currentStatus == ValidationSent && pos == v.validated() {
-			if validationStatus.Entry.Start != v.lastValidGS {
-				log.Warn("Validation entry has wrong start state", "pos", pos, "start", validationStatus.Entry.Start, "expected", v.lastValidGS)
+		if currentStatus == ValidationSent && pos == v.checked() {
+			if validationStatus.Entry.Start != v.lastCheckedGS {
+				log.Warn("Validation entry has wrong start state", "pos", pos, "start", validationStatus.Entry.Start, "expected", v.lastCheckedGS)
 				validationStatus.Cancel()
 				return &pos, nil
 			}
 			var wasmRoots []common.Hash
+			mismatched := false
 			for i, run := range validationStatus.Runs {
 				if !run.Ready() {
 					log.Trace("advanceValidations: validation not ready", "pos", pos, "run", i)
@@ -846,6 +947,12 @@ validationsLoop:
 					if writeErr != nil {
 						log.Warn("failed to write debug results file", "err", writeErr)
 					}
+					if v.config().ContinueOnMismatch {
+						validatorFailedValidationsCounter.Inc(1)
+						log.Error("Validation mismatch, continuing with next block", "pos", pos, "moduleRoot", run.WasmModuleRoot(), "err", err)
+						mismatched = true
+						continue
+					}
 				}
 				if err != nil {
 					validatorFailedValidationsCounter.Inc(1)
@@ -854,12 +961,19 @@ validationsLoop:
 				}
 				validatorValidValidationsCounter.Inc(1)
 			}
-			err := v.writeLastValidated(validationStatus.Entry.End, wasmRoots)
-			if err != nil {
-				log.Error("failed writing new validated to database", "pos", pos, "err", err)
+			// After a mismatch, the last validated position stays pinned before the mismatched block
+			// and only the checked position moves on, validating from the expected end states
+			if !mismatched && v.checked() == v.validated() {
+				err := v.writeLastValidated(validationStatus.Entry.End, wasmRoots)
+				if err != nil {
+					log.Error("failed writing new validated to database", "pos", pos, "err", err)
+				}
+				go v.recorder.MarkValid(pos, v.lastValidGS.BlockHash)
+				atomicStorePos(&v.validatedA, pos+1, validatorMsgCountValidatedGauge)
 			}
-			go v.recorder.MarkValid(pos, v.lastValidGS.BlockHash)
-			atomicStorePos(&v.validatedA, pos+1, validatorMsgCountValidatedGauge)
+			v.lastCheckedGS = validationStatus.Entry.End
+			atomicStorePos(&v.checkedA, pos+1, validatorMsgCountCheckedGauge)
+			validationStatus.Entry.releaseSpilledPreimages()
 			v.validations.Delete(pos)
 			nonBlockingTrigger(v.createNodesChan)
 			nonBlockingTrigger(v.sendRecordChan)
@@ -869,7 +983,7 @@ validationsLoop:
 			}
 			v.testingProgressMadeMutex.Unlock()
 
-			log.Trace("result validated", "count", v.validated(), "blockHash", v.lastValidGS.BlockHash)
+			log.Trace("result validated", "count", v.validated(), "checked", v.checked(), "blockHash", v.lastCheckedGS.BlockHash)
 			continue
 		}
 		for _, moduleRoot := range wasmRoots {
@@ -1043,11 +1157,14 @@ func (v *BlockValidator) UpdateLatestStaked(count arbutil.MessageIndex, globalSt
 		return
 	}
 	// delete no-longer relevant entries
-	for iPos := v.validated(); iPos < count && iPos < v.created(); iPos++ {
+	for iPos := v.checked(); iPos < count && iPos < v.created(); iPos++ {
 		status, found := v.validations.Load(iPos)
 		if found && status != nil && status.Cancel != nil {
 			status.Cancel()
 		}
+		if found && status != nil && status.getStatus() >= Prepared {
+			status.Entry.releaseSpilledPreimages()
+		}
 		v.validations.Delete(iPos)
 	}
 	if v.created() < count {
@@ -1065,9 +1182,15 @@ func (v *BlockValidator) UpdateLatestStaked(count arbutil.MessageIndex, globalSt
 	}
 	// #nosec G115
 	v.validatedA.Store(countUint64)
-	v.valLoopPos = count
 	// #nosec G115
 	validatorMsgCountValidatedGauge.Update(int64(countUint64))
+	if v.checkedA.Load() <= countUint64 {
+		v.checkedA.Store(countUint64)
+		v.lastCheckedGS = globalState
+		v.valLoopPos = count
+		// #nosec G115
+		validatorMsgCountCheckedGauge.Update(int64(countUint64))
+	}
 	err = v.writeLastValidated(globalState, nil) // we don't know which wasm roots were validated
 	if err != nil {
 		log.Error("failed writing valid state after reorg", "err", err)
@@ -1085,6 +1208,7 @@ func (v *BlockValidator) ReorgToBatchCount(count uint64) {
 		v.nextCreateBatchReread = true
 		v.prevBatchCache = make(map[uint64][]byte)
 	}
+	v.invalidatePrefetchedBatches(count)
 }
 
 func (v *BlockValidator) Reorg(ctx context.Context, count arbutil.MessageIndex) error {
@@ -1119,18 +1243,28 @@ func (v *BlockValidator) Reorg(ctx context.Context, count arbutil.MessageIndex)
 		if found && status != nil && status.Cancel != nil {
 			status.Cancel()
 		}
+		if found && status != nil && status.getStatus() >= Prepared {
+			status.Entry.releaseSpilledPreimages()
+		}
 		v.validations.Delete(iPos)
 	}
 	v.nextCreateStartGS = BuildGlobalState(*res, endPosition)
 	v.nextCreatePrevDelayed = msg.DelayedMessagesRead
 	v.nextCreateBatchReread = true
 	v.prevBatchCache = make(map[uint64][]byte)
+	v.invalidatePrefetchedBatches(0)
 	countUint64 := uint64(count)
 	v.createdA.Store(countUint64)
 	// under the reorg mutex we don't need atomic access
 	if v.recordSentA.Load() > countUint64 {
 		v.recordSentA.Store(countUint64)
 	}
+	if v.checkedA.Load() > countUint64 {
+		v.checkedA.Store(countUint64)
+		v.lastCheckedGS = v.nextCreateStartGS
+		// #nosec G115
+		validatorMsgCountCheckedGauge.Update(int64(countUint64))
+	}
 	if v.validatedA.Load() > countUint64 {
 		v.validatedA.Store(countUint64)
 		// #nosec G115
@@ -1327,6 +1461,8 @@ func (v *BlockValidator) checkValidatedGSCaughtUp() (bool, error) {
 	atomicStorePos(&v.validatedA, count, validatorMsgCountValidatedGauge)
 	// #nosec G115
 	validatorMsgCountValidatedGauge.Update(int64(count))
+	v.lastCheckedGS = v.lastValidGS
+	atomicStorePos(&v.checkedA, count, validatorMsgCountCheckedGauge)
 	v.chainCaughtUp = true
 	return true, nil
 }
@@ -6,6 +6,7 @@ package staker
 import (
 	"github.com/ethereum/go-ethereum/common"
 
+	"github.com/offchainlabs/nitro/arbutil"
 	"github.com/offchainlabs/nitro/validator"
 )
 
@@ -23,4 +24,12 @@ type GlobalStateValidatedInfo struct {
 var (
 	lastGlobalStateValidatedInfoKey = []byte("_lastGlobalStateValidatedInfo") // contains a rlp encoded lastBlockValidatedDbInfo
 	legacyLastBlockValidatedInfoKey = []byte("_lastBlockValidatedInfo")       // LEGACY - contains a rlp encoded lastBlockValidatedDbInfo
+	preimageArchivePrefix           = []byte("_preimage")                     // followed by the preimage type and hash, contains the preimage
 )
+
+func preimageArchiveKey(ty arbutil.PreimageType, hash common.Hash) []byte {
+	key := make([]byte, 0, len(preimageArchivePrefix)+1+common.HashLength)
+	key = append(key, preimageArchivePrefix...)
+	key = append(key, byte(ty))
+	return append(key, hash.Bytes()...)
+}
@@ -0,0 +1,195 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package staker
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/validator"
+)
+
+// preimageSpill is a disk-backed store for the preimages of a validation entry that exceed the memory budget,
+// each preimage is kept in its own file under a temporary directory removed on close
+type preimageSpill struct {
+	mutex  sync.RWMutex
+	dir    string
+	hashes map[arbutil.PreimageType]map[common.Hash]struct{}
+	closed bool
+}
+
+func newPreimageSpill(parentDir string) (*preimageSpill, error) {
+	dir, err := os.MkdirTemp(parentDir, "preimages-")
+	if err != nil {
+		return nil, err
+	}
+	return &preimageSpill{
+		dir:    dir,
+		hashes: make(map[arbutil.PreimageType]map[common.Hash]struct{}),
+	}, nil
+}
+
+func (s *preimageSpill) path(ty arbutil.PreimageType, hash common.Hash) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%d-%x", ty, hash))
+}
+
+func (s *preimageSpill) has(ty arbutil.PreimageType, hash common.Hash) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	_, ok := s.hashes[ty][hash]
+	return ok
+}
+
+func (s *preimageSpill) put(ty arbutil.PreimageType, hash common.Hash, preimage []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return errors.New("preimage spill closed")
+	}
+	if _, ok := s.hashes[ty][hash]; ok {
+		return nil
+	}
+	if err := os.WriteFile(s.path(ty, hash), preimage, 0o600); err != nil {
+		return err
+	}
+	if s.hashes[ty] == nil {
+		s.hashes[ty] = make(map[common.Hash]struct{})
+	}
+	s.hashes[ty][hash] = struct{}{}
+	return nil
+}
+
+func (s *preimageSpill) ReadPreimage(ty arbutil.PreimageType, hash common.Hash) ([]byte, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.closed {
+		return nil, errors.New("preimage spill closed")
+	}
+	preimage, err := os.ReadFile(s.path(ty, hash))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, validator.ErrPreimageNotFound
+	}
+	return preimage, err
+}
+
+func (s *preimageSpill) PreimageCounts() map[arbutil.PreimageType]int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	counts := make(map[arbutil.PreimageType]int, len(s.hashes))
+	for ty, hashes := range s.hashes {
+		counts[ty] = len(hashes)
+	}
+	return counts
+}
+
+func (s *preimageSpill) ForEachPreimage(ty arbutil.PreimageType, fn func(hash common.Hash, preimage []byte) error) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.closed {
+		return errors.New("preimage spill closed")
+	}
+	for hash := range s.hashes[ty] {
+		preimage, err := os.ReadFile(s.path(ty, hash))
+		if err != nil {
+			return err
+		}
+		if err := fn(hash, preimage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *preimageSpill) close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return os.RemoveAll(s.dir)
+}
+
+// addPreimage keeps the preimage in memory while the entry's preimages fit the budget, spilling it to disk otherwise
+func (e *validationEntry) addPreimage(ty arbutil.PreimageType, hash common.Hash, preimage []byte, dir string, budget uint64) error {
+	if _, ok := e.Preimages[ty][hash]; ok {
+		return nil
+	}
+	if e.spilledPreimages != nil && e.spilledPreimages.has(ty, hash) {
+		return nil
+	}
+	size := uint64(len(preimage))
+	if e.preimagesInMemory+size <= budget {
+		if e.Preimages[ty] == nil {
+			e.Preimages[ty] = make(map[common.Hash][]byte)
+		}
+		e.Preimages[ty][hash] = preimage
+		e.preimagesInMemory += size
+		return nil
+	}
+	if e.spilledPreimages == nil {
+		spill, err := newPreimageSpill(dir)
+		if err != nil {
+			return err
+		}
+		e.spilledPreimages = spill
+	}
+	return e.spilledPreimages.put(ty, hash, preimage)
+}
+
+// addPreimages adds the preimages to the entry as addPreimage does, removing each from source once added
+// so that the spilled ones can be freed while the rest are still being added
+func (e *validationEntry) addPreimages(ty arbutil.PreimageType, source map[common.Hash][]byte, dir string, budget uint64) error {
+	for hash, preimage := range source {
+		if err := e.addPreimage(ty, hash, preimage, dir, budget); err != nil {
+			return err
+		}
+		delete(source, hash)
+	}
+	return nil
+}
+
+// spillPreimages keeps up to budget bytes of the entry's preimages in memory, moving the rest to a preimageSpill
+func (e *validationEntry) spillPreimages(dir string, budget uint64) error {
+	e.preimagesInMemory = 0
+	for ty, preimages := range e.Preimages {
+		for hash, preimage := range preimages {
+			size := uint64(len(preimage))
+			if e.preimagesInMemory+size <= budget {
+				e.preimagesInMemory += size
+				continue
+			}
+			if e.spilledPreimages == nil {
+				spill, err := newPreimageSpill(dir)
+				if err != nil {
+					return err
+				}
+				e.spilledPreimages = spill
+			}
+			if err := e.spilledPreimages.put(ty, hash, preimage); err != nil {
+				return err
+			}
+			delete(preimages, hash)
+		}
+	}
+	return nil
+}
+
+// releaseSpilledPreimages removes the entry's spilled preimages from disk, if any
+func (e *validationEntry) releaseSpilledPreimages() {
+	if e.spilledPreimages == nil {
+		return
+	}
+	if err := e.spilledPreimages.close(); err != nil {
+		log.Warn("failed removing spilled preimages", "pos", e.Pos, "dir", e.spilledPreimages.dir, "err", err)
+	}
+	e.spilledPreimages = nil
+}
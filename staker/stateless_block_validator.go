@@ -5,12 +5,17 @@ package staker
 
 import (
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"slices"
+	"sync"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/node"
@@ -27,6 +32,20 @@ import (
 	"github.com/offchainlabs/nitro/validator/server_api"
 )
 
+var (
+	// ErrMessageUnavailable and ErrBatchUnavailable are transient conditions while the node is syncing, not
+	// validation failures: creating the validation entry may succeed when retried later
+	ErrMessageUnavailable = errors.New("message not processed yet")
+	ErrBatchUnavailable   = errors.New("batch not found on L1 yet")
+	// ErrBatchChanged is returned when a batch is reorged or reposted while creating a validation entry from it,
+	// which retrying resolves against the new batch
+	ErrBatchChanged = errors.New("batch changed while creating validation entry")
+)
+
+// ErrBatchAccMismatch is returned when a sequencer message read for validation doesn't match the accumulator stored
+// for its batch, meaning the message is corrupt or belongs to another batch
+var ErrBatchAccMismatch = errors.New("sequencer message doesn't match batch accumulator")
+
 type StatelessBlockValidator struct {
 	config *BlockValidatorConfig
 
@@ -41,6 +60,21 @@ type StatelessBlockValidator struct {
 	db           ethdb.Database
 	dapReaders   []daprovider.Reader
 	stack        *node.Node
+
+	onValidationFailure func(pos arbutil.MessageIndex, expected, got validator.GoGlobalState)
+
+	prefetchMutex     sync.Mutex
+	prefetchedBatches map[uint64]*prefetchedBatch
+
+	entryPool sync.Pool
+}
+
+// prefetchedBatch holds the sequencer message of a batch read ahead of use, fields are valid once ready is closed
+type prefetchedBatch struct {
+	ready     chan struct{}
+	data      []byte
+	blockHash common.Hash
+	err       error
 }
 
 type BlockValidatorRegistrer interface {
@@ -52,6 +86,7 @@ type InboxTrackerInterface interface {
 	GetDelayedMessageBytes(context.Context, uint64) ([]byte, error)
 	GetBatchMessageCount(seqNum uint64) (arbutil.MessageIndex, error)
 	GetBatchAcc(seqNum uint64) (common.Hash, error)
+	GetDelayedAcc(seqNum uint64) (common.Hash, error)
 	GetBatchCount() (uint64, error)
 	FindInboxBatchContainingMessage(pos arbutil.MessageIndex) (uint64, bool, error)
 }
@@ -71,6 +106,17 @@ type InboxReaderInterface interface {
 	GetFinalizedMsgCount(ctx context.Context) (arbutil.MessageIndex, error)
 }
 
+// BlockHeaderReader resolves L2 block headers by hash, e.g. an ethclient.Client connected to the node
+type BlockHeaderReader interface {
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+}
+
+// BlockHeaderSubscriber notifies of new L2 block headers, e.g. a headerreader.HeaderReader following the node's chain.
+// The channel may skip headers and repeat them
+type BlockHeaderSubscriber interface {
+	Subscribe(requireBlockNrUpdates bool) (<-chan *types.Header, func())
+}
+
 type GlobalStatePosition struct {
 	BatchNumber uint64
 	PosInBatch  uint64
@@ -140,6 +186,10 @@ type validationEntry struct {
 	Preimages  map[arbutil.PreimageType]map[common.Hash][]byte
 	UserWasms  state.UserWasms
 	DelayedMsg []byte
+	// preimages beyond the memory budget, set on record
+	spilledPreimages *preimageSpill
+	// bytes of Preimages kept in memory while recording with a memory budget
+	preimagesInMemory uint64
 }
 
 func (e *validationEntry) ToInput(stylusArchs []ethdb.WasmTarget) (*validator.ValidationInput, error) {
@@ -157,6 +207,9 @@ func (e *validationEntry) ToInput(stylusArchs []ethdb.WasmTarget) (*validator.Va
 		StartState:    e.Start,
 		DebugChain:    e.ChainConfig.DebugMode(),
 	}
+	if e.spilledPreimages != nil {
+		res.SpilledPreimages = e.spilledPreimages
+	}
 	if len(stylusArchs) == 0 && len(e.UserWasms) > 0 {
 		return nil, fmt.Errorf("stylus support is required")
 	}
@@ -185,23 +238,27 @@ func newValidationEntry(
 	prevDelayed uint64,
 	chainConfig *params.ChainConfig,
 ) (*validationEntry, error) {
-	preimages := make(map[arbutil.PreimageType]map[common.Hash][]byte)
+	return fillValidationEntry(&validationEntry{}, pos, start, end, msg, fullBatchInfo, prevBatches, prevDelayed, chainConfig)
+}
+
+// fillValidationEntry initializes the given entry, reusing its Preimages map and BatchInfo slice if already allocated
+func fillValidationEntry(
+	entry *validationEntry,
+	pos arbutil.MessageIndex,
+	start validator.GoGlobalState,
+	end validator.GoGlobalState,
+	msg *arbostypes.MessageWithMetadata,
+	fullBatchInfo *FullBatchInfo,
+	prevBatches []validator.BatchInfo,
+	prevDelayed uint64,
+	chainConfig *params.ChainConfig,
+) (*validationEntry, error) {
 	if fullBatchInfo == nil {
 		return nil, fmt.Errorf("fullbatchInfo cannot be nil")
 	}
 	if fullBatchInfo.Number != start.Batch {
 		return nil, fmt.Errorf("got wrong batch expected: %d got: %d", start.Batch, fullBatchInfo.Number)
 	}
-	valBatches := []validator.BatchInfo{
-		{
-			Number: fullBatchInfo.Number,
-			Data:   fullBatchInfo.PostedData,
-		},
-	}
-	valBatches = append(valBatches, prevBatches...)
-
-	copyPreimagesInto(preimages, fullBatchInfo.Preimages)
-
 	hasDelayed := false
 	var delayedNum uint64
 	if msg.DelayedMessagesRead == prevDelayed+1 {
@@ -211,7 +268,19 @@ func newValidationEntry(
 		return nil, fmt.Errorf("illegal validation entry delayedMessage %d, previous %d", msg.DelayedMessagesRead, prevDelayed)
 	}
 
-	return &validationEntry{
+	valBatches := append(entry.BatchInfo[:0], validator.BatchInfo{
+		Number: fullBatchInfo.Number,
+		Data:   fullBatchInfo.PostedData,
+	})
+	valBatches = append(valBatches, prevBatches...)
+
+	preimages := entry.Preimages
+	if preimages == nil {
+		preimages = make(map[arbutil.PreimageType]map[common.Hash][]byte)
+	}
+	copyPreimagesInto(preimages, fullBatchInfo.Preimages)
+
+	*entry = validationEntry{
 		Stage:         ReadyForRecord,
 		Pos:           pos,
 		Start:         start,
@@ -222,7 +291,8 @@ func newValidationEntry(
 		BatchInfo:     valBatches,
 		ChainConfig:   chainConfig,
 		Preimages:     preimages,
-	}, nil
+	}
+	return entry, nil
 }
 
 func NewStatelessBlockValidator(
@@ -257,16 +327,17 @@ func NewStatelessBlockValidator(
 	}
 
 	return &StatelessBlockValidator{
-		config:         config(),
-		recorder:       recorder,
-		redisValidator: redisValClient,
-		inboxReader:    inboxReader,
-		inboxTracker:   inbox,
-		streamer:       streamer,
-		db:             arbdb,
-		dapReaders:     dapReaders,
-		execSpawners:   executionSpawners,
-		stack:          stack,
+		config:            config(),
+		recorder:          recorder,
+		redisValidator:    redisValClient,
+		inboxReader:       inboxReader,
+		inboxTracker:      inbox,
+		streamer:          streamer,
+		db:                arbdb,
+		dapReaders:        dapReaders,
+		execSpawners:      executionSpawners,
+		stack:             stack,
+		prefetchedBatches: make(map[uint64]*prefetchedBatch),
 	}, nil
 }
 
@@ -310,10 +381,13 @@ func (v *StatelessBlockValidator) readFullBatch(ctx context.Context, batchNum ui
 	if err != nil {
 		return false, nil, err
 	}
-	postedData, batchBlockHash, err := v.inboxReader.GetSequencerMessageBytes(ctx, batchNum)
+	postedData, batchBlockHash, err := v.getSequencerMessageBytes(ctx, batchNum, batchCount)
 	if err != nil {
 		return false, nil, err
 	}
+	if err := v.verifyBatchAcc(batchNum, postedData); err != nil {
+		return false, nil, err
+	}
 	preimages := make(map[arbutil.PreimageType]map[common.Hash][]byte)
 	if len(postedData) > 40 {
 		foundDA := false
@@ -349,6 +423,94 @@ func (v *StatelessBlockValidator) readFullBatch(ctx context.Context, batchNum ui
 	return true, &fullInfo, nil
 }
 
+// verifyBatchAcc checks the sequencer message of a batch against the batch accumulator, computed as the bridge does from
+// the previous batch accumulator, the message hash and the accumulator of the last delayed message read.
+// Blob batches aren't checked, as the accumulator commits to the blob hashes rather than the blob data
+func (v *StatelessBlockValidator) verifyBatchAcc(batchNum uint64, postedData []byte) error {
+	if len(postedData) < 40 {
+		return fmt.Errorf("%w: batch %d sequencer message is only %d bytes", ErrBatchAccMismatch, batchNum, len(postedData))
+	}
+	if len(postedData) > 40 && daprovider.IsBlobHashesHeaderByte(postedData[40]) {
+		return nil
+	}
+	var prevAcc, delayedAcc common.Hash
+	var err error
+	if batchNum > 0 {
+		prevAcc, err = v.inboxTracker.GetBatchAcc(batchNum - 1)
+		if err != nil {
+			return err
+		}
+	}
+	afterDelayedRead := binary.BigEndian.Uint64(postedData[32:40])
+	if afterDelayedRead > 0 {
+		delayedAcc, err = v.inboxTracker.GetDelayedAcc(afterDelayedRead - 1)
+		if err != nil {
+			return err
+		}
+	}
+	expectedAcc, err := v.inboxTracker.GetBatchAcc(batchNum)
+	if err != nil {
+		return err
+	}
+	acc := crypto.Keccak256Hash(prevAcc[:], crypto.Keccak256(postedData), delayedAcc[:])
+	if acc != expectedAcc {
+		return fmt.Errorf("%w: batch %d accumulator %v, computed %v", ErrBatchAccMismatch, batchNum, expectedAcc, acc)
+	}
+	return nil
+}
+
+// getSequencerMessageBytes reads the sequencer message of the batch, and if batch prefetch is enabled starts reading
+// the following batches concurrently so they're ready by the time they're validated
+func (v *StatelessBlockValidator) getSequencerMessageBytes(ctx context.Context, batchNum uint64, batchCount uint64) ([]byte, common.Hash, error) {
+	prefetch := v.config.BatchPrefetch
+	if prefetch == 0 {
+		return v.inboxReader.GetSequencerMessageBytes(ctx, batchNum)
+	}
+	v.prefetchMutex.Lock()
+	// batches before the current one won't be read again, keeping the cache bounded by the prefetch size
+	for num := range v.prefetchedBatches {
+		if num < batchNum || num > batchNum+prefetch {
+			delete(v.prefetchedBatches, num)
+		}
+	}
+	current := v.prefetchedBatches[batchNum]
+	for num := batchNum + 1; num <= batchNum+prefetch && num < batchCount; num++ {
+		if _, found := v.prefetchedBatches[num]; found {
+			continue
+		}
+		batch := &prefetchedBatch{ready: make(chan struct{})}
+		v.prefetchedBatches[num] = batch
+		go func(num uint64) {
+			defer close(batch.ready)
+			batch.data, batch.blockHash, batch.err = v.inboxReader.GetSequencerMessageBytes(ctx, num)
+		}(num)
+	}
+	v.prefetchMutex.Unlock()
+	if current != nil {
+		select {
+		case <-current.ready:
+			if current.err == nil {
+				return current.data, current.blockHash, nil
+			}
+			log.Warn("prefetching batch failed, reading it again", "batch", batchNum, "err", current.err)
+		case <-ctx.Done():
+			return nil, common.Hash{}, ctx.Err()
+		}
+	}
+	return v.inboxReader.GetSequencerMessageBytes(ctx, batchNum)
+}
+
+// invalidatePrefetchedBatches drops prefetched batches starting from the given batch number, which are no longer valid after a reorg
+func (v *StatelessBlockValidator) invalidatePrefetchedBatches(fromBatch uint64) {
+	v.prefetchMutex.Lock()
+	defer v.prefetchMutex.Unlock()
+	for num := range v.prefetchedBatches {
+		if num >= fromBatch {
+			delete(v.prefetchedBatches, num)
+		}
+	}
+}
+
 func copyPreimagesInto(dest, source map[arbutil.PreimageType]map[common.Hash][]byte) {
 	for piType, piMap := range source {
 		if dest[piType] == nil {
@@ -364,6 +526,7 @@ func (v *StatelessBlockValidator) ValidationEntryRecord(ctx context.Context, e *
 	if e.Stage != ReadyForRecord {
 		return fmt.Errorf("validation entry should be ReadyForRecord, is: %v", e.Stage)
 	}
+	var recordedPreimages map[arbutil.PreimageType]map[common.Hash][]byte
 	if e.Pos != 0 {
 		recording, err := v.recorder.RecordBlockCreation(ctx, e.Pos, e.msg)
 		if err != nil {
@@ -373,13 +536,37 @@ func (v *StatelessBlockValidator) ValidationEntryRecord(ctx context.Context, e *
 			return fmt.Errorf("recording failed: pos %d, hash expected %v, got %v", e.Pos, e.End.BlockHash, recording.BlockHash)
 		}
 		if recording.Preimages != nil {
-			recordingPreimages := map[arbutil.PreimageType]map[common.Hash][]byte{
+			recordedPreimages = map[arbutil.PreimageType]map[common.Hash][]byte{
 				arbutil.Keccak256PreimageType: recording.Preimages,
 			}
-			copyPreimagesInto(e.Preimages, recordingPreimages)
 		}
 		e.UserWasms = recording.UserWasms
 	}
+	if v.config.ArchivePreimages {
+		if err := v.archivePreimages(e.Preimages); err != nil {
+			return fmt.Errorf("error archiving preimages: %w", err)
+		}
+		if err := v.archivePreimages(recordedPreimages); err != nil {
+			return fmt.Errorf("error archiving preimages: %w", err)
+		}
+	}
+	if v.config.PreimageMemoryBudget > 0 {
+		// The batch preimages already in the entry count towards the budget too. Recorded preimages beyond the
+		// budget are spilled as they're moved out of the recording, instead of copying it all into the entry first
+		err := e.spillPreimages(v.config.PreimageSpillDir, v.config.PreimageMemoryBudget)
+		for ty, preimages := range recordedPreimages {
+			if err != nil {
+				break
+			}
+			err = e.addPreimages(ty, preimages, v.config.PreimageSpillDir, v.config.PreimageMemoryBudget)
+		}
+		if err != nil {
+			e.releaseSpilledPreimages()
+			return fmt.Errorf("error spilling preimages to disk: %w", err)
+		}
+	} else {
+		copyPreimagesInto(e.Preimages, recordedPreimages)
+	}
 	if e.HasDelayedMsg {
 		delayedMsg, err := v.inboxTracker.GetDelayedMessageBytes(ctx, e.DelayedMsgNr)
 		if err != nil {
@@ -396,6 +583,31 @@ func (v *StatelessBlockValidator) ValidationEntryRecord(ctx context.Context, e *
 	return nil
 }
 
+// archivePreimages writes the preimages to the database, skipping the ones already archived
+func (v *StatelessBlockValidator) archivePreimages(preimages map[arbutil.PreimageType]map[common.Hash][]byte) error {
+	for ty, piMap := range preimages {
+		for hash, preimage := range piMap {
+			key := preimageArchiveKey(ty, hash)
+			has, err := v.db.Has(key)
+			if err != nil {
+				return err
+			}
+			if has {
+				continue
+			}
+			if err := v.db.Put(key, preimage); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ReadArchivedPreimage returns a preimage persisted by a validator with archive-preimages enabled
+func ReadArchivedPreimage(db ethdb.KeyValueReader, ty arbutil.PreimageType, hash common.Hash) ([]byte, error) {
+	return db.Get(preimageArchiveKey(ty, hash))
+}
+
 func BuildGlobalState(res execution.MessageResult, pos GlobalStatePosition) validator.GoGlobalState {
 	return validator.GoGlobalState{
 		BlockHash:  res.BlockHash,
@@ -405,6 +617,16 @@ func BuildGlobalState(res execution.MessageResult, pos GlobalStatePosition) vali
 	}
 }
 
+// ExpectedEndState returns the global state validating the block with the given header is expected to end in, reading
+// the send root from the header's extra information. It doesn't need a validation entry or any execution
+func ExpectedEndState(header *types.Header, endPos GlobalStatePosition) (validator.GoGlobalState, error) {
+	if header == nil {
+		return validator.GoGlobalState{}, errors.New("cannot compute expected end state of nil header")
+	}
+	extraInfo := types.DeserializeHeaderExtraInformation(header)
+	return BuildGlobalState(execution.MessageResult{BlockHash: header.Hash(), SendRoot: extraInfo.SendRoot}, endPos), nil
+}
+
 // return the globalState position before and after processing message at the specified count
 func (v *StatelessBlockValidator) GlobalStatePositionsAtCount(count arbutil.MessageIndex) (GlobalStatePosition, GlobalStatePosition, error) {
 	if count == 0 {
@@ -418,12 +640,98 @@ func (v *StatelessBlockValidator) GlobalStatePositionsAtCount(count arbutil.Mess
 		return GlobalStatePosition{}, GlobalStatePosition{}, err
 	}
 	if !found {
-		return GlobalStatePosition{}, GlobalStatePosition{}, errors.New("batch not found on L1 yet")
+		return GlobalStatePosition{}, GlobalStatePosition{}, ErrBatchUnavailable
 	}
 	return GlobalStatePositionsAtCount(v.inboxTracker, count, batch)
 }
 
+// blockMessageIndex returns the position of the message that created the given block
+func (v *StatelessBlockValidator) blockMessageIndex(blockNum uint64) (arbutil.MessageIndex, error) {
+	genesisBlockNum := v.streamer.ChainConfig().ArbitrumChainParams.GenesisBlockNum
+	if blockNum < genesisBlockNum {
+		return 0, fmt.Errorf("block %d is before genesis block %d", blockNum, genesisBlockNum)
+	}
+	return arbutil.BlockNumberToMessageCount(blockNum, genesisBlockNum) - 1, nil
+}
+
+// BlocksInBatchRange returns the range of blocks, inclusive, created by the messages of the batches fromBatch through
+// toBatch, e.g. to validate every block of a range of batches
+func (v *StatelessBlockValidator) BlocksInBatchRange(fromBatch, toBatch uint64) (uint64, uint64, error) {
+	if fromBatch > toBatch {
+		return 0, 0, fmt.Errorf("invalid batch range %d-%d", fromBatch, toBatch)
+	}
+	batchCount, err := v.inboxTracker.GetBatchCount()
+	if err != nil {
+		return 0, 0, err
+	}
+	if toBatch >= batchCount {
+		return 0, 0, fmt.Errorf("%w: batch %d, batch count %d", ErrBatchUnavailable, toBatch, batchCount)
+	}
+	var firstMsg arbutil.MessageIndex
+	if fromBatch > 0 {
+		firstMsg, err = v.inboxTracker.GetBatchMessageCount(fromBatch - 1)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	msgCount, err := v.inboxTracker.GetBatchMessageCount(toBatch)
+	if err != nil {
+		return 0, 0, err
+	}
+	if msgCount <= firstMsg {
+		return 0, 0, fmt.Errorf("batches %d-%d hold no messages", fromBatch, toBatch)
+	}
+	genesisBlockNum := v.streamer.ChainConfig().ArbitrumChainParams.GenesisBlockNum
+	return genesisBlockNum + uint64(firstMsg), genesisBlockNum + uint64(msgCount) - 1, nil
+}
+
+// GlobalStatePositionForBlockHash returns the globalState position before processing the message that created
+// the block with the given hash, which is the start position used to validate that block
+func (v *StatelessBlockValidator) GlobalStatePositionForBlockHash(ctx context.Context, headers BlockHeaderReader, hash common.Hash) (GlobalStatePosition, error) {
+	header, err := headers.HeaderByHash(ctx, hash)
+	if err != nil {
+		return GlobalStatePosition{}, err
+	}
+	if header == nil {
+		return GlobalStatePosition{}, fmt.Errorf("block %v not found", hash)
+	}
+	blockNum := header.Number.Uint64()
+	pos, err := v.blockMessageIndex(blockNum)
+	if err != nil {
+		return GlobalStatePosition{}, err
+	}
+	count := pos + 1
+	processed, err := v.streamer.GetProcessedMessageCount()
+	if err != nil {
+		return GlobalStatePosition{}, err
+	}
+	if count > processed {
+		return GlobalStatePosition{}, fmt.Errorf("%w: block %d, processed message count %d", ErrMessageUnavailable, blockNum, processed)
+	}
+	// The header may belong to a block reorged out since, so check it against the streamer's result for its message
+	result, err := v.streamer.ResultAtCount(count)
+	if err != nil {
+		return GlobalStatePosition{}, err
+	}
+	if result.BlockHash != hash {
+		return GlobalStatePosition{}, fmt.Errorf("block %v isn't the canonical block %d, which is %v", hash, blockNum, result.BlockHash)
+	}
+	startPos, _, err := v.GlobalStatePositionsAtCount(count)
+	return startPos, err
+}
+
 func (v *StatelessBlockValidator) CreateReadyValidationEntry(ctx context.Context, pos arbutil.MessageIndex) (*validationEntry, error) {
+	return v.createReadyValidationEntry(ctx, pos, &validationEntry{})
+}
+
+func (v *StatelessBlockValidator) createReadyValidationEntry(ctx context.Context, pos arbutil.MessageIndex, scaffold *validationEntry) (*validationEntry, error) {
+	processed, err := v.streamer.GetProcessedMessageCount()
+	if err != nil {
+		return nil, err
+	}
+	if pos >= processed {
+		return nil, fmt.Errorf("%w: pos %d, processed message count %d", ErrMessageUnavailable, pos, processed)
+	}
 	msg, err := v.streamer.GetMessage(pos)
 	if err != nil {
 		return nil, err
@@ -457,6 +765,12 @@ func (v *StatelessBlockValidator) CreateReadyValidationEntry(ctx context.Context
 	if !found {
 		return nil, fmt.Errorf("batch %d not found", startPos.BatchNumber)
 	}
+	// The positions and the batch are read separately, so check the batch still ends where the positions say it does,
+	// as it may have changed in between, e.g. if it was reorged out and posted again while validating a range of blocks
+	if (endPos.BatchNumber == startPos.BatchNumber && fullBatchInfo.MsgCount <= pos+1) ||
+		(endPos.BatchNumber != startPos.BatchNumber && fullBatchInfo.MsgCount != pos+1) {
+		return nil, fmt.Errorf("%w: batch %d, pos %d, batch msg count %d", ErrBatchChanged, startPos.BatchNumber, pos, fullBatchInfo.MsgCount)
+	}
 
 	prevBatchNums, err := msg.Message.PastBatchesRequired()
 	if err != nil {
@@ -464,6 +778,10 @@ func (v *StatelessBlockValidator) CreateReadyValidationEntry(ctx context.Context
 	}
 	prevBatches := make([]validator.BatchInfo, 0, len(prevBatchNums))
 	for _, batchNum := range prevBatchNums {
+		// The batch being validated, or one already read, doesn't need to be read and added again
+		if batchNum == fullBatchInfo.Number || slices.ContainsFunc(prevBatches, func(b validator.BatchInfo) bool { return b.Number == batchNum }) {
+			continue
+		}
 		data, err := v.readPostedBatch(ctx, batchNum)
 		if err != nil {
 			return nil, err
@@ -473,7 +791,7 @@ func (v *StatelessBlockValidator) CreateReadyValidationEntry(ctx context.Context
 			Data:   data,
 		})
 	}
-	entry, err := newValidationEntry(pos, start, end, msg, fullBatchInfo, prevBatches, prevDelayed, v.streamer.ChainConfig())
+	entry, err := fillValidationEntry(scaffold, pos, start, end, msg, fullBatchInfo, prevBatches, prevDelayed, v.streamer.ChainConfig())
 	if err != nil {
 		return nil, err
 	}
@@ -485,20 +803,83 @@ func (v *StatelessBlockValidator) CreateReadyValidationEntry(ctx context.Context
 	return entry, nil
 }
 
+// ValidationDependencies is the inbox data needed to validate a block
+type ValidationDependencies struct {
+	Batches    []uint64 `json:"batches"`
+	DelayedMsg *uint64  `json:"delayedMsg,omitempty"`
+}
+
+// BatchDependencies returns the distinct batches and the delayed message, if any, read by the block at pos, without
+// recording or validating it
+func (v *StatelessBlockValidator) BatchDependencies(ctx context.Context, pos arbutil.MessageIndex) (*ValidationDependencies, error) {
+	msg, err := v.streamer.GetMessage(pos)
+	if err != nil {
+		return nil, err
+	}
+	var prevDelayed uint64
+	if pos > 0 {
+		prev, err := v.streamer.GetMessage(pos - 1)
+		if err != nil {
+			return nil, err
+		}
+		prevDelayed = prev.DelayedMessagesRead
+	}
+	startPos, _, err := v.GlobalStatePositionsAtCount(pos + 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed calculating position for validation: %w", err)
+	}
+	prevBatchNums, err := msg.Message.PastBatchesRequired()
+	if err != nil {
+		return nil, err
+	}
+	deps := &ValidationDependencies{
+		Batches: []uint64{startPos.BatchNumber},
+	}
+	for _, batchNum := range prevBatchNums {
+		if !slices.Contains(deps.Batches, batchNum) {
+			deps.Batches = append(deps.Batches, batchNum)
+		}
+	}
+	if msg.DelayedMessagesRead == prevDelayed+1 {
+		deps.DelayedMsg = &prevDelayed
+	} else if msg.DelayedMessagesRead != prevDelayed {
+		return nil, fmt.Errorf("illegal validation entry delayedMessage %d, previous %d", msg.DelayedMessagesRead, prevDelayed)
+	}
+	return deps, nil
+}
+
 func (v *StatelessBlockValidator) ValidateResult(
 	ctx context.Context, pos arbutil.MessageIndex, useExec bool, moduleRoot common.Hash,
 ) (bool, *validator.GoGlobalState, error) {
-	entry, err := v.CreateReadyValidationEntry(ctx, pos)
+	valid, _, gs, err := v.validateResult(ctx, pos, useExec, moduleRoot)
+	return valid, gs, err
+}
+
+// validateResult is ValidateResult, additionally returning the expected end state once the validation entry is created
+func (v *StatelessBlockValidator) validateResult(
+	ctx context.Context, pos arbutil.MessageIndex, useExec bool, moduleRoot common.Hash,
+) (bool, *validator.GoGlobalState, *validator.GoGlobalState, error) {
+	entry, err := v.createReadyValidationEntry(ctx, pos, v.getValidationEntry())
 	if err != nil {
-		return false, nil, err
+		return false, nil, nil, err
 	}
+	expectedEnd := entry.End
+	// The entry goes back to the pool unless a run might still be using it, its spilled preimages are removed either way
+	reuseEntry := true
+	defer func() {
+		if reuseEntry {
+			v.putValidationEntry(entry)
+		} else {
+			entry.releaseSpilledPreimages()
+		}
+	}()
 	var run validator.ValidationRun
 	if !useExec {
 		if v.redisValidator != nil {
 			if validator.SpawnerSupportsModule(v.redisValidator, moduleRoot) {
 				input, err := entry.ToInput(v.redisValidator.StylusArchs())
 				if err != nil {
-					return false, nil, err
+					return false, &expectedEnd, nil, err
 				}
 				run = v.redisValidator.Launch(input, moduleRoot)
 			}
@@ -509,7 +890,7 @@ func (v *StatelessBlockValidator) ValidateResult(
 			if validator.SpawnerSupportsModule(spawner, moduleRoot) {
 				input, err := entry.ToInput(spawner.StylusArchs())
 				if err != nil {
-					return false, nil, err
+					return false, &expectedEnd, nil, err
 				}
 				run = spawner.Launch(input, moduleRoot)
 				break
@@ -517,14 +898,117 @@ func (v *StatelessBlockValidator) ValidateResult(
 		}
 	}
 	if run == nil {
-		return false, nil, fmt.Errorf("validation with WasmModuleRoot %v not supported by node", moduleRoot)
+		return false, &expectedEnd, nil, fmt.Errorf("validation with WasmModuleRoot %v not supported by node", moduleRoot)
 	}
 	defer run.Cancel()
 	gsEnd, err := run.Await(ctx)
-	if err != nil || gsEnd != entry.End {
-		return false, &gsEnd, err
+	if err != nil {
+		reuseEntry = false
+		return false, &expectedEnd, &gsEnd, err
+	}
+	if gsEnd != expectedEnd {
+		if v.onValidationFailure != nil {
+			v.onValidationFailure(pos, expectedEnd, gsEnd)
+		}
+		return false, &expectedEnd, &gsEnd, nil
+	}
+	return true, &expectedEnd, &expectedEnd, nil
+}
+
+// getValidationEntry returns a validation entry scaffolding to be filled, reused from the pool if enabled
+func (v *StatelessBlockValidator) getValidationEntry() *validationEntry {
+	if v.config.ReuseValidationEntries {
+		if entry, ok := v.entryPool.Get().(*validationEntry); ok {
+			return entry
+		}
+	}
+	return &validationEntry{}
+}
+
+// putValidationEntry returns an entry that is no longer used to the pool, clearing its Preimages maps and BatchInfo
+// so that none of its data leaks into the next validation reusing it. Spilled preimages are removed either way
+func (v *StatelessBlockValidator) putValidationEntry(entry *validationEntry) {
+	entry.releaseSpilledPreimages()
+	if !v.config.ReuseValidationEntries {
+		return
 	}
-	return true, &entry.End, nil
+	for _, preimages := range entry.Preimages {
+		clear(preimages)
+	}
+	clear(entry.BatchInfo)
+	*entry = validationEntry{
+		Preimages: entry.Preimages,
+		BatchInfo: entry.BatchInfo[:0],
+	}
+	v.entryPool.Put(entry)
+}
+
+// OnValidationFailure sets a hook that ValidateResult calls, before returning, whenever the global state computed
+// by the validation doesn't match the expected one. It isn't called on successful validations or on errors
+func (v *StatelessBlockValidator) OnValidationFailure(hook func(pos arbutil.MessageIndex, expected, got validator.GoGlobalState)) {
+	v.onValidationFailure = hook
+}
+
+// ValidateTip validates the chain tip continuously, validating each block as its header arrives from headers until ctx
+// is done. Blocks whose headers were skipped are validated too, so that every block from the first header on is covered.
+// A header that doesn't extend the last validated block means the chain was reorged, and validation restarts from the
+// new head. Validations not matching the expected state are reported to the hook set with OnValidationFailure, blocks
+// failing to validate with an error are logged and skipped
+func (v *StatelessBlockValidator) ValidateTip(ctx context.Context, headers BlockHeaderSubscriber, full bool, moduleRoot common.Hash) error {
+	headersChan, unsubscribe := headers.Subscribe(false)
+	defer unsubscribe()
+	var last *types.Header
+	for {
+		var header *types.Header
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case h, ok := <-headersChan:
+			if !ok {
+				return errors.New("block header subscription closed")
+			}
+			header = h
+		}
+		blockNum := header.Number.Uint64()
+		fromBlock := blockNum
+		if last != nil {
+			lastNum := last.Number.Uint64()
+			switch {
+			case header.Hash() == last.Hash():
+				continue
+			case blockNum > lastNum+1:
+				fromBlock = lastNum + 1
+			case blockNum == lastNum+1 && header.ParentHash == last.Hash():
+			default:
+				log.Info("Chain reorged, restarting tip validation from the new head", "block", blockNum, "hash", header.Hash(), "lastValidated", lastNum)
+			}
+		}
+		for block := fromBlock; block <= blockNum; block++ {
+			if err := v.validateTipBlock(ctx, block, full, moduleRoot); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				log.Warn("Error validating chain tip block", "block", block, "moduleRoot", moduleRoot, "err", err)
+			}
+		}
+		last = header
+	}
+}
+
+// validateTipBlock validates a single block for ValidateTip, mismatches are reported through the failure hook
+func (v *StatelessBlockValidator) validateTipBlock(ctx context.Context, blockNum uint64, full bool, moduleRoot common.Hash) error {
+	pos, err := v.blockMessageIndex(blockNum)
+	if err != nil {
+		return err
+	}
+	valid, _, err := v.ValidateResult(ctx, pos, full, moduleRoot)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		log.Error("Chain tip block failed validation", "block", blockNum, "pos", pos, "moduleRoot", moduleRoot)
+	}
+	return nil
 }
 
 func (v *StatelessBlockValidator) ValidationInputsAt(ctx context.Context, pos arbutil.MessageIndex, targets ...ethdb.WasmTarget) (server_api.InputJSON, error) {
@@ -532,11 +1016,16 @@ func (v *StatelessBlockValidator) ValidationInputsAt(ctx context.Context, pos ar
 	if err != nil {
 		return server_api.InputJSON{}, err
 	}
+	defer entry.releaseSpilledPreimages()
 	input, err := entry.ToInput(targets)
 	if err != nil {
 		return server_api.InputJSON{}, err
 	}
-	return *server_api.ValidationInputToJson(input), nil
+	inputJson, err := server_api.ValidationInputToJson(input)
+	if err != nil {
+		return server_api.InputJSON{}, err
+	}
+	return *inputJson, nil
 }
 
 func (v *StatelessBlockValidator) OverrideRecorder(t *testing.T, recorder execution.ExecutionRecorder) {
@@ -0,0 +1,1402 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package staker
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/cmd/chaininfo"
+	"github.com/offchainlabs/nitro/execution"
+	"github.com/offchainlabs/nitro/util/containers"
+	"github.com/offchainlabs/nitro/util/rpcclient"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+	"github.com/offchainlabs/nitro/validator"
+	validatorclient "github.com/offchainlabs/nitro/validator/client"
+	"github.com/offchainlabs/nitro/validator/inputs"
+	"github.com/offchainlabs/nitro/validator/server_api"
+)
+
+var testWasmModuleRoot = common.HexToHash("0x1234")
+
+// testHeader is the header of the block created by the message at count-1, with the test chain's genesis block number
+func testHeader(count arbutil.MessageIndex) *types.Header {
+	genesisBlockNum := chaininfo.ArbitrumDevTestChainConfig().ArbitrumChainParams.GenesisBlockNum
+	return &types.Header{Number: big.NewInt(arbutil.MessageCountToBlockNumber(count, genesisBlockNum))}
+}
+
+// testBlockHash is the block hash the test streamer and recorder report for the block created by the message at count-1
+func testBlockHash(count arbutil.MessageIndex) common.Hash {
+	return testHeader(count).Hash()
+}
+
+// testInbox implements the inbox tracker, inbox reader and transaction streamer used by the StatelessBlockValidator.
+// Batch 0 holds the init message only, every following batch holds batchSize messages
+type testInbox struct {
+	numMessages arbutil.MessageIndex
+	processed   arbutil.MessageIndex
+	batchSize   arbutil.MessageIndex
+	chainConfig *params.ChainConfig
+	// positions of the messages that read a delayed message
+	delayedAt map[arbutil.MessageIndex]bool
+	// batch posting reports, by position of the message and the batch reported
+	batchReports map[arbutil.MessageIndex]uint64
+	// message counts of batches that differ from the batchSize layout, e.g. after a batch was reposted
+	batchMsgCounts map[uint64]arbutil.MessageIndex
+	// called whenever the batch count is read
+	onGetBatchCount func()
+	// batches whose sequencer message is read corrupted, not matching the batch accumulator
+	corruptBatches map[uint64]bool
+
+	readsMutex sync.Mutex
+	reads      map[uint64]int
+}
+
+func newTestInbox(numBatches, batchSize uint64) *testInbox {
+	numMessages := arbutil.MessageIndex(1 + (numBatches-1)*batchSize)
+	return &testInbox{
+		numMessages: numMessages,
+		processed:   numMessages,
+		batchSize:   arbutil.MessageIndex(batchSize),
+		chainConfig: chaininfo.ArbitrumDevTestChainConfig(),
+		reads:       make(map[uint64]int),
+	}
+}
+
+func (i *testInbox) SetBlockValidator(*BlockValidator) {}
+
+func (i *testInbox) GetDelayedMessageBytes(ctx context.Context, seqNum uint64) ([]byte, error) {
+	if seqNum >= i.delayedMessagesRead(i.processed-1) {
+		return nil, fmt.Errorf("delayed message %d not found", seqNum)
+	}
+	// #nosec G115
+	return []byte{0xde, byte(seqNum)}, nil
+}
+
+func (i *testInbox) delayedMessagesRead(pos arbutil.MessageIndex) uint64 {
+	var read uint64
+	for delayedPos := range i.delayedAt {
+		if delayedPos <= pos {
+			read++
+		}
+	}
+	return read
+}
+
+func (i *testInbox) GetBatchMessageCount(seqNum uint64) (arbutil.MessageIndex, error) {
+	if seqNum >= i.batchCount() {
+		return 0, fmt.Errorf("batch %d not found", seqNum)
+	}
+	if count, ok := i.batchMsgCounts[seqNum]; ok {
+		return count, nil
+	}
+	return 1 + arbutil.MessageIndex(seqNum)*i.batchSize, nil
+}
+
+// GetBatchAcc accumulates the batches' sequencer messages the same way the bridge does
+func (i *testInbox) GetBatchAcc(seqNum uint64) (common.Hash, error) {
+	var acc common.Hash
+	for batch := uint64(0); batch <= seqNum; batch++ {
+		msg := i.sequencerMessage(batch)
+		var delayedAcc common.Hash
+		if afterDelayedRead := binary.BigEndian.Uint64(msg[32:40]); afterDelayedRead > 0 {
+			delayedAcc, _ = i.GetDelayedAcc(afterDelayedRead - 1)
+		}
+		acc = crypto.Keccak256Hash(acc[:], crypto.Keccak256(msg), delayedAcc[:])
+	}
+	return acc, nil
+}
+
+func (i *testInbox) GetDelayedAcc(seqNum uint64) (common.Hash, error) {
+	return crypto.Keccak256Hash([]byte("delayed"), binary.BigEndian.AppendUint64(nil, seqNum)), nil
+}
+
+// sequencerMessage is the posted data of a batch, a header with the delayed messages read followed by the batch number
+func (i *testInbox) sequencerMessage(seqNum uint64) []byte {
+	msg := make([]byte, 40, 42)
+	if count, err := i.GetBatchMessageCount(seqNum); err == nil {
+		binary.BigEndian.PutUint64(msg[32:40], i.delayedMessagesRead(count-1))
+	}
+	// #nosec G115
+	return append(msg, 0, byte(seqNum))
+}
+
+func (i *testInbox) GetBatchCount() (uint64, error) {
+	if i.onGetBatchCount != nil {
+		i.onGetBatchCount()
+	}
+	return i.batchCount(), nil
+}
+
+func (i *testInbox) batchCount() uint64 {
+	return 1 + uint64((i.numMessages-1)/i.batchSize)
+}
+
+func (i *testInbox) FindInboxBatchContainingMessage(pos arbutil.MessageIndex) (uint64, bool, error) {
+	if pos >= i.numMessages {
+		return 0, false, nil
+	}
+	if pos == 0 {
+		return 0, true, nil
+	}
+	return 1 + uint64((pos-1)/i.batchSize), true, nil
+}
+
+func (i *testInbox) GetSequencerMessageBytes(ctx context.Context, seqNum uint64) ([]byte, common.Hash, error) {
+	i.readsMutex.Lock()
+	defer i.readsMutex.Unlock()
+	i.reads[seqNum]++
+	msg := i.sequencerMessage(seqNum)
+	if i.corruptBatches[seqNum] {
+		msg[len(msg)-1] ^= 0xff
+	}
+	return msg, common.Hash{}, nil
+}
+
+func (i *testInbox) batchReads(seqNum uint64) int {
+	i.readsMutex.Lock()
+	defer i.readsMutex.Unlock()
+	return i.reads[seqNum]
+}
+
+func (i *testInbox) GetFinalizedMsgCount(ctx context.Context) (arbutil.MessageIndex, error) {
+	return i.numMessages, nil
+}
+
+func (i *testInbox) GetProcessedMessageCount() (arbutil.MessageIndex, error) {
+	return i.processed, nil
+}
+
+func (i *testInbox) GetMessage(seqNum arbutil.MessageIndex) (*arbostypes.MessageWithMetadata, error) {
+	if seqNum >= i.processed {
+		return nil, fmt.Errorf("message %d not found", seqNum)
+	}
+	message := &arbostypes.L1IncomingMessage{
+		Header: &arbostypes.L1IncomingMessageHeader{Kind: arbostypes.L1MessageType_L2Message},
+		L2msg:  []byte{byte(seqNum)},
+	}
+	if batchNum, isReport := i.batchReports[seqNum]; isReport {
+		message = testBatchPostingReport(batchNum)
+	}
+	return &arbostypes.MessageWithMetadata{
+		Message:             message,
+		DelayedMessagesRead: i.delayedMessagesRead(seqNum),
+	}, nil
+}
+
+func testBatchPostingReport(batchNum uint64) *arbostypes.L1IncomingMessage {
+	var l2msg []byte
+	l2msg = append(l2msg, common.Hash{}.Bytes()...)    // batch timestamp
+	l2msg = append(l2msg, common.Address{}.Bytes()...) // batch poster
+	l2msg = append(l2msg, common.Hash{}.Bytes()...)    // data hash
+	l2msg = append(l2msg, common.BigToHash(new(big.Int).SetUint64(batchNum)).Bytes()...)
+	l2msg = append(l2msg, common.Hash{}.Bytes()...) // l1 base fee
+	return &arbostypes.L1IncomingMessage{
+		Header: &arbostypes.L1IncomingMessageHeader{Kind: arbostypes.L1MessageType_BatchPostingReport},
+		L2msg:  l2msg,
+	}
+}
+
+func (i *testInbox) ResultAtCount(count arbutil.MessageIndex) (*execution.MessageResult, error) {
+	return &execution.MessageResult{BlockHash: testBlockHash(count)}, nil
+}
+
+func (i *testInbox) PauseReorgs()  {}
+func (i *testInbox) ResumeReorgs() {}
+
+func (i *testInbox) ChainConfig() *params.ChainConfig {
+	return i.chainConfig
+}
+
+type testRecorder struct{}
+
+func (r *testRecorder) RecordBlockCreation(ctx context.Context, pos arbutil.MessageIndex, msg *arbostypes.MessageWithMetadata) (*execution.RecordResult, error) {
+	return &execution.RecordResult{
+		Pos:       pos,
+		BlockHash: testBlockHash(pos + 1),
+		Preimages: map[common.Hash][]byte{testBlockHash(pos + 1): {byte(pos)}},
+	}, nil
+}
+
+func (r *testRecorder) MarkValid(pos arbutil.MessageIndex, resultHash common.Hash) {}
+
+func (r *testRecorder) PrepareForRecord(ctx context.Context, start, end arbutil.MessageIndex) error {
+	return nil
+}
+
+// testSpawner "runs" validations by looking up the expected end state, unless a result override is set
+type testSpawner struct {
+	inbox *testInbox
+	// module roots supported, testWasmModuleRoot only if unset
+	roots    []common.Hash
+	override func(input *validator.ValidationInput, end validator.GoGlobalState) validator.GoGlobalState
+	// error every run fails with, if set
+	runErr error
+}
+
+func (s *testSpawner) endState(input *validator.ValidationInput) (validator.GoGlobalState, error) {
+	count := arbutil.MessageIndex(input.Id) + 1
+	var endPos GlobalStatePosition
+	if count == 1 {
+		endPos = GlobalStatePosition{1, 0}
+	} else {
+		batch, _, err := s.inbox.FindInboxBatchContainingMessage(count - 1)
+		if err != nil {
+			return validator.GoGlobalState{}, err
+		}
+		_, endPos, err = GlobalStatePositionsAtCount(s.inbox, count, batch)
+		if err != nil {
+			return validator.GoGlobalState{}, err
+		}
+	}
+	if s.runErr != nil {
+		return validator.GoGlobalState{}, s.runErr
+	}
+	end := BuildGlobalState(execution.MessageResult{BlockHash: testBlockHash(count)}, endPos)
+	if s.override != nil {
+		end = s.override(input, end)
+	}
+	return end, nil
+}
+
+func (s *testSpawner) Launch(input *validator.ValidationInput, moduleRoot common.Hash) validator.ValidationRun {
+	end, err := s.endState(input)
+	return &testValidationRun{
+		PromiseInterface: containers.NewReadyPromise(end, err),
+		root:             moduleRoot,
+	}
+}
+
+func (s *testSpawner) WasmModuleRoots() ([]common.Hash, error) {
+	if s.roots != nil {
+		return s.roots, nil
+	}
+	return []common.Hash{testWasmModuleRoot}, nil
+}
+
+func (s *testSpawner) Start(context.Context) error     { return nil }
+func (s *testSpawner) Stop()                           {}
+func (s *testSpawner) Name() string                    { return "test" }
+func (s *testSpawner) StylusArchs() []ethdb.WasmTarget { return nil }
+func (s *testSpawner) Room() int                       { return 4 }
+
+func (s *testSpawner) CreateExecutionRun(wasmModuleRoot common.Hash, input *validator.ValidationInput, useBoldMachine bool) containers.PromiseInterface[validator.ExecutionRun] {
+	return containers.NewReadyPromise[validator.ExecutionRun](nil, errors.New("execution runs not supported"))
+}
+
+func (s *testSpawner) LatestWasmModuleRoot() containers.PromiseInterface[common.Hash] {
+	return containers.NewReadyPromise(testWasmModuleRoot, nil)
+}
+
+type testValidationRun struct {
+	containers.PromiseInterface[validator.GoGlobalState]
+	root common.Hash
+}
+
+func (r *testValidationRun) WasmModuleRoot() common.Hash { return r.root }
+
+func newTestStatelessBlockValidator(inbox *testInbox, spawner *testSpawner) *StatelessBlockValidator {
+	config := DefaultBlockValidatorConfig
+	return &StatelessBlockValidator{
+		config:            &config,
+		execSpawners:      []validator.ExecutionSpawner{spawner},
+		recorder:          &testRecorder{},
+		inboxReader:       inbox,
+		inboxTracker:      inbox,
+		streamer:          inbox,
+		prefetchedBatches: make(map[uint64]*prefetchedBatch),
+	}
+}
+
+func TestValidateResult(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inbox := newTestInbox(4, 3)
+	v := newTestStatelessBlockValidator(inbox, &testSpawner{inbox: inbox})
+	v.OnValidationFailure(func(pos arbutil.MessageIndex, expected, got validator.GoGlobalState) {
+		t.Errorf("validation failure hook called for successful validation of pos %d", pos)
+	})
+	for pos := arbutil.MessageIndex(0); pos < inbox.numMessages; pos++ {
+		valid, gs, err := v.ValidateResult(ctx, pos, false, testWasmModuleRoot)
+		Require(t, err)
+		if !valid {
+			t.Fatalf("validation of pos %d failed", pos)
+		}
+		if gs.BlockHash != testBlockHash(pos+1) {
+			t.Fatalf("unexpected block hash for pos %d. Got: %v, Want: %v", pos, gs.BlockHash, testBlockHash(pos+1))
+		}
+	}
+}
+
+func TestValidateResultFailureHook(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inbox := newTestInbox(4, 3)
+	badPos := arbutil.MessageIndex(5)
+	badHash := common.HexToHash("0xbad")
+	spawner := &testSpawner{
+		inbox: inbox,
+		override: func(input *validator.ValidationInput, end validator.GoGlobalState) validator.GoGlobalState {
+			if input.Id == uint64(badPos) {
+				end.BlockHash = badHash
+			}
+			return end
+		},
+	}
+	v := newTestStatelessBlockValidator(inbox, spawner)
+	var calls int
+	var failedPos arbutil.MessageIndex
+	var expected, got validator.GoGlobalState
+	v.OnValidationFailure(func(pos arbutil.MessageIndex, expectedState, gotState validator.GoGlobalState) {
+		calls++
+		failedPos, expected, got = pos, expectedState, gotState
+	})
+
+	valid, _, err := v.ValidateResult(ctx, badPos-1, false, testWasmModuleRoot)
+	Require(t, err)
+	if !valid || calls != 0 {
+		t.Fatalf("expected successful validation without calling the hook, valid: %v calls: %d", valid, calls)
+	}
+
+	valid, gs, err := v.ValidateResult(ctx, badPos, false, testWasmModuleRoot)
+	Require(t, err)
+	if valid {
+		t.Fatal("expected validation to fail")
+	}
+	if calls != 1 {
+		t.Fatalf("expected hook to be called once, got %d", calls)
+	}
+	if failedPos != badPos {
+		t.Fatalf("hook called with wrong pos. Got: %d, Want: %d", failedPos, badPos)
+	}
+	if expected.BlockHash != testBlockHash(badPos+1) {
+		t.Fatalf("hook called with wrong expected state: %v", expected)
+	}
+	if got != *gs || got.BlockHash != badHash {
+		t.Fatalf("hook called with wrong computed state: %v", got)
+	}
+	if expected.Batch != got.Batch || expected.PosInBatch != got.PosInBatch {
+		t.Fatalf("states should only differ in block hash, expected: %v got: %v", expected, got)
+	}
+}
+
+// testRemoteExecutor serves the validation RPC API of a remote validation server, running validations with a testSpawner
+type testRemoteExecutor struct {
+	spawner  *testSpawner
+	received []*server_api.InputJSON
+}
+
+func (e *testRemoteExecutor) Name() string {
+	return "test-remote"
+}
+
+func (e *testRemoteExecutor) Room() int {
+	return 4
+}
+
+func (e *testRemoteExecutor) StylusArchs() []ethdb.WasmTarget {
+	return []ethdb.WasmTarget{"mock"}
+}
+
+func (e *testRemoteExecutor) WasmModuleRoots() ([]common.Hash, error) {
+	return e.spawner.WasmModuleRoots()
+}
+
+func (e *testRemoteExecutor) Validate(ctx context.Context, input *server_api.InputJSON, moduleRoot common.Hash) (validator.GoGlobalState, error) {
+	e.received = append(e.received, input)
+	valInput, err := server_api.ValidationInputFromJson(input)
+	if err != nil {
+		return validator.GoGlobalState{}, err
+	}
+	return e.spawner.endState(valInput)
+}
+
+func TestValidateResultWithRemoteExecutor(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inbox := newTestInbox(3, 2)
+	remote := &testRemoteExecutor{spawner: &testSpawner{inbox: inbox}}
+	rpcServer := rpc.NewServer()
+	Require(t, rpcServer.RegisterName(server_api.Namespace, remote))
+	httpServer := httptest.NewServer(rpcServer)
+	defer httpServer.Close()
+
+	// Validation entries are shipped to whichever validation server is configured, here a remote one
+	clientConfig := rpcclient.TestClientConfig
+	clientConfig.URL = httpServer.URL
+	v := newTestStatelessBlockValidator(inbox, nil)
+	v.execSpawners = []validator.ExecutionSpawner{validatorclient.NewExecutionClient(func() *rpcclient.ClientConfig { return &clientConfig }, nil)}
+	Require(t, v.Start(ctx))
+	defer v.Stop()
+
+	for pos := arbutil.MessageIndex(0); pos < inbox.numMessages; pos++ {
+		valid, gs, err := v.ValidateResult(ctx, pos, false, testWasmModuleRoot)
+		Require(t, err)
+		if !valid {
+			t.Fatalf("remote validation of pos %d failed", pos)
+		}
+		if gs.BlockHash != testBlockHash(pos+1) {
+			t.Fatalf("unexpected block hash for pos %d. Got: %v, Want: %v", pos, gs.BlockHash, testBlockHash(pos+1))
+		}
+	}
+	if len(remote.received) != int(inbox.numMessages) {
+		t.Fatalf("remote executor received %d validations, want %d", len(remote.received), inbox.numMessages)
+	}
+
+	// The remote executor receives the validation inputs in the same format as they are exported
+	exported, err := v.ValidationInputsAt(ctx, 1, "mock")
+	Require(t, err)
+	exportedJson, err := json.Marshal(exported)
+	Require(t, err)
+	receivedJson, err := json.Marshal(remote.received[1])
+	Require(t, err)
+	if !bytes.Equal(exportedJson, receivedJson) {
+		t.Fatalf("remote executor input doesn't match exported validation input. Got: %s, Want: %s", receivedJson, exportedJson)
+	}
+}
+
+func TestReadFullBatchPrefetch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inbox := newTestInbox(8, 2)
+	v := newTestStatelessBlockValidator(inbox, &testSpawner{inbox: inbox})
+	v.config.BatchPrefetch = 3
+
+	waitForReads := func(batches ...uint64) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for _, batch := range batches {
+			for inbox.batchReads(batch) == 0 {
+				if time.Now().After(deadline) {
+					t.Fatalf("batch %d wasn't prefetched", batch)
+				}
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}
+
+	_, batch, err := v.readFullBatch(ctx, 2)
+	Require(t, err)
+	if !bytes.Equal(batch.PostedData, inbox.sequencerMessage(2)) {
+		t.Fatalf("unexpected data for batch 2: %v", batch.PostedData)
+	}
+	// The following batches are read from the inbox ahead of use
+	waitForReads(3, 4, 5)
+	if inbox.batchReads(6) != 0 {
+		t.Fatal("batch 6 is beyond the prefetch limit and shouldn't have been read")
+	}
+
+	// Prefetched batches are served from the cache
+	for num := uint64(3); num <= 5; num++ {
+		_, batch, err := v.readFullBatch(ctx, num)
+		Require(t, err)
+		if !bytes.Equal(batch.PostedData, inbox.sequencerMessage(num)) {
+			t.Fatalf("unexpected data for batch %d: %v", num, batch.PostedData)
+		}
+		if reads := inbox.batchReads(num); reads != 1 {
+			t.Fatalf("batch %d was read %d times from the inbox, want 1", num, reads)
+		}
+	}
+	// Prefetching stops at the batch count, and the cache only holds the current and upcoming batches
+	waitForReads(6, 7)
+	v.prefetchMutex.Lock()
+	cached := len(v.prefetchedBatches)
+	v.prefetchMutex.Unlock()
+	if cached > 3 {
+		t.Fatalf("prefetch cache holds %d batches, want at most 3", cached)
+	}
+
+	// A reorg invalidates prefetched batches, so they are read from the inbox again
+	v.invalidatePrefetchedBatches(6)
+	_, _, err = v.readFullBatch(ctx, 6)
+	Require(t, err)
+	if reads := inbox.batchReads(6); reads != 2 {
+		t.Fatalf("batch 6 was read %d times from the inbox, want 2", reads)
+	}
+}
+
+func TestValidateResultReusingEntries(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inbox := newTestInbox(20, 5)
+	usedPreimageMaps := make(map[uintptr]struct{})
+	spawner := &testSpawner{
+		inbox: inbox,
+		override: func(input *validator.ValidationInput, end validator.GoGlobalState) validator.GoGlobalState {
+			usedPreimageMaps[reflect.ValueOf(input.Preimages).Pointer()] = struct{}{}
+			// Each block records a single preimage, anything else leaked from a previous validation
+			preimages := input.Preimages[arbutil.Keccak256PreimageType]
+			wantPreimages := 1
+			if input.Id == 0 {
+				wantPreimages = 0
+			}
+			if len(preimages) != wantPreimages {
+				t.Errorf("validation of pos %d got %d preimages, want %d", input.Id, len(preimages), wantPreimages)
+				end.BlockHash = common.Hash{}
+			}
+			if len(input.BatchInfo) != 1 || input.BatchInfo[0].Number != input.StartState.Batch {
+				t.Errorf("validation of pos %d got unexpected batches %v", input.Id, input.BatchInfo)
+				end.BlockHash = common.Hash{}
+			}
+			return end
+		},
+	}
+	v := newTestStatelessBlockValidator(inbox, spawner)
+	v.config.ReuseValidationEntries = true
+	for pos := arbutil.MessageIndex(0); pos < inbox.numMessages; pos++ {
+		valid, gs, err := v.ValidateResult(ctx, pos, false, testWasmModuleRoot)
+		Require(t, err)
+		if !valid {
+			t.Fatalf("validation of pos %d failed", pos)
+		}
+		if gs.BlockHash != testBlockHash(pos+1) {
+			t.Fatalf("unexpected block hash for pos %d. Got: %v, Want: %v", pos, gs.BlockHash, testBlockHash(pos+1))
+		}
+	}
+	if len(usedPreimageMaps) >= int(inbox.numMessages) {
+		t.Fatal("validation entries weren't reused")
+	}
+}
+
+// countingDB counts the writes to the underlying database
+type countingDB struct {
+	ethdb.Database
+	puts int
+}
+
+func (db *countingDB) Put(key []byte, value []byte) error {
+	db.puts++
+	return db.Database.Put(key, value)
+}
+
+func TestValidationEntryRecordArchivesPreimages(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inbox := newTestInbox(3, 2)
+	v := newTestStatelessBlockValidator(inbox, &testSpawner{inbox: inbox})
+	db := &countingDB{Database: rawdb.NewMemoryDatabase()}
+	v.db = db
+	v.config.ArchivePreimages = true
+
+	pos := arbutil.MessageIndex(2)
+	valid, _, err := v.ValidateResult(ctx, pos, false, testWasmModuleRoot)
+	Require(t, err)
+	if !valid {
+		t.Fatal("validation failed")
+	}
+	preimage, err := ReadArchivedPreimage(db, arbutil.Keccak256PreimageType, testBlockHash(pos+1))
+	Require(t, err)
+	// #nosec G115
+	if !bytes.Equal(preimage, []byte{byte(pos)}) {
+		t.Fatalf("unexpected archived preimage. Got: %v, Want: %v", preimage, []byte{byte(pos)})
+	}
+	if db.puts != 1 {
+		t.Fatalf("expected a single preimage write, got %d", db.puts)
+	}
+
+	// Validating the block again doesn't rewrite the archived preimages
+	_, _, err = v.ValidateResult(ctx, pos, false, testWasmModuleRoot)
+	Require(t, err)
+	if db.puts != 1 {
+		t.Fatalf("archived preimages were written again, writes: %d", db.puts)
+	}
+}
+
+// largePreimageRecorder records several 32 byte preimages per block
+type largePreimageRecorder struct {
+	testRecorder
+}
+
+func largePreimages(pos arbutil.MessageIndex) map[common.Hash][]byte {
+	preimages := make(map[common.Hash][]byte)
+	for i := uint64(0); i < 4; i++ {
+		preimage := common.BigToHash(new(big.Int).SetUint64(uint64(pos)*10 + i)).Bytes()
+		preimages[crypto.Keccak256Hash(preimage)] = preimage
+	}
+	return preimages
+}
+
+func (r *largePreimageRecorder) RecordBlockCreation(ctx context.Context, pos arbutil.MessageIndex, msg *arbostypes.MessageWithMetadata) (*execution.RecordResult, error) {
+	return &execution.RecordResult{
+		Pos:       pos,
+		BlockHash: testBlockHash(pos + 1),
+		Preimages: largePreimages(pos),
+	}, nil
+}
+
+func TestValidationEntryRecordSpillsPreimages(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inbox := newTestInbox(3, 2)
+	spilled := 0
+	spawner := &testSpawner{inbox: inbox}
+	// the validation only succeeds if all recorded preimages can be read
+	spawner.override = func(input *validator.ValidationInput, end validator.GoGlobalState) validator.GoGlobalState {
+		if input.SpilledPreimages != nil {
+			spilled++
+		}
+		for hash, want := range largePreimages(arbutil.MessageIndex(input.Id)) {
+			preimage, err := input.ReadPreimage(arbutil.Keccak256PreimageType, hash)
+			if err != nil || !bytes.Equal(preimage, want) {
+				t.Errorf("failed reading preimage %v of pos %d: %v", hash, input.Id, err)
+				return validator.GoGlobalState{}
+			}
+		}
+		// preimages are spilled as they're recorded, so the ones in memory never exceed the budget
+		var inMemory int
+		for _, preimage := range input.Preimages[arbutil.Keccak256PreimageType] {
+			inMemory += len(preimage)
+		}
+		if inMemory > 40 {
+			t.Errorf("pos %d kept %d bytes of preimages in memory", input.Id, inMemory)
+		}
+		if count := input.PreimageCounts()[arbutil.Keccak256PreimageType]; count != 4 {
+			t.Errorf("unexpected preimage count of pos %d: %d", input.Id, count)
+			return validator.GoGlobalState{}
+		}
+		// remote validators get all preimages, spilled ones included
+		inputJson, err := server_api.ValidationInputToJson(input)
+		if err != nil {
+			t.Errorf("failed encoding input of pos %d: %v", input.Id, err)
+			return validator.GoGlobalState{}
+		}
+		encoded, err := json.Marshal(inputJson)
+		if err != nil {
+			t.Errorf("failed marshaling input of pos %d: %v", input.Id, err)
+			return validator.GoGlobalState{}
+		}
+		var decodedJson server_api.InputJSON
+		if err := json.Unmarshal(encoded, &decodedJson); err != nil {
+			t.Errorf("failed unmarshaling input of pos %d: %v", input.Id, err)
+			return validator.GoGlobalState{}
+		}
+		decoded, err := server_api.ValidationInputFromJson(&decodedJson)
+		if err != nil || !maps.EqualFunc(decoded.Preimages[arbutil.Keccak256PreimageType], largePreimages(arbutil.MessageIndex(input.Id)), bytes.Equal) {
+			t.Errorf("unexpected encoded preimages of pos %d, err: %v", input.Id, err)
+			return validator.GoGlobalState{}
+		}
+		return end
+	}
+	v := newTestStatelessBlockValidator(inbox, spawner)
+	v.recorder = &largePreimageRecorder{}
+	v.config.PreimageMemoryBudget = 40
+	v.config.PreimageSpillDir = t.TempDir()
+
+	for pos := arbutil.MessageIndex(1); pos < inbox.numMessages; pos++ {
+		valid, _, err := v.ValidateResult(ctx, pos, false, testWasmModuleRoot)
+		Require(t, err)
+		if !valid {
+			t.Fatalf("validation of pos %d failed", pos)
+		}
+	}
+	// #nosec G115
+	if arbutil.MessageIndex(spilled) != inbox.numMessages-1 {
+		t.Fatalf("expected preimages of every validation to spill, spilled %d times", spilled)
+	}
+	// Spilled preimages are removed once validated
+	leftover, err := os.ReadDir(v.config.PreimageSpillDir)
+	Require(t, err)
+	if len(leftover) != 0 {
+		t.Fatalf("spilled preimages left on disk: %v", leftover)
+	}
+}
+
+func TestValidateResultFailedRunReleasesSpilledPreimages(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inbox := newTestInbox(3, 2)
+	runErr := errors.New("run failed")
+	v := newTestStatelessBlockValidator(inbox, &testSpawner{inbox: inbox, runErr: runErr})
+	v.recorder = &largePreimageRecorder{}
+	v.config.PreimageMemoryBudget = 40
+	v.config.PreimageSpillDir = t.TempDir()
+
+	_, _, err := v.ValidateResult(ctx, 1, false, testWasmModuleRoot)
+	if !errors.Is(err, runErr) {
+		t.Fatalf("expected the run error, got: %v", err)
+	}
+	leftover, err := os.ReadDir(v.config.PreimageSpillDir)
+	Require(t, err)
+	if len(leftover) != 0 {
+		t.Fatalf("spilled preimages left on disk after a failed run: %v", leftover)
+	}
+}
+
+func TestValidateResultUnavailable(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inbox := newTestInbox(3, 2)
+	v := newTestStatelessBlockValidator(inbox, &testSpawner{inbox: inbox})
+	v.OnValidationFailure(func(pos arbutil.MessageIndex, expected, got validator.GoGlobalState) {
+		t.Errorf("validation failure hook called for unavailable pos %d", pos)
+	})
+
+	// The message hasn't been processed yet
+	inbox.processed = 3
+	_, _, err := v.ValidateResult(ctx, 3, false, testWasmModuleRoot)
+	if !errors.Is(err, ErrMessageUnavailable) {
+		t.Fatalf("expected ErrMessageUnavailable, got: %v", err)
+	}
+
+	// The message was processed but its batch isn't known yet
+	inbox.processed = inbox.numMessages + 1
+	_, _, err = v.ValidateResult(ctx, inbox.numMessages, false, testWasmModuleRoot)
+	if !errors.Is(err, ErrBatchUnavailable) {
+		t.Fatalf("expected ErrBatchUnavailable, got: %v", err)
+	}
+
+	// Once available the same positions validate
+	inbox.processed = inbox.numMessages
+	valid, _, err := v.ValidateResult(ctx, 3, false, testWasmModuleRoot)
+	Require(t, err)
+	if !valid {
+		t.Fatal("validation failed")
+	}
+}
+
+func TestValidateFirstBlockAfterGenesis(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inbox := newTestInbox(3, 2)
+	// The init message is the first delayed message, and the first block after genesis reads the second one
+	inbox.delayedAt = map[arbutil.MessageIndex]bool{0: true, 1: true}
+	v := newTestStatelessBlockValidator(inbox, &testSpawner{inbox: inbox})
+
+	entry, err := v.CreateReadyValidationEntry(ctx, 1)
+	Require(t, err)
+	if entry.Start.BlockHash != testBlockHash(1) || entry.Start.Batch != 1 || entry.Start.PosInBatch != 0 {
+		t.Fatalf("first block after genesis should start from the genesis block at batch 1, got: %v", entry.Start)
+	}
+	if entry.End.BlockHash != testBlockHash(2) || entry.End.Batch != 1 || entry.End.PosInBatch != 1 {
+		t.Fatalf("unexpected end state for first block after genesis: %v", entry.End)
+	}
+	if !entry.HasDelayedMsg || entry.DelayedMsgNr != 1 {
+		t.Fatalf("first block after genesis should read delayed message 1, HasDelayedMsg: %v DelayedMsgNr: %d", entry.HasDelayedMsg, entry.DelayedMsgNr)
+	}
+	if !bytes.Equal(entry.DelayedMsg, []byte{0xde, 1}) {
+		t.Fatalf("unexpected delayed message: %v", entry.DelayedMsg)
+	}
+	if len(entry.Preimages[arbutil.Keccak256PreimageType]) != 1 {
+		t.Fatal("first block after genesis should be recorded")
+	}
+
+	// The block following it doesn't read a delayed message
+	entry, err = v.CreateReadyValidationEntry(ctx, 2)
+	Require(t, err)
+	if entry.HasDelayedMsg {
+		t.Fatalf("second block after genesis shouldn't read a delayed message, DelayedMsgNr: %d", entry.DelayedMsgNr)
+	}
+
+	for pos := arbutil.MessageIndex(1); pos <= 2; pos++ {
+		valid, _, err := v.ValidateResult(ctx, pos, false, testWasmModuleRoot)
+		Require(t, err)
+		if !valid {
+			t.Fatalf("validation of pos %d failed", pos)
+		}
+	}
+}
+
+func TestValidateLastBlockOfBatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inbox := newTestInbox(4, 3)
+	// Batch 1 holds messages 1 to 3, so the block of message 3 ends the batch
+	lastInBatch := arbutil.MessageIndex(3)
+	v := newTestStatelessBlockValidator(inbox, &testSpawner{inbox: inbox})
+	// Without prefetching, the batches read are exactly those the validation asked for
+	v.config.BatchPrefetch = 0
+
+	entry, err := v.CreateReadyValidationEntry(ctx, lastInBatch)
+	Require(t, err)
+	if entry.Start.BlockHash != testBlockHash(lastInBatch) || entry.Start.Batch != 1 || entry.Start.PosInBatch != 2 {
+		t.Fatalf("last block of batch 1 should start from its last position, got: %v", entry.Start)
+	}
+	if entry.End.BlockHash != testBlockHash(lastInBatch+1) || entry.End.Batch != 2 || entry.End.PosInBatch != 0 {
+		t.Fatalf("last block of batch 1 should end at the start of batch 2, got: %v", entry.End)
+	}
+	// The machine reads the message from the start batch, the end batch isn't needed
+	if len(entry.BatchInfo) != 1 || entry.BatchInfo[0].Number != 1 || !bytes.Equal(entry.BatchInfo[0].Data, inbox.sequencerMessage(1)) {
+		t.Fatalf("last block of batch 1 should only be given batch 1, got: %v", entry.BatchInfo)
+	}
+	if reads := inbox.batchReads(2); reads != 0 {
+		t.Fatalf("batch 2 shouldn't be read to validate the last block of batch 1, read %d times", reads)
+	}
+
+	// The first block of the next batch picks up where it left off
+	entry, err = v.CreateReadyValidationEntry(ctx, lastInBatch+1)
+	Require(t, err)
+	if entry.Start.Batch != 2 || entry.Start.PosInBatch != 0 || entry.End.Batch != 2 || entry.End.PosInBatch != 1 {
+		t.Fatalf("unexpected positions for first block of batch 2, start: %v end: %v", entry.Start, entry.End)
+	}
+
+	for pos := lastInBatch - 1; pos <= lastInBatch+1; pos++ {
+		valid, gs, err := v.ValidateResult(ctx, pos, false, testWasmModuleRoot)
+		Require(t, err)
+		if !valid {
+			t.Fatalf("validation of pos %d failed", pos)
+		}
+		if pos == lastInBatch && (gs.Batch != 2 || gs.PosInBatch != 0) {
+			t.Fatalf("validation of the last block of batch 1 should end at the start of batch 2, got: %v", gs)
+		}
+	}
+}
+
+func TestValidateResultBatchChangedMidValidation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inbox := newTestInbox(4, 3)
+	pos := arbutil.MessageIndex(2)
+	v := newTestStatelessBlockValidator(inbox, &testSpawner{inbox: inbox})
+
+	// Batch 1 is reposted with one message less after the positions of pos were computed against the original batch,
+	// which makes pos the last message of batch 1
+	inbox.onGetBatchCount = func() {
+		inbox.batchMsgCounts = map[uint64]arbutil.MessageIndex{1: pos + 1}
+		inbox.onGetBatchCount = nil
+	}
+	_, _, err := v.ValidateResult(ctx, pos, false, testWasmModuleRoot)
+	if !errors.Is(err, ErrBatchChanged) {
+		t.Fatalf("expected ErrBatchChanged, got: %v", err)
+	}
+
+	// Retrying validates against the new batch consistently
+	entry, err := v.CreateReadyValidationEntry(ctx, pos)
+	Require(t, err)
+	if entry.Start.Batch != 1 || entry.Start.PosInBatch != 1 || entry.End.Batch != 2 || entry.End.PosInBatch != 0 {
+		t.Fatalf("unexpected positions after batch change, start: %v end: %v", entry.Start, entry.End)
+	}
+	valid, _, err := v.ValidateResult(ctx, pos, false, testWasmModuleRoot)
+	Require(t, err)
+	if !valid {
+		t.Fatalf("validation of pos %d failed", pos)
+	}
+}
+
+func TestValidateBlockJSON(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inbox := newTestInbox(4, 3)
+	unsupportedRoot := common.HexToHash("0x5678")
+	v := &BlockValidator{
+		StatelessBlockValidator: newTestStatelessBlockValidator(inbox, &testSpawner{inbox: inbox}),
+		currentWasmModuleRoot:   testWasmModuleRoot,
+		pendingWasmModuleRoot:   unsupportedRoot,
+	}
+	genesisBlockNum := inbox.chainConfig.ArbitrumChainParams.GenesisBlockNum
+	pos := arbutil.MessageIndex(4)
+
+	// With a pending module root the node has no machine for, the block isn't reported as valid
+	data, err := v.ValidateBlockJSON(ctx, genesisBlockNum+uint64(pos), false)
+	Require(t, err)
+	var report BlockValidationReport
+	Require(t, json.Unmarshal(data, &report))
+	if report.BlockNumber != genesisBlockNum+uint64(pos) || report.MessageNum != uint64(pos) || report.Valid {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if len(report.ModuleRoots) != 2 || report.ModuleRoots[1].ModuleRoot != unsupportedRoot || report.ModuleRoots[1].Valid || report.ModuleRoots[1].Error == "" {
+		t.Fatalf("expected the pending module root to fail validation, got: %+v", report.ModuleRoots)
+	}
+
+	v.pendingWasmModuleRoot = common.Hash{}
+	data, err = v.ValidateBlockJSON(ctx, genesisBlockNum+uint64(pos), false)
+	Require(t, err)
+	// Check the JSON field names, as runbooks rely on them
+	var fields map[string]json.RawMessage
+	Require(t, json.Unmarshal(data, &fields))
+	for _, field := range []string{"blockNumber", "messageNum", "valid", "latency", "moduleRoots"} {
+		if _, ok := fields[field]; !ok {
+			t.Fatalf("report is missing field %s: %s", field, data)
+		}
+	}
+	report = BlockValidationReport{}
+	Require(t, json.Unmarshal(data, &report))
+	if !report.Valid || len(report.ModuleRoots) != 1 {
+		t.Fatalf("expected the block to be valid against the current module root, got: %s", data)
+	}
+	rootReport := report.ModuleRoots[0]
+	if rootReport.ModuleRoot != testWasmModuleRoot || !rootReport.Valid || rootReport.Error != "" {
+		t.Fatalf("unexpected module root report: %s", data)
+	}
+	if rootReport.Expected == nil || rootReport.Actual == nil || *rootReport.Expected != *rootReport.Actual || rootReport.Actual.BlockHash != testBlockHash(pos+1) {
+		t.Fatalf("unexpected global states in module root report: %s", data)
+	}
+}
+
+func TestValidateBlockAllRoots(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inbox := newTestInbox(4, 3)
+	pendingRoot := common.HexToHash("0x5678")
+	spawner := &testSpawner{inbox: inbox, roots: []common.Hash{testWasmModuleRoot, pendingRoot}}
+	v := &BlockValidator{
+		StatelessBlockValidator: newTestStatelessBlockValidator(inbox, spawner),
+		currentWasmModuleRoot:   testWasmModuleRoot,
+		pendingWasmModuleRoot:   pendingRoot,
+	}
+	pos := arbutil.MessageIndex(4)
+
+	results, err := v.ValidateBlockAllRoots(ctx, testHeader(pos+1), false)
+	Require(t, err)
+	if len(results) != 2 || !results[testWasmModuleRoot] || !results[pendingRoot] {
+		t.Fatalf("expected the block to be valid under both the current and pending roots, got: %v", results)
+	}
+
+	// The header must be the one of the block at its height
+	otherHeader := testHeader(pos + 1)
+	otherHeader.Time = 1
+	if _, err := v.ValidateBlockAllRoots(ctx, otherHeader, false); err == nil {
+		t.Fatal("expected an error validating a header that isn't in the chain")
+	}
+}
+
+// newTestAdvancingBlockValidator returns a block validator with validations sent for all messages of the inbox
+func newTestAdvancingBlockValidator(t *testing.T, ctx context.Context, inbox *testInbox, spawner *testSpawner, config *BlockValidatorConfig) *BlockValidator {
+	t.Helper()
+	inputsWriter, err := inputs.NewWriter(inputs.WithBaseDir(t.TempDir()))
+	Require(t, err)
+	v := &BlockValidator{
+		StatelessBlockValidator: newTestStatelessBlockValidator(inbox, spawner),
+		config:                  func() *BlockValidatorConfig { return config },
+		currentWasmModuleRoot:   testWasmModuleRoot,
+		validationInputsWriter:  inputsWriter,
+	}
+	v.db = rawdb.NewMemoryDatabase()
+	for pos := arbutil.MessageIndex(0); pos < inbox.numMessages; pos++ {
+		entry, err := v.CreateReadyValidationEntry(ctx, pos)
+		Require(t, err)
+		input, err := entry.ToInput(nil)
+		Require(t, err)
+		status := &validationStatus{
+			Entry: entry,
+			Runs:  []validator.ValidationRun{spawner.Launch(input, testWasmModuleRoot)},
+		}
+		status.Status.Store(uint32(ValidationSent))
+		v.validations.Store(pos, status)
+		if pos == 0 {
+			v.lastValidGS = entry.Start
+			v.lastCheckedGS = entry.Start
+		}
+	}
+	atomicStorePos(&v.recordSentA, inbox.numMessages, validatorMsgCountRecordSentGauge)
+	return v
+}
+
+func TestAdvanceValidationsMismatchPolicy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inbox := newTestInbox(3, 2)
+	mismatchPos := arbutil.MessageIndex(2)
+	spawner := &testSpawner{
+		inbox: inbox,
+		override: func(input *validator.ValidationInput, end validator.GoGlobalState) validator.GoGlobalState {
+			if arbutil.MessageIndex(input.Id) == mismatchPos {
+				end.BlockHash = common.Hash{0xba, 0xd}
+			}
+			return end
+		},
+	}
+
+	// By default a mismatch is fatal, and validation doesn't advance past it
+	config := DefaultBlockValidatorConfig
+	fatalErr := make(chan error, 1)
+	v := newTestAdvancingBlockValidator(t, ctx, inbox, spawner, &config)
+	v.fatalErr = fatalErr
+	_, err := v.advanceValidations(ctx)
+	Require(t, err)
+	if v.validated() != mismatchPos {
+		t.Fatalf("expected validation to stop at the mismatch, validated: %d", v.validated())
+	}
+	select {
+	case <-fatalErr:
+	default:
+		t.Fatal("expected a fatal error for the mismatch")
+	}
+
+	// Continuing on mismatch checks the following blocks without a fatal error,
+	// but the last validated position stays before the mismatched block
+	config.ContinueOnMismatch = true
+	v = newTestAdvancingBlockValidator(t, ctx, inbox, spawner, &config)
+	v.fatalErr = fatalErr
+	status, found := v.validations.Load(mismatchPos - 1)
+	if !found {
+		t.Fatal("validation entry before the mismatch not found")
+	}
+	lastGoodGS := status.Entry.End
+	_, err = v.advanceValidations(ctx)
+	Require(t, err)
+	if v.checked() != inbox.numMessages {
+		t.Fatalf("expected checking to continue past the mismatch, checked: %d", v.checked())
+	}
+	if v.validated() != mismatchPos {
+		t.Fatalf("expected validated to stay at the mismatch, validated: %d", v.validated())
+	}
+	select {
+	case err := <-fatalErr:
+		t.Fatalf("unexpected fatal error: %v", err)
+	default:
+	}
+	lastValid, err := v.ReadLastValidatedInfo()
+	Require(t, err)
+	if lastValid == nil || lastValid.GlobalState != lastGoodGS {
+		t.Fatalf("unexpected last validated info: %v, expected global state %v", lastValid, lastGoodGS)
+	}
+}
+
+func TestValidationEntryMinimalBatchInfo(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inbox := newTestInbox(6, 2)
+	// The first message of batch 4 reports on batch 2
+	reportPos := arbutil.MessageIndex(7)
+	inbox.batchReports = map[arbutil.MessageIndex]uint64{reportPos: 2}
+	spawner := &testSpawner{
+		inbox: inbox,
+		override: func(input *validator.ValidationInput, end validator.GoGlobalState) validator.GoGlobalState {
+			// Only the batches the machine reads are provided: the current one, and the reported one for batch posting reports
+			wantBatches := []uint64{input.StartState.Batch}
+			if arbutil.MessageIndex(input.Id) == reportPos {
+				wantBatches = append(wantBatches, 2)
+			}
+			if len(input.BatchInfo) != len(wantBatches) {
+				t.Errorf("validation of pos %d got %d batches, want %d", input.Id, len(input.BatchInfo), len(wantBatches))
+				return validator.GoGlobalState{}
+			}
+			for i, batch := range input.BatchInfo {
+				if batch.Number != wantBatches[i] || !bytes.Equal(batch.Data, inbox.sequencerMessage(wantBatches[i])) {
+					t.Errorf("validation of pos %d got batch %d, want %d", input.Id, batch.Number, wantBatches[i])
+					return validator.GoGlobalState{}
+				}
+			}
+			return end
+		},
+	}
+	v := newTestStatelessBlockValidator(inbox, spawner)
+	for pos := reportPos - 1; pos <= reportPos+1; pos++ {
+		valid, _, err := v.ValidateResult(ctx, pos, false, testWasmModuleRoot)
+		Require(t, err)
+		if !valid {
+			t.Fatalf("validation of pos %d failed", pos)
+		}
+	}
+}
+
+func TestBatchDependencies(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inbox := newTestInbox(6, 2)
+	// The first message of batch 4 reports on batch 2 and reads the second delayed message
+	reportPos := arbutil.MessageIndex(7)
+	inbox.batchReports = map[arbutil.MessageIndex]uint64{reportPos: 2}
+	inbox.delayedAt = map[arbutil.MessageIndex]bool{0: true, reportPos: true}
+	v := newTestStatelessBlockValidator(inbox, &testSpawner{inbox: inbox})
+
+	deps, err := v.BatchDependencies(ctx, reportPos)
+	Require(t, err)
+	if !slices.Equal(deps.Batches, []uint64{4, 2}) {
+		t.Fatalf("unexpected batch dependencies: %v", deps.Batches)
+	}
+	if deps.DelayedMsg == nil || *deps.DelayedMsg != 1 {
+		t.Fatalf("expected dependency on delayed message 1, got: %v", deps.DelayedMsg)
+	}
+
+	deps, err = v.BatchDependencies(ctx, reportPos+1)
+	Require(t, err)
+	if !slices.Equal(deps.Batches, []uint64{4}) || deps.DelayedMsg != nil {
+		t.Fatalf("unexpected dependencies: %v %v", deps.Batches, deps.DelayedMsg)
+	}
+
+	// Dependencies are listed without reading the batches
+	for batch := uint64(0); batch < 6; batch++ {
+		if reads := inbox.batchReads(batch); reads != 0 {
+			t.Fatalf("batch %d was read %d times", batch, reads)
+		}
+	}
+
+	// The listed dependencies match the entry created for validation
+	entry, err := v.CreateReadyValidationEntry(ctx, reportPos)
+	Require(t, err)
+	if len(entry.BatchInfo) != 2 || entry.BatchInfo[0].Number != 4 || entry.BatchInfo[1].Number != 2 {
+		t.Fatalf("unexpected validation entry batches: %v", entry.BatchInfo)
+	}
+	if !entry.HasDelayedMsg || entry.DelayedMsgNr != 1 {
+		t.Fatalf("unexpected validation entry delayed message, HasDelayedMsg: %v DelayedMsgNr: %d", entry.HasDelayedMsg, entry.DelayedMsgNr)
+	}
+}
+
+func TestValidationEntrySkipsDuplicateBatches(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inbox := newTestInbox(6, 2)
+	// The first message of batch 4 reports on the batch it's in
+	reportPos := arbutil.MessageIndex(7)
+	inbox.batchReports = map[arbutil.MessageIndex]uint64{reportPos: 4}
+	v := newTestStatelessBlockValidator(inbox, &testSpawner{inbox: inbox})
+
+	entry, err := v.CreateReadyValidationEntry(ctx, reportPos)
+	Require(t, err)
+	if len(entry.BatchInfo) != 1 || entry.BatchInfo[0].Number != 4 {
+		t.Fatalf("unexpected validation entry batches: %v", entry.BatchInfo)
+	}
+	if reads := inbox.batchReads(4); reads != 1 {
+		t.Fatalf("batch 4 was read %d times", reads)
+	}
+}
+
+func TestGlobalStatePositionsOfFirstMessage(t *testing.T) {
+	inbox := newTestInbox(3, 2)
+	v := newTestStatelessBlockValidator(inbox, &testSpawner{inbox: inbox})
+	want := GlobalStatePosition{BatchNumber: 1, PosInBatch: 0}
+
+	// The init message is the only message of batch 0, so validating it starts at the very beginning of the inbox
+	startPos, endPos, err := GlobalStatePositionsAtCount(inbox, 1, 0)
+	Require(t, err)
+	if startPos != (GlobalStatePosition{}) || endPos != want {
+		t.Fatalf("unexpected positions of the first message, start: %v end: %v", startPos, endPos)
+	}
+	startPos, endPos, err = v.GlobalStatePositionsAtCount(1)
+	Require(t, err)
+	if startPos != (GlobalStatePosition{}) || endPos != want {
+		t.Fatalf("unexpected validator positions of the first message, start: %v end: %v", startPos, endPos)
+	}
+
+	// The first message after it starts the next batch
+	startPos, endPos, err = v.GlobalStatePositionsAtCount(2)
+	Require(t, err)
+	if startPos != want || endPos != (GlobalStatePosition{BatchNumber: 1, PosInBatch: 1}) {
+		t.Fatalf("unexpected positions of the second message, start: %v end: %v", startPos, endPos)
+	}
+
+	if _, _, err := v.GlobalStatePositionsAtCount(0); err == nil {
+		t.Fatal("expected error for count 0")
+	}
+}
+
+func TestValidationEntryBatchAccMismatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inbox := newTestInbox(4, 3)
+	inbox.delayedAt = map[arbutil.MessageIndex]bool{0: true, 5: true}
+	inbox.corruptBatches = map[uint64]bool{2: true}
+	v := newTestStatelessBlockValidator(inbox, &testSpawner{inbox: inbox})
+
+	// Batches whose sequencer message matches the accumulator are validated
+	valid, _, err := v.ValidateResult(ctx, 1, false, testWasmModuleRoot)
+	Require(t, err)
+	if !valid {
+		t.Fatal("validation of pos 1 failed")
+	}
+
+	// The corrupt sequencer message is caught before validating
+	_, err = v.CreateReadyValidationEntry(ctx, 5)
+	if !errors.Is(err, ErrBatchAccMismatch) {
+		t.Fatalf("expected ErrBatchAccMismatch, got: %v", err)
+	}
+}
+
+func TestBlocksInBatchRange(t *testing.T) {
+	inbox := newTestInbox(4, 3)
+	v := newTestStatelessBlockValidator(inbox, &testSpawner{inbox: inbox})
+	genesisBlockNum := inbox.chainConfig.ArbitrumChainParams.GenesisBlockNum
+
+	for _, tc := range []struct {
+		fromBatch, toBatch uint64
+		fromBlock, toBlock uint64
+	}{
+		{0, 0, 0, 0},
+		{1, 1, 1, 3},
+		{1, 2, 1, 6},
+		{2, 3, 4, 9},
+	} {
+		fromBlock, toBlock, err := v.BlocksInBatchRange(tc.fromBatch, tc.toBatch)
+		Require(t, err)
+		if fromBlock != genesisBlockNum+tc.fromBlock || toBlock != genesisBlockNum+tc.toBlock {
+			t.Fatalf("unexpected blocks of batches %d-%d. Got: %d-%d, Want: %d-%d", tc.fromBatch, tc.toBatch, fromBlock, toBlock, genesisBlockNum+tc.fromBlock, genesisBlockNum+tc.toBlock)
+		}
+		// The range matches the batches the blocks are validated in
+		startPos, _, err := v.GlobalStatePositionsAtCount(arbutil.BlockNumberToMessageCount(fromBlock, genesisBlockNum))
+		Require(t, err)
+		_, endPos, err := v.GlobalStatePositionsAtCount(arbutil.BlockNumberToMessageCount(toBlock, genesisBlockNum))
+		Require(t, err)
+		if startPos.BatchNumber != tc.fromBatch || startPos.PosInBatch != 0 || endPos.BatchNumber != tc.toBatch+1 || endPos.PosInBatch != 0 {
+			t.Fatalf("block range of batches %d-%d validates from %v to %v", tc.fromBatch, tc.toBatch, startPos, endPos)
+		}
+	}
+
+	if _, _, err := v.BlocksInBatchRange(2, 1); err == nil {
+		t.Fatal("expected error for inverted batch range")
+	}
+	if _, _, err := v.BlocksInBatchRange(1, 4); !errors.Is(err, ErrBatchUnavailable) {
+		t.Fatalf("expected ErrBatchUnavailable for a batch not posted yet, got: %v", err)
+	}
+}
+
+func TestExpectedEndState(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inbox := newTestInbox(4, 3)
+	v := newTestStatelessBlockValidator(inbox, &testSpawner{inbox: inbox})
+
+	for pos := arbutil.MessageIndex(0); pos < inbox.numMessages; pos++ {
+		entry, err := v.CreateReadyValidationEntry(ctx, pos)
+		Require(t, err)
+		_, endPos, err := v.GlobalStatePositionsAtCount(pos + 1)
+		Require(t, err)
+		end, err := ExpectedEndState(testHeader(pos+1), endPos)
+		Require(t, err)
+		if end != entry.End {
+			t.Fatalf("unexpected end state of pos %d. Got: %v, Want: %v", pos, end, entry.End)
+		}
+	}
+
+	// The send root is read from the header
+	header := testHeader(2)
+	sendRoot := common.HexToHash("0x5e4d")
+	types.HeaderInfo{SendRoot: sendRoot, ArbOSFormatVersion: 1}.UpdateHeaderWithInfo(header)
+	end, err := ExpectedEndState(header, GlobalStatePosition{BatchNumber: 1, PosInBatch: 1})
+	Require(t, err)
+	if end.SendRoot != sendRoot || end.BlockHash != header.Hash() || end.Batch != 1 || end.PosInBatch != 1 {
+		t.Fatalf("unexpected end state: %v", end)
+	}
+
+	if _, err := ExpectedEndState(nil, GlobalStatePosition{}); err == nil {
+		t.Fatal("expected error for nil header")
+	}
+}
+
+// testHeaderReader resolves the block hashes reported by the test streamer
+type testHeaderReader struct {
+	inbox *testInbox
+}
+
+func (r *testHeaderReader) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	for count := arbutil.MessageIndex(1); count <= r.inbox.processed; count++ {
+		if testBlockHash(count) == hash {
+			return testHeader(count), nil
+		}
+	}
+	return nil, fmt.Errorf("block %v not found", hash)
+}
+
+func TestGlobalStatePositionForBlockHash(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inbox := newTestInbox(4, 3)
+	v := newTestStatelessBlockValidator(inbox, &testSpawner{inbox: inbox})
+	headers := &testHeaderReader{inbox}
+	for pos := arbutil.MessageIndex(0); pos < inbox.numMessages; pos++ {
+		entry, err := v.CreateReadyValidationEntry(ctx, pos)
+		Require(t, err)
+		startPos, err := v.GlobalStatePositionForBlockHash(ctx, headers, testBlockHash(pos+1))
+		Require(t, err)
+		if startPos.BatchNumber != entry.Start.Batch || startPos.PosInBatch != entry.Start.PosInBatch {
+			t.Fatalf("unexpected position for block of pos %d. Got: %+v, Want: batch %d pos %d", pos, startPos, entry.Start.Batch, entry.Start.PosInBatch)
+		}
+	}
+
+	if _, err := v.GlobalStatePositionForBlockHash(ctx, headers, common.HexToHash("0xbad")); err == nil {
+		t.Fatal("expected an error for an unknown block hash")
+	}
+
+	// A block whose message isn't processed yet has no position
+	inbox.processed--
+	_, err := v.GlobalStatePositionForBlockHash(ctx, &testHeaderReader{newTestInbox(4, 3)}, testBlockHash(inbox.numMessages))
+	if !errors.Is(err, ErrMessageUnavailable) {
+		t.Fatalf("expected ErrMessageUnavailable, got %v", err)
+	}
+}
+
+// testHeaderSubscriber hands out a single channel of headers fed by the test
+type testHeaderSubscriber struct {
+	headers chan *types.Header
+}
+
+func (s *testHeaderSubscriber) Subscribe(bool) (<-chan *types.Header, func()) {
+	return s.headers, func() {}
+}
+
+func TestValidateTip(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inbox := newTestInbox(4, 3)
+	var validated []arbutil.MessageIndex
+	badPos := arbutil.MessageIndex(6)
+	spawner := &testSpawner{
+		inbox: inbox,
+		override: func(input *validator.ValidationInput, end validator.GoGlobalState) validator.GoGlobalState {
+			validated = append(validated, arbutil.MessageIndex(input.Id))
+			if input.Id == uint64(badPos) {
+				end.BlockHash = common.HexToHash("0xbad")
+			}
+			return end
+		},
+	}
+	v := newTestStatelessBlockValidator(inbox, spawner)
+	var failures []arbutil.MessageIndex
+	v.OnValidationFailure(func(pos arbutil.MessageIndex, expected, got validator.GoGlobalState) {
+		failures = append(failures, pos)
+	})
+	subscriber := &testHeaderSubscriber{headers: make(chan *types.Header)}
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- v.ValidateTip(ctx, subscriber, false, testWasmModuleRoot)
+	}()
+
+	// header returns the header of the block created by the message at pos, on top of parent
+	header := func(pos arbutil.MessageIndex, parent *types.Header) *types.Header {
+		h := testHeader(pos + 1)
+		if parent != nil {
+			h.ParentHash = parent.Hash()
+		}
+		return h
+	}
+	h2 := header(2, nil)
+	h3 := header(3, h2)
+	h5 := header(5, header(4, h3)) // the header of pos 4 is skipped
+	reorged := header(4, &types.Header{Number: h3.Number, Extra: []byte("other")})
+	h7 := header(7, header(6, reorged))
+	for _, h := range []*types.Header{h2, h3, h5, h5, reorged, h7, h7} {
+		subscriber.headers <- h
+	}
+	cancel()
+	if err := <-errChan; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected tip validation to stop with the context, got %v", err)
+	}
+
+	// Skipped blocks are filled in, repeated headers are validated once, and validation restarts from a reorged head
+	want := []arbutil.MessageIndex{2, 3, 4, 5, 4, 5, 6, 7}
+	if !slices.Equal(validated, want) {
+		t.Fatalf("unexpected validated positions. Got: %v, Want: %v", validated, want)
+	}
+	if !slices.Equal(failures, []arbutil.MessageIndex{badPos}) {
+		t.Fatalf("expected the failure hook to be called for pos %d only, got %v", badPos, failures)
+	}
+}
+
+func Require(t *testing.T, err error, printables ...interface{}) {
+	t.Helper()
+	testhelpers.RequireImpl(t, err, printables...)
+}
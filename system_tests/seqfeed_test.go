@@ -145,6 +145,27 @@ func TestRelayedSequencerFeed(t *testing.T) {
 	if l2balance.Cmp(big.NewInt(1e12)) != 0 {
 		t.Fatal("Unexpected balance:", l2balance)
 	}
+
+	// Express lane auction results reach the nodes behind the relay
+	winner := common.HexToAddress("0x1234")
+	Require(t, seqNode.BroadcastServer.BroadcastAuctionResult(&message.AuctionResultMessage{
+		Round:       1,
+		Winner:      winner,
+		FirstPrice:  big.NewInt(2),
+		SecondPrice: big.NewInt(1),
+	}))
+	for i := 0; ; i++ {
+		if controller, ok := builder.L2.ExecNode.ExpressLaneControllerTracker.Controller(1); ok {
+			if controller != winner {
+				t.Fatal("Unexpected express lane controller:", controller)
+			}
+			break
+		}
+		if i == 50 {
+			t.Fatal("Auction result not received through the relay")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
 }
 
 func compareAllMsgResultsFromConsensusAndExecution(
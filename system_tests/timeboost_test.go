@@ -48,6 +48,7 @@ import (
 	"github.com/offchainlabs/nitro/util/containers"
 	"github.com/offchainlabs/nitro/util/redisutil"
 	"github.com/offchainlabs/nitro/util/rpcclient"
+	"github.com/offchainlabs/nitro/util/signature"
 	"github.com/offchainlabs/nitro/util/stopwaiter"
 	"github.com/offchainlabs/nitro/util/testhelpers"
 )
@@ -570,6 +571,355 @@ func TestExpressLaneTransactionHandling(t *testing.T) {
 	checkFailErr("Transaction sequencing hit timeout")
 }
 
+func TestExpressLaneLegacyTransactions(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tmpDir := t.TempDir()
+
+	auctionContractAddr, aliceBidderClient, bobBidderClient, roundDuration, builderSeq, cleanupSeq, _, _ := setupExpressLaneAuction(t, tmpDir, ctx, 0)
+	seq, seqClient, seqInfo := builderSeq.L2.ConsensusNode, builderSeq.L2.Client, builderSeq.L2Info
+	defer cleanupSeq()
+
+	auctionContract, err := express_lane_auctiongen.NewExpressLaneAuction(auctionContractAddr, seqClient)
+	Require(t, err)
+	rawRoundTimingInfo, err := auctionContract.RoundTimingInfo(&bind.CallOpts{})
+	Require(t, err)
+	roundTimingInfo, err := timeboost.NewRoundTimingInfo(rawRoundTimingInfo)
+	Require(t, err)
+
+	placeBidsAndDecideWinner(t, ctx, seqClient, seqInfo, auctionContract, "Bob", "Alice", bobBidderClient, aliceBidderClient, roundDuration)
+	time.Sleep(roundTimingInfo.TimeTilNextRound())
+
+	chainId, err := seqClient.ChainID(ctx)
+	Require(t, err)
+
+	bobPriv := seqInfo.Accounts["Bob"].PrivateKey
+	seqDial, err := rpc.Dial(seq.Stack.HTTPEndpoint())
+	Require(t, err)
+	expressLaneClient := newExpressLaneClient(
+		bobPriv,
+		chainId,
+		*roundTimingInfo,
+		auctionContractAddr,
+		seqDial,
+	)
+	expressLaneClient.Start(ctx)
+
+	// Controllers using older signing libraries may only produce pre-EIP-1559 transactions
+	ownerAddr := seqInfo.GetAddress("Owner")
+	nonce, err := seqClient.PendingNonceAt(ctx, seqInfo.GetAddress("Alice"))
+	Require(t, err)
+	legacyTx := seqInfo.SignTxAs("Alice", &types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: new(big.Int).Set(seqInfo.GasPrice),
+		Gas:      seqInfo.TransferGas,
+		To:       &ownerAddr,
+		Value:    big.NewInt(1),
+	})
+	accessListTx := seqInfo.SignTxAs("Alice", &types.AccessListTx{
+		ChainID:    chainId,
+		Nonce:      nonce + 1,
+		GasPrice:   new(big.Int).Set(seqInfo.GasPrice),
+		Gas:        seqInfo.TransferGas * 2,
+		To:         &ownerAddr,
+		Value:      big.NewInt(1),
+		AccessList: types.AccessList{{Address: ownerAddr}},
+	})
+
+	for _, tx := range []*types.Transaction{legacyTx, accessListTx} {
+		Require(t, expressLaneClient.SendTransaction(ctx, tx))
+		receipt, err := seqClient.TransactionReceipt(ctx, tx.Hash())
+		Require(t, err)
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			t.Fatalf("express lane tx of type %d failed", tx.Type())
+		}
+		if receipt.Type != tx.Type() {
+			t.Fatalf("unexpected receipt type %d for express lane tx of type %d", receipt.Type, tx.Type())
+		}
+		verifyTimeboostedCorrectness(t, ctx, "Alice", seq, seqClient, true, tx, receipt.BlockNumber.Uint64())
+	}
+}
+
+func TestExpressLaneServerCoordinatedSequencing(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tmpDir := t.TempDir()
+
+	auctionContractAddr, aliceBidderClient, bobBidderClient, roundDuration, builderSeq, cleanupSeq, _, _ := setupExpressLaneAuction(t, tmpDir, ctx, 0)
+	seq, seqClient, seqInfo := builderSeq.L2.ConsensusNode, builderSeq.L2.Client, builderSeq.L2Info
+	defer cleanupSeq()
+	builderSeq.execConfig.Sequencer.Dangerous.Timeboost.EnableSequenceReservation = true
+
+	auctionContract, err := express_lane_auctiongen.NewExpressLaneAuction(auctionContractAddr, seqClient)
+	Require(t, err)
+	rawRoundTimingInfo, err := auctionContract.RoundTimingInfo(&bind.CallOpts{})
+	Require(t, err)
+	roundTimingInfo, err := timeboost.NewRoundTimingInfo(rawRoundTimingInfo)
+	Require(t, err)
+
+	placeBidsAndDecideWinner(t, ctx, seqClient, seqInfo, auctionContract, "Bob", "Alice", bobBidderClient, aliceBidderClient, roundDuration)
+	time.Sleep(roundTimingInfo.TimeTilNextRound())
+
+	chainId, err := seqClient.ChainID(ctx)
+	Require(t, err)
+
+	// Two active-active clients of Bob's express lane, neither of which knows what the other has submitted,
+	// reserving sequence numbers on the JWT authenticated endpoint
+	jwt, err := signature.LoadSigningKey(seq.Stack.JWTPath())
+	Require(t, err)
+	var clients []*expressLaneClient
+	for i := 0; i < 2; i++ {
+		seqDial, err := rpc.DialOptions(ctx, seq.Stack.WSAuthEndpoint(), rpc.WithHTTPAuth(node.NewJWTAuth([32]byte(*jwt))))
+		Require(t, err)
+		client := newExpressLaneClient(
+			seqInfo.Accounts["Bob"].PrivateKey,
+			chainId,
+			*roundTimingInfo,
+			auctionContractAddr,
+			seqDial,
+		)
+		client.serverSequencing = true
+		client.Start(ctx)
+		clients = append(clients, client)
+	}
+
+	currNonce, err := seqClient.PendingNonceAt(ctx, seqInfo.GetAddress("Alice"))
+	Require(t, err)
+	seqInfo.GetInfoWithPrivKey("Alice").Nonce.Store(currNonce)
+	var txs []*types.Transaction
+	for i := 0; i < 6; i++ {
+		tx := seqInfo.PrepareTx("Alice", "Owner", seqInfo.TransferGas, big.NewInt(1), nil)
+		Require(t, clients[i%2].SendTransaction(ctx, tx))
+		txs = append(txs, tx)
+	}
+	for _, tx := range txs {
+		_, err = EnsureTxSucceeded(ctx, seqClient, tx)
+		Require(t, err)
+	}
+	for _, client := range clients {
+		if client.sequence != 0 {
+			t.Fatalf("expected the client not to track the sequence itself, got %d", client.sequence)
+		}
+	}
+}
+
+func TestExpressLaneClientResyncsSequence(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tmpDir := t.TempDir()
+
+	auctionContractAddr, aliceBidderClient, bobBidderClient, roundDuration, builderSeq, cleanupSeq, _, _ := setupExpressLaneAuction(t, tmpDir, ctx, 0)
+	seq, seqClient, seqInfo := builderSeq.L2.ConsensusNode, builderSeq.L2.Client, builderSeq.L2Info
+	defer cleanupSeq()
+
+	auctionContract, err := express_lane_auctiongen.NewExpressLaneAuction(auctionContractAddr, seqClient)
+	Require(t, err)
+	rawRoundTimingInfo, err := auctionContract.RoundTimingInfo(&bind.CallOpts{})
+	Require(t, err)
+	roundTimingInfo, err := timeboost.NewRoundTimingInfo(rawRoundTimingInfo)
+	Require(t, err)
+
+	placeBidsAndDecideWinner(t, ctx, seqClient, seqInfo, auctionContract, "Bob", "Alice", bobBidderClient, aliceBidderClient, roundDuration)
+	time.Sleep(roundTimingInfo.TimeTilNextRound())
+
+	chainId, err := seqClient.ChainID(ctx)
+	Require(t, err)
+
+	bobPriv := seqInfo.Accounts["Bob"].PrivateKey
+	seqDial, err := rpc.Dial(seq.Stack.HTTPEndpoint())
+	Require(t, err)
+	expressLaneClient := newExpressLaneClient(
+		bobPriv,
+		chainId,
+		*roundTimingInfo,
+		auctionContractAddr,
+		seqDial,
+	)
+	expressLaneClient.Start(ctx)
+
+	currNonce, err := seqClient.PendingNonceAt(ctx, seqInfo.GetAddress("Alice"))
+	Require(t, err)
+	seqInfo.GetInfoWithPrivKey("Alice").Nonce.Store(currNonce)
+	round := roundTimingInfo.RoundNumber()
+
+	// A sequence number far ahead of the round's, as if left over from a previous round, is rejected with a hint
+	staleTx := seqInfo.PrepareTx("Alice", "Owner", seqInfo.TransferGas, big.NewInt(1), nil)
+	err = expressLaneClient.SendTransactionWithSequence(ctx, staleTx, 5000)
+	if err == nil || !strings.Contains(err.Error(), timeboost.ErrSequenceNumberTooHigh.Error()) {
+		t.Fatalf("expected a sequence number too high error, got: %v", err)
+	}
+	hint, ok := timeboost.SequenceHintFromError(err)
+	if !ok {
+		t.Fatalf("sequencer's error didn't include a sequence hint: %v", err)
+	}
+	if uint64(hint.Round) != round || hint.ExpectedSequenceNumber != 0 {
+		t.Fatalf("unexpected sequence hint %+v for round %d", hint, round)
+	}
+
+	// The client resynchronizes from the hint instead of failing
+	expressLaneClient.sequence = 5000
+	Require(t, expressLaneClient.SendTransaction(ctx, staleTx))
+	if expressLaneClient.sequence != 1 {
+		t.Fatalf("expected the client to resynchronize to sequence 1, got %d", expressLaneClient.sequence)
+	}
+	_, err = EnsureTxSucceeded(ctx, seqClient, staleTx)
+	Require(t, err)
+
+	// Likewise when it falls behind
+	expressLaneClient.sequence = 0
+	nextTx := seqInfo.PrepareTx("Alice", "Owner", seqInfo.TransferGas, big.NewInt(1), nil)
+	Require(t, expressLaneClient.SendTransaction(ctx, nextTx))
+	if expressLaneClient.sequence != 2 {
+		t.Fatalf("expected the client to resynchronize to sequence 2, got %d", expressLaneClient.sequence)
+	}
+	_, err = EnsureTxSucceeded(ctx, seqClient, nextTx)
+	Require(t, err)
+}
+
+func TestExpressLaneRejectsSubmissionsWithoutController(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tmpDir := t.TempDir()
+
+	auctionContractAddr, _, _, _, builderSeq, cleanupSeq, _, _ := setupExpressLaneAuction(t, tmpDir, ctx, 0)
+	seq, seqClient, seqInfo := builderSeq.L2.ConsensusNode, builderSeq.L2.Client, builderSeq.L2Info
+	defer cleanupSeq()
+
+	auctionContract, err := express_lane_auctiongen.NewExpressLaneAuction(auctionContractAddr, seqClient)
+	Require(t, err)
+	rawRoundTimingInfo, err := auctionContract.RoundTimingInfo(&bind.CallOpts{})
+	Require(t, err)
+	roundTimingInfo, err := timeboost.NewRoundTimingInfo(rawRoundTimingInfo)
+	Require(t, err)
+
+	// Nobody bids, so the round starts without an express lane controller
+	time.Sleep(roundTimingInfo.TimeTilNextRound())
+
+	chainId, err := seqClient.ChainID(ctx)
+	Require(t, err)
+	seqDial, err := rpc.Dial(seq.Stack.HTTPEndpoint())
+	Require(t, err)
+	expressLaneClient := newExpressLaneClient(
+		seqInfo.Accounts["Bob"].PrivateKey,
+		chainId,
+		*roundTimingInfo,
+		auctionContractAddr,
+		seqDial,
+	)
+	expressLaneClient.Start(ctx)
+
+	tx := seqInfo.PrepareTx("Bob", "Owner", seqInfo.TransferGas, big.NewInt(1), nil)
+	err = expressLaneClient.SendTransaction(ctx, tx)
+	if err == nil || !strings.Contains(err.Error(), timeboost.ErrNoOnchainController.Error()) {
+		t.Fatalf("expected express lane submission to be rejected with %v, got: %v", timeboost.ErrNoOnchainController, err)
+	}
+	if _, _, err := seqClient.TransactionByHash(ctx, tx.Hash()); err == nil {
+		t.Fatal("rejected express lane tx shouldn't have been sequenced")
+	}
+}
+
+func TestTimeboostSimulateOrdering(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tmpDir := t.TempDir()
+
+	auctionContractAddr, aliceBidderClient, bobBidderClient, roundDuration, builderSeq, cleanupSeq, _, _ := setupExpressLaneAuction(t, tmpDir, ctx, 0)
+	seq, seqClient, seqInfo := builderSeq.L2.ConsensusNode, builderSeq.L2.Client, builderSeq.L2Info
+	defer cleanupSeq()
+
+	auctionContract, err := express_lane_auctiongen.NewExpressLaneAuction(auctionContractAddr, seqClient)
+	Require(t, err)
+	rawRoundTimingInfo, err := auctionContract.RoundTimingInfo(&bind.CallOpts{})
+	Require(t, err)
+	roundTimingInfo, err := timeboost.NewRoundTimingInfo(rawRoundTimingInfo)
+	Require(t, err)
+
+	placeBidsAndDecideWinner(t, ctx, seqClient, seqInfo, auctionContract, "Bob", "Alice", bobBidderClient, aliceBidderClient, roundDuration)
+	time.Sleep(roundTimingInfo.TimeTilNextRound())
+
+	chainId, err := seqClient.ChainID(ctx)
+	Require(t, err)
+
+	bobPriv := seqInfo.Accounts["Bob"].PrivateKey
+	seqDial, err := rpc.Dial(seq.Stack.HTTPEndpoint())
+	Require(t, err)
+	expressLaneClient := newExpressLaneClient(
+		bobPriv,
+		chainId,
+		*roundTimingInfo,
+		auctionContractAddr,
+		seqDial,
+	)
+	expressLaneClient.Start(ctx)
+
+	aliceNonce, err := seqClient.PendingNonceAt(ctx, seqInfo.GetAddress("Alice"))
+	Require(t, err)
+	seqInfo.GetInfoWithPrivKey("Alice").Nonce.Store(aliceNonce)
+	aliceTx := seqInfo.PrepareTx("Alice", "Owner", seqInfo.TransferGas, big.NewInt(1), nil)
+	bobNonce, err := seqClient.PendingNonceAt(ctx, seqInfo.GetAddress("Bob"))
+	Require(t, err)
+	seqInfo.GetInfoWithPrivKey("Bob").Nonce.Store(bobNonce)
+	bobTx := seqInfo.PrepareTx("Bob", "Owner", seqInfo.TransferGas, big.NewInt(1), nil)
+
+	// Alice's tx arrives first, but Bob's express lane tx should still be sequenced before it
+	const bobArrivalOffset = 10 * time.Millisecond
+	aliceTxBytes, err := aliceTx.MarshalBinary()
+	Require(t, err)
+	bobTxBytes, err := bobTx.MarshalBinary()
+	Require(t, err)
+	var simulated []gethexec.SimulatedOrderedTransaction
+	err = seqDial.CallContext(ctx, &simulated, "timeboost_simulateOrdering",
+		[]gethexec.SimulatedTransaction{{Transaction: bobTxBytes, ArrivalOffsetMs: hexutil.Uint64(bobArrivalOffset.Milliseconds())}},
+		[]gethexec.SimulatedTransaction{{Transaction: aliceTxBytes, ArrivalOffsetMs: 0}},
+	)
+	Require(t, err)
+	if len(simulated) != 2 {
+		t.Fatalf("expected 2 simulated txs, got %d", len(simulated))
+	}
+	if simulated[0].TxHash != bobTx.Hash() || !simulated[0].Timeboosted || simulated[1].TxHash != aliceTx.Hash() {
+		t.Fatalf("unexpected simulated order: %v", simulated)
+	}
+
+	// Simulating doesn't sequence anything
+	if _, _, err := seqClient.TransactionByHash(ctx, bobTx.Hash()); err == nil {
+		t.Fatal("simulated tx shouldn't have been sequenced")
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		Require(t, seqClient.SendTransaction(ctx, aliceTx))
+	}()
+	go func() {
+		defer wg.Done()
+		time.Sleep(bobArrivalOffset)
+		Require(t, expressLaneClient.SendTransaction(ctx, bobTx))
+	}()
+	wg.Wait()
+
+	position := func(tx *types.Transaction) (uint64, uint) {
+		receipt, err := EnsureTxSucceeded(ctx, seqClient, tx)
+		Require(t, err)
+		return receipt.BlockNumber.Uint64(), receipt.TransactionIndex
+	}
+	bobBlock, bobIndex := position(bobTx)
+	aliceBlock, aliceIndex := position(aliceTx)
+	if aliceBlock < bobBlock || (aliceBlock == bobBlock && aliceIndex < bobIndex) {
+		t.Fatal("actual order doesn't match the simulated order, Alice's tx was sequenced before Bob's express lane tx")
+	}
+}
+
 func dbKey(prefix []byte, pos uint64) []byte {
 	var key []byte
 	key = append(key, prefix...)
@@ -948,6 +1298,74 @@ func TestTimeboostBulkBlockMetadataAPI(t *testing.T) {
 	}
 }
 
+func TestTimeboostBulkBlockMetadataAPIClampLatest(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	builder := NewNodeBuilder(ctx).DefaultConfig(t, false)
+	builder.nodeConfig.TransactionStreamer.TrackBlockMetadataFrom = 1
+	builder.execConfig.BlockMetadataApiBlocksLimit = 5
+	builder.execConfig.BlockMetadataApiClampLatest = true
+	cleanup := builder.Build(t)
+	defer cleanup()
+
+	arbDb := builder.L2.ConsensusNode.ArbDB
+
+	builder.L2Info.GenerateAccount("User")
+	user := builder.L2Info.GetDefaultTransactOpts("User", ctx)
+	var latestL2 uint64
+	for {
+		builder.L2.TransferBalanceTo(t, "Owner", util.RemapL1Address(user.From), big.NewInt(1e18), builder.L2Info)
+		var err error
+		latestL2, err = builder.L2.Client.BlockNumber(ctx)
+		Require(t, err)
+		if latestL2 > 20 {
+			break
+		}
+	}
+	for i := uint64(1); i <= latestL2; i++ {
+		// #nosec G115
+		Require(t, arbDb.Put(dbKey([]byte("t"), i), []byte{0, uint8(i)}))
+	}
+
+	// A large range ending at "latest" is clamped to the last BlockMetadataApiBlocksLimit blocks
+	l2rpc := builder.L2.Stack.Attach()
+	var result []gethexec.NumberAndBlockMetadata
+	err := l2rpc.CallContext(ctx, &result, "arb_getRawBlockMetadata", rpc.BlockNumber(1), "latest")
+	Require(t, err)
+	if len(result) != 5 {
+		t.Fatalf("number of entries in arb_getRawBlockMetadata is incorrect. Got: %d, Want: %d", len(result), 5)
+	}
+	for i, data := range result {
+		want := latestL2 - 4 + uint64(i) // #nosec G115
+		if data.BlockNumber != want {
+			t.Fatalf("BlockNumber mismatch. Got: %d, Want: %d", data.BlockNumber, want)
+		}
+		// #nosec G115
+		if !bytes.Equal(data.RawMetadata, []byte{0, uint8(want)}) {
+			t.Fatalf("RawMetadata mismatch. Got: %s, Want: %v", data.RawMetadata, []byte{0, uint8(want)})
+		}
+	}
+
+	// A relative start is resolved against the current head
+	latestL2, err = builder.L2.Client.BlockNumber(ctx)
+	Require(t, err)
+	err = l2rpc.CallContext(ctx, &result, "arb_getRawBlockMetadata", "-3", "latest")
+	Require(t, err)
+	if len(result) != 4 {
+		t.Fatalf("number of entries in arb_getRawBlockMetadata is incorrect. Got: %d, Want: %d", len(result), 4)
+	}
+	if result[0].BlockNumber != latestL2-3 || result[3].BlockNumber != latestL2 {
+		t.Fatalf("relative range resolved incorrectly. Got: %d-%d, Want: %d-%d", result[0].BlockNumber, result[3].BlockNumber, latestL2-3, latestL2)
+	}
+
+	// Ranges with an explicit end are still rejected when exceeding the limit
+	err = l2rpc.CallContext(ctx, &result, "arb_getRawBlockMetadata", rpc.BlockNumber(1), rpc.BlockNumber(latestL2)) // #nosec G115
+	if err == nil || !strings.Contains(err.Error(), gethexec.ErrBlockMetadataApiBlocksLimitExceeded.Error()) {
+		t.Fatalf("expecting ErrBlockMetadataApiBlocksLimitExceeded error, got: %v", err)
+	}
+}
+
 // func TestExpressLaneControlTransfer(t *testing.T) {
 // 	t.Parallel()
 // 	ctx, cancel := context.WithCancel(context.Background())
@@ -1386,6 +1804,7 @@ func setupExpressLaneAuction(
 	builderSeq.l2StackConfig.HTTPHost = "localhost"
 	builderSeq.l2StackConfig.HTTPPort = seqPort
 	builderSeq.l2StackConfig.HTTPModules = []string{"eth", "arb", "debug", "timeboost", "auctioneer"}
+	builderSeq.l2StackConfig.AuthModules = append(builderSeq.l2StackConfig.AuthModules, "timeboost")
 	builderSeq.nodeConfig.Feed.Output = *newBroadcasterConfigTest()
 	builderSeq.nodeConfig.Dangerous.NoSequencerCoordinator = false
 	builderSeq.nodeConfig.SeqCoordinator.Enable = true
@@ -1762,6 +2181,8 @@ type expressLaneClient struct {
 	auctionContractAddr common.Address
 	client              *rpc.Client
 	sequence            uint64
+	// serverSequencing makes the client reserve each sequence number from the sequencer instead of tracking it
+	serverSequencing bool
 }
 
 func newExpressLaneClient(
@@ -1819,9 +2240,22 @@ func (elc *expressLaneClient) SendTransactionWithSequence(ctx context.Context, t
 }
 
 func (elc *expressLaneClient) SendTransaction(ctx context.Context, transaction *types.Transaction) error {
+	if elc.serverSequencing {
+		var reserved timeboost.SequenceHint
+		round := hexutil.Uint64(elc.roundTimingInfo.RoundNumber())
+		if err := elc.client.CallContext(ctx, &reserved, "timeboost_nextExpressLaneSequence", &round); err != nil {
+			return err
+		}
+		return elc.SendTransactionWithSequence(ctx, transaction, uint64(reserved.ExpectedSequenceNumber))
+	}
 	elc.Lock()
 	defer elc.Unlock()
 	err := elc.SendTransactionWithSequence(ctx, transaction, elc.sequence)
+	// If our sequence number is out of sync, e.g. after missing a round boundary, resync from the sequencer's hint and retry
+	if hint, ok := timeboost.SequenceHintFromError(err); ok && uint64(hint.ExpectedSequenceNumber) != elc.sequence {
+		elc.sequence = uint64(hint.ExpectedSequenceNumber)
+		err = elc.SendTransactionWithSequence(ctx, transaction, elc.sequence)
+	}
 	if err == nil || strings.Contains(err.Error(), timeboost.ErrAcceptedTxFailed.Error()) {
 		elc.sequence += 1
 	}
@@ -7,6 +7,8 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -15,15 +17,19 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/offchainlabs/nitro/cmd/genericconf"
 	"github.com/offchainlabs/nitro/cmd/util"
 	"github.com/offchainlabs/nitro/pubsub"
 	"github.com/offchainlabs/nitro/solgen/go/express_lane_auctiongen"
+	"github.com/offchainlabs/nitro/util/arbmath"
 	"github.com/offchainlabs/nitro/util/redisutil"
 	"github.com/offchainlabs/nitro/util/stopwaiter"
 )
@@ -35,13 +41,20 @@ var domainValue []byte
 const (
 	AuctioneerNamespace      = "auctioneer"
 	validatedBidsRedisStream = "validated_bids"
+	// Stream of the same validated bids for read-only auctioneers, as the active auctioneer deletes the bids it consumes.
+	readOnlyValidatedBidsRedisStream = "validated_bids_read_only"
+	// Number of bids buffered per subscriber before further bids are dropped for that subscriber.
+	validatedBidsSubscriptionBuffer = 1000
 )
 
 var (
-	receivedBidsCounter  = metrics.NewRegisteredCounter("arb/auctioneer/bids/received", nil)
-	validatedBidsCounter = metrics.NewRegisteredCounter("arb/auctioneer/bids/validated", nil)
-	FirstBidValueGauge   = metrics.NewRegisteredGauge("arb/auctioneer/bids/firstbidvalue", nil)
-	SecondBidValueGauge  = metrics.NewRegisteredGauge("arb/auctioneer/bids/secondbidvalue", nil)
+	receivedBidsCounter           = metrics.NewRegisteredCounter("arb/auctioneer/bids/received", nil)
+	validatedBidsCounter          = metrics.NewRegisteredCounter("arb/auctioneer/bids/validated", nil)
+	droppedSubscribedBidsCounter  = metrics.NewRegisteredCounter("arb/auctioneer/bids/subscription/dropped", nil)
+	FirstBidValueGauge            = metrics.NewRegisteredGauge("arb/auctioneer/bids/firstbidvalue", nil)
+	SecondBidValueGauge           = metrics.NewRegisteredGauge("arb/auctioneer/bids/secondbidvalue", nil)
+	auctionResolutionLatency      = metrics.NewRegisteredHistogram("arb/auctioneer/resolution/latency", nil, metrics.NewBoundedHistogramSample())
+	lateResolutionsSkippedCounter = metrics.NewRegisteredCounter("arb/auctioneer/resolution/late/skipped", nil)
 )
 
 func init() {
@@ -57,25 +70,45 @@ type AuctioneerServerConfig struct {
 	RedisURL       string                `koanf:"redis-url"`
 	ConsumerConfig pubsub.ConsumerConfig `koanf:"consumer-config"`
 	// Timeout on polling for existence of each redis stream.
-	StreamTimeout             time.Duration            `koanf:"stream-timeout"`
-	Wallet                    genericconf.WalletConfig `koanf:"wallet"`
-	SequencerEndpoint         string                   `koanf:"sequencer-endpoint"`
-	SequencerJWTPath          string                   `koanf:"sequencer-jwt-path"`
-	UseRedisCoordinator       bool                     `koanf:"use-redis-coordinator"`
-	RedisCoordinatorURL       string                   `koanf:"redis-coordinator-url"`
-	AuctionContractAddress    string                   `koanf:"auction-contract-address"`
-	DbDirectory               string                   `koanf:"db-directory"`
-	AuctionResolutionWaitTime time.Duration            `koanf:"auction-resolution-wait-time"`
-	S3Storage                 S3StorageServiceConfig   `koanf:"s3-storage"`
+	StreamTimeout              time.Duration            `koanf:"stream-timeout"`
+	Wallet                     genericconf.WalletConfig `koanf:"wallet"`
+	SequencerEndpoint          string                   `koanf:"sequencer-endpoint"`
+	SequencerJWTPath           string                   `koanf:"sequencer-jwt-path"`
+	UseRedisCoordinator        bool                     `koanf:"use-redis-coordinator"`
+	RedisCoordinatorURL        string                   `koanf:"redis-coordinator-url"`
+	AuctionContractAddress     string                   `koanf:"auction-contract-address"`
+	DbDirectory                string                   `koanf:"db-directory"`
+	DbMaintenanceInterval      time.Duration            `koanf:"db-maintenance-interval"`
+	MinBidsToResolve           uint64                   `koanf:"min-bids-to-resolve"`
+	AuctionResolutionWaitTime  time.Duration            `koanf:"auction-resolution-wait-time"`
+	S3Storage                  S3StorageServiceConfig   `koanf:"s3-storage"`
+	VerifyDepositsAtResolution bool                     `koanf:"verify-deposits-at-resolution"`
+	LateResolutionGrace        time.Duration            `koanf:"late-resolution-grace"`
+	LogResolutionBids          bool                     `koanf:"log-resolution-bids"`
+	RevealBidAmounts           bool                     `koanf:"reveal-bid-amounts"`
+	ReadOnly                   bool                     `koanf:"read-only"`
+	MinWalletBalanceGwei       float64                  `koanf:"min-wallet-balance-gwei"`
+	ResolutionSubmission       string                   `koanf:"resolution-submission"`
+	RelayURL                   string                   `koanf:"relay-url"`
 }
 
+const (
+	// Resolution transactions are submitted to the sequencer endpoint
+	ResolutionSubmissionDirect = "direct"
+	// Resolution transactions are submitted to a relay or private transaction endpoint with eth_sendRawTransaction
+	ResolutionSubmissionRelay = "relay"
+)
+
 var DefaultAuctioneerServerConfig = AuctioneerServerConfig{
 	Enable:                    true,
 	RedisURL:                  "",
 	ConsumerConfig:            pubsub.DefaultConsumerConfig,
 	StreamTimeout:             10 * time.Minute,
 	AuctionResolutionWaitTime: 2 * time.Second,
+	DbMaintenanceInterval:     24 * time.Hour,
+	MinBidsToResolve:          1,
 	S3Storage:                 DefaultS3StorageServiceConfig,
+	ResolutionSubmission:      ResolutionSubmissionDirect,
 }
 
 var TestAuctioneerServerConfig = AuctioneerServerConfig{
@@ -84,6 +117,8 @@ var TestAuctioneerServerConfig = AuctioneerServerConfig{
 	ConsumerConfig:            pubsub.TestConsumerConfig,
 	StreamTimeout:             time.Minute,
 	AuctionResolutionWaitTime: 2 * time.Second,
+	MinBidsToResolve:          1,
+	ResolutionSubmission:      ResolutionSubmissionDirect,
 }
 
 func AuctioneerServerConfigAddOptions(prefix string, f *pflag.FlagSet) {
@@ -98,7 +133,17 @@ func AuctioneerServerConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.String(prefix+".redis-coordinator-url", DefaultAuctioneerServerConfig.RedisCoordinatorURL, "redis coordinator url for finding active sequencer")
 	f.String(prefix+".auction-contract-address", DefaultAuctioneerServerConfig.AuctionContractAddress, "express lane auction contract address")
 	f.String(prefix+".db-directory", DefaultAuctioneerServerConfig.DbDirectory, "path to database directory for persisting validated bids in a sqlite file")
+	f.Duration(prefix+".db-maintenance-interval", DefaultAuctioneerServerConfig.DbMaintenanceInterval, "interval at which the bids database is vacuumed and analyzed to reclaim space from deleted bids, 0 to disable")
+	f.Uint64(prefix+".min-bids-to-resolve", DefaultAuctioneerServerConfig.MinBidsToResolve, "minimum number of valid bids required to resolve an auction, rounds with fewer bids are left without an express lane controller")
 	f.Duration(prefix+".auction-resolution-wait-time", DefaultAuctioneerServerConfig.AuctionResolutionWaitTime, "wait time after auction closing before resolving the auction")
+	f.Bool(prefix+".verify-deposits-at-resolution", DefaultAuctioneerServerConfig.VerifyDepositsAtResolution, "re-check that bidders' deposits still cover their bids when resolving an auction, resolving with the highest funded bids instead of failing on an under-funded winner")
+	f.Duration(prefix+".late-resolution-grace", DefaultAuctioneerServerConfig.LateResolutionGrace, "period after the start of a round within which a delayed resolution of its auction is still attempted, 0 skips resolutions that would be submitted after the round started")
+	f.Bool(prefix+".log-resolution-bids", DefaultAuctioneerServerConfig.LogResolutionBids, "log every valid bid considered when resolving an auction along with the winner and clearing price, as an audit trail of each round")
+	f.Bool(prefix+".reveal-bid-amounts", DefaultAuctioneerServerConfig.RevealBidAmounts, "include the amounts of all bids when listing active bidders, rather than only those of the top two bids")
+	f.Bool(prefix+".read-only", DefaultAuctioneerServerConfig.ReadOnly, "run as a standby auctioneer, computing auction resolutions from the bids bid validators queue for read-only auctioneers without submitting them, so that no wallet is needed")
+	f.Float64(prefix+".min-wallet-balance-gwei", DefaultAuctioneerServerConfig.MinWalletBalanceGwei, "minimum balance the auctioneer's wallet needs on startup to pay for submitting auction resolutions, 0 to disable the check")
+	f.String(prefix+".resolution-submission", DefaultAuctioneerServerConfig.ResolutionSubmission, "how auction resolution transactions are submitted, either direct to the sequencer endpoint or relay to the relay-url endpoint so that they aren't front-run")
+	f.String(prefix+".relay-url", DefaultAuctioneerServerConfig.RelayURL, "url of the relay or private transaction endpoint that resolution transactions are sent to with eth_sendRawTransaction when resolution-submission is relay")
 	S3StorageServiceConfigAddOptions(prefix+".s3-storage", f)
 }
 
@@ -110,6 +155,7 @@ type AuctioneerServer struct {
 	txOpts                         *bind.TransactOpts
 	chainId                        *big.Int
 	endpointManager                SequencerEndpointManager
+	relayClient                    *rpc.Client
 	auctionContract                *express_lane_auctiongen.ExpressLaneAuction
 	auctionContractAddr            common.Address
 	auctionContractDomainSeparator [32]byte
@@ -118,8 +164,21 @@ type AuctioneerServer struct {
 	roundTimingInfo                RoundTimingInfo
 	streamTimeout                  time.Duration
 	auctionResolutionWaitTime      time.Duration
+	dbMaintenanceInterval          time.Duration
+	minBidsToResolve               uint64
+	verifyDepositsAtResolution     bool
+	lateResolutionGrace            time.Duration
+	logResolutionBids              bool
+	revealBidAmounts               bool
+	readOnly                       bool
+	bidResolutionPolicy            BidResolutionPolicy
 	database                       *SqliteDatabase
 	s3StorageService               *S3StorageService
+	bidSubscribersLock             sync.Mutex
+	bidSubscribers                 map[chan *ValidatedBid]struct{}
+	futureBidsLock                 sync.Mutex
+	futureBids                     map[uint64][]*ValidatedBid
+	promotedRound                  uint64
 }
 
 // NewAuctioneerServer creates a new autonomous auctioneer struct.
@@ -144,17 +203,43 @@ func NewAuctioneerServer(ctx context.Context, configFetcher AuctioneerServerConf
 		if err != nil {
 			return nil, err
 		}
+		if err := s3StorageService.Ping(ctx); err != nil {
+			return nil, err
+		}
+		info := s3StorageService.Info()
+		log.Info("Persisting validated bids to s3", "bucket", info.Bucket, "objectPrefix", info.ObjectPrefix, "region", info.Region, "format", info.Format, "compression", info.Compression)
 	}
 	auctionContractAddr := common.HexToAddress(cfg.AuctionContractAddress)
 	redisClient, err := redisutil.RedisClientFromURL(cfg.RedisURL)
 	if err != nil {
 		return nil, err
 	}
-	c, err := pubsub.NewConsumer[*JsonValidatedBid, error](redisClient, validatedBidsRedisStream, &cfg.ConsumerConfig)
+	stream := validatedBidsRedisStream
+	if cfg.ReadOnly {
+		stream = readOnlyValidatedBidsRedisStream
+	}
+	c, err := pubsub.NewConsumer[*JsonValidatedBid, error](redisClient, stream, &cfg.ConsumerConfig)
 	if err != nil {
 		return nil, fmt.Errorf("creating consumer for validation: %w", err)
 	}
 
+	var relayClient *rpc.Client
+	switch cfg.ResolutionSubmission {
+	case ResolutionSubmissionDirect:
+	case ResolutionSubmissionRelay:
+		if cfg.RelayURL == "" {
+			return nil, errors.New("relay url cannot be empty when submitting auction resolutions to a relay")
+		}
+		if !cfg.ReadOnly {
+			relayClient, err = rpc.DialContext(ctx, cfg.RelayURL)
+			if err != nil {
+				return nil, fmt.Errorf("error connecting to relay endpoint: %w", err)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid resolution-submission value, it should be either %s or %s, got: %s", ResolutionSubmissionDirect, ResolutionSubmissionRelay, cfg.ResolutionSubmission)
+	}
+
 	var endpointManager SequencerEndpointManager
 	if cfg.UseRedisCoordinator {
 		redisCoordinator, err := redisutil.NewRedisCoordinator(cfg.RedisCoordinatorURL)
@@ -176,9 +261,16 @@ func NewAuctioneerServer(ctx context.Context, configFetcher AuctioneerServerConf
 	if err != nil {
 		return nil, err
 	}
-	txOpts, _, err := util.OpenWallet("auctioneer-server", &cfg.Wallet, chainId)
-	if err != nil {
-		return nil, errors.Wrap(err, "opening wallet")
+	var txOpts *bind.TransactOpts
+	if !cfg.ReadOnly {
+		txOpts, _, err = util.OpenWallet("auctioneer-server", &cfg.Wallet, chainId)
+		if err != nil {
+			return nil, errors.Wrap(err, "opening wallet")
+		}
+		minBalance := arbmath.FloatToBig(cfg.MinWalletBalanceGwei * params.GWei)
+		if err := checkWalletBalance(ctx, txOpts.From, minBalance, sequencerClient.BalanceAt); err != nil {
+			return nil, err
+		}
 	}
 	auctionContract, err := express_lane_auctiongen.NewExpressLaneAuction(auctionContractAddr, sequencerClient)
 	if err != nil {
@@ -204,6 +296,7 @@ func NewAuctioneerServer(ctx context.Context, configFetcher AuctioneerServerConf
 	return &AuctioneerServer{
 		txOpts:                         txOpts,
 		endpointManager:                endpointManager,
+		relayClient:                    relayClient,
 		chainId:                        chainId,
 		database:                       database,
 		s3StorageService:               s3StorageService,
@@ -215,15 +308,42 @@ func NewAuctioneerServer(ctx context.Context, configFetcher AuctioneerServerConf
 		bidCache:                       newBidCache(domainSeparator),
 		roundTimingInfo:                *roundTimingInfo,
 		auctionResolutionWaitTime:      cfg.AuctionResolutionWaitTime,
+		dbMaintenanceInterval:          cfg.DbMaintenanceInterval,
+		minBidsToResolve:               cfg.MinBidsToResolve,
+		verifyDepositsAtResolution:     cfg.VerifyDepositsAtResolution,
+		lateResolutionGrace:            cfg.LateResolutionGrace,
+		logResolutionBids:              cfg.LogResolutionBids,
+		revealBidAmounts:               cfg.RevealBidAmounts,
+		readOnly:                       cfg.ReadOnly,
+		bidResolutionPolicy:            NewSecondPriceBidResolutionPolicy(domainSeparator),
+		bidSubscribers:                 make(map[chan *ValidatedBid]struct{}),
 	}, nil
 }
 
-func (a *AuctioneerServer) Start(ctx_in context.Context) {
-	a.StopWaiter.Start(ctx_in, a)
+// startBidsArchival starts persisting validated bids to s3 and maintaining the bids database, which is left
+// to the active auctioneer when read-only
+func (a *AuctioneerServer) startBidsArchival(ctx_in context.Context) {
+	if a.readOnly {
+		return
+	}
 	// Start S3 storage service to persist validated bids to s3
 	if a.s3StorageService != nil {
 		a.s3StorageService.Start(ctx_in)
 	}
+	// Periodically reclaim space left behind by bids deleted after being persisted to s3
+	if a.dbMaintenanceInterval > 0 {
+		a.StopWaiter.CallIteratively(func(ctx context.Context) time.Duration {
+			if err := a.database.Maintain(ctx); err != nil {
+				log.Error("Error running bids database maintenance", "error", err)
+			}
+			return a.dbMaintenanceInterval
+		})
+	}
+}
+
+func (a *AuctioneerServer) Start(ctx_in context.Context) {
+	a.StopWaiter.Start(ctx_in, a)
+	a.startBidsArchival(ctx_in)
 	// Channel that consumer uses to indicate its readiness.
 	readyStream := make(chan struct{}, 1)
 	a.consumer.Start(ctx_in)
@@ -297,10 +417,7 @@ func (a *AuctioneerServer) Start(ctx_in context.Context) {
 		for {
 			select {
 			case bid := <-a.bidsReceiver:
-				log.Info("Consumed validated bid", "bidder", bid.Bidder, "amount", bid.Amount, "round", bid.Round)
-				a.bidCache.add(JsonValidatedBidToGo(bid))
-				// Persist the validated bid to the database as a non-blocking operation.
-				go a.persistValidatedBid(bid)
+				a.handleConsumedBid(bid)
 			case <-ctx.Done():
 				log.Info("Context done while waiting redis streams to be ready, failed to start")
 				return
@@ -318,26 +435,270 @@ func (a *AuctioneerServer) Start(ctx_in context.Context) {
 				log.Error("Context closed, autonomous auctioneer shutting down")
 				return
 			case auctionClosingTime := <-ticker.c:
-				log.Info("New auction closing time reached", "closingTime", auctionClosingTime, "totalBids", a.bidCache.size())
-				time.Sleep(a.auctionResolutionWaitTime)
-				if err := a.resolveAuction(ctx); err != nil {
-					log.Error("Could not resolve auction for round", "error", err)
-				}
-				// Clear the bid cache.
-				a.bidCache = newBidCache(a.auctionContractDomainSeparator)
+				log.Info("New auction closing time reached", "closingTime", auctionClosingTime, "totalBids", a.currentBidCache().size())
+				a.closeAuction(ctx)
 			}
 		}
 	})
 }
 
-// Resolves the auction by calling the smart contract with the top two bids.
-func (a *AuctioneerServer) resolveAuction(ctx context.Context) error {
+// closeAuction resolves the auction once the resolution wait time has passed, so that bids validated before
+// the auction closed but still on their way through the redis stream are included, and then replaces the bid cache.
+func (a *AuctioneerServer) closeAuction(ctx context.Context) {
+	upcomingRound := a.roundTimingInfo.RoundNumber() + 1
+	time.Sleep(a.auctionResolutionWaitTime)
+	if err := a.resolveAuction(ctx, upcomingRound); err != nil {
+		log.Error("Could not resolve auction for round", "error", err)
+	}
+	// Replace the bid cache with one holding the bids already received for the next auction's round.
+	a.futureBidsLock.Lock()
+	defer a.futureBidsLock.Unlock()
+	nextRound := upcomingRound + 1
+	bidCache := newBidCache(a.auctionContractDomainSeparator)
+	for _, bid := range a.futureBids[nextRound] {
+		bidCache.addHeld(bid)
+	}
+	for round := range a.futureBids {
+		if round <= nextRound {
+			delete(a.futureBids, round)
+		}
+	}
+	a.promotedRound = nextRound
+	a.bidCache = bidCache
+}
+
+// currentBidCache returns the cache of the bids for the auction being held, which closeAuction replaces under
+// futureBidsLock once the auction is resolved.
+func (a *AuctioneerServer) currentBidCache() *bidCache {
+	a.futureBidsLock.Lock()
+	defer a.futureBidsLock.Unlock()
+	return a.bidCache
+}
+
+// addBid caches the bid for resolution, holding bids for rounds past the one being auctioned until their auction.
+// Without round timing info the round being auctioned is unknown, so bids are cached right away.
+func (a *AuctioneerServer) addBid(bid *ValidatedBid) {
+	a.futureBidsLock.Lock()
+	defer a.futureBidsLock.Unlock()
+	if a.roundTimingInfo.Round > 0 && bid.Round > a.roundTimingInfo.RoundNumber()+1 {
+		if bid.Round <= a.promotedRound {
+			a.bidCache.addHeld(bid)
+			return
+		}
+		if a.futureBids == nil {
+			a.futureBids = make(map[uint64][]*ValidatedBid)
+		}
+		a.futureBids[bid.Round] = append(a.futureBids[bid.Round], bid)
+		return
+	}
+	a.bidCache.add(bid)
+}
+
+func (a *AuctioneerServer) handleConsumedBid(bid *JsonValidatedBid) {
+	log.Info("Consumed validated bid", "bidder", bid.Bidder, "amount", bid.Amount, "round", bid.Round)
+	validatedBid := JsonValidatedBidToGo(bid)
+	a.addBid(validatedBid)
+	// Persist the validated bid to the database as a non-blocking operation.
+	go a.persistValidatedBid(bid)
+	a.notifyBidSubscribers(validatedBid)
+}
+
+// SubscribeValidatedBids returns a channel receiving every validated bid consumed by the auctioneer
+// until ctx is done, at which point the channel is closed. Subscribers that fall behind have bids
+// dropped instead of blocking the auctioneer. Received bids are shared and must not be modified.
+func (a *AuctioneerServer) SubscribeValidatedBids(ctx context.Context) (<-chan *ValidatedBid, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ch := make(chan *ValidatedBid, validatedBidsSubscriptionBuffer)
+	a.bidSubscribersLock.Lock()
+	a.bidSubscribers[ch] = struct{}{}
+	a.bidSubscribersLock.Unlock()
+	go func() {
+		<-ctx.Done()
+		a.bidSubscribersLock.Lock()
+		defer a.bidSubscribersLock.Unlock()
+		delete(a.bidSubscribers, ch)
+		close(ch)
+	}()
+	return ch, nil
+}
+
+func (a *AuctioneerServer) notifyBidSubscribers(bid *ValidatedBid) {
+	a.bidSubscribersLock.Lock()
+	defer a.bidSubscribersLock.Unlock()
+	for ch := range a.bidSubscribers {
+		select {
+		case ch <- bid:
+		default:
+			droppedSubscribedBidsCounter.Inc(1)
+		}
+	}
+}
+
+// SetBidResolutionPolicy replaces the second price rule used to resolve auctions. Must be called before Start.
+func (a *AuctioneerServer) SetBidResolutionPolicy(policy BidResolutionPolicy) {
+	a.bidResolutionPolicy = policy
+}
+
+// resolutionBids returns the bids to resolve the auction with according to the bid resolution policy,
+// with a nil second bid if the winner is to pay the reserve price.
+func (a *AuctioneerServer) resolutionBids(bids []*ValidatedBid) (*ValidatedBid, *ValidatedBid, error) {
+	winner, price, err := a.bidResolutionPolicy.Resolve(bids)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resolutionBids(a.auctionContractDomainSeparator, bids, winner, price)
+}
+
+// EstimateResolution computes the outcome of the auction for the upcoming round from the bids consumed so far,
+// without submitting a resolution transaction. The bids are selected as they would be at resolution, so an auction
+// that would be left unresolved fails with ErrNotEnoughBids or ErrNoBids. As on-chain, the second price is what the
+// winner pays: the second highest bid, or the reserve price if there is only a single bid.
+func (a *AuctioneerServer) EstimateResolution(ctx context.Context, round uint64) (winner common.Address, firstPrice, secondPrice *big.Int, err error) {
 	upcomingRound := a.roundTimingInfo.RoundNumber() + 1
-	result := a.bidCache.topTwoBids()
-	first := result.firstPlace
-	second := result.secondPlace
+	if round != upcomingRound {
+		return common.Address{}, nil, nil, errors.Wrapf(ErrBadRoundNumber, "can only estimate the resolution of upcoming round %d, got %d", upcomingRound, round)
+	}
+	_, first, second, err := a.selectResolutionBids(ctx, round, time.Now())
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	if second != nil {
+		return first.ExpressLaneController, first.Amount, second.Amount, nil
+	}
+	reservePrice, err := a.auctionContract.ReservePrice(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return common.Address{}, nil, nil, fmt.Errorf("failed to fetch reserve price: %w", err)
+	}
+	return first.ExpressLaneController, first.Amount, reservePrice, nil
+}
+
+// ActiveBidder is a bidder with a valid bid in the auction for the upcoming round, along with its highest bid.
+type ActiveBidder struct {
+	Bidder                common.Address
+	ExpressLaneController common.Address
+	// Amount of the bid, nil for bids outside the top two unless all bid amounts are revealed.
+	Amount *big.Int
+}
+
+// ActiveBidders returns the distinct bidders with valid bids consumed so far for the upcoming round, those of the bids
+// the bid resolution policy would resolve the auction with first. Only the amounts of these bids, which determine the
+// resolution, are included unless the auctioneer is configured to reveal all bid amounts.
+func (a *AuctioneerServer) ActiveBidders() ([]ActiveBidder, error) {
+	bids := a.currentBidCache().validBids(time.Now())
+	if len(bids) == 0 {
+		return nil, nil
+	}
+	first, second, err := a.resolutionBids(bids)
+	if err != nil {
+		return nil, err
+	}
+	activeBidders := make([]ActiveBidder, 0, len(bids))
+	for _, bid := range []*ValidatedBid{first, second} {
+		if bid != nil {
+			activeBidders = append(activeBidders, ActiveBidder{Bidder: bid.Bidder, ExpressLaneController: bid.ExpressLaneController, Amount: new(big.Int).Set(bid.Amount)})
+		}
+	}
+	others := make([]ActiveBidder, 0, len(bids))
+	for _, bid := range bids {
+		if bid == first || bid == second {
+			continue
+		}
+		activeBidder := ActiveBidder{Bidder: bid.Bidder, ExpressLaneController: bid.ExpressLaneController}
+		if a.revealBidAmounts {
+			activeBidder.Amount = new(big.Int).Set(bid.Amount)
+		}
+		others = append(others, activeBidder)
+	}
+	// Order the rest by address so that the order doesn't give away the amounts.
+	slices.SortFunc(others, func(x, y ActiveBidder) int {
+		return x.Bidder.Cmp(y.Bidder)
+	})
+	return append(activeBidders, others...), nil
+}
+
+// selectResolutionBids returns the valid bids the auction of round is resolved with as of resolutionTime, along with
+// the two the bid resolution policy picks from them. It fails with ErrNotEnoughBids if fewer than minBidsToResolve
+// bids are valid, and with ErrNoBids if there are none, or none left covered by their bidder's deposit when deposits
+// are verified at resolution.
+func (a *AuctioneerServer) selectResolutionBids(ctx context.Context, round uint64, resolutionTime time.Time) ([]*ValidatedBid, *ValidatedBid, *ValidatedBid, error) {
+	bidCache := a.currentBidCache()
+	// #nosec G115
+	if numBids := bidCache.numValidBids(resolutionTime); numBids > 0 && uint64(numBids) < a.minBidsToResolve {
+		return nil, nil, nil, errors.Wrapf(ErrNotEnoughBids, "round %d has %d bids, %d needed", round, numBids, a.minBidsToResolve)
+	}
+	bids := bidCache.validBids(resolutionTime)
+	if len(bids) == 0 {
+		return nil, nil, nil, errors.Wrapf(ErrNoBids, "round %d", round)
+	}
+	if bidCache.hasHeldBids() {
+		reservePrice, err := a.auctionContract.ReservePrice(&bind.CallOpts{Context: ctx})
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to get reserve price: %w", err)
+		}
+		bids = bidsMeetingReservePrice(bids, reservePrice)
+		if len(bids) == 0 {
+			return nil, nil, nil, errors.Wrapf(ErrNoBids, "no bids of round %d meet the reserve price %s", round, reservePrice)
+		}
+	}
+	if a.verifyDepositsAtResolution {
+		funded, err := fundedBids(ctx, bids, a.auctionContract.BalanceOf)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to verify bidder deposits: %w", err)
+		}
+		if len(funded) == 0 {
+			return nil, nil, nil, errors.Wrapf(ErrNoBids, "no bids of round %d covered by their bidder's deposit", round)
+		}
+		bids = funded
+	}
+	first, second, err := a.resolutionBids(bids)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return bids, first, second, nil
+}
+
+// resolutionDeadline is the time until which the auction of the given round is attempted to be resolved,
+// the start of the round plus the late resolution grace.
+func (a *AuctioneerServer) resolutionDeadline(round uint64) time.Time {
+	roundStart := a.roundTimingInfo.Offset.Add(a.roundTimingInfo.Round * arbmath.SaturatingCast[time.Duration](round))
+	return roundStart.Add(a.lateResolutionGrace)
+}
+
+// Resolves the auction of upcomingRound by calling the smart contract with the bids chosen by the bid resolution policy.
+func (a *AuctioneerServer) resolveAuction(ctx context.Context, upcomingRound uint64) error {
+	resolutionTime := time.Now()
+	deadline := a.resolutionDeadline(upcomingRound)
+	if resolutionTime.After(deadline) {
+		lateResolutionsSkippedCounter.Inc(1)
+		log.Warn("Auction resolution too late, leaving round without express lane controller", "round", upcomingRound, "deadline", deadline, "lateResolutionGrace", a.lateResolutionGrace)
+		return nil
+	}
+	if a.roundTimingInfo.RoundNumberAt(resolutionTime) >= upcomingRound {
+		log.Warn("Attempting late auction resolution within grace", "round", upcomingRound, "deadline", deadline)
+	}
+	bids, first, second, err := a.selectResolutionBids(ctx, upcomingRound, resolutionTime)
+	if errors.Is(err, ErrNotEnoughBids) || errors.Is(err, ErrNoBids) {
+		log.Info("Not resolving auction, skipping round", "round", upcomingRound, "reason", err)
+		return nil
+	}
+	if err != nil {
+		log.Error("Error selecting auction resolution bids", "round", upcomingRound, "error", err)
+		return err
+	}
+	if a.logResolutionBids {
+		logResolutionBids(log.Root(), upcomingRound, bids, first, second)
+	}
+	if a.readOnly {
+		var secondPrice *big.Int
+		if second != nil {
+			secondPrice = second.Amount
+		}
+		log.Info("Read-only auctioneer not submitting auction resolution", "round", upcomingRound, "winner", first.Bidder, "controller", first.ExpressLaneController, "firstPrice", first.Amount, "secondPrice", secondPrice)
+		return nil
+	}
 	var tx *types.Transaction
-	var err error
 	opts := copyTxOpts(a.txOpts)
 	opts.NoSend = true
 
@@ -354,7 +715,7 @@ func (a *AuctioneerServer) resolveAuction(ctx context.Context) error {
 	}
 
 	switch {
-	case first != nil && second != nil: // Both bids are present
+	case second != nil: // Both bids are present
 		tx, err = a.auctionContract.ResolveMultiBidAuction(
 			opts,
 			express_lane_auctiongen.Bid{
@@ -372,7 +733,7 @@ func (a *AuctioneerServer) resolveAuction(ctx context.Context) error {
 		SecondBidValueGauge.Update(second.Amount.Int64())
 		log.Info("Resolving auction with two bids", "round", upcomingRound)
 
-	case first != nil: // Single bid is present
+	default: // Single bid is present, or the policy charges the reserve price
 		tx, err = a.auctionContract.ResolveSingleBidAuction(
 			opts,
 			express_lane_auctiongen.Bid{
@@ -383,22 +744,16 @@ func (a *AuctioneerServer) resolveAuction(ctx context.Context) error {
 		)
 		FirstBidValueGauge.Update(first.Amount.Int64())
 		log.Info("Resolving auction with single bid", "round", upcomingRound)
-
-	case second == nil: // No bids received
-		log.Info("No bids received for auction resolution", "round", upcomingRound)
-		return nil
 	}
 	if err != nil {
 		log.Error("Error resolving auction", "error", err)
 		return err
 	}
 
-	roundEndTime := a.roundTimingInfo.TimeOfNextRound()
 	retryInterval := 1 * time.Second
 
 	if err := retryUntil(ctx, func() error {
-		if err := sequencerRpc.CallContext(ctx, nil, "auctioneer_submitAuctionResolutionTransaction", tx); err != nil {
-			log.Error("Error submitting auction resolution to sequencer endpoint", "error", err)
+		if err := a.submitResolutionTransaction(ctx, sequencerRpc, tx); err != nil {
 			return err
 		}
 
@@ -418,14 +773,153 @@ func (a *AuctioneerServer) resolveAuction(ctx context.Context) error {
 		}
 
 		return nil
-	}, retryInterval, roundEndTime); err != nil {
+	}, retryInterval, deadline); err != nil {
 		return err
 	}
 
-	log.Info("Auction resolved successfully", "txHash", tx.Hash().Hex())
+	sinceAuctionClose := a.recordResolutionLatency(upcomingRound, time.Now())
+	log.Info("Auction resolved successfully", "round", upcomingRound, "txHash", tx.Hash().Hex(), "sinceAuctionClose", sinceAuctionClose)
 	return nil
 }
 
+// submitResolutionTransaction submits the signed resolution transaction to the relay endpoint if one is configured,
+// and to the sequencer endpoint otherwise.
+func (a *AuctioneerServer) submitResolutionTransaction(ctx context.Context, sequencerRpc *rpc.Client, tx *types.Transaction) error {
+	if a.relayClient == nil {
+		if err := sequencerRpc.CallContext(ctx, nil, "auctioneer_submitAuctionResolutionTransaction", tx); err != nil {
+			log.Error("Error submitting auction resolution to sequencer endpoint", "error", err)
+			return err
+		}
+		return nil
+	}
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("error encoding auction resolution transaction: %w", err)
+	}
+	if err := a.relayClient.CallContext(ctx, nil, "eth_sendRawTransaction", hexutil.Bytes(data)); err != nil {
+		log.Error("Error submitting auction resolution to relay endpoint", "txHash", tx.Hash(), "error", err)
+		return err
+	}
+	return nil
+}
+
+// logResolutionBids logs each bid considered in resolving the round's auction, then the winner and the price it pays:
+// the second bid's amount, or the reserve price if the auction is resolved with a single bid.
+func logResolutionBids(logger log.Logger, round uint64, bids []*ValidatedBid, first, second *ValidatedBid) {
+	for _, bid := range bids {
+		logger.Info("Bid considered for auction resolution", "round", round, "bidder", bid.Bidder, "controller", bid.ExpressLaneController, "amount", bid.Amount, "hash", bid.Hash())
+	}
+	clearingPrice := "reserve price"
+	if second != nil {
+		clearingPrice = second.Amount.String()
+	}
+	logger.Info("Auction resolution outcome", "round", round, "bids", len(bids), "winner", first.Bidder, "controller", first.ExpressLaneController, "firstPrice", first.Amount, "clearingPrice", clearingPrice)
+}
+
+// checkWalletBalance fails if the balance of the auctioneer's wallet is below minBalance, so that an auctioneer that
+// couldn't pay for submitting resolutions fails on startup rather than when resolving its first auction.
+func checkWalletBalance(ctx context.Context, account common.Address, minBalance *big.Int, balanceAt func(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)) error {
+	if minBalance.Sign() <= 0 {
+		return nil
+	}
+	balance, err := balanceAt(ctx, account, nil)
+	if err != nil {
+		return fmt.Errorf("error fetching balance of auctioneer wallet %s: %w", account, err)
+	}
+	if balance.Cmp(minBalance) < 0 {
+		return fmt.Errorf("auctioneer wallet %s has balance %s wei, below the required minimum of %s wei to submit auction resolutions", account, balance, minBalance)
+	}
+	log.Info("Auctioneer wallet balance", "account", account, "balance", balance, "minBalance", minBalance)
+	return nil
+}
+
+// fundedBids returns the bids whose amount is still covered by their bidder's deposit, as balances may have changed
+// since the bids were validated and the auction contract rejects a resolution with an under-funded bid.
+func fundedBids(ctx context.Context, bids []*ValidatedBid, balanceOf func(opts *bind.CallOpts, account common.Address) (*big.Int, error)) ([]*ValidatedBid, error) {
+	balances := make(map[common.Address]*big.Int)
+	funded := make([]*ValidatedBid, 0, len(bids))
+	for _, bid := range bids {
+		balance, ok := balances[bid.Bidder]
+		if !ok {
+			var err error
+			balance, err = balanceOf(&bind.CallOpts{Context: ctx}, bid.Bidder)
+			if err != nil {
+				return nil, err
+			}
+			balances[bid.Bidder] = balance
+		}
+		if balance.Cmp(bid.Amount) < 0 {
+			log.Warn("Dropping bid no longer covered by the bidder's deposit", "bidder", bid.Bidder, "controller", bid.ExpressLaneController, "amount", bid.Amount, "balance", balance)
+			continue
+		}
+		funded = append(funded, bid)
+	}
+	return funded, nil
+}
+
+// bidsMeetingReservePrice returns the bids not below the reserve price. The bid validator only checks bids for the
+// upcoming round against it, as the reserve price of a later round may change until its reserve submission deadline.
+func bidsMeetingReservePrice(bids []*ValidatedBid, reservePrice *big.Int) []*ValidatedBid {
+	meeting := make([]*ValidatedBid, 0, len(bids))
+	for _, bid := range bids {
+		if bid.Amount.Cmp(reservePrice) < 0 {
+			log.Warn("Dropping bid below the reserve price", "bidder", bid.Bidder, "controller", bid.ExpressLaneController, "amount", bid.Amount, "reservePrice", reservePrice)
+			continue
+		}
+		meeting = append(meeting, bid)
+	}
+	return meeting
+}
+
+// UnresolvedRounds returns the rounds between fromRound and toRound, inclusive, that received bids but have no
+// AuctionResolved event, i.e. were left without an express lane controller despite interest. Events are searched
+// from parent chain block fromBlock on, and only bids still in the database are considered.
+func (a *AuctioneerServer) UnresolvedRounds(ctx context.Context, fromRound, toRound, fromBlock uint64) ([]uint64, error) {
+	if a.database == nil {
+		return nil, errors.New("bids database not available")
+	}
+	rounds, err := a.database.RoundsWithBids(fromRound, toRound)
+	if err != nil {
+		return nil, err
+	}
+	if len(rounds) == 0 {
+		return nil, nil
+	}
+	it, err := a.auctionContract.FilterAuctionResolved(&bind.FilterOpts{Context: ctx, Start: fromBlock}, nil, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter auction resolutions: %w", err)
+	}
+	defer it.Close()
+	resolved := make(map[uint64]struct{})
+	for it.Next() {
+		resolved[it.Event.Round] = struct{}{}
+	}
+	if err := it.Error(); err != nil {
+		return nil, fmt.Errorf("failed to iterate auction resolutions: %w", err)
+	}
+	return unresolvedRounds(rounds, resolved), nil
+}
+
+func unresolvedRounds(roundsWithBids []uint64, resolved map[uint64]struct{}) []uint64 {
+	var unresolved []uint64
+	for _, round := range roundsWithBids {
+		if _, ok := resolved[round]; !ok {
+			unresolved = append(unresolved, round)
+		}
+	}
+	return unresolved
+}
+
+// recordResolutionLatency records the time from the close of the auction for round to the confirmation of its
+// resolution at confirmedAt, leaving out the bidding window. A growing latency means the auctioneer is lagging.
+func (a *AuctioneerServer) recordResolutionLatency(round uint64, confirmedAt time.Time) time.Duration {
+	roundStart := a.roundTimingInfo.Offset.Add(a.roundTimingInfo.Round * arbmath.SaturatingCast[time.Duration](round))
+	auctionClose := roundStart.Add(-a.roundTimingInfo.AuctionClosing)
+	sinceAuctionClose := confirmedAt.Sub(auctionClose)
+	auctionResolutionLatency.Update(sinceAuctionClose.Nanoseconds())
+	return sinceAuctionClose
+}
+
 // retryUntil retries a given operation defined by the closure until the specified duration
 // has passed or the operation succeeds. It waits for the specified retry interval between
 // attempts. The function returns an error if all attempts fail.
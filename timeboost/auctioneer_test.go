@@ -1,12 +1,14 @@
 package timeboost
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"math/big"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -15,12 +17,16 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/offchainlabs/nitro/cmd/genericconf"
 	"github.com/offchainlabs/nitro/pubsub"
+	"github.com/offchainlabs/nitro/solgen/go/express_lane_auctiongen"
 	"github.com/offchainlabs/nitro/util/redisutil"
 )
 
@@ -150,9 +156,9 @@ func TestBidValidatorAuctioneerRedisStream(t *testing.T) {
 	// We verify that the auctioneer has consumed all validated bids from the single Redis stream.
 	// We also verify the top two bids are those we expect.
 	am.bidCache.Lock()
-	require.Equal(t, 3, len(am.bidCache.bidsByExpressLaneControllerAddr))
+	require.Equal(t, 3, len(am.bidCache.bidsByBidder))
 	am.bidCache.Unlock()
-	result := am.bidCache.topTwoBids()
+	result := am.bidCache.topTwoBids(time.Now())
 	require.Equal(t, big.NewInt(7), result.firstPlace.Amount) // Best bid should be Charlie's last bid 7
 	require.Equal(t, charlieAddr, result.firstPlace.Bidder)
 	require.Equal(t, big.NewInt(6), result.secondPlace.Amount) // Second best bid should be Bob's last bid of 6
@@ -222,3 +228,668 @@ func mockOperation(successAfter int, currentAttempt *int) func() error {
 		return errors.New("operation failed")
 	}
 }
+
+func TestSubscribeValidatedBids(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	database, err := NewDatabase(t.TempDir())
+	require.NoError(t, err)
+	am := &AuctioneerServer{
+		database:        database,
+		bidCache:        newBidCache([32]byte{}),
+		roundTimingInfo: RoundTimingInfo{Offset: time.Now(), Round: time.Minute, AuctionClosing: time.Second * 15},
+		bidSubscribers:  make(map[chan *ValidatedBid]struct{}),
+	}
+
+	subCtx, subCancel := context.WithCancel(ctx)
+	sub, err := am.SubscribeValidatedBids(subCtx)
+	require.NoError(t, err)
+
+	// A subscriber that never reads should not block consumption of bids.
+	slowSub, err := am.SubscribeValidatedBids(ctx)
+	require.NoError(t, err)
+
+	numBids := validatedBidsSubscriptionBuffer + 10
+	for i := 0; i < numBids; i++ {
+		bid := &ValidatedBid{
+			ChainId:                big.NewInt(1),
+			ExpressLaneController:  common.BigToAddress(big.NewInt(int64(i))),
+			AuctionContractAddress: common.HexToAddress("0x2"),
+			Bidder:                 common.BigToAddress(big.NewInt(int64(numBids + i))),
+			Round:                  1,
+			Amount:                 big.NewInt(int64(i)),
+			Signature:              []byte("signature"),
+		}
+		am.handleConsumedBid(bid.ToJson())
+		if i < validatedBidsSubscriptionBuffer {
+			select {
+			case got := <-sub:
+				require.Equal(t, bid.ExpressLaneController, got.ExpressLaneController)
+				require.Equal(t, bid.Amount, got.Amount)
+			case <-time.After(time.Second):
+				t.Fatal("timed out waiting for subscribed bid")
+			}
+		}
+	}
+	// Every bid comes from its own bidder, as only a bidder's highest bid is cached
+	require.Equal(t, numBids, am.bidCache.size())
+	require.Len(t, slowSub, validatedBidsSubscriptionBuffer)
+	// Wait for the bids to be persisted so that the database isn't written to after the test ends.
+	require.Eventually(t, func() bool {
+		var count int
+		return database.sqlDB.Get(&count, "SELECT COUNT(*) FROM Bids") == nil && count == numBids
+	}, 10*time.Second, 50*time.Millisecond)
+
+	// Cancelling the subscription closes the channel.
+	subCancel()
+	for range sub {
+	}
+	am.bidSubscribersLock.Lock()
+	require.Len(t, am.bidSubscribers, 1)
+	am.bidSubscribersLock.Unlock()
+}
+
+func TestEstimateResolutionMatchesAuctionResolved(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	redisURL := redisutil.CreateTestRedis(ctx, t)
+	testSetup := setupAuctionTest(t, ctx)
+	_, endpoint := setupBidValidator(t, ctx, redisURL, testSetup)
+	alice := setupBidderClient(t, ctx, testSetup.accounts[1], testSetup, endpoint)
+	bob := setupBidderClient(t, ctx, testSetup.accounts[2], testSetup, endpoint)
+	require.NoError(t, alice.Deposit(ctx, big.NewInt(10)))
+	require.NoError(t, bob.Deposit(ctx, big.NewInt(10)))
+
+	domainSeparator, err := testSetup.expressLaneAuction.DomainSeparator(&bind.CallOpts{Context: ctx})
+	require.NoError(t, err)
+	am := &AuctioneerServer{
+		auctionContract:                testSetup.expressLaneAuction,
+		auctionContractDomainSeparator: domainSeparator,
+		bidCache:                       newBidCache(domainSeparator),
+		bidResolutionPolicy:            NewSecondPriceBidResolutionPolicy(domainSeparator),
+		roundTimingInfo:                alice.roundTimingInfo,
+	}
+
+	// Wait for the first round to start so that the upcoming round can be resolved
+	time.Sleep(time.Until(am.roundTimingInfo.Offset) + time.Millisecond*250)
+	round := am.roundTimingInfo.RoundNumber() + 1
+
+	_, _, _, err = am.EstimateResolution(ctx, round)
+	require.ErrorIs(t, err, ErrNoBids)
+	_, _, _, err = am.EstimateResolution(ctx, round+1)
+	require.ErrorIs(t, err, ErrBadRoundNumber)
+
+	for _, bidder := range []struct {
+		client *BidderClient
+		amount int64
+	}{{alice, 3}, {bob, 5}} {
+		bid, err := bidder.client.Bid(ctx, big.NewInt(bidder.amount), common.Address{})
+		require.NoError(t, err)
+		require.Equal(t, round, bid.Round)
+		am.bidCache.add(&ValidatedBid{
+			ExpressLaneController:  bid.ExpressLaneController,
+			Amount:                 bid.Amount,
+			Signature:              bid.Signature,
+			ChainId:                bid.ChainId,
+			AuctionContractAddress: bid.AuctionContractAddress,
+			Round:                  bid.Round,
+			Bidder:                 bidder.client.txOpts.From,
+		})
+	}
+	winner, firstPrice, secondPrice, err := am.EstimateResolution(ctx, round)
+	require.NoError(t, err)
+	require.Equal(t, bob.txOpts.From, winner)
+	require.Equal(t, big.NewInt(5), firstPrice)
+	require.Equal(t, big.NewInt(3), secondPrice)
+
+	// Resolve the auction on-chain within the auction closing window and compare with the estimate
+	time.Sleep(am.roundTimingInfo.TimeTilNextRound() - am.roundTimingInfo.AuctionClosing + time.Second)
+	result := am.bidCache.topTwoBids(time.Now())
+	tx, err := testSetup.expressLaneAuction.ResolveMultiBidAuction(
+		testSetup.accounts[0].txOpts,
+		express_lane_auctiongen.Bid{
+			ExpressLaneController: result.firstPlace.ExpressLaneController,
+			Amount:                result.firstPlace.Amount,
+			Signature:             result.firstPlace.Signature,
+		},
+		express_lane_auctiongen.Bid{
+			ExpressLaneController: result.secondPlace.ExpressLaneController,
+			Amount:                result.secondPlace.Amount,
+			Signature:             result.secondPlace.Signature,
+		},
+	)
+	require.NoError(t, err)
+	receipt, err := bind.WaitMined(ctx, testSetup.backend.Client(), tx)
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+
+	it, err := testSetup.expressLaneAuction.FilterAuctionResolved(&bind.FilterOpts{Context: ctx}, nil, nil, nil)
+	require.NoError(t, err)
+	require.True(t, it.Next())
+	require.Equal(t, round, it.Event.Round)
+	require.Equal(t, winner, it.Event.FirstPriceExpressLaneController)
+	require.Equal(t, firstPrice, it.Event.FirstPriceAmount)
+	require.Equal(t, secondPrice, it.Event.Price)
+}
+
+type countingEndpointManager struct {
+	calls int
+}
+
+func (m *countingEndpointManager) GetSequencerRPC(ctx context.Context) (*rpc.Client, bool, error) {
+	m.calls++
+	return nil, false, errors.New("no sequencer")
+}
+
+func TestResolveAuctionSkipsRoundBelowMinBids(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	endpointManager := &countingEndpointManager{}
+	am := &AuctioneerServer{
+		endpointManager:     endpointManager,
+		bidCache:            newBidCache([32]byte{}),
+		bidResolutionPolicy: NewSecondPriceBidResolutionPolicy([32]byte{}),
+		roundTimingInfo:     RoundTimingInfo{Offset: time.Now(), Round: time.Minute, AuctionClosing: time.Second * 15},
+		minBidsToResolve:    2,
+	}
+	newBid := func(controller int64) *ValidatedBid {
+		return &ValidatedBid{
+			ChainId:                big.NewInt(1),
+			ExpressLaneController:  common.BigToAddress(big.NewInt(controller)),
+			AuctionContractAddress: common.HexToAddress("0x2"),
+			Bidder:                 common.BigToAddress(big.NewInt(controller)),
+			Round:                  1,
+			Amount:                 big.NewInt(controller),
+			Signature:              []byte("signature"),
+		}
+	}
+
+	// A single bid is below the minimum, so the round is skipped without contacting the sequencer
+	am.bidCache.add(newBid(1))
+	require.NoError(t, am.resolveAuction(ctx, 1))
+	require.Equal(t, 0, endpointManager.calls)
+	// and the estimate agrees the auction won't be resolved
+	_, _, _, err := am.EstimateResolution(ctx, 1)
+	require.ErrorIs(t, err, ErrNotEnoughBids)
+
+	// Expired bids don't count towards the minimum
+	expiredBid := newBid(2)
+	expiredBid.Expiry = uint64(time.Now().Add(-time.Second).Unix())
+	am.bidCache.add(expiredBid)
+	require.NoError(t, am.resolveAuction(ctx, 1))
+	require.Equal(t, 0, endpointManager.calls)
+	_, _, _, err = am.EstimateResolution(ctx, 1)
+	require.ErrorIs(t, err, ErrNotEnoughBids)
+
+	// Once the minimum is met the auctioneer goes ahead with the resolution
+	am.bidCache.add(newBid(3))
+	winner, firstPrice, secondPrice, err := am.EstimateResolution(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, common.BigToAddress(big.NewInt(3)), winner)
+	require.Equal(t, big.NewInt(3), firstPrice)
+	require.Equal(t, big.NewInt(1), secondPrice)
+	require.Error(t, am.resolveAuction(ctx, 1))
+	require.Equal(t, 1, endpointManager.calls)
+}
+
+func TestResolveAuctionLateResolution(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	endpointManager := &countingEndpointManager{}
+	am := &AuctioneerServer{
+		endpointManager:     endpointManager,
+		bidCache:            newBidCache([32]byte{}),
+		bidResolutionPolicy: NewSecondPriceBidResolutionPolicy([32]byte{}),
+		// Round 1 started 5 seconds ago
+		roundTimingInfo:  RoundTimingInfo{Offset: time.Now().Add(-time.Minute - time.Second*5), Round: time.Minute, AuctionClosing: time.Second * 15},
+		minBidsToResolve: 1,
+	}
+	am.bidCache.add(&ValidatedBid{
+		ChainId:                big.NewInt(1),
+		ExpressLaneController:  common.HexToAddress("0x1"),
+		AuctionContractAddress: common.HexToAddress("0x2"),
+		Bidder:                 common.HexToAddress("0x1"),
+		Round:                  1,
+		Amount:                 big.NewInt(1),
+		Signature:              []byte("signature"),
+	})
+
+	// Without grace the resolution would certainly revert, so it's skipped without contacting the sequencer
+	require.NoError(t, am.resolveAuction(ctx, 1))
+	require.Equal(t, 0, endpointManager.calls)
+
+	// Within the grace the resolution is still attempted
+	am.lateResolutionGrace = time.Second * 10
+	require.Error(t, am.resolveAuction(ctx, 1))
+	require.Equal(t, 1, endpointManager.calls)
+
+	// Past the grace it's skipped again
+	am.lateResolutionGrace = time.Second * 2
+	require.NoError(t, am.resolveAuction(ctx, 1))
+	require.Equal(t, 1, endpointManager.calls)
+}
+
+func TestFundedBidsFallThroughToRunnerUp(t *testing.T) {
+	ctx := context.Background()
+	newBid := func(bidder int64, amount int64) *ValidatedBid {
+		return &ValidatedBid{
+			ChainId:               big.NewInt(1),
+			ExpressLaneController: common.BigToAddress(big.NewInt(bidder)),
+			Bidder:                common.BigToAddress(big.NewInt(bidder)),
+			Amount:                big.NewInt(amount),
+		}
+	}
+	bids := []*ValidatedBid{newBid(1, 9), newBid(2, 7), newBid(3, 4)}
+	// The highest bidder withdrew its deposit after bidding
+	balances := map[common.Address]*big.Int{
+		bids[0].Bidder: big.NewInt(2),
+		bids[1].Bidder: big.NewInt(10),
+		bids[2].Bidder: big.NewInt(4),
+	}
+	balanceOf := func(_ *bind.CallOpts, account common.Address) (*big.Int, error) {
+		return balances[account], nil
+	}
+	am := &AuctioneerServer{
+		bidResolutionPolicy: NewSecondPriceBidResolutionPolicy([32]byte{}),
+	}
+
+	first, second, err := am.resolutionBids(bids)
+	require.NoError(t, err)
+	require.Equal(t, bids[0], first)
+	require.Equal(t, bids[1], second)
+
+	funded, err := fundedBids(ctx, bids, balanceOf)
+	require.NoError(t, err)
+	require.Equal(t, []*ValidatedBid{bids[1], bids[2]}, funded)
+	first, second, err = am.resolutionBids(funded)
+	require.NoError(t, err)
+	require.Equal(t, bids[1], first)
+	require.Equal(t, bids[2], second)
+
+	// Nothing is left if no bidder can cover its bid
+	balances[bids[1].Bidder] = big.NewInt(0)
+	balances[bids[2].Bidder] = big.NewInt(3)
+	funded, err = fundedBids(ctx, bids, balanceOf)
+	require.NoError(t, err)
+	require.Empty(t, funded)
+
+	_, err = fundedBids(ctx, bids, func(*bind.CallOpts, common.Address) (*big.Int, error) {
+		return nil, errors.New("rpc error")
+	})
+	require.Error(t, err)
+}
+
+func TestCheckWalletBalance(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	account := common.HexToAddress("0x1")
+	balance := big.NewInt(100)
+	balanceAt := func(_ context.Context, _ common.Address, _ *big.Int) (*big.Int, error) {
+		return balance, nil
+	}
+
+	require.NoError(t, checkWalletBalance(ctx, account, big.NewInt(100), balanceAt))
+	err := checkWalletBalance(ctx, account, big.NewInt(101), balanceAt)
+	require.ErrorContains(t, err, "below the required minimum of 101 wei")
+
+	// The check is disabled without a minimum balance, even if the balance can't be fetched
+	require.NoError(t, checkWalletBalance(ctx, account, big.NewInt(0), func(context.Context, common.Address, *big.Int) (*big.Int, error) {
+		return nil, errors.New("rpc error")
+	}))
+	require.Error(t, checkWalletBalance(ctx, account, big.NewInt(1), func(context.Context, common.Address, *big.Int) (*big.Int, error) {
+		return nil, errors.New("rpc error")
+	}))
+}
+
+type recordingTxEndpoint struct {
+	rawTxs [][]byte
+	txs    []*types.Transaction
+}
+
+func (e *recordingTxEndpoint) SendRawTransaction(_ context.Context, input hexutil.Bytes) (common.Hash, error) {
+	e.rawTxs = append(e.rawTxs, input)
+	return common.Hash{}, nil
+}
+
+func (e *recordingTxEndpoint) SubmitAuctionResolutionTransaction(_ context.Context, tx *types.Transaction) error {
+	e.txs = append(e.txs, tx)
+	return nil
+}
+
+func TestSubmitResolutionTransactionToRelay(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dialEndpoint := func(namespace string, endpoint *recordingTxEndpoint) *rpc.Client {
+		server := rpc.NewServer()
+		t.Cleanup(server.Stop)
+		require.NoError(t, server.RegisterName(namespace, endpoint))
+		return rpc.DialInProc(server)
+	}
+	sequencer := &recordingTxEndpoint{}
+	sequencerRpc := dialEndpoint("auctioneer", sequencer)
+	relay := &recordingTxEndpoint{}
+
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	tx, err := types.SignNewTx(privateKey, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     3,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(2),
+		Gas:       100000,
+		To:        &common.Address{},
+		Data:      []byte{1, 2, 3},
+	})
+	require.NoError(t, err)
+	wantRawTx, err := tx.MarshalBinary()
+	require.NoError(t, err)
+
+	// The signed resolution is sent to the relay rather than the sequencer
+	am := &AuctioneerServer{relayClient: dialEndpoint("eth", relay)}
+	require.NoError(t, am.submitResolutionTransaction(ctx, sequencerRpc, tx))
+	require.Equal(t, [][]byte{wantRawTx}, relay.rawTxs)
+	require.Empty(t, sequencer.txs)
+
+	// Without a relay the resolution goes to the sequencer endpoint
+	am = &AuctioneerServer{}
+	require.NoError(t, am.submitResolutionTransaction(ctx, sequencerRpc, tx))
+	require.Len(t, sequencer.txs, 1)
+	require.Equal(t, tx.Hash(), sequencer.txs[0].Hash())
+	require.Len(t, relay.rawTxs, 1)
+}
+
+func TestCloseAuctionHoldsFutureRoundBids(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	am := &AuctioneerServer{
+		bidCache:            newBidCache([32]byte{}),
+		bidResolutionPolicy: NewSecondPriceBidResolutionPolicy([32]byte{}),
+		roundTimingInfo:     RoundTimingInfo{Offset: time.Now(), Round: time.Minute, AuctionClosing: time.Second * 15},
+		minBidsToResolve:    1,
+	}
+	newBid := func(bidder int64, round uint64) *ValidatedBid {
+		return &ValidatedBid{
+			ChainId:               big.NewInt(1),
+			ExpressLaneController: common.BigToAddress(big.NewInt(bidder)),
+			Bidder:                common.BigToAddress(big.NewInt(bidder)),
+			Round:                 round,
+			Amount:                big.NewInt(bidder),
+		}
+	}
+
+	// Bids for rounds past the upcoming one are held back from the auction being resolved
+	am.addBid(newBid(1, 2))
+	am.addBid(newBid(2, 3))
+	require.Equal(t, 0, am.bidCache.size())
+
+	// Once the upcoming round's auction closes, the bids for the next round are auctioned
+	am.closeAuction(ctx)
+	require.Equal(t, 1, am.bidCache.size())
+	require.Equal(t, uint64(2), am.bidCache.validBids(time.Now())[0].Round)
+
+	// Bids for the next round still arriving before it becomes the upcoming round are auctioned too
+	am.addBid(newBid(3, 2))
+	require.Equal(t, 2, am.bidCache.size())
+	require.True(t, am.bidCache.hasHeldBids())
+	require.Len(t, bidsMeetingReservePrice(am.bidCache.validBids(time.Now()), big.NewInt(3)), 1)
+	am.futureBidsLock.Lock()
+	require.Len(t, am.futureBids[3], 1)
+	am.futureBidsLock.Unlock()
+
+	// Without round timing info bids are cached right away rather than panicking
+	am = &AuctioneerServer{bidCache: newBidCache([32]byte{})}
+	am.addBid(newBid(1, 5))
+	require.Equal(t, 1, am.bidCache.size())
+}
+
+func TestActiveBidders(t *testing.T) {
+	t.Parallel()
+	am := &AuctioneerServer{
+		bidCache:            newBidCache([32]byte{}),
+		bidResolutionPolicy: NewSecondPriceBidResolutionPolicy([32]byte{}),
+	}
+	activeBidders, err := am.ActiveBidders()
+	require.NoError(t, err)
+	require.Empty(t, activeBidders)
+
+	newBid := func(bidder int64, amount int64) *ValidatedBid {
+		return &ValidatedBid{
+			ChainId:               big.NewInt(1),
+			ExpressLaneController: common.BigToAddress(big.NewInt(bidder + 100)),
+			Bidder:                common.BigToAddress(big.NewInt(bidder)),
+			Amount:                big.NewInt(amount),
+		}
+	}
+	am.bidCache.add(newBid(1, 4))
+	am.bidCache.add(newBid(2, 9))
+	am.bidCache.add(newBid(3, 2))
+	bidder4Bid := newBid(4, 7)
+	am.bidCache.add(bidder4Bid)
+	am.bidCache.add(newBid(3, 5)) // Bidder 3 raises its bid
+	expiredBid := newBid(5, 20)
+	expiredBid.Expiry = uint64(time.Now().Add(-time.Second).Unix()) // #nosec G115
+	am.bidCache.add(expiredBid)
+
+	activeBidder := func(bidder int64, amount *big.Int) ActiveBidder {
+		return ActiveBidder{
+			Bidder:                common.BigToAddress(big.NewInt(bidder)),
+			ExpressLaneController: common.BigToAddress(big.NewInt(bidder + 100)),
+			Amount:                amount,
+		}
+	}
+	activeBidders, err = am.ActiveBidders()
+	require.NoError(t, err)
+	require.Equal(t, []ActiveBidder{
+		activeBidder(2, big.NewInt(9)),
+		activeBidder(4, big.NewInt(7)),
+		activeBidder(1, nil),
+		activeBidder(3, nil),
+	}, activeBidders)
+
+	am.revealBidAmounts = true
+	activeBidders, err = am.ActiveBidders()
+	require.NoError(t, err)
+	require.Equal(t, []ActiveBidder{
+		activeBidder(2, big.NewInt(9)),
+		activeBidder(4, big.NewInt(7)),
+		activeBidder(1, big.NewInt(4)),
+		activeBidder(3, big.NewInt(5)),
+	}, activeBidders)
+
+	// The leaders are those the configured bid resolution policy resolves the auction with
+	am.revealBidAmounts = false
+	am.SetBidResolutionPolicy(&fixedPolicy{winner: bidder4Bid, price: big.NewInt(5)})
+	activeBidders, err = am.ActiveBidders()
+	require.NoError(t, err)
+	require.Equal(t, []ActiveBidder{
+		activeBidder(4, big.NewInt(7)),
+		activeBidder(3, big.NewInt(5)),
+		activeBidder(1, nil),
+		activeBidder(2, nil),
+	}, activeBidders)
+	am.SetBidResolutionPolicy(&reservePricePolicy{*NewSecondPriceBidResolutionPolicy([32]byte{})})
+	activeBidders, err = am.ActiveBidders()
+	require.NoError(t, err)
+	require.Equal(t, []ActiveBidder{
+		activeBidder(2, big.NewInt(9)),
+		activeBidder(1, nil),
+		activeBidder(3, nil),
+		activeBidder(4, nil),
+	}, activeBidders)
+}
+
+func TestResolveAuctionReadOnly(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	endpointManager := &countingEndpointManager{}
+	am := &AuctioneerServer{
+		endpointManager:     endpointManager,
+		bidCache:            newBidCache([32]byte{}),
+		bidResolutionPolicy: NewSecondPriceBidResolutionPolicy([32]byte{}),
+		roundTimingInfo:     RoundTimingInfo{Offset: time.Now(), Round: time.Minute, AuctionClosing: time.Second * 15},
+		minBidsToResolve:    1,
+		readOnly:            true,
+	}
+	for bidder, amount := range []int64{4, 9, 7} {
+		am.bidCache.add(&ValidatedBid{
+			ChainId:                big.NewInt(1),
+			ExpressLaneController:  common.BigToAddress(big.NewInt(int64(bidder) + 100)),
+			AuctionContractAddress: common.HexToAddress("0x2"),
+			Bidder:                 common.BigToAddress(big.NewInt(int64(bidder) + 1)),
+			Round:                  1,
+			Amount:                 big.NewInt(amount),
+			Signature:              []byte("signature"),
+		})
+	}
+	winner, firstPrice, secondPrice, err := am.EstimateResolution(ctx, 1)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	prevLogger := log.Root()
+	log.SetDefault(log.NewLogger(log.NewTerminalHandler(&buf, false)))
+	defer log.SetDefault(prevLogger)
+
+	// The read-only auctioneer computes the resolution without reaching out to the sequencer to submit it
+	require.NoError(t, am.resolveAuction(ctx, 1))
+	require.Equal(t, 0, endpointManager.calls)
+	logged := buf.String()
+	require.Contains(t, logged, "Read-only auctioneer not submitting auction resolution")
+	require.Contains(t, logged, "controller="+winner.Hex())
+	require.Contains(t, logged, fmt.Sprintf("firstPrice=%d", firstPrice))
+	require.Contains(t, logged, fmt.Sprintf("secondPrice=%d", secondPrice))
+
+	// The same auctioneer not in read-only mode goes on to submit the resolution
+	am.readOnly = false
+	require.Error(t, am.resolveAuction(ctx, 1))
+	require.Equal(t, 1, endpointManager.calls)
+}
+
+func TestReadOnlyAuctioneerLeavesBidsArchival(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db, err := NewDatabase(t.TempDir())
+	require.NoError(t, err)
+	s3StorageService := &S3StorageService{
+		client: newmockS3FullClient(),
+		now:    fixedClock,
+		config: &S3StorageServiceConfig{UploadInterval: time.Hour},
+		sqlDB:  db,
+	}
+	am := &AuctioneerServer{
+		database:              db,
+		s3StorageService:      s3StorageService,
+		dbMaintenanceInterval: time.Hour,
+		readOnly:              true,
+	}
+	am.StopWaiter.Start(ctx, am)
+	defer am.StopAndWait()
+
+	am.startBidsArchival(ctx)
+	require.False(t, s3StorageService.Started())
+
+	// The same auctioneer not in read-only mode persists bids to s3
+	am.readOnly = false
+	am.startBidsArchival(ctx)
+	require.True(t, s3StorageService.Started())
+	s3StorageService.StopAndWait()
+}
+
+func TestLogResolutionBids(t *testing.T) {
+	newBid := func(bidder int64, amount int64) *ValidatedBid {
+		return &ValidatedBid{
+			ChainId:               big.NewInt(1),
+			ExpressLaneController: common.BigToAddress(big.NewInt(bidder + 100)),
+			Bidder:                common.BigToAddress(big.NewInt(bidder)),
+			Amount:                big.NewInt(amount),
+		}
+	}
+	bids := []*ValidatedBid{newBid(1, 4), newBid(2, 9), newBid(3, 7)}
+	am := &AuctioneerServer{
+		bidResolutionPolicy: NewSecondPriceBidResolutionPolicy([32]byte{}),
+	}
+	first, second, err := am.resolutionBids(bids)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	logResolutionBids(log.NewLogger(log.NewTerminalHandler(&buf, false)), 5, bids, first, second)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, len(bids)+1)
+	for i, bid := range bids {
+		require.Contains(t, lines[i], "Bid considered for auction resolution")
+		require.Contains(t, lines[i], "round=5")
+		require.Contains(t, lines[i], bid.Bidder.Hex())
+		require.Contains(t, lines[i], bid.ExpressLaneController.Hex())
+		require.Contains(t, lines[i], fmt.Sprintf("amount=%d", bid.Amount))
+	}
+	outcome := lines[len(bids)]
+	require.Contains(t, outcome, "Auction resolution outcome")
+	require.Contains(t, outcome, "bids=3")
+	require.Contains(t, outcome, bids[1].Bidder.Hex())
+	require.Contains(t, outcome, "firstPrice=9")
+	require.Contains(t, outcome, "clearingPrice=7")
+
+	// A single bid pays the reserve price
+	buf.Reset()
+	logResolutionBids(log.NewLogger(log.NewTerminalHandler(&buf, false)), 6, bids[:1], bids[0], nil)
+	require.Contains(t, buf.String(), bids[0].Bidder.Hex())
+	require.Contains(t, buf.String(), `clearingPrice="reserve price"`)
+}
+
+func TestCloseAuctionIncludesBidsWithinResolutionWaitTime(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	endpointManager := &countingEndpointManager{}
+	am := &AuctioneerServer{
+		endpointManager:           endpointManager,
+		bidCache:                  newBidCache([32]byte{}),
+		bidResolutionPolicy:       NewSecondPriceBidResolutionPolicy([32]byte{}),
+		roundTimingInfo:           RoundTimingInfo{Offset: time.Now(), Round: time.Minute, AuctionClosing: time.Second * 15},
+		minBidsToResolve:          2,
+		auctionResolutionWaitTime: time.Second,
+	}
+	newBid := func(controller int64) *ValidatedBid {
+		return &ValidatedBid{
+			ChainId:                big.NewInt(1),
+			ExpressLaneController:  common.BigToAddress(big.NewInt(controller)),
+			AuctionContractAddress: common.HexToAddress("0x2"),
+			Bidder:                 common.BigToAddress(big.NewInt(controller)),
+			Round:                  1,
+			Amount:                 big.NewInt(controller),
+			Signature:              []byte("signature"),
+		}
+	}
+
+	// The second bid arrives after the auction closed but within the resolution wait time,
+	// so the minimum number of bids is met and the auctioneer goes ahead with the resolution
+	am.bidCache.add(newBid(1))
+	bidCache := am.bidCache
+	go func() {
+		time.Sleep(am.auctionResolutionWaitTime / 4)
+		bidCache.add(newBid(2))
+	}()
+	am.closeAuction(ctx)
+	require.Equal(t, 1, endpointManager.calls)
+	require.Equal(t, 0, am.bidCache.size())
+}
+
+func TestRecordResolutionLatency(t *testing.T) {
+	offset := time.Unix(1_700_000_000, 0)
+	am := &AuctioneerServer{
+		roundTimingInfo: RoundTimingInfo{Offset: offset, Round: time.Minute, AuctionClosing: time.Second * 15},
+	}
+	countBefore := auctionResolutionLatency.Snapshot().Count()
+
+	// The auction for round 10 closes 15 seconds before the round starts, its resolution is confirmed 4 seconds later
+	confirmedAt := offset.Add(10*time.Minute - 11*time.Second)
+	require.Equal(t, 4*time.Second, am.recordResolutionLatency(10, confirmedAt))
+	require.Equal(t, countBefore+1, auctionResolutionLatency.Snapshot().Count())
+
+	// A resolution confirmed late, after its round started, includes the time into the round
+	require.Equal(t, 17*time.Second, am.recordResolutionLatency(10, offset.Add(10*time.Minute+2*time.Second)))
+}
@@ -2,6 +2,7 @@ package timeboost
 
 import (
 	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -9,20 +10,71 @@ import (
 type bidCache struct {
 	auctionContractDomainSeparator [32]byte
 	sync.RWMutex
-	bidsByExpressLaneControllerAddr map[common.Address]*ValidatedBid
+	bidsByBidder map[common.Address]*ValidatedBid
+	// fallbackBidsByBidder holds a bidder's highest bid without an expiry if its highest bid expires,
+	// so that the bidder still has a bid once that one expires.
+	fallbackBidsByBidder map[common.Address]*ValidatedBid
+	// heldBids is set once a bid received before its round was the upcoming one is cached, which the bid validator
+	// didn't check against the reserve price.
+	heldBids bool
 }
 
 func newBidCache(auctionContractDomainSeparator [32]byte) *bidCache {
 	return &bidCache{
-		bidsByExpressLaneControllerAddr: make(map[common.Address]*ValidatedBid),
-		auctionContractDomainSeparator:  auctionContractDomainSeparator,
+		bidsByBidder:                   make(map[common.Address]*ValidatedBid),
+		fallbackBidsByBidder:           make(map[common.Address]*ValidatedBid),
+		auctionContractDomainSeparator: auctionContractDomainSeparator,
 	}
 }
 
+// add caches the bid in place of the bidder's earlier bid if it is higher. Only a bidder's highest bid is considered
+// at resolution, so that a lower bid it resubmitted over can't take part in the resolution alongside it. Of equal bids
+// the one received first is kept: the bid signature checked by the auction contract doesn't cover the expiry, so a copy
+// of a signed bid may arrive with another expiry or none, and letting it decide the replacement could evict the bid.
+// The bidder's highest bid without an expiry is kept as well while its highest bid expires, and takes its place once
+// it has expired.
 func (bc *bidCache) add(bid *ValidatedBid) {
 	bc.Lock()
 	defer bc.Unlock()
-	bc.bidsByExpressLaneControllerAddr[bid.ExpressLaneController] = bid
+	existing, ok := bc.bidsByBidder[bid.Bidder]
+	if ok && existing.Amount.Cmp(bid.Amount) >= 0 {
+		if bid.Expiry == 0 && existing.Expiry != 0 {
+			if fallback, ok := bc.fallbackBidsByBidder[bid.Bidder]; !ok || fallback.Amount.Cmp(bid.Amount) < 0 {
+				bc.fallbackBidsByBidder[bid.Bidder] = bid
+			}
+		}
+		return
+	}
+	if bid.Expiry == 0 {
+		delete(bc.fallbackBidsByBidder, bid.Bidder)
+	} else if ok && existing.Expiry == 0 {
+		bc.fallbackBidsByBidder[bid.Bidder] = existing
+	}
+	bc.bidsByBidder[bid.Bidder] = bid
+}
+
+// validBidOf returns the bidder's highest bid that hasn't expired as of resolutionTime, if any.
+// The caller must hold the lock.
+func (bc *bidCache) validBidOf(bidder common.Address, resolutionTime time.Time) *ValidatedBid {
+	if bid := bc.bidsByBidder[bidder]; bid != nil && !bid.IsExpiredAt(resolutionTime) {
+		return bid
+	}
+	return bc.fallbackBidsByBidder[bidder]
+}
+
+// addHeld caches a bid received before its round was the upcoming one, see add.
+func (bc *bidCache) addHeld(bid *ValidatedBid) {
+	bc.add(bid)
+	bc.Lock()
+	defer bc.Unlock()
+	bc.heldBids = true
+}
+
+// hasHeldBids returns whether any bid received before its round was the upcoming one was cached.
+func (bc *bidCache) hasHeldBids() bool {
+	bc.RLock()
+	defer bc.RUnlock()
+	return bc.heldBids
 }
 
 // TwoTopBids returns the top two bids for the given chain ID and round
@@ -34,34 +86,62 @@ type auctionResult struct {
 func (bc *bidCache) size() int {
 	bc.RLock()
 	defer bc.RUnlock()
-	return len(bc.bidsByExpressLaneControllerAddr)
+	return len(bc.bidsByBidder)
 
 }
 
-// topTwoBids returns the top two bids in the cache.
-func (bc *bidCache) topTwoBids() *auctionResult {
+// numValidBids returns the number of bidders with a bid in the cache that hasn't expired as of resolutionTime.
+func (bc *bidCache) numValidBids(resolutionTime time.Time) int {
 	bc.RLock()
 	defer bc.RUnlock()
+	count := 0
+	for bidder := range bc.bidsByBidder {
+		if bc.validBidOf(bidder, resolutionTime) != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// validBids returns each bidder's highest bid in the cache that hasn't expired as of resolutionTime.
+func (bc *bidCache) validBids(resolutionTime time.Time) []*ValidatedBid {
+	bc.RLock()
+	defer bc.RUnlock()
+	bids := make([]*ValidatedBid, 0, len(bc.bidsByBidder))
+	for bidder := range bc.bidsByBidder {
+		if bid := bc.validBidOf(bidder, resolutionTime); bid != nil {
+			bids = append(bids, bid)
+		}
+	}
+	return bids
+}
+
+// topTwoBids returns the top two bids in the cache, ignoring bids that have expired as of resolutionTime.
+func (bc *bidCache) topTwoBids(resolutionTime time.Time) *auctionResult {
+	return rankTopTwoBids(bc.auctionContractDomainSeparator, bc.validBids(resolutionTime))
+}
 
+// rankTopTwoBids returns the top two of the given bids, breaking ties by bid hash as the auction contract does.
+func rankTopTwoBids(auctionContractDomainSeparator [32]byte, bids []*ValidatedBid) *auctionResult {
 	result := &auctionResult{}
 
-	for _, bid := range bc.bidsByExpressLaneControllerAddr {
+	for _, bid := range bids {
 		if result.firstPlace == nil {
 			result.firstPlace = bid
 		} else if bid.Amount.Cmp(result.firstPlace.Amount) > 0 {
 			result.secondPlace = result.firstPlace
 			result.firstPlace = bid
 		} else if bid.Amount.Cmp(result.firstPlace.Amount) == 0 {
-			if bid.BigIntHash(bc.auctionContractDomainSeparator).Cmp(result.firstPlace.BigIntHash(bc.auctionContractDomainSeparator)) > 0 {
+			if bid.BigIntHash(auctionContractDomainSeparator).Cmp(result.firstPlace.BigIntHash(auctionContractDomainSeparator)) > 0 {
 				result.secondPlace = result.firstPlace
 				result.firstPlace = bid
-			} else if result.secondPlace == nil || bid.BigIntHash(bc.auctionContractDomainSeparator).Cmp(result.secondPlace.BigIntHash(bc.auctionContractDomainSeparator)) > 0 {
+			} else if result.secondPlace == nil || bid.BigIntHash(auctionContractDomainSeparator).Cmp(result.secondPlace.BigIntHash(auctionContractDomainSeparator)) > 0 {
 				result.secondPlace = bid
 			}
 		} else if result.secondPlace == nil || bid.Amount.Cmp(result.secondPlace.Amount) > 0 {
 			result.secondPlace = bid
 		} else if bid.Amount.Cmp(result.secondPlace.Amount) == 0 {
-			if bid.BigIntHash(bc.auctionContractDomainSeparator).Cmp(result.secondPlace.BigIntHash(bc.auctionContractDomainSeparator)) > 0 {
+			if bid.BigIntHash(auctionContractDomainSeparator).Cmp(result.secondPlace.BigIntHash(auctionContractDomainSeparator)) > 0 {
 				result.secondPlace = bid
 			}
 		}
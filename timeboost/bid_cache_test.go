@@ -6,6 +6,7 @@ import (
 	"math/big"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -20,6 +21,7 @@ import (
 
 func TestTopTwoBids(t *testing.T) {
 	t.Parallel()
+	resolutionTime := time.Unix(1000, 0)
 	tests := []struct {
 		name     string
 		bids     map[common.Address]*ValidatedBid
@@ -106,6 +108,18 @@ func TestTopTwoBids(t *testing.T) {
 				secondPlace: &ValidatedBid{Amount: big.NewInt(100), ChainId: big.NewInt(2), Bidder: common.HexToAddress("0x2"), ExpressLaneController: common.HexToAddress("0x2")},
 			},
 		},
+		{
+			name: "expired bid is excluded even though it is highest",
+			bids: map[common.Address]*ValidatedBid{
+				common.HexToAddress("0x1"): {Amount: big.NewInt(300), ChainId: big.NewInt(1), Bidder: common.HexToAddress("0x1"), ExpressLaneController: common.HexToAddress("0x1"), Expiry: 999},
+				common.HexToAddress("0x2"): {Amount: big.NewInt(200), ChainId: big.NewInt(1), Bidder: common.HexToAddress("0x2"), ExpressLaneController: common.HexToAddress("0x2"), Expiry: 1001},
+				common.HexToAddress("0x3"): {Amount: big.NewInt(100), ChainId: big.NewInt(1), Bidder: common.HexToAddress("0x3"), ExpressLaneController: common.HexToAddress("0x3")},
+			},
+			expected: &auctionResult{
+				firstPlace:  &ValidatedBid{Amount: big.NewInt(200), ChainId: big.NewInt(1), Bidder: common.HexToAddress("0x2"), ExpressLaneController: common.HexToAddress("0x2"), Expiry: 1001},
+				secondPlace: &ValidatedBid{Amount: big.NewInt(100), ChainId: big.NewInt(1), Bidder: common.HexToAddress("0x3"), ExpressLaneController: common.HexToAddress("0x3")},
+			},
+		},
 		{
 			name:     "no bids",
 			bids:     nil,
@@ -115,11 +129,11 @@ func TestTopTwoBids(t *testing.T) {
 			name: "identical bids",
 			bids: map[common.Address]*ValidatedBid{
 				common.HexToAddress("0x1"): {Amount: big.NewInt(100), ChainId: big.NewInt(1), Bidder: common.HexToAddress("0x1"), ExpressLaneController: common.HexToAddress("0x1")},
-				common.HexToAddress("0x2"): {Amount: big.NewInt(100), ChainId: big.NewInt(1), Bidder: common.HexToAddress("0x1"), ExpressLaneController: common.HexToAddress("0x2")},
+				common.HexToAddress("0x2"): {Amount: big.NewInt(100), ChainId: big.NewInt(1), Bidder: common.HexToAddress("0x2"), ExpressLaneController: common.HexToAddress("0x2")},
 			},
 			expected: &auctionResult{
 				firstPlace:  &ValidatedBid{Amount: big.NewInt(100), ChainId: big.NewInt(1), Bidder: common.HexToAddress("0x1"), ExpressLaneController: common.HexToAddress("0x1")},
-				secondPlace: &ValidatedBid{Amount: big.NewInt(100), ChainId: big.NewInt(1), Bidder: common.HexToAddress("0x1"), ExpressLaneController: common.HexToAddress("0x2")},
+				secondPlace: &ValidatedBid{Amount: big.NewInt(100), ChainId: big.NewInt(1), Bidder: common.HexToAddress("0x2"), ExpressLaneController: common.HexToAddress("0x2")},
 			},
 		},
 	}
@@ -127,9 +141,9 @@ func TestTopTwoBids(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			bc := &bidCache{
-				bidsByExpressLaneControllerAddr: tt.bids,
+				bidsByBidder: tt.bids,
 			}
-			result := bc.topTwoBids()
+			result := bc.topTwoBids(resolutionTime)
 			if (result.firstPlace == nil) != (tt.expected.firstPlace == nil) || (result.secondPlace == nil) != (tt.expected.secondPlace == nil) {
 				t.Fatalf("expected firstPlace: %v, secondPlace: %v, got firstPlace: %v, secondPlace: %v", tt.expected.firstPlace, tt.expected.secondPlace, result.firstPlace, result.secondPlace)
 			}
@@ -143,6 +157,64 @@ func TestTopTwoBids(t *testing.T) {
 	}
 }
 
+func TestBidCacheKeepsBiddersHighestBid(t *testing.T) {
+	t.Parallel()
+	alice := common.HexToAddress("0x1")
+	bob := common.HexToAddress("0x2")
+	newBid := func(bidder common.Address, amount int64, expiry uint64) *ValidatedBid {
+		return &ValidatedBid{Amount: big.NewInt(amount), ChainId: big.NewInt(1), Bidder: bidder, ExpressLaneController: bidder, Expiry: expiry}
+	}
+	bc := newBidCache([32]byte{})
+	bc.add(newBid(alice, 1, 0))
+	bc.add(newBid(bob, 2, 0))
+	bc.add(newBid(alice, 3, 0))
+
+	// Alice's bid of 1 is replaced by her bid of 3 and isn't considered
+	now := time.Now()
+	require.Equal(t, 2, bc.numValidBids(now))
+	result := bc.topTwoBids(now)
+	require.Equal(t, alice, result.firstPlace.Bidder)
+	require.Equal(t, big.NewInt(3), result.firstPlace.Amount)
+	require.Equal(t, bob, result.secondPlace.Bidder)
+	require.Equal(t, big.NewInt(2), result.secondPlace.Amount)
+
+	// A later, lower bid doesn't replace the higher one
+	bc.add(newBid(alice, 2, 0))
+	require.Equal(t, big.NewInt(3), bc.topTwoBids(now).firstPlace.Amount)
+
+	// Nor does a lower one expiring later
+	expiry := uint64(now.Add(time.Minute).Unix()) // #nosec G115
+	bc.add(newBid(bob, 5, expiry))
+	bc.add(newBid(bob, 4, expiry+60))
+	require.Equal(t, big.NewInt(5), bc.topTwoBids(now).firstPlace.Amount)
+	require.Equal(t, 2, bc.size())
+
+	// Once the expiring bid has expired, the bidder's highest bid without an expiry takes its place
+	afterExpiry := now.Add(2 * time.Minute)
+	require.Equal(t, 2, bc.numValidBids(afterExpiry))
+	result = bc.topTwoBids(afterExpiry)
+	require.Equal(t, alice, result.firstPlace.Bidder)
+	require.Equal(t, bob, result.secondPlace.Bidder)
+	require.Equal(t, big.NewInt(2), result.secondPlace.Amount)
+
+	// A higher bid without an expiry received later takes the place of the one held back too
+	bc.add(newBid(bob, 3, 0))
+	require.Equal(t, big.NewInt(5), bc.topTwoBids(now).firstPlace.Amount)
+	require.Equal(t, big.NewInt(3), bc.topTwoBids(afterExpiry).secondPlace.Amount)
+	bc.add(newBid(bob, 6, 0))
+	require.Equal(t, big.NewInt(6), bc.topTwoBids(afterExpiry).firstPlace.Amount)
+	require.Len(t, bc.validBids(now), 2)
+
+	// A copy of a signed bid with an earlier expiry doesn't evict the instance received first
+	signed := newBid(alice, 7, 0)
+	signed.Signature = []byte("signature")
+	bc.add(signed)
+	replayed := *signed
+	replayed.Expiry = uint64(now.Add(time.Second).Unix()) // #nosec G115
+	bc.add(&replayed)
+	require.Same(t, signed, bc.topTwoBids(now.Add(time.Minute)).firstPlace)
+}
+
 func BenchmarkBidValidation(b *testing.B) {
 	b.StopTimer()
 	ctx, cancel := context.WithCancel(context.Background())
@@ -159,7 +231,7 @@ func BenchmarkBidValidation(b *testing.B) {
 
 	b.StartTimer()
 	for i := 0; i < b.N; i++ {
-		_, err = bv.validateBid(newBid, bv.auctionContract.BalanceOf)
+		_, err = bv.validateBid(ctx, newBid, bv.auctionContract.BalanceOf)
 		require.NoError(b, err)
 	}
 }
@@ -0,0 +1,63 @@
+package timeboost
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+// BidResolutionPolicy decides the outcome of an auction from its unexpired bids, which allows the clearing rule
+// to be swapped, e.g. to experiment with alternative rules on testnets.
+// As the auction contract charges the winner either the amount of another bid or the reserve price, the price
+// must be the amount of a lower ranked bid from another bidder, or nil for the winner to pay the reserve price.
+// The winner is returned as one of the given bids rather than its controller, as several bidders may nominate the
+// same express lane controller.
+type BidResolutionPolicy interface {
+	Resolve(bids []*ValidatedBid) (winner *ValidatedBid, price *big.Int, err error)
+}
+
+// SecondPriceBidResolutionPolicy is the default policy, the highest bid wins and pays the second highest bid,
+// or the reserve price if it is the only bid.
+type SecondPriceBidResolutionPolicy struct {
+	auctionContractDomainSeparator [32]byte
+}
+
+func NewSecondPriceBidResolutionPolicy(auctionContractDomainSeparator [32]byte) *SecondPriceBidResolutionPolicy {
+	return &SecondPriceBidResolutionPolicy{auctionContractDomainSeparator}
+}
+
+func (p *SecondPriceBidResolutionPolicy) Resolve(bids []*ValidatedBid) (*ValidatedBid, *big.Int, error) {
+	result := rankTopTwoBids(p.auctionContractDomainSeparator, bids)
+	if result.firstPlace == nil {
+		return nil, nil, ErrNoBids
+	}
+	if result.secondPlace == nil {
+		return result.firstPlace, nil, nil
+	}
+	return result.firstPlace, result.secondPlace.Amount, nil
+}
+
+// resolutionBids maps the winner and price chosen by a policy back onto the bids to resolve the auction contract with.
+// A nil second bid means the auction is to be resolved as a single bid auction.
+func resolutionBids(auctionContractDomainSeparator [32]byte, bids []*ValidatedBid, winner *ValidatedBid, price *big.Int) (*ValidatedBid, *ValidatedBid, error) {
+	if winner == nil || !slices.Contains(bids, winner) {
+		return nil, nil, errors.New("bid resolution policy chose a winner that isn't one of the valid bids")
+	}
+	first := winner
+	if price == nil {
+		return first, nil, nil
+	}
+	firstHash := first.BigIntHash(auctionContractDomainSeparator)
+	for _, bid := range bids {
+		// The auction contract rejects resolving an auction with two bids of the same bidder
+		if bid == first || bid.Bidder == first.Bidder || bid.Amount.Cmp(price) != 0 {
+			continue
+		}
+		// The auction contract requires the first price bid to outrank the second price bid
+		if cmp := first.Amount.Cmp(bid.Amount); cmp > 0 || (cmp == 0 && firstHash.Cmp(bid.BigIntHash(auctionContractDomainSeparator)) > 0) {
+			return first, bid, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("bid resolution policy price %v for winner %v isn't the amount of a lower ranked bid of another bidder", price, first.Bidder)
+}
@@ -0,0 +1,186 @@
+package timeboost
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/offchainlabs/nitro/solgen/go/express_lane_auctiongen"
+	"github.com/offchainlabs/nitro/util/redisutil"
+)
+
+// reservePricePolicy awards the express lane to the highest bid at the reserve price
+type reservePricePolicy struct {
+	SecondPriceBidResolutionPolicy
+}
+
+func (p *reservePricePolicy) Resolve(bids []*ValidatedBid) (*ValidatedBid, *big.Int, error) {
+	winner, _, err := p.SecondPriceBidResolutionPolicy.Resolve(bids)
+	return winner, nil, err
+}
+
+// fixedPolicy resolves auctions to the given winner and price regardless of the bids
+type fixedPolicy struct {
+	winner *ValidatedBid
+	price  *big.Int
+}
+
+func (p *fixedPolicy) Resolve([]*ValidatedBid) (*ValidatedBid, *big.Int, error) {
+	return p.winner, p.price, nil
+}
+
+func TestSecondPriceBidResolutionPolicy(t *testing.T) {
+	policy := NewSecondPriceBidResolutionPolicy([32]byte{})
+	_, _, err := policy.Resolve(nil)
+	require.ErrorIs(t, err, ErrNoBids)
+
+	bids := []*ValidatedBid{
+		{Bidder: common.HexToAddress("0x11"), ExpressLaneController: common.HexToAddress("0x1"), Amount: big.NewInt(5), ChainId: big.NewInt(1)},
+		{Bidder: common.HexToAddress("0x12"), ExpressLaneController: common.HexToAddress("0x2"), Amount: big.NewInt(7), ChainId: big.NewInt(1)},
+		{Bidder: common.HexToAddress("0x13"), ExpressLaneController: common.HexToAddress("0x3"), Amount: big.NewInt(3), ChainId: big.NewInt(1)},
+	}
+	winner, price, err := policy.Resolve(bids)
+	require.NoError(t, err)
+	require.Equal(t, bids[1], winner)
+	require.Equal(t, big.NewInt(5), price)
+
+	winner, price, err = policy.Resolve(bids[:1])
+	require.NoError(t, err)
+	require.Equal(t, bids[0], winner)
+	require.Nil(t, price)
+}
+
+func TestResolutionBids(t *testing.T) {
+	bids := []*ValidatedBid{
+		{Bidder: common.HexToAddress("0x11"), ExpressLaneController: common.HexToAddress("0x1"), Amount: big.NewInt(5), ChainId: big.NewInt(1)},
+		{Bidder: common.HexToAddress("0x12"), ExpressLaneController: common.HexToAddress("0x2"), Amount: big.NewInt(7), ChainId: big.NewInt(1)},
+		{Bidder: common.HexToAddress("0x13"), ExpressLaneController: common.HexToAddress("0x3"), Amount: big.NewInt(3), ChainId: big.NewInt(1)},
+	}
+
+	// The winner may be charged any lower bid
+	first, second, err := resolutionBids([32]byte{}, bids, bids[1], big.NewInt(3))
+	require.NoError(t, err)
+	require.Equal(t, bids[1], first)
+	require.Equal(t, bids[2], second)
+
+	first, second, err = resolutionBids([32]byte{}, bids, bids[0], nil)
+	require.NoError(t, err)
+	require.Equal(t, bids[0], first)
+	require.Nil(t, second)
+
+	// The auction contract can't charge the winner a higher bid or an arbitrary amount
+	_, _, err = resolutionBids([32]byte{}, bids, bids[0], big.NewInt(7))
+	require.Error(t, err)
+	_, _, err = resolutionBids([32]byte{}, bids, bids[1], big.NewInt(4))
+	require.Error(t, err)
+	_, _, err = resolutionBids([32]byte{}, bids, &ValidatedBid{ExpressLaneController: common.HexToAddress("0x1"), Amount: big.NewInt(5)}, nil)
+	require.Error(t, err)
+
+	am := &AuctioneerServer{bidResolutionPolicy: NewSecondPriceBidResolutionPolicy([32]byte{})}
+	am.SetBidResolutionPolicy(&fixedPolicy{bids[0], big.NewInt(3)})
+	first, second, err = am.resolutionBids(bids)
+	require.NoError(t, err)
+	require.Equal(t, bids[0], first)
+	require.Equal(t, bids[2], second)
+}
+
+func TestResolutionBidsSameController(t *testing.T) {
+	controller := common.HexToAddress("0x1")
+	bids := []*ValidatedBid{
+		{Bidder: common.HexToAddress("0x12"), ExpressLaneController: controller, Amount: big.NewInt(5), ChainId: big.NewInt(1)},
+		{Bidder: common.HexToAddress("0x11"), ExpressLaneController: controller, Amount: big.NewInt(10), ChainId: big.NewInt(1)},
+	}
+
+	// Two bidders nominating the same controller resolve to the highest bid, paying the other bidder's bid
+	am := &AuctioneerServer{bidResolutionPolicy: NewSecondPriceBidResolutionPolicy([32]byte{})}
+	first, second, err := am.resolutionBids(bids)
+	require.NoError(t, err)
+	require.Equal(t, bids[1], first)
+	require.Equal(t, bids[0], second)
+
+	// The price can't be taken from another bid of the winning bidder
+	sameBidder := &ValidatedBid{Bidder: bids[1].Bidder, ExpressLaneController: controller, Amount: big.NewInt(3), ChainId: big.NewInt(1)}
+	_, _, err = resolutionBids([32]byte{}, append(bids, sameBidder), bids[1], big.NewInt(3))
+	require.Error(t, err)
+}
+
+func TestCustomBidResolutionPolicyResolvesOnchain(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	redisURL := redisutil.CreateTestRedis(ctx, t)
+	testSetup := setupAuctionTest(t, ctx)
+	_, endpoint := setupBidValidator(t, ctx, redisURL, testSetup)
+	alice := setupBidderClient(t, ctx, testSetup.accounts[1], testSetup, endpoint)
+	bob := setupBidderClient(t, ctx, testSetup.accounts[2], testSetup, endpoint)
+	require.NoError(t, alice.Deposit(ctx, big.NewInt(10)))
+	require.NoError(t, bob.Deposit(ctx, big.NewInt(10)))
+
+	domainSeparator, err := testSetup.expressLaneAuction.DomainSeparator(&bind.CallOpts{Context: ctx})
+	require.NoError(t, err)
+	am := &AuctioneerServer{
+		auctionContract:                testSetup.expressLaneAuction,
+		auctionContractDomainSeparator: domainSeparator,
+		bidCache:                       newBidCache(domainSeparator),
+		roundTimingInfo:                alice.roundTimingInfo,
+	}
+	am.SetBidResolutionPolicy(&reservePricePolicy{*NewSecondPriceBidResolutionPolicy(domainSeparator)})
+
+	time.Sleep(time.Until(am.roundTimingInfo.Offset) + time.Millisecond*250)
+	round := am.roundTimingInfo.RoundNumber() + 1
+	for _, bidder := range []struct {
+		client *BidderClient
+		amount int64
+	}{{alice, 3}, {bob, 5}} {
+		bid, err := bidder.client.Bid(ctx, big.NewInt(bidder.amount), common.Address{})
+		require.NoError(t, err)
+		am.bidCache.add(&ValidatedBid{
+			ExpressLaneController:  bid.ExpressLaneController,
+			Amount:                 bid.Amount,
+			Signature:              bid.Signature,
+			ChainId:                bid.ChainId,
+			AuctionContractAddress: bid.AuctionContractAddress,
+			Round:                  bid.Round,
+			Bidder:                 bidder.client.txOpts.From,
+		})
+	}
+	reservePrice, err := testSetup.expressLaneAuction.ReservePrice(&bind.CallOpts{Context: ctx})
+	require.NoError(t, err)
+	winner, firstPrice, secondPrice, err := am.EstimateResolution(ctx, round)
+	require.NoError(t, err)
+	require.Equal(t, bob.txOpts.From, winner)
+	require.Equal(t, big.NewInt(5), firstPrice)
+	require.Equal(t, reservePrice, secondPrice)
+
+	// The policy's outcome must be accepted by the auction contract
+	time.Sleep(am.roundTimingInfo.TimeTilNextRound() - am.roundTimingInfo.AuctionClosing + time.Second)
+	first, second, err := am.resolutionBids(am.bidCache.validBids(time.Now()))
+	require.NoError(t, err)
+	require.Nil(t, second)
+	tx, err := testSetup.expressLaneAuction.ResolveSingleBidAuction(
+		testSetup.accounts[0].txOpts,
+		express_lane_auctiongen.Bid{
+			ExpressLaneController: first.ExpressLaneController,
+			Amount:                first.Amount,
+			Signature:             first.Signature,
+		},
+	)
+	require.NoError(t, err)
+	receipt, err := bind.WaitMined(ctx, testSetup.backend.Client(), tx)
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+
+	it, err := testSetup.expressLaneAuction.FilterAuctionResolved(&bind.FilterOpts{Context: ctx}, nil, nil, nil)
+	require.NoError(t, err)
+	require.True(t, it.Next())
+	require.Equal(t, round, it.Event.Round)
+	require.Equal(t, winner, it.Event.FirstPriceExpressLaneController)
+	require.Equal(t, reservePrice, it.Event.Price)
+}
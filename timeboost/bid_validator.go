@@ -2,8 +2,11 @@ package timeboost
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math/big"
+	"net/http"
+	"runtime"
 	"sync"
 	"time"
 
@@ -34,12 +37,40 @@ type BidValidatorConfig struct {
 	// Timeout on polling for existence of each redis stream.
 	SequencerEndpoint      string `koanf:"sequencer-endpoint"`
 	AuctionContractAddress string `koanf:"auction-contract-address"`
+	// Bids from these bidder or express lane controller addresses are rejected.
+	BlockedAddresses         []string `koanf:"blocked-addresses" reload:"hot"`
+	SignatureRecoveryWorkers int      `koanf:"signature-recovery-workers"`
+	MaxFutureRounds          uint64   `koanf:"max-future-rounds"`
+	ReadOnlyAuctioneerStream bool     `koanf:"read-only-auctioneer-stream"`
+}
+
+func (c *BidValidatorConfig) Validate() error {
+	if c.SignatureRecoveryWorkers < 0 {
+		return fmt.Errorf("signature-recovery-workers option cannot be negative, got: %d", c.SignatureRecoveryWorkers)
+	}
+	for _, address := range c.BlockedAddresses {
+		if !common.IsHexAddress(address) {
+			return fmt.Errorf("invalid blocked address %q", address)
+		}
+	}
+	return nil
+}
+
+// isBlocked returns whether bids from the given address are to be rejected.
+func (c *BidValidatorConfig) isBlocked(address common.Address) bool {
+	for _, blocked := range c.BlockedAddresses {
+		if common.HexToAddress(blocked) == address {
+			return true
+		}
+	}
+	return false
 }
 
 var DefaultBidValidatorConfig = BidValidatorConfig{
-	Enable:         true,
-	RedisURL:       "",
-	ProducerConfig: pubsub.DefaultProducerConfig,
+	Enable:                   true,
+	RedisURL:                 "",
+	ProducerConfig:           pubsub.DefaultProducerConfig,
+	SignatureRecoveryWorkers: 0, // Defaults to the number of CPUs
 }
 
 var TestBidValidatorConfig = BidValidatorConfig{
@@ -54,15 +85,21 @@ func BidValidatorConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	pubsub.ProducerAddConfigAddOptions(prefix+".producer-config", f)
 	f.String(prefix+".sequencer-endpoint", DefaultAuctioneerServerConfig.SequencerEndpoint, "sequencer RPC endpoint")
 	f.String(prefix+".auction-contract-address", DefaultAuctioneerServerConfig.AuctionContractAddress, "express lane auction contract address")
+	f.StringSlice(prefix+".blocked-addresses", DefaultBidValidatorConfig.BlockedAddresses, "bidder or express lane controller addresses whose bids are rejected")
+	f.Int(prefix+".signature-recovery-workers", DefaultBidValidatorConfig.SignatureRecoveryWorkers, "maximum number of bid signatures recovered in parallel, 0 uses the number of CPUs")
+	f.Uint64(prefix+".max-future-rounds", DefaultBidValidatorConfig.MaxFutureRounds, "number of rounds past the upcoming round that bids are accepted for, 0 only accepts bids for the upcoming round")
+	f.Bool(prefix+".read-only-auctioneer-stream", DefaultBidValidatorConfig.ReadOnlyAuctioneerStream, "also queue validated bids on the redis stream consumed by read-only standby auctioneers")
 }
 
 type BidValidator struct {
 	stopwaiter.StopWaiter
 	sync.RWMutex
+	config                         BidValidatorConfigFetcher
 	chainId                        *big.Int
 	stack                          *node.Node
 	producerCfg                    *pubsub.ProducerConfig
 	producer                       *pubsub.Producer[*JsonValidatedBid, error]
+	readOnlyProducer               *pubsub.Producer[*JsonValidatedBid, error]
 	redisClient                    redis.UniversalClient
 	domainValue                    []byte
 	client                         *ethclient.Client
@@ -73,8 +110,10 @@ type BidValidator struct {
 	roundTimingInfo                RoundTimingInfo
 	reservePriceLock               sync.RWMutex
 	reservePrice                   *big.Int
-	bidsPerSenderInRound           map[common.Address]uint8
+	bidsPerSenderInRound           map[uint64]map[common.Address]uint8
 	maxBidsPerSenderInRound        uint8
+	maxFutureRounds                uint64
+	recoveryTokens                 chan struct{}
 }
 
 func NewBidValidator(
@@ -129,7 +168,13 @@ func NewBidValidator(
 		return nil, err
 	}
 
+	recoveryWorkers := cfg.SignatureRecoveryWorkers
+	if recoveryWorkers == 0 {
+		recoveryWorkers = runtime.NumCPU()
+	}
+
 	bidValidator := &BidValidator{
+		config:                         configFetcher,
 		chainId:                        chainId,
 		client:                         sequencerClient,
 		redisClient:                    redisClient,
@@ -141,9 +186,11 @@ func NewBidValidator(
 		roundTimingInfo:                *roundTimingInfo,
 		reservePrice:                   reservePrice,
 		domainValue:                    domainValue,
-		bidsPerSenderInRound:           make(map[common.Address]uint8),
+		bidsPerSenderInRound:           make(map[uint64]map[common.Address]uint8),
 		maxBidsPerSenderInRound:        5, // 5 max bids per sender address in a round.
+		maxFutureRounds:                cfg.MaxFutureRounds,
 		producerCfg:                    &cfg.ProducerConfig,
+		recoveryTokens:                 make(chan struct{}, recoveryWorkers),
 	}
 	api := &BidValidatorAPI{bidValidator}
 	valAPIs := []rpc.API{{
@@ -153,6 +200,7 @@ func NewBidValidator(
 		Public:    true,
 	}}
 	stack.RegisterAPIs(valAPIs)
+	stack.RegisterHandler("bid submission", BidSubmissionPath, &bidSubmissionHandler{bidValidator})
 	return bidValidator, nil
 }
 
@@ -184,6 +232,17 @@ func (bv *BidValidator) Initialize(ctx context.Context) error {
 		return fmt.Errorf("failed to init redis in bid validator: %w", err)
 	}
 	bv.producer = p
+	if bv.config().ReadOnlyAuctioneerStream {
+		if err := pubsub.CreateStream(ctx, readOnlyValidatedBidsRedisStream, bv.redisClient); err != nil {
+			return fmt.Errorf("creating read-only auctioneer redis stream: %w", err)
+		}
+		bv.readOnlyProducer, err = pubsub.NewProducer[*JsonValidatedBid, error](
+			bv.redisClient, readOnlyValidatedBidsRedisStream, bv.producerCfg,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to init read-only auctioneer redis producer in bid validator: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -193,6 +252,9 @@ func (bv *BidValidator) Start(ctx_in context.Context) {
 		log.Crit("Bid validator not yet initialized by calling Initialize(ctx)")
 	}
 	bv.producer.Start(ctx_in)
+	if bv.readOnlyProducer != nil {
+		bv.readOnlyProducer.Start(ctx_in)
+	}
 
 	// Thread to set reserve price and clear per-round map of bid count per account.
 	bv.StopWaiter.LaunchThread(func(ctx context.Context) {
@@ -222,9 +284,7 @@ func (bv *BidValidator) Start(ctx_in context.Context) {
 				bv.setReservePrice(rp)
 
 			case <-auctionCloseTicker.c:
-				bv.Lock()
-				bv.bidsPerSenderInRound = make(map[common.Address]uint8)
-				bv.Unlock()
+				bv.pruneBidCounts()
 			}
 		}
 	})
@@ -235,9 +295,15 @@ type BidValidatorAPI struct {
 }
 
 func (bv *BidValidatorAPI) SubmitBid(ctx context.Context, bid *JsonBid) error {
+	return bv.submitBid(ctx, bid)
+}
+
+// submitBid validates the bid and queues it for the auctioneer, it is shared by the RPC and HTTP bid submission endpoints
+func (bv *BidValidator) submitBid(ctx context.Context, bid *JsonBid) error {
 	start := time.Now()
 	receivedBidsCounter.Inc(1)
 	validatedBid, err := bv.validateBid(
+		ctx,
 		&Bid{
 			ChainId:                bid.ChainId.ToInt(),
 			ExpressLaneController:  bid.ExpressLaneController,
@@ -245,6 +311,8 @@ func (bv *BidValidatorAPI) SubmitBid(ctx context.Context, bid *JsonBid) error {
 			Round:                  uint64(bid.Round),
 			Amount:                 bid.Amount.ToInt(),
 			Signature:              bid.Signature,
+			Expiry:                 uint64(bid.Expiry),
+			ExpirySignature:        bid.ExpirySignature,
 		},
 		bv.auctionContract.BalanceOf,
 	)
@@ -255,11 +323,83 @@ func (bv *BidValidatorAPI) SubmitBid(ctx context.Context, bid *JsonBid) error {
 	log.Info("Validated bid", "bidder", validatedBid.Bidder.Hex(), "amount", validatedBid.Amount.String(), "round", validatedBid.Round, "elapsed", time.Since(start))
 	_, err = bv.producer.Produce(ctx, validatedBid)
 	if err != nil {
-		return err
+		// The bid is valid but couldn't be queued for the auctioneer, so the bidder may retry it
+		// without the attempt counting towards its bids in the round
+		bv.uncountBid(validatedBid.Bidder, validatedBid.Round)
+		return NewAuctioneerBusyError(err)
+	}
+	if bv.readOnlyProducer != nil {
+		if _, err := bv.readOnlyProducer.Produce(ctx, validatedBid); err != nil {
+			log.Warn("Could not queue validated bid for read-only auctioneers", "bidder", validatedBid.Bidder.Hex(), "round", validatedBid.Round, "err", err)
+		}
 	}
 	return nil
 }
 
+// uncountBid takes back a bid counted towards the bidder's bids in the round by validateBid, unless the round's
+// auction has closed since, as its counts are pruned then
+func (bv *BidValidator) uncountBid(bidder common.Address, round uint64) {
+	bv.Lock()
+	defer bv.Unlock()
+	if bv.isBidCountClosed(round) {
+		return
+	}
+	if numBids := bv.bidsPerSenderInRound[round][bidder]; numBids > 0 {
+		bv.bidsPerSenderInRound[round][bidder] = numBids - 1
+	}
+}
+
+// pruneBidCounts drops the bid counts of the rounds whose auction has closed.
+func (bv *BidValidator) pruneBidCounts() {
+	bv.Lock()
+	defer bv.Unlock()
+	for round := range bv.bidsPerSenderInRound {
+		if bv.isBidCountClosed(round) {
+			delete(bv.bidsPerSenderInRound, round)
+		}
+	}
+}
+
+// isBidCountClosed returns whether the auction of the round has closed, so bids for it are no longer counted.
+func (bv *BidValidator) isBidCountClosed(round uint64) bool {
+	upcomingRound := bv.roundTimingInfo.RoundNumber() + 1
+	return round < upcomingRound || (round == upcomingRound && bv.roundTimingInfo.isAuctionRoundClosed())
+}
+
+// BidSubmissionPath is the path of the bid validator's HTTP endpoint, which accepts bids POSTed as JSON
+// for bidders not using the auctioneer_submitBid RPC method.
+const BidSubmissionPath = "/bid"
+
+const maxBidSubmissionBodySize = 1 << 16
+
+type bidSubmissionHandler struct {
+	bv *BidValidator
+}
+
+// ServeHTTP responds with 200 if the bid was accepted, with 400 and the reason if it was rejected,
+// and with 503 if it was valid but couldn't be queued for the auctioneer.
+func (h *bidSubmissionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "bids must be submitted with POST", http.StatusMethodNotAllowed)
+		return
+	}
+	var bid JsonBid
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBidSubmissionBodySize)).Decode(&bid); err != nil {
+		http.Error(w, errors.Wrap(ErrMalformedData, err.Error()).Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.bv.submitBid(r.Context(), &bid); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrAuctioneerBusy) {
+			status = http.StatusServiceUnavailable
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
 func (bv *BidValidator) setReservePrice(p *big.Int) {
 	bv.reservePriceLock.Lock()
 	defer bv.reservePriceLock.Unlock()
@@ -272,7 +412,52 @@ func (bv *BidValidator) fetchReservePrice() *big.Int {
 	return bv.reservePrice
 }
 
+// isBlocked returns whether the address is in the currently configured blocklist.
+func (bv *BidValidator) isBlocked(address common.Address) bool {
+	if bv.config == nil {
+		return false
+	}
+	return bv.config().isBlocked(address)
+}
+
+// recoverableSignature checks the signature's length and returns a copy with the recovery ID adjusted for recovery.
+func recoverableSignature(signature []byte) ([]byte, error) {
+	if len(signature) != 65 {
+		return nil, errors.Wrap(ErrMalformedData, "signature length is not 65")
+	}
+	sigItem := make([]byte, len(signature))
+	copy(sigItem, signature)
+
+	// Signature verification expects the last byte of the signature to have 27 subtracted,
+	// as it represents the recovery ID. If the last byte is greater than or equal to 27, it indicates a recovery ID that hasn't been adjusted yet,
+	// it's needed for internal signature verification logic.
+	if sigItem[len(sigItem)-1] >= 27 {
+		sigItem[len(sigItem)-1] -= 27
+	}
+	return sigItem, nil
+}
+
+// recoverBidder recovers the signer of a bid, at most signature-recovery-workers recoveries run at the same time
+// so that a burst of bids doesn't starve the rest of the validator of CPU. It gives up waiting for a worker once
+// ctx is done.
+func (bv *BidValidator) recoverBidder(ctx context.Context, bidHash common.Hash, signature []byte) (common.Address, error) {
+	if bv.recoveryTokens != nil {
+		select {
+		case bv.recoveryTokens <- struct{}{}:
+		case <-ctx.Done():
+			return common.Address{}, ctx.Err()
+		}
+		defer func() { <-bv.recoveryTokens }()
+	}
+	pubkey, err := crypto.SigToPub(bidHash[:], signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pubkey), nil
+}
+
 func (bv *BidValidator) validateBid(
+	ctx context.Context,
 	bid *Bid,
 	balanceCheckerFn func(opts *bind.CallOpts, account common.Address) (*big.Int, error)) (*JsonValidatedBid, error) {
 	// Check basic integrity.
@@ -285,6 +470,9 @@ func (bv *BidValidator) validateBid(
 	if bid.ExpressLaneController == (common.Address{}) {
 		return nil, errors.Wrap(ErrMalformedData, "empty express lane controller address")
 	}
+	if bv.isBlocked(bid.ExpressLaneController) {
+		return nil, errors.Wrapf(ErrBidderBlocked, "express lane controller %s", bid.ExpressLaneController.Hex())
+	}
 	if bid.ChainId == nil {
 		return nil, errors.Wrap(ErrMalformedData, "empty chain id")
 	}
@@ -294,61 +482,94 @@ func (bv *BidValidator) validateBid(
 		return nil, errors.Wrapf(ErrWrongChainId, "can not auction for chain id: %d", bid.ChainId)
 	}
 
-	// Check if the bid is intended for upcoming round.
+	// Check if the bid is intended for upcoming round, or one of the future rounds bids are accepted for.
 	upcomingRound := bv.roundTimingInfo.RoundNumber() + 1
-	if bid.Round != upcomingRound {
+	if bid.Round < upcomingRound {
 		return nil, errors.Wrapf(ErrBadRoundNumber, "wanted %d, got %d", upcomingRound, bid.Round)
 	}
+	if latestRound := upcomingRound + bv.maxFutureRounds; bid.Round > latestRound {
+		return nil, errors.Wrapf(ErrRoundTooFarInFuture, "latest accepted round %d, got %d", latestRound, bid.Round)
+	}
 
 	// Check if the auction is closed.
-	if bv.roundTimingInfo.isAuctionRoundClosed() {
+	if bid.Round == upcomingRound && bv.roundTimingInfo.isAuctionRoundClosed() {
 		return nil, errors.Wrap(ErrBadRoundNumber, "auction is closed")
 	}
 
-	// Check bid is higher than or equal to reserve price.
-	if bid.Amount.Cmp(bv.reservePrice) == -1 {
-		return nil, errors.Wrapf(ErrReservePriceNotMet, "reserve price %s, bid %s", bv.reservePrice.String(), bid.Amount.String())
+	// Check if the bid has already expired.
+	if bid.Expiry != 0 && time.Now().Unix() >= int64(bid.Expiry) { // #nosec G115
+		return nil, errors.Wrapf(ErrBidExpired, "bid expired at %d", bid.Expiry)
 	}
 
-	// Validate the signature.
-	if len(bid.Signature) != 65 {
-		return nil, errors.Wrap(ErrMalformedData, "signature length is not 65")
+	// Check bid is higher than or equal to reserve price. The reserve price of a future round may still change
+	// until its reserve submission deadline, so the auctioneer checks bids for it when resolving its auction instead.
+	if reservePrice := bv.fetchReservePrice(); bid.Round == upcomingRound && bid.Amount.Cmp(reservePrice) == -1 {
+		return nil, errors.Wrapf(ErrReservePriceNotMet, "reserve price %s, bid %s", reservePrice.String(), bid.Amount.String())
 	}
 
-	// Recover the public key.
-	sigItem := make([]byte, len(bid.Signature))
-	copy(sigItem, bid.Signature)
-
-	// Signature verification expects the last byte of the signature to have 27 subtracted,
-	// as it represents the recovery ID. If the last byte is greater than or equal to 27, it indicates a recovery ID that hasn't been adjusted yet,
-	// it's needed for internal signature verification logic.
-	if sigItem[len(sigItem)-1] >= 27 {
-		sigItem[len(sigItem)-1] -= 27
+	// Validate the signature.
+	sigItem, err := recoverableSignature(bid.Signature)
+	if err != nil {
+		return nil, err
 	}
 
 	bidHash, err := bid.ToEIP712Hash(bv.auctionContractDomainSeparator)
 	if err != nil {
 		return nil, err
 	}
-	pubkey, err := crypto.SigToPub(bidHash[:], sigItem)
+	bidder, err := bv.recoverBidder(ctx, bidHash, sigItem)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
 	if err != nil {
 		return nil, ErrMalformedData
 	}
+	// The expiry isn't covered by the bid signature, so it is only accepted if the bidder signed it as well.
+	if bid.Expiry != 0 || len(bid.ExpirySignature) != 0 {
+		expirySig, err := recoverableSignature(bid.ExpirySignature)
+		if err != nil {
+			return nil, errors.Wrap(err, "expiry signature")
+		}
+		expiryHash, err := bid.ToExpiryEIP712Hash(bv.auctionContractDomainSeparator)
+		if err != nil {
+			return nil, err
+		}
+		expirySigner, err := bv.recoverBidder(ctx, expiryHash, expirySig)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if err != nil {
+			return nil, errors.Wrap(ErrMalformedData, "expiry signature")
+		}
+		if expirySigner != bidder {
+			return nil, errors.Wrapf(ErrWrongSignature, "expiry not signed by bidder %s", bidder.Hex())
+		}
+	}
+	// The auction may have closed while waiting for a signature recovery worker,
+	// so the bid mustn't be accepted into the producer for a round that is no longer being auctioned.
+	upcomingRound = bv.roundTimingInfo.RoundNumber() + 1
+	if bid.Round < upcomingRound || (bid.Round == upcomingRound && bv.roundTimingInfo.isAuctionRoundClosed()) {
+		return nil, errors.Wrap(ErrBadRoundNumber, "auction closed while recovering the bid signature")
+	}
 	// Check how many bids the bidder has sent in this round and cap according to a limit.
-	bidder := crypto.PubkeyToAddress(*pubkey)
+	if bv.isBlocked(bidder) {
+		return nil, errors.Wrapf(ErrBidderBlocked, "bidder %s", bidder.Hex())
+	}
 	bv.Lock()
-	numBids, ok := bv.bidsPerSenderInRound[bidder]
+	bidsPerSender, ok := bv.bidsPerSenderInRound[bid.Round]
 	if !ok {
-		bv.bidsPerSenderInRound[bidder] = 0
+		bidsPerSender = make(map[common.Address]uint8)
+		bv.bidsPerSenderInRound[bid.Round] = bidsPerSender
 	}
+	numBids := bidsPerSender[bidder]
 	if numBids >= bv.maxBidsPerSenderInRound {
 		bv.Unlock()
-		return nil, errors.Wrapf(ErrTooManyBids, "bidder %s has already sent the maximum allowed bids = %d in this round", bidder.Hex(), numBids)
+		return nil, errors.Wrapf(ErrTooManyBids, "bidder %s has already sent the maximum allowed bids = %d in round %d", bidder.Hex(), numBids, bid.Round)
 	}
-	bv.bidsPerSenderInRound[bidder]++
+	bidsPerSender[bidder]++
 	bv.Unlock()
 
-	depositBal, err := balanceCheckerFn(&bind.CallOpts{}, bidder)
+	depositBal, err := balanceCheckerFn(&bind.CallOpts{Context: ctx}, bidder)
 	if err != nil {
 		return nil, err
 	}
@@ -366,6 +587,7 @@ func (bv *BidValidator) validateBid(
 		AuctionContractAddress: bid.AuctionContractAddress,
 		Round:                  bid.Round,
 		Bidder:                 bidder,
+		Expiry:                 bid.Expiry,
 	}
 	return vb.ToJson(), nil
 }
@@ -1,8 +1,15 @@
 package timeboost
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"io"
+	"math"
 	"math/big"
+	"net/http"
+	"sync"
 	"testing"
 	"time"
 
@@ -11,11 +18,14 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/nitro/util/redisutil"
 )
 
 func TestBidValidator_validateBid(t *testing.T) {
 	t.Parallel()
-	setup := setupAuctionTest(t, context.Background())
+	ctx := context.Background()
+	setup := setupAuctionTest(t, ctx)
 	tests := []struct {
 		name          string
 		bid           *Bid
@@ -110,22 +120,71 @@ func TestBidValidator_validateBid(t *testing.T) {
 			reservePrice:            big.NewInt(2),
 			auctionContract:         setup.expressLaneAuction,
 			auctionContractAddr:     setup.expressLaneAuctionAddr,
-			bidsPerSenderInRound:    make(map[common.Address]uint8),
+			bidsPerSenderInRound:    make(map[uint64]map[common.Address]uint8),
 			maxBidsPerSenderInRound: 5,
 		}
 		t.Run(tt.name, func(t *testing.T) {
 			if tt.auctionClosed {
 				time.Sleep(time.Second * 3)
 			}
-			_, err := bv.validateBid(tt.bid, setup.expressLaneAuction.BalanceOf)
+			_, err := bv.validateBid(ctx, tt.bid, setup.expressLaneAuction.BalanceOf)
 			require.ErrorIs(t, err, tt.expectedErr)
 			require.Contains(t, err.Error(), tt.errMsg)
 		})
 	}
 }
 
+func TestBidValidator_validateBid_maxFutureRounds(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	setup := setupAuctionTest(t, ctx)
+	bv := BidValidator{
+		chainId: big.NewInt(1),
+		roundTimingInfo: RoundTimingInfo{
+			Offset:         time.Now().Add(-time.Second * 3),
+			Round:          10 * time.Second,
+			AuctionClosing: 5 * time.Second,
+		},
+		reservePrice:            big.NewInt(2),
+		auctionContract:         setup.expressLaneAuction,
+		auctionContractAddr:     setup.expressLaneAuctionAddr,
+		bidsPerSenderInRound:    make(map[uint64]map[common.Address]uint8),
+		maxBidsPerSenderInRound: 5,
+		maxFutureRounds:         2,
+	}
+	bidForRound := func(round uint64) *Bid {
+		return &Bid{
+			ExpressLaneController:  common.Address{'b'},
+			AuctionContractAddress: setup.expressLaneAuctionAddr,
+			ChainId:                big.NewInt(1),
+			Round:                  round,
+			Amount:                 big.NewInt(1),
+		}
+	}
+
+	// Bids for rounds within the window get past the round checks, to be rejected for the amount if for the
+	// upcoming round, whereas the reserve price of future rounds is only checked by the auctioneer
+	_, err := bv.validateBid(ctx, bidForRound(1), setup.expressLaneAuction.BalanceOf)
+	require.ErrorIs(t, err, ErrReservePriceNotMet)
+	for round := uint64(2); round <= 3; round++ {
+		_, err := bv.validateBid(ctx, bidForRound(round), setup.expressLaneAuction.BalanceOf)
+		require.ErrorIs(t, err, ErrMalformedData)
+	}
+	_, err = bv.validateBid(ctx, bidForRound(4), setup.expressLaneAuction.BalanceOf)
+	require.ErrorIs(t, err, ErrRoundTooFarInFuture)
+	require.Contains(t, err.Error(), "latest accepted round 3, got 4")
+	_, err = bv.validateBid(ctx, bidForRound(1000), setup.expressLaneAuction.BalanceOf)
+	require.ErrorIs(t, err, ErrRoundTooFarInFuture)
+
+	// Without a window only the upcoming round is accepted
+	bv.maxFutureRounds = 0
+	_, err = bv.validateBid(ctx, bidForRound(2), setup.expressLaneAuction.BalanceOf)
+	require.ErrorIs(t, err, ErrRoundTooFarInFuture)
+}
+
 func TestBidValidator_validateBid_perRoundBidLimitReached(t *testing.T) {
 	t.Parallel()
+	ctx := context.Background()
 	balanceCheckerFn := func(_ *bind.CallOpts, _ common.Address) (*big.Int, error) {
 		return big.NewInt(10), nil
 	}
@@ -138,7 +197,7 @@ func TestBidValidator_validateBid_perRoundBidLimitReached(t *testing.T) {
 			AuctionClosing: 45 * time.Second,
 		},
 		reservePrice:                   big.NewInt(2),
-		bidsPerSenderInRound:           make(map[common.Address]uint8),
+		bidsPerSenderInRound:           make(map[uint64]map[common.Address]uint8),
 		maxBidsPerSenderInRound:        5,
 		auctionContractAddr:            auctionContractAddr,
 		auctionContractDomainSeparator: common.Hash{},
@@ -162,12 +221,448 @@ func TestBidValidator_validateBid_perRoundBidLimitReached(t *testing.T) {
 
 	bid.Signature = signature
 	for i := 0; i < int(bv.maxBidsPerSenderInRound); i++ {
-		_, err := bv.validateBid(bid, balanceCheckerFn)
+		_, err := bv.validateBid(ctx, bid, balanceCheckerFn)
+		require.NoError(t, err)
+	}
+	_, err = bv.validateBid(ctx, bid, balanceCheckerFn)
+	require.ErrorIs(t, err, ErrTooManyBids)
+
+	// Bids for a future round are counted towards that round's limit only
+	bv.maxFutureRounds = 1
+	futureBid := *bid
+	futureBid.Round = 2
+	futureBidHash, err := futureBid.ToEIP712Hash(bv.auctionContractDomainSeparator)
+	require.NoError(t, err)
+	futureBid.Signature, err = crypto.Sign(futureBidHash[:], privateKey)
+	require.NoError(t, err)
+	for i := 0; i < int(bv.maxBidsPerSenderInRound); i++ {
+		_, err := bv.validateBid(ctx, &futureBid, balanceCheckerFn)
 		require.NoError(t, err)
 	}
-	_, err = bv.validateBid(bid, balanceCheckerFn)
+	_, err = bv.validateBid(ctx, &futureBid, balanceCheckerFn)
 	require.ErrorIs(t, err, ErrTooManyBids)
 
+	// Taking back a bid frees a slot in its own round only
+	bidder := crypto.PubkeyToAddress(privateKey.PublicKey)
+	bv.uncountBid(bidder, futureBid.Round)
+	require.Equal(t, uint8(5), bv.bidsPerSenderInRound[bid.Round][bidder])
+	require.Equal(t, uint8(4), bv.bidsPerSenderInRound[futureBid.Round][bidder])
+	_, err = bv.validateBid(ctx, bid, balanceCheckerFn)
+	require.ErrorIs(t, err, ErrTooManyBids)
+	_, err = bv.validateBid(ctx, &futureBid, balanceCheckerFn)
+	require.NoError(t, err)
+
+	// Once the upcoming round's auction closes only its counts are pruned
+	bv.roundTimingInfo.Offset = time.Now().Add(-50 * time.Second)
+	bv.pruneBidCounts()
+	require.NotContains(t, bv.bidsPerSenderInRound, bid.Round)
+	require.Equal(t, uint8(5), bv.bidsPerSenderInRound[futureBid.Round][bidder])
+}
+
+func TestBidValidator_validateBid_blockedAddresses(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	balanceCheckerFn := func(_ *bind.CallOpts, _ common.Address) (*big.Int, error) {
+		return big.NewInt(10), nil
+	}
+	auctionContractAddr := common.Address{'a'}
+	cfg := DefaultBidValidatorConfig
+	bv := BidValidator{
+		config:  func() *BidValidatorConfig { return &cfg },
+		chainId: big.NewInt(1),
+		roundTimingInfo: RoundTimingInfo{
+			Offset:         time.Now().Add(-time.Second),
+			Round:          time.Minute,
+			AuctionClosing: 45 * time.Second,
+		},
+		reservePrice:            big.NewInt(2),
+		bidsPerSenderInRound:    make(map[uint64]map[common.Address]uint8),
+		maxBidsPerSenderInRound: 5,
+		auctionContractAddr:     auctionContractAddr,
+	}
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	bidder := crypto.PubkeyToAddress(privateKey.PublicKey)
+	bid := &Bid{
+		ExpressLaneController:  common.Address{'b'},
+		AuctionContractAddress: auctionContractAddr,
+		ChainId:                big.NewInt(1),
+		Round:                  1,
+		Amount:                 big.NewInt(3),
+	}
+	bidHash, err := bid.ToEIP712Hash(bv.auctionContractDomainSeparator)
+	require.NoError(t, err)
+	bid.Signature, err = crypto.Sign(bidHash[:], privateKey)
+	require.NoError(t, err)
+
+	cfg.BlockedAddresses = []string{bidder.Hex()}
+	require.NoError(t, cfg.Validate())
+	_, err = bv.validateBid(ctx, bid, balanceCheckerFn)
+	require.ErrorIs(t, err, ErrBidderBlocked)
+	require.Empty(t, bv.bidsPerSenderInRound)
+
+	cfg.BlockedAddresses = []string{bid.ExpressLaneController.Hex()}
+	_, err = bv.validateBid(ctx, bid, balanceCheckerFn)
+	require.ErrorIs(t, err, ErrBidderBlocked)
+
+	// Removing the address from the blocklist takes effect without restarting the validator
+	cfg.BlockedAddresses = nil
+	validatedBid, err := bv.validateBid(ctx, bid, balanceCheckerFn)
+	require.NoError(t, err)
+	require.Equal(t, bidder, validatedBid.Bidder)
+
+	cfg.BlockedAddresses = []string{"not an address"}
+	require.Error(t, cfg.Validate())
+}
+
+func TestBidValidator_validateBid_typedDataSignature(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	setup := setupAuctionTest(t, ctx)
+	domainSeparator, err := setup.expressLaneAuction.DomainSeparator(&bind.CallOpts{Context: ctx})
+	require.NoError(t, err)
+	bv := BidValidator{
+		chainId: setup.chainId,
+		roundTimingInfo: RoundTimingInfo{
+			Offset:         time.Now().Add(-time.Second),
+			Round:          time.Minute,
+			AuctionClosing: 45 * time.Second,
+		},
+		reservePrice:                   big.NewInt(2),
+		bidsPerSenderInRound:           make(map[uint64]map[common.Address]uint8),
+		maxBidsPerSenderInRound:        5,
+		auctionContractAddr:            setup.expressLaneAuctionAddr,
+		auctionContractDomainSeparator: domainSeparator,
+	}
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := crypto.PubkeyToAddress(privateKey.PublicKey)
+	// Only the signer has a deposit, so a bid recovering to any other address is rejected
+	balanceCheckerFn := func(_ *bind.CallOpts, account common.Address) (*big.Int, error) {
+		if account == signer {
+			return big.NewInt(10), nil
+		}
+		return new(big.Int), nil
+	}
+
+	bid := &Bid{
+		ExpressLaneController:  common.Address{'b'},
+		AuctionContractAddress: setup.expressLaneAuctionAddr,
+		ChainId:                setup.chainId,
+		Round:                  1,
+		Amount:                 big.NewInt(3),
+	}
+	// The domain separator is fetched from the auction contract, binding the typed bid to the chain and contract
+	bidHash, err := bid.ToEIP712Hash(domainSeparator)
+	require.NoError(t, err)
+	bid.Signature, err = crypto.Sign(bidHash[:], privateKey)
+	require.NoError(t, err)
+	bid.Signature[64] += 27
+
+	validatedBid, err := bv.validateBid(ctx, bid, balanceCheckerFn)
+	require.NoError(t, err)
+	require.Equal(t, signer, validatedBid.Bidder)
+
+	tampered := *bid
+	tampered.Amount = big.NewInt(4)
+	_, err = bv.validateBid(ctx, &tampered, balanceCheckerFn)
+	require.ErrorIs(t, err, ErrNotDepositor)
+
+	// A bid signed for another auction contract doesn't recover to the signer
+	otherHash, err := bid.ToEIP712Hash(common.Hash{})
+	require.NoError(t, err)
+	tampered = *bid
+	tampered.Signature, err = crypto.Sign(otherHash[:], privateKey)
+	require.NoError(t, err)
+	_, err = bv.validateBid(ctx, &tampered, balanceCheckerFn)
+	require.ErrorIs(t, err, ErrNotDepositor)
+}
+
+func TestBidValidator_validateBid_expirySignature(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	balanceCheckerFn := func(_ *bind.CallOpts, _ common.Address) (*big.Int, error) {
+		return big.NewInt(10), nil
+	}
+	auctionContractAddr := common.Address{'a'}
+	bv := BidValidator{
+		chainId: big.NewInt(1),
+		roundTimingInfo: RoundTimingInfo{
+			Offset:         time.Now().Add(-time.Second),
+			Round:          time.Minute,
+			AuctionClosing: 45 * time.Second,
+		},
+		reservePrice:            big.NewInt(2),
+		bidsPerSenderInRound:    make(map[uint64]map[common.Address]uint8),
+		maxBidsPerSenderInRound: 10,
+		auctionContractAddr:     auctionContractAddr,
+	}
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sign := func(hash common.Hash, key *ecdsa.PrivateKey) []byte {
+		sig, err := crypto.Sign(hash[:], key)
+		require.NoError(t, err)
+		return sig
+	}
+	bid := &Bid{
+		ExpressLaneController:  common.Address{'b'},
+		AuctionContractAddress: auctionContractAddr,
+		ChainId:                big.NewInt(1),
+		Round:                  1,
+		Amount:                 big.NewInt(3),
+		Expiry:                 uint64(time.Now().Add(time.Minute).Unix()), // #nosec G115
+	}
+	bidHash, err := bid.ToEIP712Hash(bv.auctionContractDomainSeparator)
+	require.NoError(t, err)
+	bid.Signature = sign(bidHash, privateKey)
+	expiryHash, err := bid.ToExpiryEIP712Hash(bv.auctionContractDomainSeparator)
+	require.NoError(t, err)
+	bid.ExpirySignature = sign(expiryHash, privateKey)
+
+	validatedBid, err := bv.validateBid(ctx, bid, balanceCheckerFn)
+	require.NoError(t, err)
+	require.Equal(t, bid.Expiry, validatedBid.Expiry)
+
+	// Extending the expiry invalidates the expiry signature
+	tampered := *bid
+	tampered.Expiry += 60
+	_, err = bv.validateBid(ctx, &tampered, balanceCheckerFn)
+	require.ErrorIs(t, err, ErrWrongSignature)
+
+	// So does signing it with another key
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	tampered = *bid
+	tampered.ExpirySignature = sign(expiryHash, otherKey)
+	_, err = bv.validateBid(ctx, &tampered, balanceCheckerFn)
+	require.ErrorIs(t, err, ErrWrongSignature)
+
+	// An expiry without its signature is rejected
+	tampered = *bid
+	tampered.ExpirySignature = nil
+	_, err = bv.validateBid(ctx, &tampered, balanceCheckerFn)
+	require.ErrorIs(t, err, ErrMalformedData)
+
+	// As is an expiry signature left on a bid whose expiry was removed
+	tampered = *bid
+	tampered.Expiry = 0
+	_, err = bv.validateBid(ctx, &tampered, balanceCheckerFn)
+	require.ErrorIs(t, err, ErrWrongSignature)
+}
+
+// newRecoveryTestBidValidator returns a validator accepting bids for round 1 with the given number of signature
+// recovery workers, along with bids signed by distinct bidders and the expected bidder of each
+func newRecoveryTestBidValidator(t testing.TB, workers int, numBids int) (*BidValidator, []*Bid, []common.Address) {
+	auctionContractAddr := common.Address{'a'}
+	bv := &BidValidator{
+		chainId: big.NewInt(1),
+		roundTimingInfo: RoundTimingInfo{
+			Offset:         time.Now().Add(-time.Second),
+			Round:          time.Minute,
+			AuctionClosing: 45 * time.Second,
+		},
+		reservePrice:            big.NewInt(2),
+		bidsPerSenderInRound:    make(map[uint64]map[common.Address]uint8),
+		maxBidsPerSenderInRound: math.MaxUint8,
+		auctionContractAddr:     auctionContractAddr,
+		recoveryTokens:          make(chan struct{}, workers),
+	}
+	var bids []*Bid
+	var bidders []common.Address
+	for i := 0; i < numBids; i++ {
+		privateKey, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		bid := &Bid{
+			ExpressLaneController:  common.Address{'b'},
+			AuctionContractAddress: auctionContractAddr,
+			ChainId:                big.NewInt(1),
+			Round:                  1,
+			Amount:                 big.NewInt(3),
+		}
+		bidHash, err := bid.ToEIP712Hash(bv.auctionContractDomainSeparator)
+		require.NoError(t, err)
+		bid.Signature, err = crypto.Sign(bidHash[:], privateKey)
+		require.NoError(t, err)
+		bids = append(bids, bid)
+		bidders = append(bidders, crypto.PubkeyToAddress(privateKey.PublicKey))
+	}
+	return bv, bids, bidders
+}
+
+func TestBidValidator_validateBid_parallelSignatureRecovery(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	balanceCheckerFn := func(_ *bind.CallOpts, _ common.Address) (*big.Int, error) {
+		return big.NewInt(10), nil
+	}
+	const numBids = 64
+	bv, bids, bidders := newRecoveryTestBidValidator(t, 4, numBids)
+
+	var wg sync.WaitGroup
+	validated := make([]*JsonValidatedBid, numBids)
+	errs := make([]error, numBids)
+	for i := range bids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			validated[i], errs[i] = bv.validateBid(ctx, bids[i], balanceCheckerFn)
+		}()
+	}
+	wg.Wait()
+	for i := range bids {
+		require.NoError(t, errs[i])
+		require.Equal(t, bidders[i], validated[i].Bidder)
+		require.Equal(t, bids[i].Round, uint64(validated[i].Round))
+	}
+	require.Empty(t, bv.recoveryTokens)
+	require.Len(t, bv.bidsPerSenderInRound[1], numBids)
+}
+
+func TestBidValidator_validateBid_signatureRecoveryLimit(t *testing.T) {
+	t.Parallel()
+	balanceCheckerFn := func(_ *bind.CallOpts, _ common.Address) (*big.Int, error) {
+		return big.NewInt(10), nil
+	}
+	ctx := context.Background()
+	const workers = 2
+	bv, bids, bidders := newRecoveryTestBidValidator(t, workers, 1)
+
+	// With every worker busy, a bid waits for one to free up before its signature is recovered
+	for i := 0; i < workers; i++ {
+		bv.recoveryTokens <- struct{}{}
+	}
+	done := make(chan struct{})
+	var validated *JsonValidatedBid
+	var err error
+	go func() {
+		defer close(done)
+		validated, err = bv.validateBid(ctx, bids[0], balanceCheckerFn)
+	}()
+	require.Never(t, func() bool {
+		select {
+		case <-done:
+			return true
+		default:
+			return false
+		}
+	}, 200*time.Millisecond, 10*time.Millisecond)
+
+	<-bv.recoveryTokens
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("bid not validated after a signature recovery worker freed up")
+	}
+	require.NoError(t, err)
+	require.Equal(t, bidders[0], validated.Bidder)
+	require.Len(t, bv.recoveryTokens, workers-1)
+
+	// A bid waiting for a worker is given up on once its context is done
+	bv.recoveryTokens <- struct{}{}
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = bv.validateBid(cancelledCtx, bids[0], balanceCheckerFn)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Len(t, bv.recoveryTokens, workers)
+}
+
+func TestBidValidatorHttpBidSubmission(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	redisURL := redisutil.CreateTestRedis(ctx, t)
+	setup := setupAuctionTest(t, ctx)
+	bv, endpoint := setupBidValidator(t, ctx, redisURL, setup)
+	bc := setupBidderClient(t, ctx, setup.accounts[0], setup, endpoint)
+	require.NoError(t, bc.Deposit(ctx, big.NewInt(5)))
+
+	domainSeparator, err := setup.expressLaneAuction.DomainSeparator(&bind.CallOpts{Context: ctx})
+	require.NoError(t, err)
+	bid := &Bid{
+		ExpressLaneController:  setup.accounts[0].accountAddr,
+		AuctionContractAddress: setup.expressLaneAuctionAddr,
+		ChainId:                setup.chainId,
+		Round:                  bv.roundTimingInfo.RoundNumber() + 1,
+		Amount:                 big.NewInt(5),
+	}
+	bidHash, err := bid.ToEIP712Hash(domainSeparator)
+	require.NoError(t, err)
+	bid.Signature, err = crypto.Sign(bidHash[:], setup.accounts[0].privKey)
+	require.NoError(t, err)
+	bid.Signature[64] += 27
+
+	post := func(body []byte) (int, string) {
+		resp, err := http.Post(endpoint+BidSubmissionPath, "application/json", bytes.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		reason, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(reason)
+	}
+
+	body, err := json.Marshal(bid.ToJson())
+	require.NoError(t, err)
+	status, reason := post(body)
+	require.Equal(t, http.StatusOK, status, reason)
+
+	// A tampered bid no longer recovers to the depositor
+	tampered := *bid
+	tampered.Amount = big.NewInt(4)
+	body, err = json.Marshal(tampered.ToJson())
+	require.NoError(t, err)
+	status, reason = post(body)
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Contains(t, reason, ErrNotDepositor.Error())
+
+	status, reason = post([]byte("not a bid"))
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Contains(t, reason, ErrMalformedData.Error())
+}
+
+func TestBidValidatorBusySubmissionsNotCounted(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	redisURL := redisutil.CreateTestRedis(ctx, t)
+	setup := setupAuctionTest(t, ctx)
+	bv, endpoint := setupBidValidator(t, ctx, redisURL, setup)
+	bc := setupBidderClient(t, ctx, setup.accounts[0], setup, endpoint)
+	require.NoError(t, bc.Deposit(ctx, big.NewInt(5)))
+
+	domainSeparator, err := setup.expressLaneAuction.DomainSeparator(&bind.CallOpts{Context: ctx})
+	require.NoError(t, err)
+	bid := &Bid{
+		ExpressLaneController:  setup.accounts[0].accountAddr,
+		AuctionContractAddress: setup.expressLaneAuctionAddr,
+		ChainId:                setup.chainId,
+		Round:                  bv.roundTimingInfo.RoundNumber() + 1,
+		Amount:                 big.NewInt(5),
+	}
+	bidHash, err := bid.ToEIP712Hash(domainSeparator)
+	require.NoError(t, err)
+	bid.Signature, err = crypto.Sign(bidHash[:], setup.accounts[0].privKey)
+	require.NoError(t, err)
+	bid.Signature[64] += 27
+	bidsOfBidder := func() uint8 {
+		bv.Lock()
+		defer bv.Unlock()
+		return bv.bidsPerSenderInRound[bid.Round][setup.accounts[0].accountAddr]
+	}
+
+	// Valid bids that can't be queued for the auctioneer are retried by bidders, even past the per round limit
+	cancelledCtx, cancelSubmissions := context.WithCancel(ctx)
+	cancelSubmissions()
+	for i := 0; i <= int(bv.maxBidsPerSenderInRound); i++ {
+		err := bv.submitBid(cancelledCtx, bid.ToJson())
+		var busyErr *AuctioneerBusyError
+		require.ErrorAs(t, err, &busyErr)
+	}
+	require.Zero(t, bidsOfBidder())
+
+	// Only queued bids count towards the limit
+	require.NoError(t, bv.submitBid(ctx, bid.ToJson()))
+	require.Equal(t, uint8(1), bidsOfBidder())
 }
 
 func buildValidBid(t *testing.T, auctionContractAddr common.Address) *Bid {
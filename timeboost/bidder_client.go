@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/pflag"
@@ -33,16 +35,22 @@ type BidderClientConfig struct {
 	AuctionContractAddress string                   `koanf:"auction-contract-address"`
 	DepositGwei            int                      `koanf:"deposit-gwei"`
 	BidGwei                int                      `koanf:"bid-gwei"`
+	BidSubmitRetries       int                      `koanf:"bid-submit-retries"`
+	BidSubmitBackoff       time.Duration            `koanf:"bid-submit-backoff"`
 }
 
 var DefaultBidderClientConfig = BidderClientConfig{
 	ArbitrumNodeEndpoint: "http://localhost:8547",
 	BidValidatorEndpoint: "http://localhost:9372",
+	BidSubmitRetries:     0,
+	BidSubmitBackoff:     100 * time.Millisecond,
 }
 
 var TestBidderClientConfig = BidderClientConfig{
 	ArbitrumNodeEndpoint: "http://localhost:8547",
 	BidValidatorEndpoint: "http://localhost:9372",
+	BidSubmitRetries:     0,
+	BidSubmitBackoff:     10 * time.Millisecond,
 }
 
 func BidderClientConfigAddOptions(f *pflag.FlagSet) {
@@ -52,12 +60,16 @@ func BidderClientConfigAddOptions(f *pflag.FlagSet) {
 	f.String("auction-contract-address", DefaultBidderClientConfig.AuctionContractAddress, "express lane auction contract address")
 	f.Int("deposit-gwei", DefaultBidderClientConfig.DepositGwei, "deposit amount in gwei to take from bidder's account and send to auction contract")
 	f.Int("bid-gwei", DefaultBidderClientConfig.BidGwei, "bid amount in gwei, bidder must have already deposited enough into the auction contract")
+	f.Int("bid-submit-retries", DefaultBidderClientConfig.BidSubmitRetries, "number of times to retry submitting a bid the bid validator is too busy to accept, retries stop once the auction closes")
+	f.Duration("bid-submit-backoff", DefaultBidderClientConfig.BidSubmitBackoff, "time to wait before the first bid submission retry, doubling with each further retry")
 }
 
 type BidderClient struct {
 	stopwaiter.StopWaiter
+	config                 BidderClientConfigFetcher
 	chainId                *big.Int
 	auctionContractAddress common.Address
+	biddingTokenLock       sync.Mutex
 	biddingTokenAddress    common.Address
 	txOpts                 *bind.TransactOpts
 	client                 *ethclient.Client
@@ -124,6 +136,7 @@ func NewBidderClient(
 		return nil, err
 	}
 	return &BidderClient{
+		config:                 configFetcher,
 		chainId:                chainId,
 		auctionContractAddress: auctionContractAddr,
 		biddingTokenAddress:    biddingTokenAddr,
@@ -145,7 +158,11 @@ func (bd *BidderClient) Start(ctx_in context.Context) {
 // Deposit into the auction contract for the account configured by the BidderClient wallet.
 // Handles approving the auction contract to spend the erc20 on behalf of the account.
 func (bd *BidderClient) Deposit(ctx context.Context, amount *big.Int) error {
-	allowance, err := bd.biddingTokenContract.Allowance(&bind.CallOpts{
+	biddingTokenAddress, biddingTokenContract, err := bd.refreshBiddingToken(ctx)
+	if err != nil {
+		return err
+	}
+	allowance, err := biddingTokenContract.Allowance(&bind.CallOpts{
 		Context: ctx,
 	}, bd.txOpts.From, bd.auctionContractAddress)
 	if err != nil {
@@ -153,9 +170,9 @@ func (bd *BidderClient) Deposit(ctx context.Context, amount *big.Int) error {
 	}
 
 	if amount.Cmp(allowance) > 0 {
-		log.Info("Spend allowance of bidding token from auction contract is insufficient, increasing allowance", "from", bd.txOpts.From, "auctionContract", bd.auctionContractAddress, "biddingToken", bd.biddingTokenAddress, "amount", amount.Int64())
+		log.Info("Spend allowance of bidding token from auction contract is insufficient, increasing allowance", "from", bd.txOpts.From, "auctionContract", bd.auctionContractAddress, "biddingToken", biddingTokenAddress, "amount", amount.Int64())
 		//		defecit := arbmath.BigSub(allowance, amount)
-		tx, err := bd.biddingTokenContract.Approve(bd.txOpts, bd.auctionContractAddress, amount)
+		tx, err := biddingTokenContract.Approve(bd.txOpts, bd.auctionContractAddress, amount)
 		if err != nil {
 			return err
 		}
@@ -179,16 +196,127 @@ func (bd *BidderClient) Deposit(ctx context.Context, amount *big.Int) error {
 	if receipt.Status != types.ReceiptStatusSuccessful {
 		return errors.New("deposit failed")
 	}
-	return nil
+	return bd.confirmDeposit(receipt, amount)
+}
+
+// confirmDeposit checks that a mined deposit transaction credited the amount to the account configured by the
+// BidderClient wallet, by looking for the Deposit event the auction contract emits.
+func (bd *BidderClient) confirmDeposit(receipt *types.Receipt, amount *big.Int) error {
+	for _, l := range receipt.Logs {
+		if l.Address != bd.auctionContractAddress {
+			continue
+		}
+		event, err := bd.auctionContract.ParseDeposit(*l)
+		if err != nil {
+			continue
+		}
+		if event.Account == bd.txOpts.From && event.Amount.Cmp(amount) == 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("deposit transaction %v was mined but didn't credit %v to %v", receipt.TxHash, amount, bd.txOpts.From)
+}
+
+// BiddingToken returns the address of the erc20 token that the auction contract accepts for deposits.
+func (bd *BidderClient) BiddingToken() common.Address {
+	bd.biddingTokenLock.Lock()
+	defer bd.biddingTokenLock.Unlock()
+	return bd.biddingTokenAddress
+}
+
+// refreshBiddingToken re-reads the bidding token from the auction contract, so that approvals and
+// deposits keep targeting the right token if the contract is upgraded to use a different one.
+func (bd *BidderClient) refreshBiddingToken(ctx context.Context) (common.Address, *bindings.MockERC20, error) {
+	biddingTokenAddr, err := bd.auctionContract.BiddingToken(&bind.CallOpts{
+		Context: ctx,
+	})
+	if err != nil {
+		return common.Address{}, nil, errors.Wrap(err, "fetching bidding token")
+	}
+	bd.biddingTokenLock.Lock()
+	defer bd.biddingTokenLock.Unlock()
+	if biddingTokenAddr != bd.biddingTokenAddress {
+		biddingTokenContract, err := bindings.NewMockERC20(biddingTokenAddr, bd.client)
+		if err != nil {
+			return common.Address{}, nil, errors.Wrap(err, "creating bindings to bidding token contract")
+		}
+		log.Info("Bidding token of the auction contract changed", "auctionContract", bd.auctionContractAddress, "previous", bd.biddingTokenAddress, "new", biddingTokenAddr)
+		bd.biddingTokenAddress = biddingTokenAddr
+		bd.biddingTokenContract = biddingTokenContract
+	}
+	return bd.biddingTokenAddress, bd.biddingTokenContract, nil
 }
 
+// DepositBalance returns the balance deposited in the auction contract by the account configured by the BidderClient wallet.
+func (bd *BidderClient) DepositBalance(ctx context.Context) (*big.Int, error) {
+	return bd.auctionContract.BalanceOf(&bind.CallOpts{
+		Context: ctx,
+	}, bd.txOpts.From)
+}
+
+// ReservePrice returns the current reserve price of the auction contract, bids below it are rejected.
+// The reserve price is never below the contract's minimum reserve price, and equals it until the reserve price setter raises it.
+func (bd *BidderClient) ReservePrice(ctx context.Context) (*big.Int, error) {
+	return bd.auctionContract.ReservePrice(&bind.CallOpts{
+		Context: ctx,
+	})
+}
+
+type bidOptions struct {
+	skipDepositCheck bool
+	expiry           time.Time
+}
+
+// BidOption is a function that configures a single call to BidderClient.Bid.
+type BidOption func(*bidOptions)
+
+// WithExpiry makes the bid valid only if the auction is resolved before the given time.
+func WithExpiry(expiry time.Time) BidOption {
+	return func(o *bidOptions) {
+		o.expiry = expiry
+	}
+}
+
+// WithoutDepositCheck skips checking the bid amount against the on-chain deposit
+// balance before submitting, leaving it to the bid validator to reject under-funded bids.
+func WithoutDepositCheck() BidOption {
+	return func(o *bidOptions) {
+		o.skipDepositCheck = true
+	}
+}
+
+// Bid signs and submits a bid for the upcoming round on behalf of the wallet's account, which pays for the bid from its
+// deposit if it wins. The express lane controller, the wallet's account if zero, may be any other address: it is part of
+// the signed bid, so the signature is the bidder's authorization of that controller.
 func (bd *BidderClient) Bid(
-	ctx context.Context, amount *big.Int, expressLaneController common.Address,
+	ctx context.Context, amount *big.Int, expressLaneController common.Address, options ...BidOption,
 ) (*Bid, error) {
+	opts := &bidOptions{}
+	for _, o := range options {
+		o(opts)
+	}
 	if (expressLaneController == common.Address{}) {
 		expressLaneController = bd.txOpts.From
 	}
 
+	if !opts.skipDepositCheck {
+		depositBal, err := bd.DepositBalance(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "fetching deposit balance")
+		}
+		if depositBal.Cmp(amount) < 0 {
+			return nil, errors.Wrapf(ErrInsufficientDeposit, "deposit balance %s, bid amount %s", depositBal.String(), amount.String())
+		}
+	}
+
+	// The reserve price may still change before the bid is validated, so a low bid is only warned about
+	reservePrice, err := bd.ReservePrice(ctx)
+	if err != nil {
+		log.Warn("Could not fetch reserve price", "err", err)
+	} else if amount.Cmp(reservePrice) < 0 {
+		log.Warn("Bid amount is below the reserve price", "amount", amount, "reservePrice", reservePrice)
+	}
+
 	domainSeparator, err := bd.auctionContract.DomainSeparator(&bind.CallOpts{
 		Context: ctx,
 	})
@@ -202,6 +330,9 @@ func (bd *BidderClient) Bid(
 		Round:                  bd.roundTimingInfo.RoundNumber() + 1,
 		Amount:                 amount,
 	}
+	if !opts.expiry.IsZero() {
+		newBid.Expiry = uint64(opts.expiry.Unix()) // #nosec G115
+	}
 	bidHash, err := newBid.ToEIP712Hash(domainSeparator)
 	if err != nil {
 		return nil, err
@@ -215,13 +346,135 @@ func (bd *BidderClient) Bid(
 
 	newBid.Signature = sig
 
-	promise := bd.submitBid(newBid)
-	if _, err := promise.Await(ctx); err != nil {
+	if newBid.Expiry != 0 {
+		expiryHash, err := newBid.ToExpiryEIP712Hash(domainSeparator)
+		if err != nil {
+			return nil, err
+		}
+		expirySig, err := bd.signer(expiryHash.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		expirySig[64] += 27
+		newBid.ExpirySignature = expirySig
+	}
+
+	if err := bd.submitBidWithRetries(ctx, newBid); err != nil {
 		return nil, err
 	}
 	return newBid, nil
 }
 
+// AuctionResolution is the outcome of a round's auction as recorded by the AuctionResolved event.
+type AuctionResolution struct {
+	Round                 uint64
+	Winner                common.Address
+	ExpressLaneController common.Address
+	FirstPrice            *big.Int
+	Price                 *big.Int
+	BlockNumber           uint64
+}
+
+const resolutionPollInterval = time.Millisecond * 250
+
+// WaitForResolution blocks until the auction for the given round is resolved, returning immediately if it already
+// was, or until the context is done. Resolutions are found by polling for the AuctionResolved event.
+func (bd *BidderClient) WaitForResolution(ctx context.Context, round uint64) (AuctionResolution, error) {
+	ticker := time.NewTicker(resolutionPollInterval)
+	defer ticker.Stop()
+	var fromBlock uint64
+	for {
+		resolution, toBlock, err := bd.findResolution(ctx, round, fromBlock)
+		if err == nil {
+			if resolution != nil {
+				return *resolution, nil
+			}
+			fromBlock = toBlock + 1
+		} else if ctx.Err() == nil {
+			log.Warn("Could not look up auction resolution", "round", round, "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return AuctionResolution{}, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// findResolution searches blocks from fromBlock up to the latest one for the round's AuctionResolved event,
+// returning the resolution if found along with the last block searched.
+func (bd *BidderClient) findResolution(ctx context.Context, round, fromBlock uint64) (*AuctionResolution, uint64, error) {
+	toBlock, err := bd.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if toBlock < fromBlock {
+		return nil, fromBlock - 1, nil
+	}
+	it, err := bd.auctionContract.FilterAuctionResolved(&bind.FilterOpts{
+		Context: ctx,
+		Start:   fromBlock,
+		End:     &toBlock,
+	}, nil, []uint64{round}, nil)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "filtering auction resolutions")
+	}
+	defer it.Close()
+	for it.Next() {
+		return &AuctionResolution{
+			Round:                 it.Event.Round,
+			Winner:                it.Event.FirstPriceBidder,
+			ExpressLaneController: it.Event.FirstPriceExpressLaneController,
+			FirstPrice:            it.Event.FirstPriceAmount,
+			Price:                 it.Event.Price,
+			BlockNumber:           it.Event.Raw.BlockNumber,
+		}, toBlock, nil
+	}
+	if err := it.Error(); err != nil {
+		return nil, 0, errors.Wrap(err, "iterating auction resolutions")
+	}
+	return nil, toBlock, nil
+}
+
+// submitBidWithRetries submits the bid, retrying with exponential backoff while the bid validator reports
+// being busy, up to the configured number of retries and as long as the bid's auction hasn't closed.
+func (bd *BidderClient) submitBidWithRetries(ctx context.Context, bid *Bid) error {
+	config := bd.config()
+	backoff := config.BidSubmitBackoff
+	auctionClosed := func() bool {
+		return bd.roundTimingInfo.RoundNumber()+1 != bid.Round || bd.roundTimingInfo.isAuctionRoundClosed()
+	}
+	for attempt := 0; ; attempt++ {
+		_, err := bd.submitBid(bid).Await(ctx)
+		if err == nil || !isAuctioneerBusy(err) || attempt >= config.BidSubmitRetries {
+			return err
+		}
+		if auctionClosed() {
+			return errors.Wrapf(err, "auction for round %d closed while retrying bid submission", bid.Round)
+		}
+		log.Warn("Bid validator is busy, retrying bid submission", "round", bid.Round, "attempt", attempt+1, "backoff", backoff, "err", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		if auctionClosed() {
+			return errors.Wrapf(err, "auction for round %d closed while retrying bid submission", bid.Round)
+		}
+		backoff *= 2
+	}
+}
+
+// isAuctioneerBusy returns whether the error is the bid validator reporting it couldn't accept a valid bid.
+// Errors received over RPC are recognized by their JSON-RPC error code.
+func isAuctioneerBusy(err error) bool {
+	if errors.Is(err, ErrAuctioneerBusy) {
+		return true
+	}
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr) && rpcErr.ErrorCode() == AuctioneerBusyErrorCode
+}
+
 func (bd *BidderClient) submitBid(bid *Bid) containers.PromiseInterface[struct{}] {
 	return stopwaiter.LaunchPromiseThread[struct{}](bd, func(ctx context.Context) (struct{}, error) {
 		err := bd.auctioneerClient.CallContext(ctx, nil, "auctioneer_submitBid", bid.ToJson())
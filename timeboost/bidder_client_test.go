@@ -0,0 +1,297 @@
+package timeboost
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/solgen/go/express_lane_auctiongen"
+	"github.com/offchainlabs/nitro/timeboost/bindings"
+	"github.com/offchainlabs/nitro/util/redisutil"
+)
+
+func TestBidderClientBidExceedingDeposit(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	redisURL := redisutil.CreateTestRedis(ctx, t)
+	testSetup := setupAuctionTest(t, ctx)
+	_, endpoint := setupBidValidator(t, ctx, redisURL, testSetup)
+	bc := setupBidderClient(t, ctx, testSetup.accounts[0], testSetup, endpoint)
+	require.NoError(t, bc.Deposit(ctx, big.NewInt(5)))
+
+	depositBal, err := bc.DepositBalance(ctx)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(5), depositBal)
+
+	// The pre-check rejects the bid before it reaches the bid validator.
+	_, err = bc.Bid(ctx, big.NewInt(6), testSetup.accounts[0].txOpts.From)
+	require.ErrorIs(t, err, ErrInsufficientDeposit)
+
+	// Skipping the pre-check leaves the rejection to the bid validator.
+	_, err = bc.Bid(ctx, big.NewInt(6), testSetup.accounts[0].txOpts.From, WithoutDepositCheck())
+	require.ErrorContains(t, err, ErrInsufficientBalance.Error())
+
+	_, err = bc.Bid(ctx, big.NewInt(5), testSetup.accounts[0].txOpts.From)
+	require.NoError(t, err)
+}
+
+func TestBidderClientWaitForResolution(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	redisURL := redisutil.CreateTestRedis(ctx, t)
+	testSetup := setupAuctionTest(t, ctx)
+	_, endpoint := setupBidValidator(t, ctx, redisURL, testSetup)
+	alice := setupBidderClient(t, ctx, testSetup.accounts[1], testSetup, endpoint)
+	require.NoError(t, alice.Deposit(ctx, big.NewInt(10)))
+
+	// Wait for the first round to start so that the upcoming round can be resolved
+	time.Sleep(time.Until(alice.roundTimingInfo.Offset) + time.Millisecond*250)
+	bid, err := alice.Bid(ctx, big.NewInt(5), common.Address{})
+	require.NoError(t, err)
+
+	type result struct {
+		resolution AuctionResolution
+		err        error
+	}
+	resultChan := make(chan result, 1)
+	go func() {
+		resolution, err := alice.WaitForResolution(ctx, bid.Round)
+		resultChan <- result{resolution, err}
+	}()
+
+	// Resolve the auction on-chain within the auction closing window
+	time.Sleep(alice.roundTimingInfo.TimeTilNextRound() - alice.roundTimingInfo.AuctionClosing + time.Second)
+	select {
+	case <-resultChan:
+		t.Fatal("WaitForResolution returned before the auction was resolved")
+	default:
+	}
+	tx, err := testSetup.expressLaneAuction.ResolveSingleBidAuction(
+		testSetup.accounts[0].txOpts,
+		express_lane_auctiongen.Bid{
+			ExpressLaneController: bid.ExpressLaneController,
+			Amount:                bid.Amount,
+			Signature:             bid.Signature,
+		},
+	)
+	require.NoError(t, err)
+	receipt, err := bind.WaitMined(ctx, testSetup.backend.Client(), tx)
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+
+	var res result
+	select {
+	case res = <-resultChan:
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for the auction resolution")
+	}
+	require.NoError(t, res.err)
+	require.Equal(t, bid.Round, res.resolution.Round)
+	require.Equal(t, alice.txOpts.From, res.resolution.Winner)
+	require.Equal(t, alice.txOpts.From, res.resolution.ExpressLaneController)
+	require.Equal(t, big.NewInt(5), res.resolution.FirstPrice)
+	require.Equal(t, receipt.BlockNumber.Uint64(), res.resolution.BlockNumber)
+
+	// An already resolved round is returned right away
+	shortCtx, shortCancel := context.WithTimeout(ctx, resolutionPollInterval/2)
+	defer shortCancel()
+	resolution, err := alice.WaitForResolution(shortCtx, bid.Round)
+	require.NoError(t, err)
+	require.Equal(t, res.resolution, resolution)
+
+	// A round that isn't resolved waits until the context is done
+	_, err = alice.WaitForResolution(shortCtx, bid.Round+1)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestBidderClientBidForOtherController(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	redisURL := redisutil.CreateTestRedis(ctx, t)
+	testSetup := setupAuctionTest(t, ctx)
+	bv, endpoint := setupBidValidator(t, ctx, redisURL, testSetup)
+	bidder := testSetup.accounts[0]
+	controller := testSetup.accounts[1].accountAddr
+	bc := setupBidderClient(t, ctx, bidder, testSetup, endpoint)
+	require.NoError(t, bc.Deposit(ctx, big.NewInt(5)))
+
+	bid, err := bc.Bid(ctx, big.NewInt(5), controller)
+	require.NoError(t, err)
+	require.Equal(t, controller, bid.ExpressLaneController)
+
+	// The validated bid is attributed to the signer, which pays for it, with the nominated controller
+	validatedBid, err := bv.validateBid(ctx, bid, bv.auctionContract.BalanceOf)
+	require.NoError(t, err)
+	require.Equal(t, bidder.accountAddr, validatedBid.Bidder)
+	require.Equal(t, controller, validatedBid.ExpressLaneController)
+
+	// Nominating another controller with the bidder's signature recovers to an unrelated address without a deposit
+	tampered := *bid
+	tampered.ExpressLaneController = testSetup.accounts[2].accountAddr
+	_, err = bv.validateBid(ctx, &tampered, bv.auctionContract.BalanceOf)
+	require.ErrorIs(t, err, ErrNotDepositor)
+}
+
+func TestBidderClientAdaptsToBiddingToken(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	redisURL := redisutil.CreateTestRedis(ctx, t)
+	testSetup := setupAuctionTest(t, ctx)
+	_, endpoint := setupBidValidator(t, ctx, redisURL, testSetup)
+	bc := setupBidderClient(t, ctx, testSetup.accounts[0], testSetup, endpoint)
+
+	// The token is read from the contract at startup
+	require.Equal(t, testSetup.erc20Addr, bc.BiddingToken())
+
+	// Simulate the client holding a token other than the one the contract reports, e.g. after a contract upgrade
+	otherTokenAddr, tx, otherToken, err := bindings.DeployMockERC20(testSetup.accounts[0].txOpts, testSetup.backend.Client())
+	require.NoError(t, err)
+	_, err = bind.WaitMined(ctx, testSetup.backend.Client(), tx)
+	require.NoError(t, err)
+	bc.biddingTokenAddress = otherTokenAddr
+	bc.biddingTokenContract = otherToken
+
+	// Deposits target the token reported by the contract
+	require.NoError(t, bc.Deposit(ctx, big.NewInt(5)))
+	require.Equal(t, testSetup.erc20Addr, bc.BiddingToken())
+	depositBal, err := bc.DepositBalance(ctx)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(5), depositBal)
+	tokenBal, err := testSetup.erc20Contract.BalanceOf(&bind.CallOpts{Context: ctx}, testSetup.accounts[0].accountAddr)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(95), tokenBal)
+}
+
+func TestBidderClientReservePrice(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	redisURL := redisutil.CreateTestRedis(ctx, t)
+	testSetup := setupAuctionTest(t, ctx)
+	_, endpoint := setupBidValidator(t, ctx, redisURL, testSetup)
+	bc := setupBidderClient(t, ctx, testSetup.accounts[0], testSetup, endpoint)
+
+	// Until it is set, the reserve price is the minimum reserve price
+	minReservePrice, err := testSetup.expressLaneAuction.MinReservePrice(&bind.CallOpts{Context: ctx})
+	require.NoError(t, err)
+	reservePrice, err := bc.ReservePrice(ctx)
+	require.NoError(t, err)
+	require.Equal(t, minReservePrice, reservePrice)
+
+	// The first round hasn't started yet, so this isn't within the reserve blackout period
+	tx, err := testSetup.expressLaneAuction.SetReservePrice(testSetup.accounts[0].txOpts, big.NewInt(5))
+	require.NoError(t, err)
+	_, err = bind.WaitMined(ctx, testSetup.backend.Client(), tx)
+	require.NoError(t, err)
+	reservePrice, err = bc.ReservePrice(ctx)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(5), reservePrice)
+}
+
+func TestBidderClientConfirmDeposit(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	redisURL := redisutil.CreateTestRedis(ctx, t)
+	testSetup := setupAuctionTest(t, ctx)
+	_, endpoint := setupBidValidator(t, ctx, redisURL, testSetup)
+	bc := setupBidderClient(t, ctx, testSetup.accounts[0], testSetup, endpoint)
+
+	tx, err := testSetup.expressLaneAuction.Deposit(testSetup.accounts[0].txOpts, big.NewInt(5))
+	require.NoError(t, err)
+	receipt, err := bind.WaitMined(ctx, testSetup.backend.Client(), tx)
+	require.NoError(t, err)
+	require.NoError(t, bc.confirmDeposit(receipt, big.NewInt(5)))
+
+	// A deposit that credited a different amount isn't confirmed
+	require.Error(t, bc.confirmDeposit(receipt, big.NewInt(6)))
+
+	// Neither is a successful transaction that didn't deposit anything
+	tx, err = testSetup.erc20Contract.Approve(testSetup.accounts[0].txOpts, testSetup.expressLaneAuctionAddr, big.NewInt(5))
+	require.NoError(t, err)
+	receipt, err = bind.WaitMined(ctx, testSetup.backend.Client(), tx)
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+	require.Error(t, bc.confirmDeposit(receipt, big.NewInt(5)))
+}
+
+// busyBidValidator reports being busy for the first busyAttempts bid submissions, then fails failedAttempts
+// submissions, then accepts bids
+type busyBidValidator struct {
+	busyAttempts int
+	// Attempts following the busy ones that fail with an error only mentioning the busy error
+	failedAttempts int
+	attempts       int
+}
+
+func (b *busyBidValidator) SubmitBid(ctx context.Context, bid *JsonBid) error {
+	b.attempts++
+	if b.attempts <= b.busyAttempts {
+		return NewAuctioneerBusyError(errors.New("stream unavailable"))
+	}
+	if b.attempts <= b.busyAttempts+b.failedAttempts {
+		return errors.New(ErrAuctioneerBusy.Error())
+	}
+	return nil
+}
+
+func TestBidderClientRetriesBusyBidSubmission(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	redisURL := redisutil.CreateTestRedis(ctx, t)
+	testSetup := setupAuctionTest(t, ctx)
+	_, endpoint := setupBidValidator(t, ctx, redisURL, testSetup)
+	bc := setupBidderClient(t, ctx, testSetup.accounts[0], testSetup, endpoint)
+	require.NoError(t, bc.Deposit(ctx, big.NewInt(5)))
+
+	validator := &busyBidValidator{busyAttempts: 1}
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName(AuctioneerNamespace, validator))
+	defer server.Stop()
+	bc.auctioneerClient = rpc.DialInProc(server)
+	config := TestBidderClientConfig
+	bc.config = func() *BidderClientConfig { return &config }
+
+	// Without retries the busy error is returned
+	_, err := bc.Bid(ctx, big.NewInt(5), common.Address{})
+	require.ErrorContains(t, err, ErrAuctioneerBusy.Error())
+	require.Equal(t, 1, validator.attempts)
+
+	// A retry after the validator stops being busy succeeds
+	config.BidSubmitRetries = 2
+	validator.attempts = 0
+	_, err = bc.Bid(ctx, big.NewInt(5), common.Address{})
+	require.NoError(t, err)
+	require.Equal(t, 2, validator.attempts)
+
+	// Other errors aren't retried, even if their message mentions the busy error
+	validator.attempts = 0
+	validator.busyAttempts = 0
+	validator.failedAttempts = 1
+	_, err = bc.Bid(ctx, big.NewInt(5), common.Address{})
+	require.ErrorContains(t, err, ErrAuctioneerBusy.Error())
+	require.Equal(t, 1, validator.attempts)
+	validator.busyAttempts = 1
+	validator.failedAttempts = 0
+
+	// Bids for an auction that has closed aren't retried
+	validator.attempts = 0
+	bid := &Bid{Round: bc.roundTimingInfo.RoundNumber()}
+	err = bc.submitBidWithRetries(ctx, bid)
+	require.ErrorContains(t, err, ErrAuctioneerBusy.Error())
+	require.Equal(t, 1, validator.attempts)
+}
@@ -0,0 +1,113 @@
+// Copyright 2025, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package timeboost
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/broadcaster/message"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+)
+
+// Number of rounds before the latest one that the tracker keeps controllers for.
+const trackedControllerRounds = 64
+
+// Number of feed messages buffered for the tracker, so that feed clients don't wait on it.
+const controllerTrackerQueueSize = 16
+
+// ExpressLaneControllerTracker keeps the express lane controllers of recent rounds as announced by the auction
+// results and control transfers the sequencer signs and broadcasts on its feed, for nodes that follow the feed rather
+// than the auction contract. Feed clients drop the messages rather than wait for the tracker when its queues are full.
+type ExpressLaneControllerTracker struct {
+	stopwaiter.StopWaiter
+	mutex               sync.RWMutex
+	controllers         map[uint64]common.Address
+	latestRound         uint64
+	auctionResults      chan *message.AuctionResultMessage
+	controllerTransfers chan *message.ControllerTransferMessage
+}
+
+func NewExpressLaneControllerTracker() *ExpressLaneControllerTracker {
+	return &ExpressLaneControllerTracker{
+		controllers:         make(map[uint64]common.Address),
+		auctionResults:      make(chan *message.AuctionResultMessage, controllerTrackerQueueSize),
+		controllerTransfers: make(chan *message.ControllerTransferMessage, controllerTrackerQueueSize),
+	}
+}
+
+// AuctionResultListener returns the channel feed clients send the auction results they receive to.
+func (t *ExpressLaneControllerTracker) AuctionResultListener() chan<- *message.AuctionResultMessage {
+	return t.auctionResults
+}
+
+// ControllerTransferListener returns the channel feed clients send the control transfers they receive to.
+func (t *ExpressLaneControllerTracker) ControllerTransferListener() chan<- *message.ControllerTransferMessage {
+	return t.controllerTransfers
+}
+
+// Start applies the feed messages sent to the tracker's listeners until the context is done.
+func (t *ExpressLaneControllerTracker) Start(ctxIn context.Context) {
+	t.StopWaiter.Start(ctxIn, t)
+	t.LaunchThread(func(ctx context.Context) {
+		for {
+			select {
+			case result := <-t.auctionResults:
+				t.RecordAuctionResult(result.Round, result.Winner)
+			case transfer := <-t.controllerTransfers:
+				t.RecordControllerTransfer(transfer.Round, transfer.PreviousController, transfer.NewController)
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
+// RecordAuctionResult sets the controller of the given round, forgetting rounds too far behind the latest one.
+func (t *ExpressLaneControllerTracker) RecordAuctionResult(round uint64, controller common.Address) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.setController(round, controller)
+}
+
+// RecordControllerTransfer hands the control of the given round over to the new controller. The transfer applies even
+// if the previous controller isn't the tracked one, as the auction result may have been missed.
+func (t *ExpressLaneControllerTracker) RecordControllerTransfer(round uint64, previous, controller common.Address) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if tracked, ok := t.controllers[round]; ok && tracked != previous {
+		log.Warn("Express lane control transfer doesn't match the tracked controller", "round", round, "tracked", tracked, "previous", previous, "new", controller)
+	}
+	t.setController(round, controller)
+}
+
+// setController must be called with the mutex held.
+func (t *ExpressLaneControllerTracker) setController(round uint64, controller common.Address) {
+	if round+trackedControllerRounds <= t.latestRound {
+		return
+	}
+	t.controllers[round] = controller
+	if round > t.latestRound {
+		t.latestRound = round
+		for tracked := range t.controllers {
+			if tracked+trackedControllerRounds <= round {
+				delete(t.controllers, tracked)
+			}
+		}
+	}
+}
+
+// Controller returns the express lane controller of the given round, if one was announced.
+func (t *ExpressLaneControllerTracker) Controller(round uint64) (common.Address, bool) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	controller, ok := t.controllers[round]
+	if !ok || controller == (common.Address{}) {
+		return common.Address{}, false
+	}
+	return controller, true
+}
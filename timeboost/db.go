@@ -1,6 +1,8 @@
 package timeboost
 
 import (
+	"context"
+	"database/sql"
 	"encoding/hex"
 	"fmt"
 	"io/fs"
@@ -106,15 +108,14 @@ func executeSchema(db *sqlx.DB, schema string, version int) error {
 	return tx.Commit()
 }
 
-func (d *SqliteDatabase) InsertBid(b *ValidatedBid) error {
-	d.lock.Lock()
-	defer d.lock.Unlock()
-	query := `INSERT INTO Bids (
-        ChainID, Bidder, ExpressLaneController, AuctionContractAddress, Round, Amount, Signature
+const insertBidQuery = `INSERT INTO Bids (
+        ChainID, Bidder, ExpressLaneController, AuctionContractAddress, Round, Amount, Signature, Expiry
     ) VALUES (
-        :ChainID, :Bidder, :ExpressLaneController, :AuctionContractAddress, :Round, :Amount, :Signature
+        :ChainID, :Bidder, :ExpressLaneController, :AuctionContractAddress, :Round, :Amount, :Signature, :Expiry
     )`
-	params := map[string]interface{}{
+
+func insertBidParams(b *ValidatedBid) map[string]interface{} {
+	return map[string]interface{}{
 		"ChainID":                b.ChainId.String(),
 		"Bidder":                 b.Bidder.Hex(),
 		"ExpressLaneController":  b.ExpressLaneController.Hex(),
@@ -122,14 +123,44 @@ func (d *SqliteDatabase) InsertBid(b *ValidatedBid) error {
 		"Round":                  b.Round,
 		"Amount":                 b.Amount.String(),
 		"Signature":              hex.EncodeToString(b.Signature),
+		"Expiry":                 b.Expiry,
 	}
-	_, err := d.sqlDB.NamedExec(query, params)
+}
+
+func (d *SqliteDatabase) InsertBid(b *ValidatedBid) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	_, err := d.sqlDB.NamedExec(insertBidQuery, insertBidParams(b))
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// InsertBids inserts all the given bids in a single transaction, so either all of them are inserted or none are.
+func (d *SqliteDatabase) InsertBids(bids []*ValidatedBid) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	tx, err := d.sqlDB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback() // No-op if the transaction was committed
+	}()
+	stmt, err := tx.PrepareNamed(insertBidQuery)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, b := range bids {
+		if _, err := stmt.Exec(insertBidParams(b)); err != nil {
+			return fmt.Errorf("failed to insert bid for round %d from bidder %s: %w", b.Round, b.Bidder.Hex(), err)
+		}
+	}
+	return tx.Commit()
+}
+
 func (d *SqliteDatabase) GetBids(maxDbRows int) ([]*SqliteDatabaseBid, uint64, error) {
 	d.lock.Lock()
 	defer d.lock.Unlock()
@@ -139,14 +170,19 @@ func (d *SqliteDatabase) GetBids(maxDbRows int) ([]*SqliteDatabaseBid, uint64, e
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to fetch maxRound from bids: %w", err)
 	}
+	// Bids that were already uploaded but are retained locally shouldn't be uploaded again
+	var uploadedRound uint64
+	if err := d.sqlDB.Get(&uploadedRound, "SELECT FlagValue FROM Flags WHERE FlagName = 'UploadedRound'"); err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch uploadedRound from flags: %w", err)
+	}
 	var sqlDBbids []*SqliteDatabaseBid
 	if maxDbRows == 0 {
-		if err := d.sqlDB.Select(&sqlDBbids, "SELECT * FROM Bids WHERE Round < ? ORDER BY Round ASC", maxRound); err != nil {
+		if err := d.sqlDB.Select(&sqlDBbids, "SELECT * FROM Bids WHERE Round >= ? AND Round < ? ORDER BY Round ASC", uploadedRound, maxRound); err != nil {
 			return nil, 0, err
 		}
 		return sqlDBbids, maxRound, nil
 	}
-	if err := d.sqlDB.Select(&sqlDBbids, "SELECT * FROM Bids WHERE Round < ? ORDER BY Round ASC LIMIT ?", maxRound, maxDbRows); err != nil {
+	if err := d.sqlDB.Select(&sqlDBbids, "SELECT * FROM Bids WHERE Round >= ? AND Round < ? ORDER BY Round ASC LIMIT ?", uploadedRound, maxRound, maxDbRows); err != nil {
 		return nil, 0, err
 	}
 	// We should return contiguous set of bids
@@ -160,6 +196,51 @@ func (d *SqliteDatabase) GetBids(maxDbRows int) ([]*SqliteDatabaseBid, uint64, e
 	return nil, 0, nil
 }
 
+// GetBidsOfRound returns the bids of the given round that are still in the database.
+func (d *SqliteDatabase) GetBidsOfRound(round uint64) ([]*SqliteDatabaseBid, error) {
+	return d.GetBidsOfRounds(round, round)
+}
+
+// GetBidsOfRounds returns the bids of the rounds between fromRound and toRound, inclusive, that are still in the database.
+func (d *SqliteDatabase) GetBidsOfRounds(fromRound, toRound uint64) ([]*SqliteDatabaseBid, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	var sqlDBbids []*SqliteDatabaseBid
+	if err := d.sqlDB.Select(&sqlDBbids, "SELECT * FROM Bids WHERE Round >= ? AND Round <= ? ORDER BY Round ASC, Id ASC", fromRound, toRound); err != nil {
+		return nil, err
+	}
+	return sqlDBbids, nil
+}
+
+// RoundsWithBids returns the distinct rounds between fromRound and toRound, inclusive, that have bids in the database.
+func (d *SqliteDatabase) RoundsWithBids(fromRound, toRound uint64) ([]uint64, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	var rounds []uint64
+	if err := d.sqlDB.Select(&rounds, "SELECT DISTINCT Round FROM Bids WHERE Round >= ? AND Round <= ? ORDER BY Round ASC", fromRound, toRound); err != nil {
+		return nil, fmt.Errorf("failed to fetch rounds with bids: %w", err)
+	}
+	return rounds, nil
+}
+
+// MarkBidsUploaded records that all bids of rounds lower than round have been persisted to s3,
+// so that GetBids doesn't return them again while they're retained locally.
+func (d *SqliteDatabase) MarkBidsUploaded(round uint64) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	_, err := d.sqlDB.Exec("UPDATE Flags SET FlagValue = MAX(FlagValue, ?) WHERE FlagName = 'UploadedRound'", round)
+	return err
+}
+
+// UploadedRound returns the round below which all bids have been persisted to s3.
+func (d *SqliteDatabase) UploadedRound() (uint64, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	var uploadedRound uint64
+	err := d.sqlDB.Get(&uploadedRound, "SELECT FlagValue FROM Flags WHERE FlagName = 'UploadedRound'")
+	return uploadedRound, err
+}
+
 func (d *SqliteDatabase) DeleteBids(round uint64) error {
 	d.lock.Lock()
 	defer d.lock.Unlock()
@@ -167,3 +248,66 @@ func (d *SqliteDatabase) DeleteBids(round uint64) error {
 	_, err := d.sqlDB.Exec(query, round)
 	return err
 }
+
+// DeleteUploadedBids marks all bids of rounds lower than round as uploaded and deletes them in a single
+// transaction, so that either both take effect or neither does. A non-zero batchSize bounds the number of
+// rows removed by each DELETE statement, the lowest ids going first, which keeps the statements small when
+// a large upload is cleaned up.
+func (d *SqliteDatabase) DeleteUploadedBids(round uint64, batchSize int) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	tx, err := d.sqlDB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback() // No-op if the transaction was committed
+	}()
+	if _, err := tx.Exec("UPDATE Flags SET FlagValue = MAX(FlagValue, ?) WHERE FlagName = 'UploadedRound'", round); err != nil {
+		return fmt.Errorf("failed to mark bids of rounds lower than %d as uploaded: %w", round, err)
+	}
+	if batchSize <= 0 {
+		if _, err := tx.Exec("DELETE FROM Bids WHERE Round < ?", round); err != nil {
+			return fmt.Errorf("failed to delete bids of rounds lower than %d: %w", round, err)
+		}
+		return tx.Commit()
+	}
+	for {
+		var maxId sql.NullInt64
+		if err := tx.Get(&maxId, "SELECT MAX(Id) FROM (SELECT Id FROM Bids WHERE Round < ? ORDER BY Id ASC LIMIT ?)", round, batchSize); err != nil {
+			return fmt.Errorf("failed to fetch next batch of bids to delete: %w", err)
+		}
+		if !maxId.Valid {
+			return tx.Commit()
+		}
+		if _, err := tx.Exec("DELETE FROM Bids WHERE Round < ? AND Id <= ?", round, maxId.Int64); err != nil {
+			return fmt.Errorf("failed to delete bids of rounds lower than %d up to id %d: %w", round, maxId.Int64, err)
+		}
+	}
+}
+
+// Maintain reclaims the space left behind by deleted bids and refreshes the statistics used by the query planner.
+// It is serialized with all other database operations so it is safe to call while bids are being inserted.
+func (d *SqliteDatabase) Maintain(ctx context.Context) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if _, err := d.sqlDB.ExecContext(ctx, "VACUUM"); err != nil {
+		return fmt.Errorf("failed to vacuum bids database: %w", err)
+	}
+	if _, err := d.sqlDB.ExecContext(ctx, "ANALYZE"); err != nil {
+		return fmt.Errorf("failed to analyze bids database: %w", err)
+	}
+	return nil
+}
+
+// Stats returns aggregate statistics of the bids currently stored in the database.
+// The earliest and latest rounds are zero if there are no bids.
+func (d *SqliteDatabase) Stats() (totalBids int, distinctRounds int, earliestRound, latestRound uint64, err error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	query := `SELECT COUNT(*), COUNT(DISTINCT Round), COALESCE(MIN(Round), 0), COALESCE(MAX(Round), 0) FROM Bids`
+	if err = d.sqlDB.QueryRow(query).Scan(&totalBids, &distinctRounds, &earliestRound, &latestRound); err != nil {
+		return 0, 0, 0, 0, fmt.Errorf("failed to fetch bids database stats: %w", err)
+	}
+	return totalBids, distinctRounds, earliestRound, latestRound, nil
+}
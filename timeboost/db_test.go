@@ -1,8 +1,11 @@
 package timeboost
 
 import (
+	"context"
 	"encoding/hex"
+	"errors"
 	"math/big"
+	"sync"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -90,6 +93,7 @@ func TestInsertBids(t *testing.T) {
 			bid.Round,
 			bid.Amount.String(),
 			hex.EncodeToString(bid.Signature),
+			bid.Expiry,
 		).WillReturnResult(sqlmock.NewResult(1, 1))
 	}
 
@@ -102,6 +106,81 @@ func TestInsertBids(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestBatchInsertBids(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	db, err := NewDatabase(tmpDir)
+	require.NoError(t, err)
+
+	numBids := 5000
+	bids := make([]*ValidatedBid, 0, numBids)
+	for i := 0; i < numBids; i++ {
+		bids = append(bids, &ValidatedBid{
+			ChainId:                big.NewInt(1),
+			ExpressLaneController:  common.BigToAddress(big.NewInt(int64(i))),
+			AuctionContractAddress: common.HexToAddress("0x0000000000000000000000000000000000000002"),
+			Bidder:                 common.BigToAddress(big.NewInt(int64(i + numBids))),
+			Round:                  uint64(i / 100),
+			Amount:                 big.NewInt(int64(i + 1)),
+			Signature:              []byte("signature"),
+			Expiry:                 uint64(i),
+		})
+	}
+	require.NoError(t, db.InsertBids(bids))
+
+	var gotBids []*SqliteDatabaseBid
+	require.NoError(t, db.sqlDB.Select(&gotBids, "SELECT * FROM Bids ORDER BY Id"))
+	require.Len(t, gotBids, numBids)
+	for i, got := range gotBids {
+		require.Equal(t, bids[i].Bidder.Hex(), got.Bidder)
+		require.Equal(t, bids[i].ExpressLaneController.Hex(), got.ExpressLaneController)
+		require.Equal(t, bids[i].Round, got.Round)
+		require.Equal(t, bids[i].Amount.String(), got.Amount)
+		require.Equal(t, hex.EncodeToString(bids[i].Signature), got.Signature)
+		require.Equal(t, bids[i].Expiry, got.Expiry)
+	}
+}
+
+func TestBatchInsertBidsIsAtomic(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	d := &SqliteDatabase{sqlDB: sqlx.NewDb(db, "sqlmock"), currentTableVersion: -1}
+
+	bids := []*ValidatedBid{
+		{
+			ChainId:                big.NewInt(1),
+			ExpressLaneController:  common.HexToAddress("0x0000000000000000000000000000000000000001"),
+			AuctionContractAddress: common.HexToAddress("0x0000000000000000000000000000000000000002"),
+			Bidder:                 common.HexToAddress("0x0000000000000000000000000000000000000002"),
+			Round:                  1,
+			Amount:                 big.NewInt(100),
+			Signature:              []byte("signature1"),
+		},
+		{
+			ChainId:                big.NewInt(1),
+			ExpressLaneController:  common.HexToAddress("0x0000000000000000000000000000000000000003"),
+			AuctionContractAddress: common.HexToAddress("0x0000000000000000000000000000000000000002"),
+			Bidder:                 common.HexToAddress("0x0000000000000000000000000000000000000004"),
+			Round:                  1,
+			Amount:                 big.NewInt(200),
+			Signature:              []byte("signature2"),
+		},
+	}
+
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare("INSERT INTO Bids")
+	prep.ExpectExec().WillReturnResult(sqlmock.NewResult(1, 1))
+	prep.ExpectExec().WillReturnError(errors.New("disk full"))
+	mock.ExpectRollback()
+
+	require.Error(t, d.InsertBids(bids))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestDeleteBidsLowerThanRound(t *testing.T) {
 	t.Parallel()
 	db, mock, err := sqlmock.New()
@@ -127,3 +206,115 @@ func TestDeleteBidsLowerThanRound(t *testing.T) {
 	err = mock.ExpectationsWereMet()
 	assert.NoError(t, err)
 }
+
+func TestMaintainAfterInsertDeleteCycles(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	db, err := NewDatabase(tmpDir)
+	require.NoError(t, err)
+
+	newBid := func(round uint64) *ValidatedBid {
+		return &ValidatedBid{
+			ChainId:                big.NewInt(1),
+			ExpressLaneController:  common.HexToAddress("0x0000000000000000000000000000000000000001"),
+			AuctionContractAddress: common.HexToAddress("0x0000000000000000000000000000000000000002"),
+			Bidder:                 common.HexToAddress("0x0000000000000000000000000000000000000003"),
+			Round:                  round,
+			Amount:                 big.NewInt(100),
+			Signature:              []byte("signature"),
+		}
+	}
+	for cycle := uint64(0); cycle < 20; cycle++ {
+		bids := make([]*ValidatedBid, 0, 100)
+		for i := 0; i < 100; i++ {
+			bids = append(bids, newBid(cycle))
+		}
+		require.NoError(t, db.InsertBids(bids))
+		require.NoError(t, db.DeleteBids(cycle+1))
+	}
+	require.NoError(t, db.Maintain(context.Background()))
+
+	// Maintenance is serialized with concurrent inserts
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			assert.NoError(t, db.InsertBid(newBid(100)))
+		}
+	}()
+	require.NoError(t, db.Maintain(context.Background()))
+	wg.Wait()
+
+	var count int
+	require.NoError(t, db.sqlDB.Get(&count, "SELECT COUNT(*) FROM Bids"))
+	require.Equal(t, 50, count)
+}
+
+func TestRoundsWithBidsAndResolutionGaps(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	db, err := NewDatabase(tmpDir)
+	require.NoError(t, err)
+
+	var bids []*ValidatedBid
+	for _, round := range []uint64{2, 2, 4, 5, 8} {
+		bids = append(bids, &ValidatedBid{
+			ChainId:                big.NewInt(1),
+			ExpressLaneController:  common.HexToAddress("0x0000000000000000000000000000000000000001"),
+			AuctionContractAddress: common.HexToAddress("0x0000000000000000000000000000000000000002"),
+			Bidder:                 common.HexToAddress("0x0000000000000000000000000000000000000003"),
+			Round:                  round,
+			Amount:                 big.NewInt(100),
+			Signature:              []byte("signature"),
+		})
+	}
+	require.NoError(t, db.InsertBids(bids))
+
+	rounds, err := db.RoundsWithBids(2, 5)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{2, 4, 5}, rounds)
+
+	// Round 4 had bids but no AuctionResolved event
+	resolved := map[uint64]struct{}{2: {}, 3: {}, 5: {}}
+	require.Equal(t, []uint64{4}, unresolvedRounds(rounds, resolved))
+	require.Empty(t, unresolvedRounds(rounds[:1], resolved))
+}
+
+func TestDatabaseStats(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	db, err := NewDatabase(tmpDir)
+	require.NoError(t, err)
+
+	totalBids, distinctRounds, earliestRound, latestRound, err := db.Stats()
+	require.NoError(t, err)
+	require.Equal(t, 0, totalBids)
+	require.Equal(t, 0, distinctRounds)
+	require.Equal(t, uint64(0), earliestRound)
+	require.Equal(t, uint64(0), latestRound)
+
+	var bids []*ValidatedBid
+	for _, round := range []uint64{3, 3, 5, 7, 7, 7} {
+		bids = append(bids, &ValidatedBid{
+			ChainId:                big.NewInt(1),
+			ExpressLaneController:  common.HexToAddress("0x0000000000000000000000000000000000000001"),
+			AuctionContractAddress: common.HexToAddress("0x0000000000000000000000000000000000000002"),
+			Bidder:                 common.HexToAddress("0x0000000000000000000000000000000000000003"),
+			Round:                  round,
+			Amount:                 big.NewInt(100),
+			Signature:              []byte("signature"),
+		})
+	}
+	require.NoError(t, db.InsertBids(bids))
+
+	totalBids, distinctRounds, earliestRound, latestRound, err = db.Stats()
+	require.NoError(t, err)
+	require.Equal(t, 6, totalBids)
+	require.Equal(t, 3, distinctRounds)
+	require.Equal(t, uint64(3), earliestRound)
+	require.Equal(t, uint64(7), latestRound)
+}
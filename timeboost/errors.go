@@ -1,20 +1,127 @@
 package timeboost
 
-import "github.com/pkg/errors"
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
 
 var (
-	ErrMalformedData            = errors.New("MALFORMED_DATA")
-	ErrNotDepositor             = errors.New("NOT_DEPOSITOR")
-	ErrWrongChainId             = errors.New("WRONG_CHAIN_ID")
-	ErrWrongSignature           = errors.New("WRONG_SIGNATURE")
-	ErrBadRoundNumber           = errors.New("BAD_ROUND_NUMBER")
-	ErrInsufficientBalance      = errors.New("INSUFFICIENT_BALANCE")
-	ErrReservePriceNotMet       = errors.New("RESERVE_PRICE_NOT_MET")
-	ErrNoOnchainController      = errors.New("NO_ONCHAIN_CONTROLLER")
-	ErrWrongAuctionContract     = errors.New("WRONG_AUCTION_CONTRACT")
-	ErrNotExpressLaneController = errors.New("NOT_EXPRESS_LANE_CONTROLLER")
-	ErrDuplicateSequenceNumber  = errors.New("SEQUENCE_NUMBER_ALREADY_SEEN")
-	ErrSequenceNumberTooLow     = errors.New("SEQUENCE_NUMBER_TOO_LOW")
-	ErrTooManyBids              = errors.New("PER_ROUND_BID_LIMIT_REACHED")
-	ErrAcceptedTxFailed         = errors.New("Accepted timeboost tx failed")
+	ErrMalformedData                = errors.New("MALFORMED_DATA")
+	ErrNotDepositor                 = errors.New("NOT_DEPOSITOR")
+	ErrWrongChainId                 = errors.New("WRONG_CHAIN_ID")
+	ErrWrongSignature               = errors.New("WRONG_SIGNATURE")
+	ErrBadRoundNumber               = errors.New("BAD_ROUND_NUMBER")
+	ErrRoundTooFarInFuture          = errors.New("ROUND_TOO_FAR_IN_FUTURE")
+	ErrInsufficientBalance          = errors.New("INSUFFICIENT_BALANCE")
+	ErrReservePriceNotMet           = errors.New("RESERVE_PRICE_NOT_MET")
+	ErrNoOnchainController          = errors.New("NO_ONCHAIN_CONTROLLER")
+	ErrWrongAuctionContract         = errors.New("WRONG_AUCTION_CONTRACT")
+	ErrNotExpressLaneController     = errors.New("NOT_EXPRESS_LANE_CONTROLLER")
+	ErrDuplicateSequenceNumber      = errors.New("SEQUENCE_NUMBER_ALREADY_SEEN")
+	ErrSequenceNumberTooLow         = errors.New("SEQUENCE_NUMBER_TOO_LOW")
+	ErrSequenceNumberTooHigh        = errors.New("SEQUENCE_NUMBER_TOO_HIGH")
+	ErrTooManyBids                  = errors.New("PER_ROUND_BID_LIMIT_REACHED")
+	ErrAcceptedTxFailed             = errors.New("Accepted timeboost tx failed")
+	ErrInsufficientDeposit          = errors.New("INSUFFICIENT_DEPOSIT")
+	ErrBidExpired                   = errors.New("BID_EXPIRED")
+	ErrExpressLaneTxTooLarge        = errors.New("EXPRESS_LANE_TX_TOO_LARGE")
+	ErrExpressLaneSubmissionTooLate = errors.New("EXPRESS_LANE_SUBMISSION_TOO_LATE")
+	ErrNoBids                       = errors.New("NO_BIDS")
+	ErrNotEnoughBids                = errors.New("NOT_ENOUGH_BIDS")
+	ErrBidderBlocked                = errors.New("BIDDER_BLOCKED")
+	ErrInnerTxChainIdMismatch       = errors.New("INNER_TX_CHAIN_ID_MISMATCH")
+	ErrAuctioneerBusy               = errors.New("AUCTIONEER_BUSY")
 )
+
+// AuctioneerBusyErrorCode is the JSON-RPC error code of valid bids the bid validator couldn't queue for the
+// auctioneer, so that bidders can tell them apart from rejected bids and retry them.
+const AuctioneerBusyErrorCode = -32050
+
+// AuctioneerBusyError is returned for valid bids that couldn't be queued for the auctioneer.
+type AuctioneerBusyError struct {
+	err error
+}
+
+func NewAuctioneerBusyError(err error) *AuctioneerBusyError {
+	return &AuctioneerBusyError{err: err}
+}
+
+func (e *AuctioneerBusyError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrAuctioneerBusy, e.err)
+}
+
+func (e *AuctioneerBusyError) Unwrap() []error {
+	return []error{ErrAuctioneerBusy, e.err}
+}
+
+// ErrorCode implements rpc.Error
+func (e *AuctioneerBusyError) ErrorCode() int {
+	return AuctioneerBusyErrorCode
+}
+
+// SequenceHint is the sequence number the sequencer expects next for an express lane round.
+type SequenceHint struct {
+	Round                  hexutil.Uint64 `json:"round"`
+	ExpectedSequenceNumber hexutil.Uint64 `json:"expectedSequenceNumber"`
+}
+
+// SequenceNumberError is returned for express lane submissions whose sequence number can't be sequenced.
+// Its hint is sent as the data of the JSON-RPC error, so that a client whose sequence number got out of sync,
+// e.g. by missing a round boundary, can resynchronize.
+type SequenceNumberError struct {
+	err  error
+	hint SequenceHint
+}
+
+func NewSequenceNumberError(err error, round, expectedSequenceNumber uint64) *SequenceNumberError {
+	return &SequenceNumberError{
+		err: err,
+		hint: SequenceHint{
+			Round:                  hexutil.Uint64(round),
+			ExpectedSequenceNumber: hexutil.Uint64(expectedSequenceNumber),
+		},
+	}
+}
+
+func (e *SequenceNumberError) Error() string {
+	return fmt.Sprintf("%v: expected sequence number %d for round %d", e.err, e.hint.ExpectedSequenceNumber, e.hint.Round)
+}
+
+func (e *SequenceNumberError) Unwrap() error {
+	return e.err
+}
+
+// ErrorData implements rpc.DataError
+func (e *SequenceNumberError) ErrorData() interface{} {
+	return e.hint
+}
+
+// SequenceHintFromError returns the sequence hint carried by err, which may have been received over RPC.
+func SequenceHintFromError(err error) (*SequenceHint, bool) {
+	var seqErr *SequenceNumberError
+	if errors.As(err, &seqErr) {
+		hint := seqErr.hint
+		return &hint, true
+	}
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return nil, false
+	}
+	data, err := json.Marshal(dataErr.ErrorData())
+	if err != nil {
+		return nil, false
+	}
+	var hint struct {
+		Round                  *hexutil.Uint64 `json:"round"`
+		ExpectedSequenceNumber *hexutil.Uint64 `json:"expectedSequenceNumber"`
+	}
+	if err := json.Unmarshal(data, &hint); err != nil || hint.Round == nil || hint.ExpectedSequenceNumber == nil {
+		return nil, false
+	}
+	return &SequenceHint{Round: *hint.Round, ExpectedSequenceNumber: *hint.ExpectedSequenceNumber}, true
+}
@@ -0,0 +1,587 @@
+package timeboost
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// This file implements the minimal subset of the Apache Parquet format needed to archive
+// validated bids: a single row group of required, non-nested columns, each stored as one
+// uncompressed PLAIN-encoded data page. File metadata is serialized with the thrift compact protocol.
+
+const parquetMagic = "PAR1"
+
+// Parquet physical types
+const (
+	parquetTypeInt64             = 2
+	parquetTypeByteArray         = 6
+	parquetTypeFixedLenByteArray = 7
+)
+
+// Parquet converted types, -1 denotes no converted type
+const (
+	parquetConvertedTypeNone   = -1
+	parquetConvertedTypeUTF8   = 0
+	parquetConvertedTypeUint64 = 14
+)
+
+const (
+	parquetRepetitionRequired = 0
+	parquetEncodingPlain      = 0
+	parquetEncodingRLE        = 3
+	parquetCodecUncompressed  = 0
+	parquetPageTypeDataPage   = 0
+)
+
+type parquetColumn struct {
+	name          string
+	physicalType  int32
+	typeLength    int32
+	convertedType int32
+}
+
+// parquetBidColumns describes the schema of the archived bids. Amount is stored as a decimal
+// string since uint256 values exceed the precision most parquet readers support for DECIMAL.
+var parquetBidColumns = []parquetColumn{
+	{name: "chain_id", physicalType: parquetTypeInt64, convertedType: parquetConvertedTypeUint64},
+	{name: "bidder", physicalType: parquetTypeFixedLenByteArray, typeLength: common.AddressLength, convertedType: parquetConvertedTypeNone},
+	{name: "express_lane_controller", physicalType: parquetTypeFixedLenByteArray, typeLength: common.AddressLength, convertedType: parquetConvertedTypeNone},
+	{name: "auction_contract_address", physicalType: parquetTypeFixedLenByteArray, typeLength: common.AddressLength, convertedType: parquetConvertedTypeNone},
+	{name: "round", physicalType: parquetTypeInt64, convertedType: parquetConvertedTypeUint64},
+	{name: "amount", physicalType: parquetTypeByteArray, convertedType: parquetConvertedTypeUTF8},
+	{name: "signature", physicalType: parquetTypeByteArray, convertedType: parquetConvertedTypeNone},
+	{name: "expiry", physicalType: parquetTypeInt64, convertedType: parquetConvertedTypeUint64},
+}
+
+func appendParquetByteArray(buf []byte, data []byte) []byte {
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(data))) // #nosec G115
+	return append(buf, data...)
+}
+
+func parseAddressColumn(name, value string) ([]byte, error) {
+	if !common.IsHexAddress(value) {
+		return nil, fmt.Errorf("invalid %s address: %s", name, value)
+	}
+	return common.HexToAddress(value).Bytes(), nil
+}
+
+// encodeBidsToParquet serializes the given bids into a parquet file.
+func encodeBidsToParquet(bids []*SqliteDatabaseBid) ([]byte, error) {
+	columns := make([][]byte, len(parquetBidColumns))
+	for _, bid := range bids {
+		chainId, err := strconv.ParseUint(bid.ChainId, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chain id %s: %w", bid.ChainId, err)
+		}
+		bidder, err := parseAddressColumn("bidder", bid.Bidder)
+		if err != nil {
+			return nil, err
+		}
+		expressLaneController, err := parseAddressColumn("express lane controller", bid.ExpressLaneController)
+		if err != nil {
+			return nil, err
+		}
+		auctionContractAddress, err := parseAddressColumn("auction contract", bid.AuctionContractAddress)
+		if err != nil {
+			return nil, err
+		}
+		signature, err := hex.DecodeString(bid.Signature)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signature %s: %w", bid.Signature, err)
+		}
+		columns[0] = binary.LittleEndian.AppendUint64(columns[0], chainId)
+		columns[1] = append(columns[1], bidder...)
+		columns[2] = append(columns[2], expressLaneController...)
+		columns[3] = append(columns[3], auctionContractAddress...)
+		columns[4] = binary.LittleEndian.AppendUint64(columns[4], bid.Round)
+		columns[5] = appendParquetByteArray(columns[5], []byte(bid.Amount))
+		columns[6] = appendParquetByteArray(columns[6], signature)
+		columns[7] = binary.LittleEndian.AppendUint64(columns[7], bid.Expiry)
+	}
+
+	var out bytes.Buffer
+	out.WriteString(parquetMagic)
+	pageOffsets := make([]int64, len(parquetBidColumns))
+	chunkSizes := make([]int64, len(parquetBidColumns))
+	for i, data := range columns {
+		pageOffsets[i] = int64(out.Len())
+		w := newThriftWriter(&out)
+		w.beginStruct()
+		w.i32(1, parquetPageTypeDataPage)
+		w.i32(2, int32(len(data))) // #nosec G115
+		w.i32(3, int32(len(data))) // #nosec G115
+		w.structField(5)
+		w.i32(1, int32(len(bids))) // #nosec G115
+		w.i32(2, parquetEncodingPlain)
+		w.i32(3, parquetEncodingRLE)
+		w.i32(4, parquetEncodingRLE)
+		w.endStruct()
+		w.endStruct()
+		out.Write(data)
+		chunkSizes[i] = int64(out.Len()) - pageOffsets[i]
+	}
+
+	footerStart := out.Len()
+	w := newThriftWriter(&out)
+	w.beginStruct()
+	w.i32(1, 1)
+	// Schema, the root element is followed by its leaf columns
+	w.list(2, thriftTypeStruct, len(parquetBidColumns)+1)
+	w.beginStruct()
+	w.binary(4, []byte("schema"))
+	w.i32(5, int32(len(parquetBidColumns)))
+	w.endStruct()
+	for _, col := range parquetBidColumns {
+		w.beginStruct()
+		w.i32(1, col.physicalType)
+		if col.typeLength != 0 {
+			w.i32(2, col.typeLength)
+		}
+		w.i32(3, parquetRepetitionRequired)
+		w.binary(4, []byte(col.name))
+		if col.convertedType != parquetConvertedTypeNone {
+			w.i32(6, col.convertedType)
+		}
+		w.endStruct()
+	}
+	w.i64(3, int64(len(bids)))
+	// Single row group holding every column chunk
+	var totalSize int64
+	for _, size := range chunkSizes {
+		totalSize += size
+	}
+	w.list(4, thriftTypeStruct, 1)
+	w.beginStruct()
+	w.list(1, thriftTypeStruct, len(parquetBidColumns))
+	for i, col := range parquetBidColumns {
+		w.beginStruct()
+		w.i64(2, pageOffsets[i])
+		w.structField(3)
+		w.i32(1, col.physicalType)
+		w.list(2, thriftTypeI32, 2)
+		w.writeVarint(parquetEncodingPlain)
+		w.writeVarint(parquetEncodingRLE)
+		w.list(3, thriftTypeBinary, 1)
+		w.writeBytes([]byte(col.name))
+		w.i32(4, parquetCodecUncompressed)
+		w.i64(5, int64(len(bids)))
+		w.i64(6, chunkSizes[i])
+		w.i64(7, chunkSizes[i])
+		w.i64(9, pageOffsets[i])
+		w.endStruct()
+		w.endStruct()
+	}
+	w.i64(2, totalSize)
+	w.i64(3, int64(len(bids)))
+	w.endStruct()
+	w.binary(6, []byte("nitro timeboost auctioneer"))
+	w.endStruct()
+
+	footerLen := out.Len() - footerStart
+	out.Write(binary.LittleEndian.AppendUint32(nil, uint32(footerLen))) // #nosec G115
+	out.WriteString(parquetMagic)
+	return out.Bytes(), nil
+}
+
+// decodeBidsFromParquet parses a parquet file produced by encodeBidsToParquet.
+func decodeBidsFromParquet(data []byte) ([]*SqliteDatabaseBid, error) {
+	if len(data) < 2*len(parquetMagic)+4 ||
+		string(data[:len(parquetMagic)]) != parquetMagic ||
+		string(data[len(data)-len(parquetMagic):]) != parquetMagic {
+		return nil, errors.New("not a parquet file")
+	}
+	footerLenPos := len(data) - len(parquetMagic) - 4
+	footerLen := int(binary.LittleEndian.Uint32(data[footerLenPos:]))
+	if footerLen > footerLenPos-len(parquetMagic) {
+		return nil, errors.New("invalid parquet footer length")
+	}
+	metadata, err := newThriftReader(data[footerLenPos-footerLen : footerLenPos]).readStruct()
+	if err != nil {
+		return nil, fmt.Errorf("error reading parquet file metadata: %w", err)
+	}
+	numRows, err := thriftInt(metadata, 3)
+	if err != nil {
+		return nil, err
+	}
+	// The row counts come from the untrusted footer, every row takes up several bytes of the file
+	if numRows < 0 || numRows > int64(len(data)) {
+		return nil, fmt.Errorf("invalid parquet row count %d", numRows)
+	}
+	rowGroups, err := thriftList(metadata, 4)
+	if err != nil {
+		return nil, err
+	}
+	bids := make([]*SqliteDatabaseBid, 0, numRows)
+	for _, rg := range rowGroups {
+		rowGroup, ok := rg.(map[int16]any)
+		if !ok {
+			return nil, errors.New("invalid parquet row group")
+		}
+		groupRows, err := thriftInt(rowGroup, 3)
+		if err != nil {
+			return nil, err
+		}
+		if groupRows < 0 || groupRows > numRows-int64(len(bids)) {
+			return nil, fmt.Errorf("invalid parquet row group row count %d", groupRows)
+		}
+		chunks, err := thriftList(rowGroup, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(chunks) != len(parquetBidColumns) {
+			return nil, fmt.Errorf("unexpected number of parquet columns, want: %d, got: %d", len(parquetBidColumns), len(chunks))
+		}
+		groupBids := make([]*SqliteDatabaseBid, groupRows)
+		for i := range groupBids {
+			groupBids[i] = &SqliteDatabaseBid{}
+		}
+		for i, c := range chunks {
+			chunk, ok := c.(map[int16]any)
+			if !ok {
+				return nil, errors.New("invalid parquet column chunk")
+			}
+			if err := decodeParquetColumn(data, chunk, parquetBidColumns[i], groupBids); err != nil {
+				return nil, err
+			}
+		}
+		bids = append(bids, groupBids...)
+	}
+	if int64(len(bids)) != numRows {
+		return nil, fmt.Errorf("parquet row groups hold %d rows, file metadata says %d", len(bids), numRows)
+	}
+	return bids, nil
+}
+
+func decodeParquetColumn(data []byte, chunk map[int16]any, col parquetColumn, bids []*SqliteDatabaseBid) error {
+	meta, ok := chunk[3].(map[int16]any)
+	if !ok {
+		return fmt.Errorf("missing metadata for parquet column %s", col.name)
+	}
+	if physicalType, err := thriftInt(meta, 1); err != nil || physicalType != int64(col.physicalType) {
+		return fmt.Errorf("unexpected physical type for parquet column %s", col.name)
+	}
+	if codec, err := thriftInt(meta, 4); err != nil || codec != parquetCodecUncompressed {
+		return fmt.Errorf("unsupported compression codec for parquet column %s", col.name)
+	}
+	pageOffset, err := thriftInt(meta, 9)
+	if err != nil {
+		return err
+	}
+	if pageOffset < 0 || pageOffset >= int64(len(data)) {
+		return fmt.Errorf("invalid data page offset for parquet column %s", col.name)
+	}
+	reader := newThriftReader(data[pageOffset:])
+	pageHeader, err := reader.readStruct()
+	if err != nil {
+		return fmt.Errorf("error reading page header of parquet column %s: %w", col.name, err)
+	}
+	if pageType, err := thriftInt(pageHeader, 1); err != nil || pageType != parquetPageTypeDataPage {
+		return fmt.Errorf("unsupported page type for parquet column %s", col.name)
+	}
+	pageSize, err := thriftInt(pageHeader, 3)
+	if err != nil {
+		return err
+	}
+	dataPageHeader, ok := pageHeader[5].(map[int16]any)
+	if !ok {
+		return fmt.Errorf("missing data page header for parquet column %s", col.name)
+	}
+	if numValues, err := thriftInt(dataPageHeader, 1); err != nil || numValues != int64(len(bids)) {
+		return fmt.Errorf("unexpected number of values in parquet column %s", col.name)
+	}
+	if encoding, err := thriftInt(dataPageHeader, 2); err != nil || encoding != parquetEncodingPlain {
+		return fmt.Errorf("unsupported encoding for parquet column %s", col.name)
+	}
+	pageStart := pageOffset + reader.offset()
+	if pageSize < 0 || pageSize > int64(len(data))-pageStart {
+		return fmt.Errorf("invalid page size for parquet column %s", col.name)
+	}
+	page := data[pageStart : pageStart+pageSize]
+
+	for _, bid := range bids {
+		var value []byte
+		switch col.physicalType {
+		case parquetTypeInt64:
+			if len(page) < 8 {
+				return fmt.Errorf("truncated parquet column %s", col.name)
+			}
+			value, page = page[:8], page[8:]
+		case parquetTypeFixedLenByteArray:
+			if len(page) < int(col.typeLength) {
+				return fmt.Errorf("truncated parquet column %s", col.name)
+			}
+			value, page = page[:col.typeLength], page[col.typeLength:]
+		case parquetTypeByteArray:
+			if len(page) < 4 {
+				return fmt.Errorf("truncated parquet column %s", col.name)
+			}
+			size := binary.LittleEndian.Uint32(page)
+			if uint64(len(page)-4) < uint64(size) {
+				return fmt.Errorf("truncated parquet column %s", col.name)
+			}
+			value, page = page[4:4+size], page[4+size:]
+		}
+		switch col.name {
+		case "chain_id":
+			bid.ChainId = strconv.FormatUint(binary.LittleEndian.Uint64(value), 10)
+		case "bidder":
+			bid.Bidder = common.BytesToAddress(value).Hex()
+		case "express_lane_controller":
+			bid.ExpressLaneController = common.BytesToAddress(value).Hex()
+		case "auction_contract_address":
+			bid.AuctionContractAddress = common.BytesToAddress(value).Hex()
+		case "round":
+			bid.Round = binary.LittleEndian.Uint64(value)
+		case "amount":
+			bid.Amount = string(value)
+		case "signature":
+			bid.Signature = hex.EncodeToString(value)
+		case "expiry":
+			bid.Expiry = binary.LittleEndian.Uint64(value)
+		}
+	}
+	return nil
+}
+
+// Thrift compact protocol type identifiers
+const (
+	thriftTypeBoolTrue  = 1
+	thriftTypeBoolFalse = 2
+	thriftTypeByte      = 3
+	thriftTypeI16       = 4
+	thriftTypeI32       = 5
+	thriftTypeI64       = 6
+	thriftTypeDouble    = 7
+	thriftTypeBinary    = 8
+	thriftTypeList      = 9
+	thriftTypeSet       = 10
+	thriftTypeMap       = 11
+	thriftTypeStruct    = 12
+)
+
+type thriftWriter struct {
+	buf        *bytes.Buffer
+	lastFields []int16
+}
+
+func newThriftWriter(buf *bytes.Buffer) *thriftWriter {
+	return &thriftWriter{buf: buf}
+}
+
+func (w *thriftWriter) beginStruct() {
+	w.lastFields = append(w.lastFields, 0)
+}
+
+func (w *thriftWriter) endStruct() {
+	w.buf.WriteByte(0)
+	w.lastFields = w.lastFields[:len(w.lastFields)-1]
+}
+
+func (w *thriftWriter) fieldHeader(id int16, fieldType byte) {
+	last := &w.lastFields[len(w.lastFields)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | fieldType) // #nosec G115
+	} else {
+		w.buf.WriteByte(fieldType)
+		w.writeVarint(int64(id))
+	}
+	*last = id
+}
+
+func (w *thriftWriter) writeVarint(v int64) {
+	w.buf.Write(binary.AppendVarint(nil, v))
+}
+
+func (w *thriftWriter) writeBytes(b []byte) {
+	w.buf.Write(binary.AppendUvarint(nil, uint64(len(b))))
+	w.buf.Write(b)
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.fieldHeader(id, thriftTypeI32)
+	w.writeVarint(int64(v))
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.fieldHeader(id, thriftTypeI64)
+	w.writeVarint(v)
+}
+
+func (w *thriftWriter) binary(id int16, b []byte) {
+	w.fieldHeader(id, thriftTypeBinary)
+	w.writeBytes(b)
+}
+
+// list writes the header of a list field, its elements must be written by the caller.
+func (w *thriftWriter) list(id int16, elemType byte, size int) {
+	w.fieldHeader(id, thriftTypeList)
+	if size < 15 {
+		w.buf.WriteByte(byte(size)<<4 | elemType) // #nosec G115
+	} else {
+		w.buf.WriteByte(0xf0 | elemType)
+		w.buf.Write(binary.AppendUvarint(nil, uint64(size)))
+	}
+}
+
+// structField writes the header of a struct field, caller must close it using endStruct.
+func (w *thriftWriter) structField(id int16) {
+	w.fieldHeader(id, thriftTypeStruct)
+	w.beginStruct()
+}
+
+// thriftReader decodes thrift compact protocol structs into maps keyed by field id.
+type thriftReader struct {
+	r    *bytes.Reader
+	size int64
+}
+
+func newThriftReader(data []byte) *thriftReader {
+	return &thriftReader{r: bytes.NewReader(data), size: int64(len(data))}
+}
+
+// offset returns the number of bytes consumed so far.
+func (r *thriftReader) offset() int64 {
+	return r.size - int64(r.r.Len())
+}
+
+func (r *thriftReader) readStruct() (map[int16]any, error) {
+	fields := make(map[int16]any)
+	var last int16
+	for {
+		header, err := r.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if header == 0 {
+			return fields, nil
+		}
+		if delta := int16(header >> 4); delta != 0 {
+			last += delta
+		} else {
+			id, err := binary.ReadVarint(r.r)
+			if err != nil {
+				return nil, err
+			}
+			last = int16(id) // #nosec G115
+		}
+		value, err := r.readValue(header & 0x0f)
+		if err != nil {
+			return nil, err
+		}
+		fields[last] = value
+	}
+}
+
+func (r *thriftReader) readBytes() ([]byte, error) {
+	size, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return nil, err
+	}
+	if size > uint64(r.r.Len()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := make([]byte, size)
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (r *thriftReader) readValue(valueType byte) (any, error) {
+	switch valueType {
+	case thriftTypeBoolTrue:
+		return true, nil
+	case thriftTypeBoolFalse:
+		return false, nil
+	case thriftTypeByte:
+		b, err := r.r.ReadByte()
+		return int64(b), err
+	case thriftTypeI16, thriftTypeI32, thriftTypeI64:
+		return binary.ReadVarint(r.r)
+	case thriftTypeDouble:
+		var b [8]byte
+		_, err := io.ReadFull(r.r, b[:])
+		return b, err
+	case thriftTypeBinary:
+		return r.readBytes()
+	case thriftTypeList, thriftTypeSet:
+		header, err := r.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		size := uint64(header >> 4)
+		if size == 15 {
+			if size, err = binary.ReadUvarint(r.r); err != nil {
+				return nil, err
+			}
+		}
+		if size > uint64(r.r.Len()) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		elemType := header & 0x0f
+		elems := make([]any, 0, size)
+		for i := uint64(0); i < size; i++ {
+			var elem any
+			if elemType == thriftTypeBoolTrue || elemType == thriftTypeBoolFalse {
+				// Booleans inside collections are encoded as a single byte
+				b, err := r.r.ReadByte()
+				if err != nil {
+					return nil, err
+				}
+				elem = b == thriftTypeBoolTrue
+			} else if elem, err = r.readValue(elemType); err != nil {
+				return nil, err
+			}
+			elems = append(elems, elem)
+		}
+		return elems, nil
+	case thriftTypeMap:
+		size, err := binary.ReadUvarint(r.r)
+		if err != nil || size == 0 {
+			return nil, err
+		}
+		if size > uint64(r.r.Len()) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		kvTypes, err := r.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		for i := uint64(0); i < size; i++ {
+			if _, err := r.readValue(kvTypes >> 4); err != nil {
+				return nil, err
+			}
+			if _, err := r.readValue(kvTypes & 0x0f); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	case thriftTypeStruct:
+		return r.readStruct()
+	default:
+		return nil, fmt.Errorf("unknown thrift type: %d", valueType)
+	}
+}
+
+func thriftInt(fields map[int16]any, id int16) (int64, error) {
+	v, ok := fields[id].(int64)
+	if !ok {
+		return 0, fmt.Errorf("missing or invalid integer thrift field %d", id)
+	}
+	return v, nil
+}
+
+func thriftList(fields map[int16]any, id int16) ([]any, error) {
+	v, ok := fields[id].([]any)
+	if !ok {
+		return nil, fmt.Errorf("missing or invalid list thrift field %d", id)
+	}
+	return v, nil
+}
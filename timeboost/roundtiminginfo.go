@@ -90,6 +90,12 @@ func (info *RoundTimingInfo) RoundNumberAt(currentTime time.Time) uint64 {
 	// info.Round has already been validated to be nonzero during construction.
 }
 
+// RoundForBlock returns the express lane round during which a block with the given timestamp, in seconds, was produced.
+// Blocks produced before the first round are attributed to round 0.
+func RoundForBlock(blockTimestamp uint64, timing RoundTimingInfo) uint64 {
+	return timing.RoundNumberAt(time.Unix(arbmath.SaturatingCast[int64](blockTimestamp), 0))
+}
+
 // TimeTilNextRound returns the time til the next round as of now.
 func (info *RoundTimingInfo) TimeTilNextRound() time.Duration {
 	return info.TimeTilNextRoundAt(time.Now())
@@ -4,22 +4,34 @@ import (
 	"bytes"
 	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/spf13/pflag"
 
 	"github.com/ethereum/go-ethereum/log"
 
+	"github.com/offchainlabs/nitro/util/arbmath"
 	"github.com/offchainlabs/nitro/util/gzip"
 	"github.com/offchainlabs/nitro/util/s3client"
 	"github.com/offchainlabs/nitro/util/stopwaiter"
 )
 
+var (
+	ErrS3Upload   = errors.New("s3 upload failed")
+	ErrS3Download = errors.New("s3 download failed")
+	// ErrKeyNotFound is returned instead of ErrS3Download when the downloaded object doesn't exist
+	ErrKeyNotFound = errors.New("s3 key not found")
+)
+
 type S3StorageServiceConfig struct {
 	Enable         bool          `koanf:"enable"`
 	AccessKey      string        `koanf:"access-key"`
@@ -30,8 +42,36 @@ type S3StorageServiceConfig struct {
 	UploadInterval time.Duration `koanf:"upload-interval"`
 	MaxBatchSize   int           `koanf:"max-batch-size"`
 	MaxDbRows      int           `koanf:"max-db-rows"`
+	Format         string        `koanf:"format"`
+	// Number of rounds, counted back from the latest round, for which uploaded bids are kept in the sql db
+	LocalRetentionRounds uint64        `koanf:"local-retention-rounds"`
+	VerifyBeforeDelete   bool          `koanf:"verify-before-delete"`
+	DeleteBatchSize      int           `koanf:"delete-batch-size"`
+	ServerSideEncryption string        `koanf:"server-side-encryption"`
+	KmsKeyId             string        `koanf:"kms-key-id"`
+	UploadRetries        int           `koanf:"upload-retries"`
+	UploadRetryBackoff   time.Duration `koanf:"upload-retry-backoff"`
 }
 
+const (
+	S3StorageFormatCSV     = "csv"
+	S3StorageFormatParquet = "parquet"
+)
+
+const (
+	S3StorageEncryptionNone   = ""
+	S3StorageEncryptionSSES3  = "sse-s3"
+	S3StorageEncryptionSSEKMS = "sse-kms"
+)
+
+// kmsKeyIdRegex matches the ways a KMS key can be referred to: key id, key ARN, alias name and alias ARN
+var kmsKeyIdRegex = func() *regexp.Regexp {
+	keyId := `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`
+	alias := `alias/[a-zA-Z0-9/_-]+`
+	arn := `arn:aws[a-z-]*:kms:[a-z0-9-]+:\d{12}:`
+	return regexp.MustCompile(`^(` + keyId + `|` + alias + `|` + arn + `key/` + keyId + `|` + arn + alias + `)$`)
+}()
+
 func (c *S3StorageServiceConfig) Validate() error {
 	if !c.Enable {
 		return nil
@@ -39,9 +79,31 @@ func (c *S3StorageServiceConfig) Validate() error {
 	if c.MaxBatchSize < 0 {
 		return fmt.Errorf("invalid max-batch-size value for auctioneer's s3-storage config, it should be non-negative, got: %d", c.MaxBatchSize)
 	}
+	if c.UploadRetries < 0 {
+		return fmt.Errorf("invalid upload-retries value for auctioneer's s3-storage config, it should be non-negative, got: %d", c.UploadRetries)
+	}
 	if c.MaxDbRows < 0 {
 		return fmt.Errorf("invalid max-db-rows value for auctioneer's s3-storage config, it should be non-negative, got: %d", c.MaxDbRows)
 	}
+	if c.DeleteBatchSize < 0 {
+		return fmt.Errorf("invalid delete-batch-size value for auctioneer's s3-storage config, it should be non-negative, got: %d", c.DeleteBatchSize)
+	}
+	if c.Format != S3StorageFormatCSV && c.Format != S3StorageFormatParquet {
+		return fmt.Errorf("invalid format value for auctioneer's s3-storage config, it should be either %s or %s, got: %s", S3StorageFormatCSV, S3StorageFormatParquet, c.Format)
+	}
+	switch c.ServerSideEncryption {
+	case S3StorageEncryptionNone, S3StorageEncryptionSSES3:
+		if c.KmsKeyId != "" {
+			return fmt.Errorf("kms-key-id of auctioneer's s3-storage config is only used with server-side-encryption %s", S3StorageEncryptionSSEKMS)
+		}
+	case S3StorageEncryptionSSEKMS:
+		// An empty key id uses the bucket's AWS managed key
+		if c.KmsKeyId != "" && !kmsKeyIdRegex.MatchString(c.KmsKeyId) {
+			return fmt.Errorf("invalid kms-key-id value for auctioneer's s3-storage config, it should be a key id, key ARN, alias name or alias ARN, got: %s", c.KmsKeyId)
+		}
+	default:
+		return fmt.Errorf("invalid server-side-encryption value for auctioneer's s3-storage config, it should be empty, %s or %s, got: %s", S3StorageEncryptionSSES3, S3StorageEncryptionSSEKMS, c.ServerSideEncryption)
+	}
 	return nil
 }
 
@@ -50,6 +112,15 @@ var DefaultS3StorageServiceConfig = S3StorageServiceConfig{
 	UploadInterval: 15 * time.Minute,
 	MaxBatchSize:   100000000,
 	MaxDbRows:      0, // Disabled by default
+	Format:         S3StorageFormatCSV,
+	// Uploaded bids are deleted right away by default
+	LocalRetentionRounds: 0,
+	VerifyBeforeDelete:   false,
+	DeleteBatchSize:      0, // Uploaded bids are deleted with a single statement by default
+	ServerSideEncryption: S3StorageEncryptionNone,
+	KmsKeyId:             "",
+	UploadRetries:        2,
+	UploadRetryBackoff:   time.Second,
 }
 
 func S3StorageServiceConfigAddOptions(prefix string, f *pflag.FlagSet) {
@@ -62,6 +133,14 @@ func S3StorageServiceConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.Duration(prefix+".upload-interval", DefaultS3StorageServiceConfig.UploadInterval, "frequency at which batches are uploaded to S3")
 	f.Int(prefix+".max-batch-size", DefaultS3StorageServiceConfig.MaxBatchSize, "max size of uncompressed batch in bytes to be uploaded to S3")
 	f.Int(prefix+".max-db-rows", DefaultS3StorageServiceConfig.MaxDbRows, "when the sql db is very large, this enables reading of db in chunks instead of all at once which might cause OOM")
+	f.String(prefix+".format", DefaultS3StorageServiceConfig.Format, "format of the batches uploaded to S3, either csv (gzip compressed) or parquet")
+	f.Uint64(prefix+".local-retention-rounds", DefaultS3StorageServiceConfig.LocalRetentionRounds, "number of most recent rounds for which bids are retained in the sql db after being uploaded to S3, 0 deletes them right after upload")
+	f.Bool(prefix+".verify-before-delete", DefaultS3StorageServiceConfig.VerifyBeforeDelete, "download each uploaded batch back from S3 and only delete its bids from the sql db if the content matches")
+	f.Int(prefix+".delete-batch-size", DefaultS3StorageServiceConfig.DeleteBatchSize, "max number of uploaded bids removed from the sql db by each delete statement, all within one transaction, 0 deletes them with a single statement")
+	f.String(prefix+".server-side-encryption", DefaultS3StorageServiceConfig.ServerSideEncryption, "server-side encryption of uploaded batches, either empty for the bucket's default, sse-s3 or sse-kms")
+	f.Int(prefix+".upload-retries", DefaultS3StorageServiceConfig.UploadRetries, "number of times a failed batch upload is retried before the upload of the batch is given up until the next upload interval")
+	f.Duration(prefix+".upload-retry-backoff", DefaultS3StorageServiceConfig.UploadRetryBackoff, "time to wait before the first retry of a failed batch upload, doubling with each further retry")
+	f.String(prefix+".kms-key-id", DefaultS3StorageServiceConfig.KmsKeyId, "KMS key id, key ARN, alias name or alias ARN to encrypt uploaded batches with when using sse-kms, defaults to the AWS managed key")
 }
 
 type S3StorageService struct {
@@ -72,6 +151,8 @@ type S3StorageService struct {
 	bucket                string
 	objectPrefix          string
 	lastFailedDeleteRound uint64
+	// now returns the time used to date uploaded batches, it is replaced in tests for deterministic keys
+	now func() time.Time
 }
 
 func NewS3StorageService(config *S3StorageServiceConfig, sqlDB *SqliteDatabase) (*S3StorageService, error) {
@@ -85,6 +166,7 @@ func NewS3StorageService(config *S3StorageServiceConfig, sqlDB *SqliteDatabase)
 		sqlDB:        sqlDB,
 		bucket:       config.Bucket,
 		objectPrefix: config.ObjectPrefix,
+		now:          time.Now,
 	}, nil
 }
 
@@ -113,41 +195,348 @@ func (s *S3StorageService) Start(ctx context.Context) {
 	}
 }
 
+// S3StorageInfo describes where and how the service persists batches of bids
+type S3StorageInfo struct {
+	Bucket       string
+	ObjectPrefix string
+	Region       string
+	Format       string
+	Compression  string
+}
+
+// Info returns the effective configuration of the service
+func (s *S3StorageService) Info() S3StorageInfo {
+	compression := "gzip"
+	if s.isParquet() {
+		// Parquet batches are written with uncompressed data pages
+		compression = "none"
+	}
+	return S3StorageInfo{
+		Bucket:       s.bucket,
+		ObjectPrefix: s.objectPrefix,
+		Region:       s.config.Region,
+		Format:       s.config.Format,
+		Compression:  compression,
+	}
+}
+
+// Ping checks that the bucket exists and is accessible with the configured credentials, so that
+// a misconfiguration is caught at startup rather than by failing uploads later on.
+func (s *S3StorageService) Ping(ctx context.Context) error {
+	if _, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(s.bucket),
+	}); err != nil {
+		return fmt.Errorf("error accessing s3 bucket %s: %w", s.bucket, err)
+	}
+	return nil
+}
+
 // Used in padding round numbers to a fixed length for naming the batch being uploaded to s3. <firstRound>-<lastRound>
 const fixedRoundStrLen = 7
 
+const (
+	csvBatchExtension     = ".csv.gzip"
+	parquetBatchExtension = ".parquet"
+)
+
+func (s *S3StorageService) isParquet() bool {
+	return s.config.Format == S3StorageFormatParquet
+}
+
+func (s *S3StorageService) batchExtension() string {
+	if s.isParquet() {
+		return parquetBatchExtension
+	}
+	return csvBatchExtension
+}
+
+// batchesPrefix is the prefix shared by the keys of all uploaded batches
+func (s *S3StorageService) batchesPrefix() string {
+	return s.objectPrefix + "validated-timeboost-bids/"
+}
+
 func (s *S3StorageService) getBatchName(firstRound, lastRound uint64) string {
 	padder := "%0" + strconv.Itoa(fixedRoundStrLen) + "d"
-	now := time.Now()
-	return fmt.Sprintf("%svalidated-timeboost-bids/%d/%02d/%02d/"+padder+"-"+padder+".csv.gzip", s.objectPrefix, now.Year(), now.Month(), now.Day(), firstRound, lastRound)
+	now := s.now()
+	return fmt.Sprintf("%s%d/%02d/%02d/"+padder+"-"+padder+s.batchExtension(), s.batchesPrefix(), now.Year(), now.Month(), now.Day(), firstRound, lastRound)
+}
+
+// batchKeyRegex matches the part of a batch key following batchesPrefix, as named by getBatchName
+var batchKeyRegex = regexp.MustCompile(`^\d+/\d{2}/\d{2}/(\d+)-(\d+)(` + regexp.QuoteMeta(csvBatchExtension) + `|` + regexp.QuoteMeta(parquetBatchExtension) + `)$`)
+
+// findBatch returns the key and round range of the uploaded batch that contains the round
+func (s *S3StorageService) findBatch(ctx context.Context, round uint64) (string, uint64, uint64, error) {
+	prefix := s.batchesPrefix()
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return "", 0, 0, fmt.Errorf("error listing uploaded batches: %w", err)
+		}
+		for _, object := range page.Contents {
+			key := aws.ToString(object.Key)
+			matches := batchKeyRegex.FindStringSubmatch(strings.TrimPrefix(key, prefix))
+			if matches == nil {
+				continue
+			}
+			firstRound, err := strconv.ParseUint(matches[1], 10, 64)
+			if err != nil {
+				continue
+			}
+			lastRound, err := strconv.ParseUint(matches[2], 10, 64)
+			if err != nil {
+				continue
+			}
+			if firstRound <= round && round <= lastRound {
+				return key, firstRound, lastRound, nil
+			}
+		}
+	}
+	return "", 0, 0, fmt.Errorf("no uploaded batch contains round %d", round)
 }
+
+// uploadBatch uploads an encoded batch to s3 under the key of its round range, see uploadBatchTo.
 func (s *S3StorageService) uploadBatch(ctx context.Context, batch []byte, firstRound, lastRound uint64) error {
-	compressedData, err := gzip.CompressGzip(batch)
-	if err != nil {
-		return err
+	return s.uploadBatchTo(ctx, batch, s.getBatchName(firstRound, lastRound))
+}
+
+// uploadBatchTo uploads an encoded batch to s3 under the given key, csv batches are gzip compressed before upload.
+// If VerifyBeforeDelete is enabled the batch is read back, and an error is returned if it doesn't match.
+func (s *S3StorageService) uploadBatchTo(ctx context.Context, batch []byte, key string) error {
+	data := batch
+	if !s.isParquet() {
+		compressedData, err := gzip.CompressGzip(batch)
+		if err != nil {
+			return err
+		}
+		data = compressedData
 	}
-	key := s.getBatchName(firstRound, lastRound)
 	putObjectInput := s3.PutObjectInput{
 		Bucket: aws.String(s.bucket),
 		Key:    aws.String(key),
-		Body:   bytes.NewReader(compressedData),
 	}
-	if _, err = s.client.Upload(ctx, &putObjectInput); err != nil {
+	if !s.isParquet() {
+		putObjectInput.Metadata = map[string]string{csvFormatVersionMetadataKey: strconv.Itoa(csvFormatVersion)}
+	}
+	// Encrypted objects are decrypted by S3 on download, so only uploads need to specify the encryption
+	switch s.config.ServerSideEncryption {
+	case S3StorageEncryptionSSES3:
+		putObjectInput.ServerSideEncryption = s3types.ServerSideEncryptionAes256
+	case S3StorageEncryptionSSEKMS:
+		putObjectInput.ServerSideEncryption = s3types.ServerSideEncryptionAwsKms
+		if s.config.KmsKeyId != "" {
+			putObjectInput.SSEKMSKeyId = aws.String(s.config.KmsKeyId)
+		}
+	}
+	// Retries reuse the key, so that an upload that went through despite failing is overwritten rather than duplicated
+	backoff := s.config.UploadRetryBackoff
+	for attempt := 0; ; attempt++ {
+		putObjectInput.Body = bytes.NewReader(data)
+		_, err := s.client.Upload(ctx, &putObjectInput)
+		if err == nil {
+			break
+		}
+		if attempt >= s.config.UploadRetries {
+			return fmt.Errorf("%w: %s: %w", ErrS3Upload, key, err)
+		}
+		log.Warn("Error uploading batch to s3, retrying", "key", key, "attempt", attempt+1, "backoff", backoff, "err", err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %s: %w", ErrS3Upload, key, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	if s.config.VerifyBeforeDelete {
+		return s.verifyUploadedBatch(ctx, key, data)
+	}
+	return nil
+}
+
+// ReuploadRound regenerates the uploaded batch containing a single round from the bids still in the sql db and uploads
+// it over the original object, e.g. to replace an archived batch found to be corrupt. The batch has to have been written
+// in the configured format, and an error is returned if any of its bids are no longer in the sql db.
+func (s *S3StorageService) ReuploadRound(ctx context.Context, round uint64) error {
+	key, firstRound, lastRound, err := s.findBatch(ctx, round)
+	if err != nil {
 		return err
 	}
+	if !strings.HasSuffix(key, s.batchExtension()) {
+		return fmt.Errorf("batch %s containing round %d was not written in the configured %s format", key, round, s.config.Format)
+	}
+	bids, err := s.sqlDB.GetBidsOfRounds(firstRound, lastRound)
+	if err != nil {
+		return fmt.Errorf("error fetching bids of rounds %d to %d from sql db: %w", firstRound, lastRound, err)
+	}
+	// Batches start with a round that has bids, and bids are deleted from the lowest round up,
+	// so the batch can only be regenerated in full if its first round's bids are still there
+	if len(bids) == 0 || bids[0].Round != firstRound {
+		return fmt.Errorf("bids of batch %s are no longer in the sql db, they may have been deleted after upload", key)
+	}
+	data, err := s.encodeBatch(bids)
+	if err != nil {
+		return fmt.Errorf("error encoding bids of batch %s: %w", key, err)
+	}
+	return s.uploadBatchTo(ctx, data, key)
+}
+
+// verifyUploadedBatch downloads the object at key and checks that it matches the uploaded data
+func (s *S3StorageService) verifyUploadedBatch(ctx context.Context, key string, data []byte) error {
+	downloaded, err := s.download(ctx, key)
+	if err != nil {
+		return fmt.Errorf("error downloading uploaded batch %s for verification: %w", key, err)
+	}
+	if !bytes.Equal(downloaded, data) {
+		return fmt.Errorf("uploaded batch %s does not match local data, downloaded %d bytes but uploaded %d bytes", key, len(downloaded), len(data))
+	}
 	return nil
 }
 
-// downloadBatch is only used for testing purposes
-func (s *S3StorageService) downloadBatch(ctx context.Context, key string) ([]byte, error) {
+// download returns the raw content of the object at key, failures are wrapped in ErrKeyNotFound
+// if the object doesn't exist and in ErrS3Download otherwise.
+func (s *S3StorageService) download(ctx context.Context, key string) ([]byte, error) {
 	buf := manager.NewWriteAtBuffer([]byte{})
 	if _, err := s.client.Download(ctx, buf, &s3.GetObjectInput{
 		Bucket: aws.String(s.bucket),
 		Key:    aws.String(key),
 	}); err != nil {
+		var noSuchKey *s3types.NoSuchKey
+		var notFound *s3types.NotFound
+		if errors.As(err, &noSuchKey) || errors.As(err, &notFound) {
+			return nil, fmt.Errorf("%w: %s: %w", ErrKeyNotFound, key, err)
+		}
+		return nil, fmt.Errorf("%w: %s: %w", ErrS3Download, key, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// downloadBatch is only used for testing purposes, the format of the batch is detected from the key's extension
+func (s *S3StorageService) downloadBatch(ctx context.Context, key string) ([]byte, error) {
+	data, err := s.download(ctx, key)
+	if err != nil {
 		return nil, err
 	}
-	return gzip.DecompressGzip(buf.Bytes())
+	if strings.HasSuffix(key, parquetBatchExtension) {
+		return data, nil
+	}
+	return gzip.DecompressGzip(data)
+}
+
+// csvFormatVersion is stored in the csvFormatVersionMetadataKey metadata of every uploaded csv object so that readers
+// can tell archives apart as the bid schema evolves. Objects uploaded before the metadata was added are version 1.
+const csvFormatVersion = 2
+
+const csvFormatVersionMetadataKey = "csv-format-version"
+
+// csvHeaders lists the columns of each version of the csv format, indexed by version - 1. Version 2 added the bid
+// expiry.
+var csvHeaders = [][]string{
+	{"ChainID", "Bidder", "ExpressLaneController", "AuctionContractAddress", "Round", "Amount", "Signature"},
+	{"ChainID", "Bidder", "ExpressLaneController", "AuctionContractAddress", "Round", "Amount", "Signature", "Expiry"},
+}
+
+var csvHeader = csvHeaders[csvFormatVersion-1]
+
+func csvRecord(bid *SqliteDatabaseBid) []string {
+	return []string{bid.ChainId, bid.Bidder, bid.ExpressLaneController, bid.AuctionContractAddress, fmt.Sprintf("%d", bid.Round), bid.Amount, bid.Signature, fmt.Sprintf("%d", bid.Expiry)}
+}
+
+// csvFormatVersionOf returns the csv format version recorded in the metadata of an uploaded object.
+func csvFormatVersionOf(metadata map[string]string) (int, error) {
+	value, ok := metadata[csvFormatVersionMetadataKey]
+	if !ok {
+		return 1, nil
+	}
+	version, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid csv format version %q: %w", value, err)
+	}
+	if version < 1 || version > len(csvHeaders) {
+		return 0, fmt.Errorf("unsupported csv format version %d, latest known version is %d", version, csvFormatVersion)
+	}
+	return version, nil
+}
+
+// decodeBidsFromCsv parses a csv batch of the given format version. Columns are mapped by their header name, so their
+// order doesn't matter, but the batch must have exactly the columns of its version. Bids of versions without an expiry
+// are returned with a zero expiry.
+func decodeBidsFromCsv(data []byte, version int) ([]*SqliteDatabaseBid, error) {
+	if version < 1 || version > len(csvHeaders) {
+		return nil, fmt.Errorf("unsupported csv format version %d, latest known version is %d", version, csvFormatVersion)
+	}
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading csv batch: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("csv batch has no header")
+	}
+	columns := make(map[string]int, len(records[0]))
+	for i, name := range records[0] {
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("csv batch has duplicate column %s", name)
+		}
+		columns[name] = i
+	}
+	headers := csvHeaders[version-1]
+	for _, name := range headers {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("csv batch of version %d is missing column %s", version, name)
+		}
+	}
+	if len(columns) != len(headers) {
+		return nil, fmt.Errorf("csv batch of version %d has %d columns, expected %d", version, len(columns), len(headers))
+	}
+	bids := make([]*SqliteDatabaseBid, 0, len(records)-1)
+	for i, record := range records[1:] {
+		bid := &SqliteDatabaseBid{
+			ChainId:                record[columns["ChainID"]],
+			Bidder:                 record[columns["Bidder"]],
+			ExpressLaneController:  record[columns["ExpressLaneController"]],
+			AuctionContractAddress: record[columns["AuctionContractAddress"]],
+			Amount:                 record[columns["Amount"]],
+			Signature:              record[columns["Signature"]],
+		}
+		if bid.Round, err = strconv.ParseUint(record[columns["Round"]], 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid round %q in csv record %d: %w", record[columns["Round"]], i, err)
+		}
+		if expiryIndex, ok := columns["Expiry"]; ok {
+			if bid.Expiry, err = strconv.ParseUint(record[expiryIndex], 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid expiry %q in csv record %d: %w", record[expiryIndex], i, err)
+			}
+		}
+		bids = append(bids, bid)
+	}
+	return bids, nil
+}
+
+func encodeBidsToCsv(bids []*SqliteDatabaseBid) ([]byte, error) {
+	var csvBuffer bytes.Buffer
+	csvWriter := csv.NewWriter(&csvBuffer)
+	if err := csvWriter.Write(csvHeader); err != nil {
+		return nil, err
+	}
+	for _, bid := range bids {
+		if err := csvWriter.Write(csvRecord(bid)); err != nil {
+			return nil, err
+		}
+	}
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		return nil, err
+	}
+	return csvBuffer.Bytes(), nil
+}
+
+// encodeBatch encodes the bids of a batch in the configured format
+func (s *S3StorageService) encodeBatch(bids []*SqliteDatabaseBid) ([]byte, error) {
+	if s.isParquet() {
+		return encodeBidsToParquet(bids)
+	}
+	return encodeBidsToCsv(bids)
 }
 
 func csvRecordSize(record []string) int {
@@ -161,7 +550,7 @@ func csvRecordSize(record []string) int {
 func (s *S3StorageService) uploadBatches(ctx context.Context) time.Duration {
 	// Before doing anything first try to delete the previously uploaded bids that were not successfully erased from the sqlDB
 	if s.lastFailedDeleteRound != 0 {
-		if err := s.sqlDB.DeleteBids(s.lastFailedDeleteRound); err != nil {
+		if err := s.sqlDB.DeleteUploadedBids(s.lastFailedDeleteRound, s.config.DeleteBatchSize); err != nil {
 			log.Error("error deleting s3-persisted bids from sql db using lastFailedDeleteRound", "lastFailedDeleteRound", s.lastFailedDeleteRound, "err", err)
 			return 5 * time.Second
 		}
@@ -175,27 +564,33 @@ func (s *S3StorageService) uploadBatches(ctx context.Context) time.Duration {
 	}
 	// Nothing to persist or a contiguous set of bids wasn't found, so exit early
 	if len(bids) == 0 {
-		return s.config.UploadInterval
+		return s.deleteBidsOutsideRetention()
 	}
 
-	var csvBuffer bytes.Buffer
 	var size int
 	var firstBidId int
-	csvWriter := csv.NewWriter(&csvBuffer)
-	uploadAndDeleteBids := func(firstRound, lastRound, deletRound uint64) error {
+	uploadAndDeleteBids := func(batch []*SqliteDatabaseBid, firstRound, lastRound, deletRound uint64) error {
 		// End current batch when size exceeds MaxBatchSize and the current round ends
-		csvWriter.Flush()
-		if err := csvWriter.Error(); err != nil {
-			log.Error("Error flushing csv writer", "err", err)
+		data, err := s.encodeBatch(batch)
+		if err != nil {
+			log.Error("Error encoding batch", "format", s.config.Format, "err", err)
 			return err
 		}
-		if err := s.uploadBatch(ctx, csvBuffer.Bytes(), firstRound, lastRound); err != nil {
+		if err := s.uploadBatch(ctx, data, firstRound, lastRound); err != nil {
 			log.Error("Error uploading batch to s3", "firstRound", firstRound, "lastRound", lastRound, "err", err)
 			return err
 		}
-		// After successful upload we should go ahead and delete the uploaded bids from DB to prevent duplicate uploads
-		// If the delete fails, we track the deleteRound until a future delete succeeds.
-		if err := s.sqlDB.DeleteBids(deletRound); err != nil {
+		// After successful upload we should go ahead and mark the uploaded bids to prevent duplicate uploads,
+		// retained bids are deleted once they fall out of the retention window
+		if s.config.LocalRetentionRounds > 0 {
+			if err := s.sqlDB.MarkBidsUploaded(deletRound); err != nil {
+				log.Error("error marking s3-persisted bids as uploaded in sql db", "round", deletRound, "err", err)
+				return err
+			}
+			return nil
+		}
+		// Marking and deleting happen in one transaction. If it fails, we track the deleteRound until a future delete succeeds.
+		if err := s.sqlDB.DeleteUploadedBids(deletRound, s.config.DeleteBatchSize); err != nil {
 			log.Error("error deleting s3-persisted bids from sql db", "round", deletRound, "err", err)
 			s.lastFailedDeleteRound = deletRound
 		} else {
@@ -205,27 +600,11 @@ func (s *S3StorageService) uploadBatches(ctx context.Context) time.Duration {
 		return nil
 	}
 
-	header := []string{"ChainID", "Bidder", "ExpressLaneController", "AuctionContractAddress", "Round", "Amount", "Signature"}
-	if err := csvWriter.Write(header); err != nil {
-		log.Error("Error writing to csv writer", "err", err)
-		return 5 * time.Second
-	}
 	for index, bid := range bids {
-		record := []string{bid.ChainId, bid.Bidder, bid.ExpressLaneController, bid.AuctionContractAddress, fmt.Sprintf("%d", bid.Round), bid.Amount, bid.Signature}
-		if err := csvWriter.Write(record); err != nil {
-			log.Error("Error writing to csv writer", "err", err)
-			return 5 * time.Second
-		}
 		if s.config.MaxBatchSize != 0 {
-			size += csvRecordSize(record)
+			size += csvRecordSize(csvRecord(bid))
 			if size >= s.config.MaxBatchSize && index < len(bids)-1 && bid.Round != bids[index+1].Round {
-				if uploadAndDeleteBids(bids[firstBidId].Round, bid.Round, bids[index+1].Round) != nil {
-					return 5 * time.Second
-				}
-				// Reset csv for next batch
-				csvBuffer.Reset()
-				if err := csvWriter.Write(header); err != nil {
-					log.Error("Error writing to csv writer", "err", err)
+				if uploadAndDeleteBids(bids[firstBidId:index+1], bids[firstBidId].Round, bid.Round, bids[index+1].Round) != nil {
 					return 5 * time.Second
 				}
 				size = 0
@@ -234,7 +613,7 @@ func (s *S3StorageService) uploadBatches(ctx context.Context) time.Duration {
 		}
 	}
 	if s.config.MaxBatchSize == 0 || size > 0 {
-		if uploadAndDeleteBids(bids[firstBidId].Round, bids[len(bids)-1].Round, round) != nil {
+		if uploadAndDeleteBids(bids[firstBidId:], bids[firstBidId].Round, bids[len(bids)-1].Round, round) != nil {
 			return 5 * time.Second
 		}
 	}
@@ -243,5 +622,29 @@ func (s *S3StorageService) uploadBatches(ctx context.Context) time.Duration {
 		return 5 * time.Second
 	}
 
+	return s.deleteBidsOutsideRetention()
+}
+
+// deleteBidsOutsideRetention deletes the uploaded bids that are older than the local retention window, it is
+// a no-op when retention is disabled as uploaded bids are then deleted right after upload.
+func (s *S3StorageService) deleteBidsOutsideRetention() time.Duration {
+	if s.config.LocalRetentionRounds == 0 {
+		return s.config.UploadInterval
+	}
+	_, _, _, latestRound, err := s.sqlDB.Stats()
+	if err != nil {
+		log.Error("Error fetching latest round from sql DB", "err", err)
+		return 5 * time.Second
+	}
+	uploadedRound, err := s.sqlDB.UploadedRound()
+	if err != nil {
+		log.Error("Error fetching uploaded round from sql DB", "err", err)
+		return 5 * time.Second
+	}
+	deleteRound := arbmath.MinInt(uploadedRound, arbmath.SaturatingUSub(latestRound, s.config.LocalRetentionRounds))
+	if err := s.sqlDB.DeleteBids(deleteRound); err != nil {
+		log.Error("error deleting s3-persisted bids outside of local retention from sql db", "round", deleteRound, "err", err)
+		return 5 * time.Second
+	}
 	return s.config.UploadInterval
 }
@@ -3,30 +3,56 @@ package timeboost
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/stretchr/testify/require"
 
 	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/util/gzip"
 )
 
 type mockS3FullClient struct {
-	data map[string][]byte
+	data     map[string][]byte
+	metadata map[string]map[string]string
+	// Simulates silently failed uploads by storing corrupted data
+	corruptUploads bool
+	// Simulates a bucket that doesn't exist or that the credentials can't access
+	inaccessibleBucket bool
+	// Simulates network or auth failures of uploads and downloads
+	failRequests bool
+	// The input of the last successful upload
+	lastUpload *s3.PutObjectInput
+	// Simulates transient failures of the given number of uploads
+	failNextUploads int
+	uploadAttempts  int
 }
 
 func newmockS3FullClient() *mockS3FullClient {
-	return &mockS3FullClient{make(map[string][]byte)}
+	return &mockS3FullClient{data: make(map[string][]byte), metadata: make(map[string]map[string]string)}
 }
 
 func (m *mockS3FullClient) clear() {
 	m.data = make(map[string][]byte)
+	m.metadata = make(map[string]map[string]string)
 }
 
 func (m *mockS3FullClient) Client() *s3.Client {
@@ -34,16 +60,57 @@ func (m *mockS3FullClient) Client() *s3.Client {
 }
 
 func (m *mockS3FullClient) Upload(ctx context.Context, input *s3.PutObjectInput, opts ...func(*manager.Uploader)) (*manager.UploadOutput, error) {
+	m.uploadAttempts++
+	if m.failRequests {
+		return nil, errors.New("connection reset")
+	}
+	if m.failNextUploads > 0 {
+		m.failNextUploads--
+		return nil, errors.New("connection reset")
+	}
 	buf := new(bytes.Buffer)
 	_, err := buf.ReadFrom(input.Body)
 	if err != nil {
 		return nil, err
 	}
 	m.data[*input.Key] = buf.Bytes()
+	m.metadata[*input.Key] = input.Metadata
+	m.lastUpload = input
+	if m.corruptUploads {
+		m.data[*input.Key] = buf.Bytes()[:buf.Len()/2]
+	}
 	return nil, nil
 }
 
+func (m *mockS3FullClient) HeadBucket(ctx context.Context, input *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error) {
+	if m.inaccessibleBucket {
+		return nil, errors.New("forbidden")
+	}
+	return &s3.HeadBucketOutput{}, nil
+}
+
+func (m *mockS3FullClient) ListObjectsV2(ctx context.Context, input *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	if m.failRequests {
+		return nil, errors.New("connection reset")
+	}
+	var keys []string
+	for key := range m.data {
+		if strings.HasPrefix(key, aws.ToString(input.Prefix)) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	output := &s3.ListObjectsV2Output{}
+	for _, key := range keys {
+		output.Contents = append(output.Contents, s3types.Object{Key: aws.String(key)})
+	}
+	return output, nil
+}
+
 func (m *mockS3FullClient) Download(ctx context.Context, w io.WriterAt, input *s3.GetObjectInput, options ...func(*manager.Downloader)) (n int64, err error) {
+	if m.failRequests {
+		return 0, errors.New("connection reset")
+	}
 	if _, ok := m.data[*input.Key]; ok {
 		ret, err := w.WriteAt(m.data[*input.Key], 0)
 		if err != nil {
@@ -51,7 +118,36 @@ func (m *mockS3FullClient) Download(ctx context.Context, w io.WriterAt, input *s
 		}
 		return int64(ret), nil
 	}
-	return 0, errors.New("key not found")
+	return 0, &s3types.NoSuchKey{Message: aws.String("key not found")}
+}
+
+// fixedClock dates uploaded batches on a fixed day, so that batch keys don't depend on when the tests run
+func fixedClock() time.Time {
+	return time.Date(2024, time.March, 7, 23, 59, 59, 0, time.UTC)
+}
+
+// testBid returns a bid of the given round from the bidder with the given index.
+func testBid(round uint64, bidder int) *ValidatedBid {
+	return &ValidatedBid{
+		ChainId:                big.NewInt(1),
+		ExpressLaneController:  common.HexToAddress("0x0000000000000000000000000000000000000001"),
+		AuctionContractAddress: common.HexToAddress("0x0000000000000000000000000000000000000002"),
+		Bidder:                 common.BigToAddress(big.NewInt(int64(bidder + 3))),
+		Round:                  round,
+		Amount:                 big.NewInt(100),
+		Signature:              []byte(fmt.Sprintf("signature%d-%d", round, bidder)),
+	}
+}
+
+// insertTestBids inserts bids from biddersPerRound bidders for each of the rounds before numRounds into db.
+func insertTestBids(t *testing.T, db *SqliteDatabase, numRounds uint64, biddersPerRound int) {
+	var bids []*ValidatedBid
+	for round := uint64(0); round < numRounds; round++ {
+		for bidder := 0; bidder < biddersPerRound; bidder++ {
+			bids = append(bids, testBid(round, bidder))
+		}
+	}
+	require.NoError(t, db.InsertBids(bids))
 }
 
 func TestS3StorageServiceUploadAndDownload(t *testing.T) {
@@ -61,6 +157,7 @@ func TestS3StorageServiceUploadAndDownload(t *testing.T) {
 	mockClient := newmockS3FullClient()
 	s3StorageService := &S3StorageService{
 		client: mockClient,
+		now:    fixedClock,
 		config: &S3StorageServiceConfig{MaxBatchSize: 0},
 	}
 
@@ -122,9 +219,9 @@ func TestS3StorageServiceUploadAndDownload(t *testing.T) {
 
 	// UploadBatches should upload only the first bid and only one bid (round = 2) should remain in the sql database
 	s3StorageService.uploadBatches(ctx)
-	verifyBatchUploadCorrectness(0, 1, []byte(fmt.Sprintf(`ChainID,Bidder,ExpressLaneController,AuctionContractAddress,Round,Amount,Signature
-2,0x0000000000000000000000000000000000000003,0x0000000000000000000000000000000000000001,0x0000000000000000000000000000000000000002,0,10,%s
-1,0x0000000000000000000000000000000000000003,0x0000000000000000000000000000000000000001,0x0000000000000000000000000000000000000002,1,100,%s
+	verifyBatchUploadCorrectness(0, 1, []byte(fmt.Sprintf(`ChainID,Bidder,ExpressLaneController,AuctionContractAddress,Round,Amount,Signature,Expiry
+2,0x0000000000000000000000000000000000000003,0x0000000000000000000000000000000000000001,0x0000000000000000000000000000000000000002,0,10,%s,0
+1,0x0000000000000000000000000000000000000003,0x0000000000000000000000000000000000000001,0x0000000000000000000000000000000000000002,1,100,%s,0
 `, hex.EncodeToString([]byte("signature0")), hex.EncodeToString([]byte("signature1")))))
 	checkUploadedBidsRemoval(2)
 
@@ -156,20 +253,20 @@ func TestS3StorageServiceUploadAndDownload(t *testing.T) {
 		Amount:                 big.NewInt(350),
 		Signature:              []byte("signature5"),
 	}))
-	record := []string{sqlDBbids[0].ChainId, sqlDBbids[0].Bidder, sqlDBbids[0].ExpressLaneController, sqlDBbids[0].AuctionContractAddress, fmt.Sprintf("%d", sqlDBbids[0].Round), sqlDBbids[0].Amount, sqlDBbids[0].Signature}
+	record := []string{sqlDBbids[0].ChainId, sqlDBbids[0].Bidder, sqlDBbids[0].ExpressLaneController, sqlDBbids[0].AuctionContractAddress, fmt.Sprintf("%d", sqlDBbids[0].Round), sqlDBbids[0].Amount, sqlDBbids[0].Signature, fmt.Sprintf("%d", sqlDBbids[0].Expiry)}
 	s3StorageService.config.MaxBatchSize = csvRecordSize(record)
 
 	// Round 2 bids should all be in the same batch even though the resulting batch exceeds MaxBatchSize
 	s3StorageService.uploadBatches(ctx)
-	verifyBatchUploadCorrectness(2, 2, []byte(fmt.Sprintf(`ChainID,Bidder,ExpressLaneController,AuctionContractAddress,Round,Amount,Signature
-2,0x0000000000000000000000000000000000000006,0x0000000000000000000000000000000000000004,0x0000000000000000000000000000000000000005,2,200,%s
-1,0x0000000000000000000000000000000000000009,0x0000000000000000000000000000000000000007,0x0000000000000000000000000000000000000008,2,150,%s
+	verifyBatchUploadCorrectness(2, 2, []byte(fmt.Sprintf(`ChainID,Bidder,ExpressLaneController,AuctionContractAddress,Round,Amount,Signature,Expiry
+2,0x0000000000000000000000000000000000000006,0x0000000000000000000000000000000000000004,0x0000000000000000000000000000000000000005,2,200,%s,0
+1,0x0000000000000000000000000000000000000009,0x0000000000000000000000000000000000000007,0x0000000000000000000000000000000000000008,2,150,%s,0
 `, hex.EncodeToString([]byte("signature2")), hex.EncodeToString([]byte("signature3")))))
 
 	// After Batching Round 2 bids we end that batch and create a new batch for Round 3 bids to adhere to MaxBatchSize rule
 	s3StorageService.uploadBatches(ctx)
-	verifyBatchUploadCorrectness(3, 3, []byte(fmt.Sprintf(`ChainID,Bidder,ExpressLaneController,AuctionContractAddress,Round,Amount,Signature
-2,0x0000000000000000000000000000000000000003,0x0000000000000000000000000000000000000001,0x0000000000000000000000000000000000000002,3,250,%s
+	verifyBatchUploadCorrectness(3, 3, []byte(fmt.Sprintf(`ChainID,Bidder,ExpressLaneController,AuctionContractAddress,Round,Amount,Signature,Expiry
+2,0x0000000000000000000000000000000000000003,0x0000000000000000000000000000000000000001,0x0000000000000000000000000000000000000002,3,250,%s,0
 `, hex.EncodeToString([]byte("signature4")))))
 	checkUploadedBidsRemoval(4)
 
@@ -224,16 +321,559 @@ func TestS3StorageServiceUploadAndDownload(t *testing.T) {
 	// Since config.MaxBatchSize is kept same and config.MaxDbRows is 5, sqldb.GetBids would return all bids from round 4 and 5, with round used for DeletBids as 6
 	// maxBatchSize would then batch bids from round 4 & 5 separately and uploads them to s3
 	s3StorageService.uploadBatches(ctx)
-	verifyBatchUploadCorrectness(4, 4, []byte(fmt.Sprintf(`ChainID,Bidder,ExpressLaneController,AuctionContractAddress,Round,Amount,Signature
-2,0x0000000000000000000000000000000000000006,0x0000000000000000000000000000000000000004,0x0000000000000000000000000000000000000005,4,350,%s
-1,0x0000000000000000000000000000000000000009,0x0000000000000000000000000000000000000007,0x0000000000000000000000000000000000000008,4,450,%s
+	verifyBatchUploadCorrectness(4, 4, []byte(fmt.Sprintf(`ChainID,Bidder,ExpressLaneController,AuctionContractAddress,Round,Amount,Signature,Expiry
+2,0x0000000000000000000000000000000000000006,0x0000000000000000000000000000000000000004,0x0000000000000000000000000000000000000005,4,350,%s,0
+1,0x0000000000000000000000000000000000000009,0x0000000000000000000000000000000000000007,0x0000000000000000000000000000000000000008,4,450,%s,0
 `, hex.EncodeToString([]byte("signature5")), hex.EncodeToString([]byte("signature6")))))
-	verifyBatchUploadCorrectness(5, 5, []byte(fmt.Sprintf(`ChainID,Bidder,ExpressLaneController,AuctionContractAddress,Round,Amount,Signature
-2,0x0000000000000000000000000000000000000003,0x0000000000000000000000000000000000000001,0x0000000000000000000000000000000000000002,5,550,%s
-2,0x0000000000000000000000000000000000000006,0x0000000000000000000000000000000000000004,0x0000000000000000000000000000000000000005,5,650,%s
+	verifyBatchUploadCorrectness(5, 5, []byte(fmt.Sprintf(`ChainID,Bidder,ExpressLaneController,AuctionContractAddress,Round,Amount,Signature,Expiry
+2,0x0000000000000000000000000000000000000003,0x0000000000000000000000000000000000000001,0x0000000000000000000000000000000000000002,5,550,%s,0
+2,0x0000000000000000000000000000000000000006,0x0000000000000000000000000000000000000004,0x0000000000000000000000000000000000000005,5,650,%s,0
 `, hex.EncodeToString([]byte("signature7")), hex.EncodeToString([]byte("signature8")))))
 	require.NoError(t, db.sqlDB.Select(&sqlDBbids, "SELECT * FROM Bids ORDER BY Round ASC"))
 	require.Equal(t, 2, len(sqlDBbids))
 	require.Equal(t, uint64(6), sqlDBbids[0].Round)
 	require.Equal(t, uint64(7), sqlDBbids[1].Round)
 }
+
+func TestS3StorageServiceParquetRoundTrip(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockClient := newmockS3FullClient()
+	db, err := NewDatabase(t.TempDir())
+	require.NoError(t, err)
+	s3StorageService := &S3StorageService{
+		client: mockClient,
+		now:    fixedClock,
+		config: &S3StorageServiceConfig{Format: S3StorageFormatParquet},
+		sqlDB:  db,
+	}
+	for round := uint64(0); round < 3; round++ {
+		bid := testBid(round, 0)
+		bid.Amount = new(big.Int).Lsh(big.NewInt(int64(round+1)), 200) // Larger than uint64
+		require.NoError(t, db.InsertBid(bid))
+	}
+	var wantBids []*SqliteDatabaseBid
+	require.NoError(t, db.sqlDB.Select(&wantBids, "SELECT * FROM Bids WHERE Round < 2 ORDER BY Round ASC"))
+	for _, bid := range wantBids {
+		bid.Id = 0
+	}
+
+	// Bids from rounds 0 and 1 should be uploaded as a parquet file
+	s3StorageService.uploadBatches(ctx)
+	key := s3StorageService.getBatchName(0, 1)
+	require.True(t, strings.HasSuffix(key, ".parquet"))
+	data, err := s3StorageService.downloadBatch(ctx, key)
+	require.NoError(t, err)
+	gotBids, err := decodeBidsFromParquet(data)
+	require.NoError(t, err)
+	require.Equal(t, wantBids, gotBids)
+
+	// Csv batches are still downloadable by the same service since the format is detected from the key
+	s3StorageService.config.Format = S3StorageFormatCSV
+	require.NoError(t, s3StorageService.uploadBatch(ctx, []byte{1, 2, 3}, 5, 6))
+	s3StorageService.config.Format = S3StorageFormatParquet
+	csvData, err := s3StorageService.downloadBatch(ctx, strings.Replace(key, "0000000-0000001.parquet", "0000005-0000006.csv.gzip", 1))
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, csvData)
+}
+
+// parquetTestBids are the bids of testdata/validated_bids.parquet.
+var parquetTestBids = []*SqliteDatabaseBid{
+	{
+		ChainId:                "42161",
+		Bidder:                 "0x0000000000000000000000000000000000000003",
+		ExpressLaneController:  "0x0000000000000000000000000000000000000001",
+		AuctionContractAddress: "0x0000000000000000000000000000000000000002",
+		Round:                  7,
+		Amount:                 "1000000000000000000",
+		Signature:              "01020304",
+		Expiry:                 1700000000,
+	},
+	{
+		ChainId:                "42161",
+		Bidder:                 "0x0000000000000000000000000000000000000004",
+		ExpressLaneController:  "0x0000000000000000000000000000000000000004",
+		AuctionContractAddress: "0x0000000000000000000000000000000000000002",
+		Round:                  8,
+		Amount:                 "5",
+		Signature:              "0a0b0c",
+		Expiry:                 0,
+	},
+}
+
+// TestParquetGoldenFile checks the encoder's output against testdata/validated_bids.parquet, so that changes to the
+// hand-written encoder can't go unnoticed. The golden file must only be replaced by one that
+// TestParquetReadableByPyarrow reads back as parquetTestBids.
+func TestParquetGoldenFile(t *testing.T) {
+	golden, err := os.ReadFile("testdata/validated_bids.parquet")
+	require.NoError(t, err)
+
+	data, err := encodeBidsToParquet(parquetTestBids)
+	require.NoError(t, err)
+	require.Equal(t, golden, data)
+
+	gotBids, err := decodeBidsFromParquet(golden)
+	require.NoError(t, err)
+	require.Equal(t, parquetTestBids, gotBids)
+}
+
+// readParquetWithPyarrow prints the rows of a parquet file as json, with binary values hex encoded.
+const readParquetWithPyarrow = `
+import json, sys
+try:
+    import pyarrow.parquet as pq
+except ImportError:
+    sys.exit(3)
+rows = pq.read_table(sys.argv[1]).to_pylist()
+print(json.dumps([{k: v.hex() if isinstance(v, bytes) else v for k, v in row.items()} for row in rows]))
+`
+
+// TestParquetReadableByPyarrow checks that the encoder's output is read back by pyarrow, a widely used parquet
+// implementation, as the encoded bids. It is skipped where python3 with pyarrow isn't installed.
+func TestParquetReadableByPyarrow(t *testing.T) {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 not installed")
+	}
+	data, err := encodeBidsToParquet(parquetTestBids)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "bids.parquet")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	out, err := exec.Command(python, "-c", readParquetWithPyarrow, path).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 3 {
+		t.Skip("pyarrow not installed")
+	}
+	require.NoError(t, err)
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	decoder.UseNumber()
+	var rows []map[string]any
+	require.NoError(t, decoder.Decode(&rows))
+
+	addressHex := func(address string) string {
+		return hex.EncodeToString(common.HexToAddress(address).Bytes())
+	}
+	var want []map[string]any
+	for _, bid := range parquetTestBids {
+		want = append(want, map[string]any{
+			"chain_id":                 json.Number(bid.ChainId),
+			"bidder":                   addressHex(bid.Bidder),
+			"express_lane_controller":  addressHex(bid.ExpressLaneController),
+			"auction_contract_address": addressHex(bid.AuctionContractAddress),
+			"round":                    json.Number(strconv.FormatUint(bid.Round, 10)),
+			"amount":                   bid.Amount,
+			"signature":                bid.Signature,
+			"expiry":                   json.Number(strconv.FormatUint(bid.Expiry, 10)),
+		})
+	}
+	require.Equal(t, want, rows)
+}
+
+// parquetFooterOnly returns a parquet file without data pages, holding the file metadata written by writeMetadata.
+func parquetFooterOnly(writeMetadata func(w *thriftWriter)) []byte {
+	var out bytes.Buffer
+	out.WriteString(parquetMagic)
+	footerStart := out.Len()
+	w := newThriftWriter(&out)
+	w.beginStruct()
+	writeMetadata(w)
+	w.endStruct()
+	out.Write(binary.LittleEndian.AppendUint32(nil, uint32(out.Len()-footerStart))) // #nosec G115
+	out.WriteString(parquetMagic)
+	return out.Bytes()
+}
+
+func TestParquetDecodeRejectsInvalidRowCounts(t *testing.T) {
+	for _, numRows := range []int64{-1, 1 << 40} {
+		_, err := decodeBidsFromParquet(parquetFooterOnly(func(w *thriftWriter) {
+			w.i64(3, numRows)
+			w.list(4, thriftTypeStruct, 0)
+		}))
+		require.ErrorContains(t, err, "invalid parquet row count")
+	}
+	for _, groupRows := range []int64{-1, 2, 1 << 40} {
+		_, err := decodeBidsFromParquet(parquetFooterOnly(func(w *thriftWriter) {
+			w.i64(3, 1)
+			w.list(4, thriftTypeStruct, 1)
+			w.beginStruct()
+			w.list(1, thriftTypeStruct, 0)
+			w.i64(3, groupRows)
+			w.endStruct()
+		}))
+		require.ErrorContains(t, err, "invalid parquet row group row count")
+	}
+	// The row groups must add up to the row count of the file
+	_, err := decodeBidsFromParquet(parquetFooterOnly(func(w *thriftWriter) {
+		w.i64(3, 1)
+		w.list(4, thriftTypeStruct, 0)
+	}))
+	require.ErrorContains(t, err, "file metadata says 1")
+}
+
+func TestS3StorageServiceCsvVersions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockClient := newmockS3FullClient()
+	s3StorageService := &S3StorageService{
+		client: mockClient,
+		now:    fixedClock,
+		config: &S3StorageServiceConfig{Format: S3StorageFormatCSV},
+	}
+	wantBid := &SqliteDatabaseBid{
+		ChainId:                "1",
+		Bidder:                 "0x0000000000000000000000000000000000000003",
+		ExpressLaneController:  "0x0000000000000000000000000000000000000001",
+		AuctionContractAddress: "0x0000000000000000000000000000000000000002",
+		Round:                  7,
+		Amount:                 "100",
+		Signature:              hex.EncodeToString([]byte("signature")),
+		Expiry:                 12,
+	}
+
+	// Objects uploaded before the format version was recorded in their metadata are version 1, which has no expiry
+	v1 := []byte(fmt.Sprintf(`ChainID,Bidder,ExpressLaneController,AuctionContractAddress,Round,Amount,Signature
+1,0x0000000000000000000000000000000000000003,0x0000000000000000000000000000000000000001,0x0000000000000000000000000000000000000002,7,100,%s
+`, wantBid.Signature))
+	compressed, err := gzip.CompressGzip(v1)
+	require.NoError(t, err)
+	v1Key := s3StorageService.getBatchName(7, 7)
+	mockClient.data[v1Key] = compressed
+	version, err := csvFormatVersionOf(mockClient.metadata[v1Key])
+	require.NoError(t, err)
+	require.Equal(t, 1, version)
+	data, err := s3StorageService.downloadBatch(ctx, v1Key)
+	require.NoError(t, err)
+	bids, err := decodeBidsFromCsv(data, version)
+	require.NoError(t, err)
+	require.Len(t, bids, 1)
+	require.Equal(t, uint64(0), bids[0].Expiry)
+	require.Equal(t, wantBid.Round, bids[0].Round)
+	require.Equal(t, wantBid.Bidder, bids[0].Bidder)
+
+	// Current objects record their version in their metadata and round trip through encoding
+	v2, err := encodeBidsToCsv([]*SqliteDatabaseBid{wantBid})
+	require.NoError(t, err)
+	v2Key := s3StorageService.getBatchName(8, 8)
+	require.NoError(t, s3StorageService.uploadBatch(ctx, v2, 8, 8))
+	version, err = csvFormatVersionOf(mockClient.metadata[v2Key])
+	require.NoError(t, err)
+	require.Equal(t, csvFormatVersion, version)
+	data, err = s3StorageService.downloadBatch(ctx, v2Key)
+	require.NoError(t, err)
+	bids, err = decodeBidsFromCsv(data, version)
+	require.NoError(t, err)
+	require.Equal(t, []*SqliteDatabaseBid{wantBid}, bids)
+
+	// Columns are mapped by name
+	bids, err = decodeBidsFromCsv([]byte(fmt.Sprintf(`Round,Expiry,Signature,Amount,Bidder,ExpressLaneController,AuctionContractAddress,ChainID
+7,12,%s,100,0x0000000000000000000000000000000000000003,0x0000000000000000000000000000000000000001,0x0000000000000000000000000000000000000002,1
+`, wantBid.Signature)), 2)
+	require.NoError(t, err)
+	require.Equal(t, []*SqliteDatabaseBid{wantBid}, bids)
+
+	// Unknown versions and columns that don't match the version are rejected
+	_, err = csvFormatVersionOf(map[string]string{csvFormatVersionMetadataKey: "3"})
+	require.ErrorContains(t, err, "unsupported csv format version 3")
+	_, err = decodeBidsFromCsv(v2, 3)
+	require.ErrorContains(t, err, "unsupported csv format version 3")
+	_, err = decodeBidsFromCsv([]byte("ChainID,Bidder,ExpressLaneController,Round,Amount,Signature,Expiry\n1,0x3,0x1,7,100,00,12\n"), 2)
+	require.ErrorContains(t, err, "missing column AuctionContractAddress")
+	_, err = decodeBidsFromCsv(v2, 1)
+	require.ErrorContains(t, err, "csv batch of version 1 has 8 columns, expected 7")
+}
+
+func TestS3StorageServiceLocalRetention(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockClient := newmockS3FullClient()
+	db, err := NewDatabase(t.TempDir())
+	require.NoError(t, err)
+	s3StorageService := &S3StorageService{
+		client: mockClient,
+		now:    fixedClock,
+		config: &S3StorageServiceConfig{LocalRetentionRounds: 2},
+		sqlDB:  db,
+	}
+	localRounds := func() []uint64 {
+		var rounds []uint64
+		require.NoError(t, db.sqlDB.Select(&rounds, "SELECT Round FROM Bids ORDER BY Round ASC"))
+		return rounds
+	}
+	insertTestBids(t, db, 6, 1)
+
+	// Rounds 0 to 4 are uploaded, but the uploaded bids of rounds 3 and 4 are within the retention window of latest round 5
+	s3StorageService.uploadBatches(ctx)
+	_, err = s3StorageService.downloadBatch(ctx, s3StorageService.getBatchName(0, 4))
+	require.NoError(t, err)
+	require.Equal(t, []uint64{3, 4, 5}, localRounds())
+
+	// Retained bids are not uploaded again
+	mockClient.clear()
+	s3StorageService.uploadBatches(ctx)
+	require.Empty(t, mockClient.data)
+	require.Equal(t, []uint64{3, 4, 5}, localRounds())
+
+	// Once newer rounds come in the retained bids fall out of the retention window and are deleted
+	require.NoError(t, db.InsertBid(testBid(8, 0)))
+	s3StorageService.uploadBatches(ctx)
+	require.Len(t, mockClient.data, 1)
+	_, err = s3StorageService.downloadBatch(ctx, s3StorageService.getBatchName(5, 5))
+	require.NoError(t, err)
+	require.Equal(t, []uint64{8}, localRounds())
+}
+
+func TestS3StorageServiceVerifyBeforeDelete(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockClient := newmockS3FullClient()
+	db, err := NewDatabase(t.TempDir())
+	require.NoError(t, err)
+	s3StorageService := &S3StorageService{
+		client: mockClient,
+		now:    fixedClock,
+		config: &S3StorageServiceConfig{VerifyBeforeDelete: true},
+		sqlDB:  db,
+	}
+	insertTestBids(t, db, 3, 1)
+	countBids := func() int {
+		var count int
+		require.NoError(t, db.sqlDB.Get(&count, "SELECT COUNT(*) FROM Bids"))
+		return count
+	}
+
+	// A corrupted upload fails verification, so no bids are deleted and the upload is retried sooner
+	mockClient.corruptUploads = true
+	require.Equal(t, 5*time.Second, s3StorageService.uploadBatches(ctx))
+	require.Equal(t, 3, countBids())
+
+	// Once the upload goes through intact the uploaded bids are deleted
+	mockClient.corruptUploads = false
+	s3StorageService.uploadBatches(ctx)
+	require.Equal(t, 1, countBids())
+	_, err = s3StorageService.downloadBatch(ctx, s3StorageService.getBatchName(0, 1))
+	require.NoError(t, err)
+}
+
+func TestS3StorageServicePing(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockClient := newmockS3FullClient()
+	s3StorageService := &S3StorageService{
+		client: mockClient,
+		now:    fixedClock,
+		config: &S3StorageServiceConfig{Region: "us-east-1", Format: S3StorageFormatCSV},
+		bucket: "bids",
+	}
+	require.NoError(t, s3StorageService.Ping(ctx))
+	require.Equal(t, S3StorageInfo{Bucket: "bids", Region: "us-east-1", Format: S3StorageFormatCSV, Compression: "gzip"}, s3StorageService.Info())
+
+	mockClient.inaccessibleBucket = true
+	err := s3StorageService.Ping(ctx)
+	require.Error(t, err)
+	require.ErrorContains(t, err, "bids")
+}
+
+func TestS3StorageServiceErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockClient := newmockS3FullClient()
+	s3StorageService := &S3StorageService{
+		client: mockClient,
+		now:    fixedClock,
+		config: &S3StorageServiceConfig{},
+	}
+
+	// A missing object is distinguishable from a failed download
+	_, err := s3StorageService.downloadBatch(ctx, s3StorageService.getBatchName(0, 1))
+	require.ErrorIs(t, err, ErrKeyNotFound)
+	require.NotErrorIs(t, err, ErrS3Download)
+
+	mockClient.failRequests = true
+	_, err = s3StorageService.downloadBatch(ctx, s3StorageService.getBatchName(0, 1))
+	require.ErrorIs(t, err, ErrS3Download)
+	require.NotErrorIs(t, err, ErrKeyNotFound)
+
+	err = s3StorageService.uploadBatch(ctx, []byte{1, 2, 3}, 0, 1)
+	require.ErrorIs(t, err, ErrS3Upload)
+}
+
+func TestS3StorageServiceServerSideEncryption(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	kmsKeyId := "arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+	for _, tc := range []struct {
+		encryption string
+		kmsKeyId   string
+		wantSSE    s3types.ServerSideEncryption
+		wantKeyId  *string
+	}{
+		{S3StorageEncryptionNone, "", "", nil},
+		{S3StorageEncryptionSSES3, "", s3types.ServerSideEncryptionAes256, nil},
+		{S3StorageEncryptionSSEKMS, "", s3types.ServerSideEncryptionAwsKms, nil},
+		{S3StorageEncryptionSSEKMS, kmsKeyId, s3types.ServerSideEncryptionAwsKms, &kmsKeyId},
+	} {
+		config := &S3StorageServiceConfig{Enable: true, Format: S3StorageFormatCSV, ServerSideEncryption: tc.encryption, KmsKeyId: tc.kmsKeyId}
+		require.NoError(t, config.Validate())
+		mockClient := newmockS3FullClient()
+		s3StorageService := &S3StorageService{
+			client: mockClient,
+			now:    fixedClock,
+			config: config,
+		}
+		testData := []byte{1, 2, 3, 4}
+		require.NoError(t, s3StorageService.uploadBatch(ctx, testData, 0, 1))
+		require.Equal(t, tc.wantSSE, mockClient.lastUpload.ServerSideEncryption)
+		require.Equal(t, tc.wantKeyId, mockClient.lastUpload.SSEKMSKeyId)
+
+		// Downloads don't depend on the encryption
+		gotData, err := s3StorageService.downloadBatch(ctx, s3StorageService.getBatchName(0, 1))
+		require.NoError(t, err)
+		require.Equal(t, testData, gotData)
+	}
+
+	for _, keyId := range []string{"1234abcd-12ab-34cd-56ef-1234567890ab", "alias/bids", "arn:aws:kms:us-east-1:123456789012:alias/bids"} {
+		config := &S3StorageServiceConfig{Enable: true, Format: S3StorageFormatCSV, ServerSideEncryption: S3StorageEncryptionSSEKMS, KmsKeyId: keyId}
+		require.NoError(t, config.Validate(), keyId)
+	}
+	for _, config := range []*S3StorageServiceConfig{
+		{Enable: true, Format: S3StorageFormatCSV, ServerSideEncryption: S3StorageEncryptionSSEKMS, KmsKeyId: "not-a-key"},
+		{Enable: true, Format: S3StorageFormatCSV, ServerSideEncryption: S3StorageEncryptionSSES3, KmsKeyId: kmsKeyId},
+		{Enable: true, Format: S3StorageFormatCSV, ServerSideEncryption: "aes"},
+	} {
+		require.Error(t, config.Validate())
+	}
+}
+
+func TestS3StorageServiceUploadRetries(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockClient := newmockS3FullClient()
+	db, err := NewDatabase(t.TempDir())
+	require.NoError(t, err)
+	s3StorageService := &S3StorageService{
+		client: mockClient,
+		now:    fixedClock,
+		config: &S3StorageServiceConfig{UploadInterval: time.Minute, UploadRetries: 1, UploadRetryBackoff: time.Millisecond},
+		sqlDB:  db,
+	}
+	insertTestBids(t, db, 3, 1)
+	countBids := func() int {
+		var count int
+		require.NoError(t, db.sqlDB.Get(&count, "SELECT COUNT(*) FROM Bids"))
+		return count
+	}
+
+	// Failing more often than retried leaves the bids in the sql db
+	mockClient.failNextUploads = 2
+	require.Equal(t, 5*time.Second, s3StorageService.uploadBatches(ctx))
+	require.Equal(t, 2, mockClient.uploadAttempts)
+	require.Empty(t, mockClient.data)
+	require.Equal(t, 3, countBids())
+
+	// A transient failure is retried, and the batch is uploaded and its bids deleted once
+	mockClient.uploadAttempts = 0
+	mockClient.failNextUploads = 1
+	require.Equal(t, time.Minute, s3StorageService.uploadBatches(ctx))
+	require.Equal(t, 2, mockClient.uploadAttempts)
+	require.Len(t, mockClient.data, 1)
+	require.Equal(t, 1, countBids())
+	data, err := s3StorageService.downloadBatch(ctx, s3StorageService.getBatchName(0, 1))
+	require.NoError(t, err)
+	require.Equal(t, 3, len(strings.Split(strings.TrimSpace(string(data)), "\n")))
+	require.Equal(t, uint64(0), s3StorageService.lastFailedDeleteRound)
+}
+
+func TestS3StorageServiceDeleteBatchSize(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockClient := newmockS3FullClient()
+	db, err := NewDatabase(t.TempDir())
+	require.NoError(t, err)
+	s3StorageService := &S3StorageService{
+		client: mockClient,
+		now:    fixedClock,
+		config: &S3StorageServiceConfig{UploadInterval: time.Minute, DeleteBatchSize: 64},
+		sqlDB:  db,
+	}
+	insertTestBids(t, db, 10, 100)
+
+	// All uploaded bids are removed across several delete batches, only the latest round is left to be uploaded later
+	require.Equal(t, time.Minute, s3StorageService.uploadBatches(ctx))
+	require.Len(t, mockClient.data, 1)
+	data, err := s3StorageService.downloadBatch(ctx, s3StorageService.getBatchName(0, 8))
+	require.NoError(t, err)
+	require.Equal(t, 901, len(strings.Split(strings.TrimSpace(string(data)), "\n")))
+	var count int
+	require.NoError(t, db.sqlDB.Get(&count, "SELECT COUNT(*) FROM Bids"))
+	require.Equal(t, 100, count)
+	remaining, err := db.GetBidsOfRound(9)
+	require.NoError(t, err)
+	require.Len(t, remaining, 100)
+	uploadedRound, err := db.UploadedRound()
+	require.NoError(t, err)
+	require.Equal(t, uint64(9), uploadedRound)
+	require.Equal(t, uint64(0), s3StorageService.lastFailedDeleteRound)
+
+	config := &S3StorageServiceConfig{Enable: true, Format: S3StorageFormatCSV, DeleteBatchSize: -1}
+	require.Error(t, config.Validate())
+}
+
+func TestS3StorageServiceReuploadRound(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockClient := newmockS3FullClient()
+	db, err := NewDatabase(t.TempDir())
+	require.NoError(t, err)
+	s3StorageService := &S3StorageService{
+		client: mockClient,
+		now:    fixedClock,
+		config: &S3StorageServiceConfig{},
+		sqlDB:  db,
+	}
+	insertTestBids(t, db, 3, 2)
+
+	// A corrupt batch of rounds 0 to 2 uploaded on an earlier day
+	key := s3StorageService.getBatchName(0, 2)
+	require.NoError(t, s3StorageService.uploadBatch(ctx, []byte{1, 2, 3}, 0, 2))
+	s3StorageService.now = func() time.Time { return fixedClock().AddDate(0, 0, 1) }
+
+	require.NoError(t, s3StorageService.ReuploadRound(ctx, 1))
+	require.Len(t, mockClient.data, 1)
+	data, err := s3StorageService.downloadBatch(ctx, key)
+	require.NoError(t, err)
+	bids, err := db.GetBidsOfRounds(0, 2)
+	require.NoError(t, err)
+	require.Len(t, bids, 6)
+	wantData, err := encodeBidsToCsv(bids)
+	require.NoError(t, err)
+	require.Equal(t, wantData, data)
+
+	// Rounds outside of the uploaded batches can't be reuploaded
+	require.Error(t, s3StorageService.ReuploadRound(ctx, 3))
+
+	// Rounds whose bids were deleted can't be regenerated
+	require.NoError(t, db.DeleteBids(2))
+	require.Error(t, s3StorageService.ReuploadRound(ctx, 1))
+}
+
+func TestS3StorageServiceBatchName(t *testing.T) {
+	s3StorageService := &S3StorageService{
+		now:          fixedClock,
+		objectPrefix: "prefix/",
+		config:       &S3StorageServiceConfig{Format: S3StorageFormatCSV},
+	}
+	require.Equal(t, "prefix/validated-timeboost-bids/2024/03/07/0000010-0000011.csv.gzip", s3StorageService.getBatchName(10, 11))
+	s3StorageService.config.Format = S3StorageFormatParquet
+	require.Equal(t, "prefix/validated-timeboost-bids/2024/03/07/0000010-0000011.parquet", s3StorageService.getBatchName(10, 11))
+}
@@ -21,5 +21,11 @@ CREATE TABLE IF NOT EXISTS Bids (
 );
 CREATE INDEX idx_bids_round ON Bids(Round);
 `
-	schemaList = []string{version1}
+	version2 = `
+ALTER TABLE Bids ADD COLUMN Expiry INTEGER NOT NULL DEFAULT 0;
+`
+	version3 = `
+INSERT INTO Flags (FlagName, FlagValue) VALUES ('UploadedRound', 0);
+`
+	schemaList = []string{version1, version2, version3}
 )
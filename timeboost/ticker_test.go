@@ -40,3 +40,20 @@ func Test_auctionClosed(t *testing.T) {
 	isClosed = roundTimingInfo.isAuctionRoundClosedAt(initialTimestamp.Add(roundTimingInfo.Round))
 	require.False(t, isClosed)
 }
+
+func TestRoundForBlock(t *testing.T) {
+	t.Parallel()
+	roundTimingInfo := RoundTimingInfo{
+		Offset:         time.Unix(1000, 0),
+		Round:          time.Minute,
+		AuctionClosing: time.Second * 15,
+	}
+
+	require.Equal(t, uint64(0), RoundForBlock(1000, roundTimingInfo))
+	require.Equal(t, uint64(0), RoundForBlock(1059, roundTimingInfo))
+	require.Equal(t, uint64(1), RoundForBlock(1060, roundTimingInfo))
+	require.Equal(t, uint64(5), RoundForBlock(1000+5*60+42, roundTimingInfo))
+
+	// Blocks produced before the first round are attributed to round 0
+	require.Equal(t, uint64(0), RoundForBlock(10, roundTimingInfo))
+}
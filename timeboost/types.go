@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -24,6 +25,12 @@ type Bid struct {
 	Round                  uint64         `db:"Round"`
 	Amount                 *big.Int       `db:"Amount"`
 	Signature              []byte         `db:"Signature"`
+	// Optional unix timestamp in seconds after which the bid is no longer valid, zero means no expiry.
+	// It is not part of the signed bid as the auction contract has no notion of bid expiry,
+	// instead the bidder signs it separately in ExpirySignature.
+	Expiry uint64 `db:"Expiry"`
+	// Bidder's signature of ToExpiryEIP712Hash, required if the bid has an expiry
+	ExpirySignature []byte `db:"-"`
 }
 
 func (b *Bid) ToJson() *JsonBid {
@@ -34,6 +41,8 @@ func (b *Bid) ToJson() *JsonBid {
 		Round:                  hexutil.Uint64(b.Round),
 		Amount:                 (*hexutil.Big)(b.Amount),
 		Signature:              b.Signature,
+		Expiry:                 hexutil.Uint64(b.Expiry),
+		ExpirySignature:        b.ExpirySignature,
 	}
 }
 
@@ -73,6 +82,44 @@ func (b *Bid) ToEIP712Hash(domainSeparator [32]byte) (common.Hash, error) {
 	return bidHash, nil
 }
 
+// ToExpiryEIP712Hash returns the hash the bidder signs to set the bid's expiry. It covers the bid's EIP-712 hash,
+// so an expiry signature can't be moved to another bid, nor can the bid's expiry be changed without the bidder.
+func (b *Bid) ToExpiryEIP712Hash(domainSeparator [32]byte) (common.Hash, error) {
+	bidHash, err := b.ToEIP712Hash(domainSeparator)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	types := apitypes.Types{
+		"BidExpiry": []apitypes.Type{
+			{Name: "bidHash", Type: "bytes32"},
+			{Name: "expiry", Type: "uint64"},
+		},
+	}
+
+	message := apitypes.TypedDataMessage{
+		"bidHash": bidHash.Bytes(),
+		"expiry":  big.NewInt(0).SetUint64(b.Expiry),
+	}
+
+	typedData := apitypes.TypedData{
+		Types:       types,
+		PrimaryType: "BidExpiry",
+		Message:     message,
+		Domain:      apitypes.TypedDataDomain{Salt: "Unused; domain separator fetched from method on contract. This must be nonempty for validation."},
+	}
+
+	messageHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return crypto.Keccak256Hash(
+		[]byte("\x19\x01"),
+		domainSeparator[:],
+		messageHash,
+	), nil
+}
+
 type JsonBid struct {
 	ChainId                *hexutil.Big   `json:"chainId"`
 	ExpressLaneController  common.Address `json:"expressLaneController"`
@@ -80,6 +127,8 @@ type JsonBid struct {
 	Round                  hexutil.Uint64 `json:"round"`
 	Amount                 *hexutil.Big   `json:"amount"`
 	Signature              hexutil.Bytes  `json:"signature"`
+	Expiry                 hexutil.Uint64 `json:"expiry,omitempty"`
+	ExpirySignature        hexutil.Bytes  `json:"expirySignature,omitempty"`
 }
 
 type ValidatedBid struct {
@@ -92,6 +141,14 @@ type ValidatedBid struct {
 	ExpressLaneController common.Address
 	Round                 uint64
 	Amount                *big.Int
+
+	// Unix timestamp in seconds after which the bid is ignored at auction resolution, zero means no expiry.
+	Expiry uint64
+}
+
+// IsExpiredAt returns true if the bid has an expiry and it has passed as of the given time.
+func (v *ValidatedBid) IsExpiredAt(t time.Time) bool {
+	return v.Expiry != 0 && t.Unix() >= int64(v.Expiry) // #nosec G115
 }
 
 // BigIntHash returns the hash of the bidder and bidBytes in the form of a big.Int.
@@ -115,6 +172,32 @@ func (v *ValidatedBid) BigIntHash(domainSeparator [32]byte) *big.Int {
 	return new(big.Int).SetBytes(crypto.Keccak256Hash(bidder, bidHash.Bytes()).Bytes())
 }
 
+// Hash returns a deterministic hash over all the fields of the bid, for deduplicating and logging bids.
+// It only depends on the values of the fields, so it is stable across the JSON, database and parquet encodings.
+// The signature is the only variable length field and is hashed last to keep the encoding unambiguous.
+func (v *ValidatedBid) Hash() common.Hash {
+	chainId, amount := v.ChainId, v.Amount
+	if chainId == nil {
+		chainId = new(big.Int)
+	}
+	if amount == nil {
+		amount = new(big.Int)
+	}
+	buf := new(bytes.Buffer)
+	buf.Write(padBigInt(chainId))
+	buf.Write(v.AuctionContractAddress[:])
+	buf.Write(v.Bidder[:])
+	buf.Write(v.ExpressLaneController[:])
+	uintBuf := make([]byte, 8)
+	binary.BigEndian.PutUint64(uintBuf, v.Round)
+	buf.Write(uintBuf)
+	buf.Write(padBigInt(amount))
+	binary.BigEndian.PutUint64(uintBuf, v.Expiry)
+	buf.Write(uintBuf)
+	buf.Write(v.Signature)
+	return crypto.Keccak256Hash(buf.Bytes())
+}
+
 func (v *ValidatedBid) ToJson() *JsonValidatedBid {
 	return &JsonValidatedBid{
 		ExpressLaneController:  v.ExpressLaneController,
@@ -124,6 +207,7 @@ func (v *ValidatedBid) ToJson() *JsonValidatedBid {
 		AuctionContractAddress: v.AuctionContractAddress,
 		Round:                  hexutil.Uint64(v.Round),
 		Bidder:                 v.Bidder,
+		Expiry:                 hexutil.Uint64(v.Expiry),
 	}
 }
 
@@ -135,6 +219,7 @@ type JsonValidatedBid struct {
 	AuctionContractAddress common.Address `json:"auctionContractAddress"`
 	Round                  hexutil.Uint64 `json:"round"`
 	Bidder                 common.Address `json:"bidder"`
+	Expiry                 hexutil.Uint64 `json:"expiry,omitempty"`
 }
 
 func JsonValidatedBidToGo(bid *JsonValidatedBid) *ValidatedBid {
@@ -146,6 +231,7 @@ func JsonValidatedBidToGo(bid *JsonValidatedBid) *ValidatedBid {
 		AuctionContractAddress: bid.AuctionContractAddress,
 		Round:                  uint64(bid.Round),
 		Bidder:                 bid.Bidder,
+		Expiry:                 uint64(bid.Expiry),
 	}
 }
 
@@ -187,6 +273,41 @@ func JsonSubmissionToGo(submission *JsonExpressLaneSubmission) (*ExpressLaneSubm
 	}, nil
 }
 
+// VerifyTarget checks that the submission is well formed and targets the given chain and auction contract.
+func (els *ExpressLaneSubmission) VerifyTarget(chainId *big.Int, auctionContract common.Address) error {
+	if els == nil || els.Transaction == nil || els.Signature == nil || els.ChainId == nil {
+		return ErrMalformedData
+	}
+	if els.ChainId.Cmp(chainId) != 0 {
+		return errors.Wrapf(ErrWrongChainId, "express lane tx chain ID %d does not match current chain ID %d", els.ChainId, chainId)
+	}
+	if els.AuctionContractAddress != auctionContract {
+		return errors.Wrapf(ErrWrongAuctionContract, "msg auction contract address %s does not match sequencer auction contract address %s", els.AuctionContractAddress, auctionContract)
+	}
+	return nil
+}
+
+// VerifyExpressLaneSubmission runs the checks the sequencer does on an express lane submission that don't depend on
+// its state: the submission must be well formed, target the given chain, auction contract and round, and its signature
+// must recover to a sender. Checking that the sender is the round's express lane controller is left to the caller.
+func VerifyExpressLaneSubmission(msg *JsonExpressLaneSubmission, expectedChainId *big.Int, auctionContract common.Address, currentRound uint64) error {
+	if msg == nil || msg.ChainId == nil {
+		return ErrMalformedData
+	}
+	submission, err := JsonSubmissionToGo(msg)
+	if err != nil {
+		return errors.Wrap(ErrMalformedData, err.Error())
+	}
+	if err := submission.VerifyTarget(expectedChainId, auctionContract); err != nil {
+		return err
+	}
+	if submission.Round != currentRound {
+		return errors.Wrapf(ErrBadRoundNumber, "express lane tx round %d does not match current round %d", submission.Round, currentRound)
+	}
+	_, err = submission.Sender()
+	return err
+}
+
 func (els *ExpressLaneSubmission) ToJson() (*JsonExpressLaneSubmission, error) {
 	encoded, err := els.Transaction.MarshalBinary()
 	if err != nil {
@@ -204,22 +325,45 @@ func (els *ExpressLaneSubmission) ToJson() (*JsonExpressLaneSubmission, error) {
 }
 
 func (els *ExpressLaneSubmission) ToMessageBytes() ([]byte, error) {
+	rlpTx, err := els.Transaction.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return expressLaneSigningMessage(els.ChainId, els.Round, els.AuctionContractAddress, rlpTx, els.SequenceNumber), nil
+}
+
+// ExpressLaneSigningMessage returns the message an express lane controller signs (with the Ethereum signed message prefix)
+// to submit the binary encoded transaction, identical to the one the sequencer derives from the submission.
+// This lets external signers compute it without building a JsonExpressLaneSubmission.
+func ExpressLaneSigningMessage(chainId *big.Int, round uint64, auctionContract common.Address, encodedTx []byte, sequence uint64) ([]byte, error) {
+	if chainId == nil || chainId.Sign() < 0 || chainId.BitLen() > 256 {
+		return nil, errors.Wrapf(ErrMalformedData, "invalid chain id %v", chainId)
+	}
+	// The sequencer signs over the re-encoding of the decoded transaction, so it is re-encoded the same way here
+	tx := &types.Transaction{}
+	if err := tx.UnmarshalBinary(encodedTx); err != nil {
+		return nil, errors.Wrap(ErrMalformedData, err.Error())
+	}
+	rlpTx, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return expressLaneSigningMessage(chainId, round, auctionContract, rlpTx, sequence), nil
+}
+
+func expressLaneSigningMessage(chainId *big.Int, round uint64, auctionContract common.Address, rlpTx []byte, sequence uint64) []byte {
 	buf := new(bytes.Buffer)
 	buf.Write(domainValue)
-	buf.Write(padBigInt(els.ChainId))
-	buf.Write(els.AuctionContractAddress[:])
+	buf.Write(padBigInt(chainId))
+	buf.Write(auctionContract[:])
 	roundBuf := make([]byte, 8)
-	binary.BigEndian.PutUint64(roundBuf, els.Round)
+	binary.BigEndian.PutUint64(roundBuf, round)
 	buf.Write(roundBuf)
 	seqBuf := make([]byte, 8)
-	binary.BigEndian.PutUint64(seqBuf, els.SequenceNumber)
+	binary.BigEndian.PutUint64(seqBuf, sequence)
 	buf.Write(seqBuf)
-	rlpTx, err := els.Transaction.MarshalBinary()
-	if err != nil {
-		return nil, err
-	}
 	buf.Write(rlpTx)
-	return buf.Bytes(), nil
+	return buf.Bytes()
 }
 
 func (els *ExpressLaneSubmission) Sender() (common.Address, error) {
@@ -269,4 +413,5 @@ type SqliteDatabaseBid struct {
 	Round                  uint64 `db:"Round"`
 	Amount                 string `db:"Amount"`
 	Signature              string `db:"Signature"`
+	Expiry                 uint64 `db:"Expiry"`
 }
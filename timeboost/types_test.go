@@ -0,0 +1,160 @@
+package timeboost
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestExpressLaneSigningMessage(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	chainId := big.NewInt(412346)
+	signer := types.LatestSignerForChainID(chainId)
+	auctionContract := common.HexToAddress("0x2")
+	to := common.HexToAddress("0x1")
+	for _, inner := range []types.TxData{
+		&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1), Gas: 21000, To: &to, Value: big.NewInt(1)},
+		&types.DynamicFeeTx{ChainID: chainId, Nonce: 2, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2), Gas: 21000, To: &to, Value: big.NewInt(1), Data: []byte{1, 2, 3}},
+	} {
+		tx, err := types.SignNewTx(privateKey, signer, inner)
+		require.NoError(t, err)
+		encodedTx, err := tx.MarshalBinary()
+		require.NoError(t, err)
+
+		submission, err := JsonSubmissionToGo(&JsonExpressLaneSubmission{
+			ChainId:                (*hexutil.Big)(chainId),
+			Round:                  7,
+			AuctionContractAddress: auctionContract,
+			Transaction:            encodedTx,
+			SequenceNumber:         3,
+		})
+		require.NoError(t, err)
+		want, err := submission.ToMessageBytes()
+		require.NoError(t, err)
+
+		got, err := ExpressLaneSigningMessage(chainId, 7, auctionContract, encodedTx, 3)
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+
+	_, err = ExpressLaneSigningMessage(nil, 7, auctionContract, nil, 3)
+	require.ErrorIs(t, err, ErrMalformedData)
+	_, err = ExpressLaneSigningMessage(chainId, 7, auctionContract, []byte{1, 2, 3}, 3)
+	require.ErrorIs(t, err, ErrMalformedData)
+}
+
+func TestVerifyExpressLaneSubmission(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	chainId := big.NewInt(412346)
+	auctionContract := common.HexToAddress("0x2")
+	to := common.HexToAddress("0x1")
+	tx, err := types.SignNewTx(privateKey, types.LatestSignerForChainID(chainId), &types.DynamicFeeTx{ChainID: chainId, Nonce: 1, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2), Gas: 21000, To: &to})
+	require.NoError(t, err)
+	encodedTx, err := tx.MarshalBinary()
+	require.NoError(t, err)
+
+	buildSubmission := func() *JsonExpressLaneSubmission {
+		msg := &JsonExpressLaneSubmission{
+			ChainId:                (*hexutil.Big)(chainId),
+			Round:                  7,
+			AuctionContractAddress: auctionContract,
+			Transaction:            encodedTx,
+			SequenceNumber:         3,
+		}
+		signingMessage, err := ExpressLaneSigningMessage(chainId, 7, auctionContract, encodedTx, 3)
+		require.NoError(t, err)
+		prefixed := crypto.Keccak256(append([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(signingMessage))), signingMessage...))
+		msg.Signature, err = crypto.Sign(prefixed, privateKey)
+		require.NoError(t, err)
+		return msg
+	}
+	require.NoError(t, VerifyExpressLaneSubmission(buildSubmission(), chainId, auctionContract, 7))
+
+	for _, tc := range []struct {
+		name    string
+		modify  func(msg *JsonExpressLaneSubmission)
+		wantErr error
+	}{
+		{"missing chain id", func(msg *JsonExpressLaneSubmission) { msg.ChainId = nil }, ErrMalformedData},
+		{"undecodable tx", func(msg *JsonExpressLaneSubmission) { msg.Transaction = []byte{1, 2, 3} }, ErrMalformedData},
+		{"missing signature", func(msg *JsonExpressLaneSubmission) { msg.Signature = nil }, ErrMalformedData},
+		{"wrong chain id", func(msg *JsonExpressLaneSubmission) { msg.ChainId = (*hexutil.Big)(big.NewInt(1)) }, ErrWrongChainId},
+		{"wrong auction contract", func(msg *JsonExpressLaneSubmission) { msg.AuctionContractAddress = common.HexToAddress("0x3") }, ErrWrongAuctionContract},
+		{"wrong round", func(msg *JsonExpressLaneSubmission) { msg.Round = 8 }, ErrBadRoundNumber},
+		{"short signature", func(msg *JsonExpressLaneSubmission) { msg.Signature = msg.Signature[:64] }, ErrMalformedData},
+		{"unrecoverable signature", func(msg *JsonExpressLaneSubmission) { msg.Signature = make([]byte, 65) }, ErrMalformedData},
+	} {
+		msg := buildSubmission()
+		tc.modify(msg)
+		require.ErrorIs(t, VerifyExpressLaneSubmission(msg, chainId, auctionContract, 7), tc.wantErr, tc.name)
+	}
+	require.ErrorIs(t, VerifyExpressLaneSubmission(nil, chainId, auctionContract, 7), ErrMalformedData)
+}
+
+func TestValidatedBidHash(t *testing.T) {
+	newBid := func() *ValidatedBid {
+		return &ValidatedBid{
+			ChainId:                big.NewInt(412346),
+			AuctionContractAddress: common.HexToAddress("0x2"),
+			Signature:              []byte("signature"),
+			Bidder:                 common.HexToAddress("0x3"),
+			ExpressLaneController:  common.HexToAddress("0x4"),
+			Round:                  5,
+			Amount:                 big.NewInt(100),
+			Expiry:                 1_700_000_000,
+		}
+	}
+	bid := newBid()
+	require.Equal(t, bid.Hash(), newBid().Hash())
+
+	for name, change := range map[string]func(*ValidatedBid){
+		"chain id":         func(b *ValidatedBid) { b.ChainId = big.NewInt(1) },
+		"auction contract": func(b *ValidatedBid) { b.AuctionContractAddress = common.HexToAddress("0x5") },
+		"signature":        func(b *ValidatedBid) { b.Signature = []byte("signaturf") },
+		"bidder":           func(b *ValidatedBid) { b.Bidder = common.HexToAddress("0x5") },
+		"controller":       func(b *ValidatedBid) { b.ExpressLaneController = common.HexToAddress("0x5") },
+		"round":            func(b *ValidatedBid) { b.Round = 6 },
+		"amount":           func(b *ValidatedBid) { b.Amount = big.NewInt(101) },
+		"expiry":           func(b *ValidatedBid) { b.Expiry = 0 },
+	} {
+		changed := newBid()
+		change(changed)
+		require.NotEqual(t, bid.Hash(), changed.Hash(), "changing the %s didn't change the hash", name)
+	}
+
+	// Stable across the JSON and database encodings
+	require.Equal(t, bid.Hash(), JsonValidatedBidToGo(bid.ToJson()).Hash())
+	db, err := NewDatabase(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, db.InsertBid(bid))
+	dbBids, err := db.GetBidsOfRound(bid.Round)
+	require.NoError(t, err)
+	require.Len(t, dbBids, 1)
+	chainId, ok := new(big.Int).SetString(dbBids[0].ChainId, 10)
+	require.True(t, ok)
+	amount, ok := new(big.Int).SetString(dbBids[0].Amount, 10)
+	require.True(t, ok)
+	signature, err := hex.DecodeString(dbBids[0].Signature)
+	require.NoError(t, err)
+	fromDb := &ValidatedBid{
+		ChainId:                chainId,
+		AuctionContractAddress: common.HexToAddress(dbBids[0].AuctionContractAddress),
+		Signature:              signature,
+		Bidder:                 common.HexToAddress(dbBids[0].Bidder),
+		ExpressLaneController:  common.HexToAddress(dbBids[0].ExpressLaneController),
+		Round:                  dbBids[0].Round,
+		Amount:                 amount,
+		Expiry:                 dbBids[0].Expiry,
+	}
+	require.Equal(t, bid.Hash(), fromDb.Hash())
+}
@@ -14,13 +14,41 @@ import (
 
 type PreimagesMapJson struct {
 	Map map[common.Hash][]byte
+	// encoded holds the JSON encoding when built by EncodePreimagesMapJson, in which case Map is nil
+	encoded []byte
 }
 
 func NewPreimagesMapJson(inner map[common.Hash][]byte) *PreimagesMapJson {
-	return &PreimagesMapJson{inner}
+	return &PreimagesMapJson{Map: inner}
+}
+
+// EncodePreimagesMapJson encodes the preimages yielded by forEach as they come, so that they're only held
+// in memory in their encoded form, e.g. when they're read one at a time from disk
+func EncodePreimagesMapJson(forEach func(fn func(hash common.Hash, preimage []byte) error) error) (*PreimagesMapJson, error) {
+	encoding := base64.StdEncoding
+	out := []byte{'{'}
+	err := forEach(func(hash common.Hash, preimage []byte) error {
+		if len(out) > 1 {
+			out = append(out, ',')
+		}
+		out = append(out, '"')
+		out = encoding.AppendEncode(out, hash[:])
+		out = append(out, '"', ':', '"')
+		out = encoding.AppendEncode(out, preimage)
+		out = append(out, '"')
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	out = append(out, '}')
+	return &PreimagesMapJson{encoded: out}, nil
 }
 
 func (m *PreimagesMapJson) MarshalJSON() ([]byte, error) {
+	if m.encoded != nil {
+		return m.encoded, nil
+	}
 	encoding := base64.StdEncoding
 	size := 2                                          // {}
 	size += (5 + encoding.EncodedLen(32)) * len(m.Map) // "000..000":""
@@ -18,9 +18,19 @@ type Downloader interface {
 	Download(ctx context.Context, w io.WriterAt, input *s3.GetObjectInput, options ...func(*manager.Downloader)) (n int64, err error)
 }
 
+type BucketChecker interface {
+	HeadBucket(ctx context.Context, input *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error)
+}
+
+type ObjectLister interface {
+	ListObjectsV2(ctx context.Context, input *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+}
+
 type FullClient interface {
 	Uploader
 	Downloader
+	BucketChecker
+	ObjectLister
 	Client() *s3.Client
 }
 
@@ -57,6 +67,14 @@ func (s *s3Client) Upload(ctx context.Context, input *s3.PutObjectInput, opts ..
 	return s.uploader.Upload(ctx, input, opts...)
 }
 
+func (s *s3Client) HeadBucket(ctx context.Context, input *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error) {
+	return s.client.HeadBucket(ctx, input, optFns...)
+}
+
+func (s *s3Client) ListObjectsV2(ctx context.Context, input *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	return s.client.ListObjectsV2(ctx, input, optFns...)
+}
+
 func (s *s3Client) Download(ctx context.Context, w io.WriterAt, input *s3.GetObjectInput, options ...func(*manager.Downloader)) (n int64, err error) {
 	return s.downloader.Download(ctx, w, input, options...)
 }
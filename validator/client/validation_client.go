@@ -46,10 +46,13 @@ func NewValidationClient(config rpcclient.ClientConfigFetcher, stack *node.Node)
 func (c *ValidationClient) Launch(entry *validator.ValidationInput, moduleRoot common.Hash) validator.ValidationRun {
 	c.room.Add(-1)
 	promise := stopwaiter.LaunchPromiseThread[validator.GoGlobalState](c, func(ctx context.Context) (validator.GoGlobalState, error) {
-		input := server_api.ValidationInputToJson(entry)
+		defer c.room.Add(1)
+		input, err := server_api.ValidationInputToJson(entry)
+		if err != nil {
+			return validator.GoGlobalState{}, err
+		}
 		var res validator.GoGlobalState
-		err := c.client.CallContext(ctx, &res, server_api.Namespace+"_validate", input, moduleRoot)
-		c.room.Add(1)
+		err = c.client.CallContext(ctx, &res, server_api.Namespace+"_validate", input, moduleRoot)
 		return res, err
 	})
 	return server_common.NewValRun(promise, moduleRoot)
@@ -159,8 +162,12 @@ func (c *ExecutionClient) CreateExecutionRun(
 	useBoldMachine bool,
 ) containers.PromiseInterface[validator.ExecutionRun] {
 	return stopwaiter.LaunchPromiseThread(c, func(ctx context.Context) (validator.ExecutionRun, error) {
+		inputJson, err := server_api.ValidationInputToJson(input)
+		if err != nil {
+			return nil, err
+		}
 		var res uint64
-		err := c.client.CallContext(ctx, &res, server_api.Namespace+"_createExecutionRun", wasmModuleRoot, server_api.ValidationInputToJson(input), useBoldMachine)
+		err = c.client.CallContext(ctx, &res, server_api.Namespace+"_createExecutionRun", wasmModuleRoot, inputJson, useBoldMachine)
 		if err != nil {
 			return nil, err
 		}
@@ -77,10 +77,23 @@ type BatchInfoJson struct {
 	DataB64 string
 }
 
-func ValidationInputToJson(entry *validator.ValidationInput) *InputJSON {
+func ValidationInputToJson(entry *validator.ValidationInput) (*InputJSON, error) {
 	jsonPreimagesMap := make(map[arbutil.PreimageType]*jsonapi.PreimagesMapJson)
-	for ty, preimages := range entry.Preimages {
-		jsonPreimagesMap[ty] = jsonapi.NewPreimagesMapJson(preimages)
+	if entry.SpilledPreimages == nil {
+		for ty, preimages := range entry.Preimages {
+			jsonPreimagesMap[ty] = jsonapi.NewPreimagesMapJson(preimages)
+		}
+	} else {
+		// spilled preimages are encoded as they're read, without loading them all into memory
+		for ty := range entry.PreimageCounts() {
+			encoded, err := jsonapi.EncodePreimagesMapJson(func(fn func(common.Hash, []byte) error) error {
+				return entry.ForEachPreimage(ty, fn)
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error reading spilled preimages of validation input %d: %w", entry.Id, err)
+			}
+			jsonPreimagesMap[ty] = encoded
+		}
 	}
 	res := &InputJSON{
 		Id:            entry.Id,
@@ -107,7 +120,7 @@ func ValidationInputToJson(entry *validator.ValidationInput) *InputJSON {
 		}
 		res.UserWasms[target] = archWasms
 	}
-	return res
+	return res, nil
 }
 
 func ValidationInputFromJson(entry *InputJSON) (*validator.ValidationInput, error) {
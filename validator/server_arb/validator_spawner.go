@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"runtime"
 	"sync/atomic"
 	"time"
@@ -12,9 +14,11 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rlp"
 
 	"github.com/offchainlabs/nitro/arbutil"
 	"github.com/offchainlabs/nitro/util/containers"
@@ -31,6 +35,9 @@ type ArbitratorSpawnerConfig struct {
 	OutputPath                  string                       `koanf:"output-path" reload:"hot"`
 	Execution                   MachineCacheConfig           `koanf:"execution" reload:"hot"` // hot reloading for new executions only
 	ExecutionRunTimeout         time.Duration                `koanf:"execution-run-timeout" reload:"hot"`
+	DumpErroredMachines         bool                         `koanf:"dump-errored-machines" reload:"hot"`
+	MinSteps                    uint64                       `koanf:"min-steps" reload:"hot"`
+	MaxSteps                    uint64                       `koanf:"max-steps" reload:"hot"`
 	RedisValidationServerConfig redis.ValidationServerConfig `koanf:"redis-validation-server-config"`
 }
 
@@ -41,6 +48,7 @@ var DefaultArbitratorSpawnerConfig = ArbitratorSpawnerConfig{
 	OutputPath:                  "./target/output",
 	Execution:                   DefaultMachineCacheConfig,
 	ExecutionRunTimeout:         time.Minute * 15,
+	DumpErroredMachines:         false,
 	RedisValidationServerConfig: redis.DefaultValidationServerConfig,
 }
 
@@ -48,6 +56,9 @@ func ArbitratorSpawnerConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.Int(prefix+".workers", DefaultArbitratorSpawnerConfig.Workers, "number of concurrent validation threads")
 	f.Duration(prefix+".execution-run-timeout", DefaultArbitratorSpawnerConfig.ExecutionRunTimeout, "timeout before discarding execution run")
 	f.String(prefix+".output-path", DefaultArbitratorSpawnerConfig.OutputPath, "path to write machines to")
+	f.Bool(prefix+".dump-errored-machines", DefaultArbitratorSpawnerConfig.DumpErroredMachines, "write the state of machines entering errored state during validation to the output path for offline inspection")
+	f.Uint64(prefix+".min-steps", DefaultArbitratorSpawnerConfig.MinSteps, "fail validations of blocks halting in fewer machine steps than this, as it likely indicates a bug (0 for no minimum)")
+	f.Uint64(prefix+".max-steps", DefaultArbitratorSpawnerConfig.MaxSteps, "fail validations of blocks running for more machine steps than this, as it likely indicates a bug (0 for no maximum)")
 	MachineCacheConfigConfigAddOptions(prefix+".execution", f)
 	redis.ValidationServerConfigAddOptions(prefix+".redis-validation-server-config", f)
 }
@@ -74,6 +85,9 @@ type ArbitratorSpawner struct {
 	// Oreder of wrappers is important. The first wrapper is the innermost.
 	machineWrappers []MachineWrapper
 	config          ArbitratorSpawnerConfigFecher
+	// blockHeaders are resolved as keccak preimages of their block hashes when
+	// the validation input doesn't carry them, allowing offline validation.
+	blockHeaders map[common.Hash]*types.Header
 }
 
 func WithWrapper(wrapper MachineWrapper) SpawnerOption {
@@ -82,6 +96,14 @@ func WithWrapper(wrapper MachineWrapper) SpawnerOption {
 	}
 }
 
+// WithBlockHeaders supplies block headers whose RLP encodings are used to
+// resolve block hash preimages missing from a validation input.
+func WithBlockHeaders(headers map[common.Hash]*types.Header) SpawnerOption {
+	return func(s *ArbitratorSpawner) {
+		s.blockHeaders = headers
+	}
+}
+
 func NewArbitratorSpawner(locator *server_common.MachineLocator, config ArbitratorSpawnerConfigFecher, opts ...SpawnerOption) (*ArbitratorSpawner, error) {
 	// TODO: preload machines
 	spawner := &ArbitratorSpawner{
@@ -117,15 +139,71 @@ func (s *ArbitratorSpawner) Name() string {
 	return "arbitrator"
 }
 
-func (v *ArbitratorSpawner) loadEntryToMachine(_ context.Context, entry *validator.ValidationInput, mach *ArbitratorMachine) error {
-	resolver := func(ty arbutil.PreimageType, hash common.Hash) ([]byte, error) {
+// ErrStepCountOutOfBounds is returned when a machine halts in fewer steps than the configured minimum, or runs for
+// more than the configured maximum
+var ErrStepCountOutOfBounds = errors.New("machine step count out of bounds")
+
+// MachineErroredError is returned when the machine enters errored state during validation, it holds the
+// machine's state at the point of error
+type MachineErroredError struct {
+	GlobalState validator.GoGlobalState
+	StepCount   uint64
+	// DumpPath is the file the machine state was written to, empty if it wasn't dumped
+	DumpPath string
+}
+
+func (e *MachineErroredError) Error() string {
+	msg := fmt.Sprintf("machine entered errored state during attempted validation at step %d, global state %v", e.StepCount, e.GlobalState)
+	if e.DumpPath != "" {
+		msg += fmt.Sprintf(", machine state written to %s", e.DumpPath)
+	}
+	return msg
+}
+
+// erroredMachineError collects the diagnostics of a machine in errored state, and dumps its state using serialize if configured
+func (v *ArbitratorSpawner) erroredMachineError(entryId uint64, mach MachineInterface, serialize func(path string) error) error {
+	errored := &MachineErroredError{
+		GlobalState: mach.GetGlobalState(),
+		StepCount:   mach.GetStepCount(),
+	}
+	config := v.config()
+	if config.DumpErroredMachines {
+		path := filepath.Join(config.OutputPath, fmt.Sprintf("errored-machine-%d-%d", entryId, time.Now().UnixMilli()))
+		err := os.MkdirAll(config.OutputPath, 0o755)
+		if err == nil {
+			err = serialize(path)
+		}
+		if err != nil {
+			log.Warn("failed to dump errored machine state", "block", entryId, "path", path, "err", err)
+		} else {
+			errored.DumpPath = path
+		}
+	}
+	log.Error("machine entered errored state during attempted validation", "block", entryId, "steps", errored.StepCount, "globalState", errored.GlobalState, "dumpPath", errored.DumpPath)
+	return errored
+}
+
+func (v *ArbitratorSpawner) preimageResolver(entry *validator.ValidationInput) GoPreimageResolver {
+	return func(ty arbutil.PreimageType, hash common.Hash) ([]byte, error) {
 		// Check if it's a known preimage
-		if preimage, ok := entry.Preimages[ty][hash]; ok {
+		preimage, err := entry.ReadPreimage(ty, hash)
+		if err == nil {
 			return preimage, nil
 		}
+		if !errors.Is(err, validator.ErrPreimageNotFound) {
+			return nil, err
+		}
+		if ty == arbutil.Keccak256PreimageType {
+			if header, ok := v.blockHeaders[hash]; ok && header != nil {
+				return rlp.EncodeToBytes(header)
+			}
+		}
 		return nil, errors.New("preimage not found")
 	}
-	if err := mach.SetPreimageResolver(resolver); err != nil {
+}
+
+func (v *ArbitratorSpawner) loadEntryToMachine(_ context.Context, entry *validator.ValidationInput, mach *ArbitratorMachine) error {
+	if err := mach.SetPreimageResolver(v.preimageResolver(entry)); err != nil {
 		return err
 	}
 	err := mach.SetGlobalState(entry.StartState)
@@ -191,12 +269,27 @@ func (v *ArbitratorSpawner) execute(
 	for _, wrapper := range v.machineWrappers {
 		mach = wrapper(mach)
 	}
+	return v.runMachine(ctx, entry.Id, moduleRoot, mach, arbMach.SerializeState)
+}
+
+// runMachine steps the machine until it halts, checking its step count is within the configured bounds
+func (v *ArbitratorSpawner) runMachine(
+	ctx context.Context, entryId uint64, moduleRoot common.Hash, mach MachineInterface, serialize func(string) error,
+) (validator.GoGlobalState, error) {
+	config := v.config()
 	var steps uint64
 	for mach.IsRunning() {
 		var count uint64 = 500000000
-		err = mach.Step(ctx, count)
+		if config.MaxSteps > 0 {
+			stepCount := mach.GetStepCount()
+			if stepCount > config.MaxSteps {
+				return validator.GoGlobalState{}, fmt.Errorf("%w: block %d still running after %d steps, max steps %d", ErrStepCountOutOfBounds, entryId, stepCount, config.MaxSteps)
+			}
+			count = min(count, config.MaxSteps-stepCount+1)
+		}
+		err := mach.Step(ctx, count)
 		if steps > 0 {
-			log.Debug("validation", "moduleRoot", moduleRoot, "block", entry.Id, "steps", steps)
+			log.Debug("validation", "moduleRoot", moduleRoot, "block", entryId, "steps", steps)
 		}
 		if err != nil {
 			return validator.GoGlobalState{}, fmt.Errorf("machine execution failed with error: %w", err)
@@ -208,8 +301,11 @@ func (v *ArbitratorSpawner) execute(
 	arbitratorValidationSteps.Update(int64(mach.GetStepCount()))
 
 	if mach.IsErrored() {
-		log.Error("machine entered errored state during attempted validation", "block", entry.Id)
-		return validator.GoGlobalState{}, errors.New("machine entered errored state during attempted validation")
+		return validator.GoGlobalState{}, v.erroredMachineError(entryId, mach, serialize)
+	}
+	stepCount := mach.GetStepCount()
+	if stepCount < config.MinSteps || (config.MaxSteps > 0 && stepCount > config.MaxSteps) {
+		return validator.GoGlobalState{}, fmt.Errorf("%w: block %d halted after %d steps, min steps %d max steps %d", ErrStepCountOutOfBounds, entryId, stepCount, config.MinSteps, config.MaxSteps)
 	}
 	return mach.GetGlobalState(), nil
 }
@@ -0,0 +1,148 @@
+package server_arb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/validator"
+)
+
+type erroredMachine struct {
+	mockMachine
+	steps uint64
+}
+
+func (m *erroredMachine) IsRunning() bool      { return false }
+func (m *erroredMachine) IsErrored() bool      { return true }
+func (m *erroredMachine) GetStepCount() uint64 { return m.steps }
+
+func TestErroredMachineDiagnostics(t *testing.T) {
+	config := DefaultArbitratorSpawnerConfig
+	config.OutputPath = t.TempDir()
+	spawner := &ArbitratorSpawner{config: func() *ArbitratorSpawnerConfig { return &config }}
+	gs := validator.GoGlobalState{Batch: 3, PosInBatch: 7}
+	mach := &erroredMachine{mockMachine: mockMachine{gs: gs}, steps: 1234}
+	var dumped string
+	serialize := func(path string) error {
+		dumped = path
+		return os.WriteFile(path, []byte("machine"), 0o600)
+	}
+
+	err := spawner.erroredMachineError(5, mach, serialize)
+	var erroredErr *MachineErroredError
+	if !errors.As(err, &erroredErr) {
+		t.Fatalf("expected MachineErroredError, got: %v", err)
+	}
+	if erroredErr.GlobalState != gs || erroredErr.StepCount != 1234 {
+		t.Fatalf("unexpected diagnostics, global state: %v steps: %d", erroredErr.GlobalState, erroredErr.StepCount)
+	}
+	if erroredErr.DumpPath != "" || dumped != "" {
+		t.Fatal("machine state shouldn't be dumped unless configured")
+	}
+
+	config.DumpErroredMachines = true
+	err = spawner.erroredMachineError(5, mach, serialize)
+	if !errors.As(err, &erroredErr) {
+		t.Fatalf("expected MachineErroredError, got: %v", err)
+	}
+	if erroredErr.DumpPath == "" || erroredErr.DumpPath != dumped || filepath.Dir(dumped) != config.OutputPath {
+		t.Fatalf("unexpected dump path %s, machine dumped to %s", erroredErr.DumpPath, dumped)
+	}
+	if _, err := os.Stat(erroredErr.DumpPath); err != nil {
+		t.Fatalf("machine dump not found: %v", err)
+	}
+
+	// Failing to dump the machine still returns the diagnostics
+	err = spawner.erroredMachineError(5, mach, func(string) error { return errors.New("dump failed") })
+	if !errors.As(err, &erroredErr) {
+		t.Fatalf("expected MachineErroredError, got: %v", err)
+	}
+	if erroredErr.DumpPath != "" || erroredErr.StepCount != 1234 {
+		t.Fatalf("unexpected diagnostics after failed dump: %v", erroredErr)
+	}
+}
+
+func TestBlockHeaderPreimageResolution(t *testing.T) {
+	header := &types.Header{Number: big.NewInt(42), Difficulty: big.NewInt(1), Time: 1234}
+	entry := &validator.ValidationInput{Preimages: make(map[arbutil.PreimageType]map[common.Hash][]byte)}
+
+	spawner := &ArbitratorSpawner{}
+	if _, err := spawner.preimageResolver(entry)(arbutil.Keccak256PreimageType, header.Hash()); err == nil {
+		t.Fatal("expected block hash preimage to be missing without supplied headers")
+	}
+
+	WithBlockHeaders(map[common.Hash]*types.Header{header.Hash(): header})(spawner)
+	resolver := spawner.preimageResolver(entry)
+	preimage, err := resolver(arbutil.Keccak256PreimageType, header.Hash())
+	if err != nil {
+		t.Fatalf("failed resolving block hash preimage: %v", err)
+	}
+	if crypto.Keccak256Hash(preimage) != header.Hash() {
+		t.Fatal("resolved preimage doesn't hash to the block hash")
+	}
+	if _, err := resolver(arbutil.Sha2_256PreimageType, header.Hash()); err == nil {
+		t.Fatal("block headers shouldn't resolve non-keccak preimages")
+	}
+
+	// Preimages carried by the validation input take precedence
+	entry.Preimages[arbutil.Keccak256PreimageType] = map[common.Hash][]byte{header.Hash(): []byte("recorded")}
+	preimage, err = resolver(arbutil.Keccak256PreimageType, header.Hash())
+	if err != nil || !bytes.Equal(preimage, []byte("recorded")) {
+		t.Fatalf("expected recorded preimage, got %q err: %v", preimage, err)
+	}
+}
+
+// stepCountingMachine halts after a fixed number of steps, reporting the steps it ran
+type stepCountingMachine struct {
+	mockMachine
+	halt  uint64
+	steps uint64
+}
+
+func (m *stepCountingMachine) Step(ctx context.Context, count uint64) error {
+	m.steps = min(m.steps+count, m.halt)
+	return nil
+}
+func (m *stepCountingMachine) IsRunning() bool      { return m.steps < m.halt }
+func (m *stepCountingMachine) GetStepCount() uint64 { return m.steps }
+
+func TestMachineStepCountBounds(t *testing.T) {
+	ctx := context.Background()
+	config := DefaultArbitratorSpawnerConfig
+	spawner := &ArbitratorSpawner{config: func() *ArbitratorSpawnerConfig { return &config }}
+	noSerialize := func(string) error { return errors.New("unexpected machine dump") }
+
+	// Unbounded by default
+	if _, err := spawner.runMachine(ctx, 1, common.Hash{}, &stepCountingMachine{}, noSerialize); err != nil {
+		t.Fatalf("unexpected error with default bounds: %v", err)
+	}
+
+	config.MinSteps = 10
+	_, err := spawner.runMachine(ctx, 1, common.Hash{}, &stepCountingMachine{}, noSerialize)
+	if !errors.Is(err, ErrStepCountOutOfBounds) {
+		t.Fatalf("expected ErrStepCountOutOfBounds for a machine halting at zero steps, got: %v", err)
+	}
+	if _, err := spawner.runMachine(ctx, 1, common.Hash{}, &stepCountingMachine{halt: 10}, noSerialize); err != nil {
+		t.Fatalf("unexpected error for a machine within bounds: %v", err)
+	}
+
+	config.MaxSteps = 100
+	mach := &stepCountingMachine{halt: 1000}
+	_, err = spawner.runMachine(ctx, 1, common.Hash{}, mach, noSerialize)
+	if !errors.Is(err, ErrStepCountOutOfBounds) {
+		t.Fatalf("expected ErrStepCountOutOfBounds for a machine exceeding max steps, got: %v", err)
+	}
+	if mach.steps > config.MaxSteps+1 {
+		t.Fatalf("machine ran %d steps past the max steps", mach.steps-config.MaxSteps)
+	}
+}
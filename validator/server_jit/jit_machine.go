@@ -202,24 +202,25 @@ func (machine *JitMachine) prove(
 	}
 
 	// send known preimages
-	preimageTypes := entry.Preimages
-	if err := writeIntAsUint32(len(preimageTypes)); err != nil {
+	preimageCounts := entry.PreimageCounts()
+	if err := writeIntAsUint32(len(preimageCounts)); err != nil {
 		return state, err
 	}
-	for ty, preimages := range preimageTypes {
+	for ty, count := range preimageCounts {
 		if err := writeUint8(uint8(ty)); err != nil {
 			return state, err
 		}
-		if err := writeIntAsUint32(len(preimages)); err != nil {
+		if err := writeIntAsUint32(count); err != nil {
 			return state, err
 		}
-		for hash, preimage := range preimages {
+		err := entry.ForEachPreimage(ty, func(hash common.Hash, preimage []byte) error {
 			if err := writeExact(hash[:]); err != nil {
-				return state, err
-			}
-			if err := writeBytes(preimage); err != nil {
-				return state, err
+				return err
 			}
+			return writeBytes(preimage)
+		})
+		if err != nil {
+			return state, err
 		}
 	}
 
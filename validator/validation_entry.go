@@ -1,25 +1,80 @@
 package validator
 
 import (
+	"errors"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethdb"
 
 	"github.com/offchainlabs/nitro/arbutil"
 )
 
+var ErrPreimageNotFound = errors.New("preimage not found")
+
 type BatchInfo struct {
 	Number uint64
 	Data   []byte
 }
 
+// PreimageReader provides preimages held outside of a ValidationInput's Preimages map, e.g. spilled to disk
+type PreimageReader interface {
+	ReadPreimage(ty arbutil.PreimageType, hash common.Hash) ([]byte, error)
+	// PreimageCounts returns the number of held preimages of each type
+	PreimageCounts() map[arbutil.PreimageType]int
+	// ForEachPreimage calls fn with each held preimage of the type, reading them one at a time
+	ForEachPreimage(ty arbutil.PreimageType, fn func(hash common.Hash, preimage []byte) error) error
+}
+
 type ValidationInput struct {
 	Id            uint64
 	HasDelayedMsg bool
 	DelayedMsgNr  uint64
 	Preimages     map[arbutil.PreimageType]map[common.Hash][]byte
-	UserWasms     map[ethdb.WasmTarget]map[common.Hash][]byte
-	BatchInfo     []BatchInfo
-	DelayedMsg    []byte
-	StartState    GoGlobalState
-	DebugChain    bool
+	// SpilledPreimages holds the preimages that didn't fit the recording memory budget, nil if none did
+	SpilledPreimages PreimageReader
+	UserWasms        map[ethdb.WasmTarget]map[common.Hash][]byte
+	BatchInfo        []BatchInfo
+	DelayedMsg       []byte
+	StartState       GoGlobalState
+	DebugChain       bool
+}
+
+// ReadPreimage looks the preimage up in the Preimages map, falling back to the spilled preimages
+func (i *ValidationInput) ReadPreimage(ty arbutil.PreimageType, hash common.Hash) ([]byte, error) {
+	if preimage, ok := i.Preimages[ty][hash]; ok {
+		return preimage, nil
+	}
+	if i.SpilledPreimages != nil {
+		return i.SpilledPreimages.ReadPreimage(ty, hash)
+	}
+	return nil, ErrPreimageNotFound
+}
+
+// PreimageCounts returns the number of preimages of each type, both in the Preimages map and spilled
+func (i *ValidationInput) PreimageCounts() map[arbutil.PreimageType]int {
+	counts := make(map[arbutil.PreimageType]int, len(i.Preimages))
+	for ty, preimages := range i.Preimages {
+		counts[ty] = len(preimages)
+	}
+	if i.SpilledPreimages != nil {
+		for ty, count := range i.SpilledPreimages.PreimageCounts() {
+			counts[ty] += count
+		}
+	}
+	return counts
+}
+
+// ForEachPreimage calls fn with each preimage of the type, first from the Preimages map and then the spilled ones.
+// Spilled preimages are read one at a time, so that consumers sending all preimages, like remote or jit validators,
+// don't need to load them all into memory
+func (i *ValidationInput) ForEachPreimage(ty arbutil.PreimageType, fn func(hash common.Hash, preimage []byte) error) error {
+	for hash, preimage := range i.Preimages[ty] {
+		if err := fn(hash, preimage); err != nil {
+			return err
+		}
+	}
+	if i.SpilledPreimages != nil {
+		return i.SpilledPreimages.ForEachPreimage(ty, fn)
+	}
+	return nil
 }